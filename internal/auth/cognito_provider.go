@@ -0,0 +1,438 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"dwell/internal/config"
+	"dwell/internal/domain"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// userInfoTTL is how long user attributes looked up for an access token
+	// are reused
+	userInfoTTL = 5 * time.Minute
+	// maxCachedUserInfo bounds the user attribute cache
+	maxCachedUserInfo = 10000
+)
+
+// CognitoClient is the part of the Cognito user pool API the provider uses
+type CognitoClient interface {
+	SignUp(ctx context.Context, params *cognitoidentityprovider.SignUpInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.SignUpOutput, error)
+	ConfirmSignUp(ctx context.Context, params *cognitoidentityprovider.ConfirmSignUpInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ConfirmSignUpOutput, error)
+	ResendConfirmationCode(ctx context.Context, params *cognitoidentityprovider.ResendConfirmationCodeInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ResendConfirmationCodeOutput, error)
+	InitiateAuth(ctx context.Context, params *cognitoidentityprovider.InitiateAuthInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.InitiateAuthOutput, error)
+	GlobalSignOut(ctx context.Context, params *cognitoidentityprovider.GlobalSignOutInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GlobalSignOutOutput, error)
+	AdminUserGlobalSignOut(ctx context.Context, params *cognitoidentityprovider.AdminUserGlobalSignOutInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminUserGlobalSignOutOutput, error)
+	AdminDeleteUser(ctx context.Context, params *cognitoidentityprovider.AdminDeleteUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDeleteUserOutput, error)
+	GetUser(ctx context.Context, params *cognitoidentityprovider.GetUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GetUserOutput, error)
+	ListUsers(ctx context.Context, params *cognitoidentityprovider.ListUsersInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersOutput, error)
+}
+
+// CognitoProvider keeps accounts in a Cognito user pool
+type CognitoProvider struct {
+	client     CognitoClient
+	clientID   string
+	userPoolID string
+	verifier   *CognitoVerifier
+	userInfo   userInfoCache
+}
+
+// userInfoCache holds user attributes looked up from Cognito, keyed by the
+// user's sub
+type userInfoCache struct {
+	mu      sync.Mutex
+	entries map[string]userInfoCacheEntry
+}
+
+type userInfoCacheEntry struct {
+	info    *domain.UserInfo
+	expires time.Time
+}
+
+// NewCognitoProvider returns a provider for the configured user pool and app
+// client. Tokens are checked against the user pool's signing keys, or the
+// key set named by COGNITO_JWKS_FILE or COGNITO_JWKS_URL.
+func NewCognitoProvider(client CognitoClient, cfg *config.CognitoConfig) *CognitoProvider {
+	issuer := CognitoIssuer(cfg.Region, cfg.UserPoolID)
+	var keys *KeySet
+	switch {
+	case cfg.JWKSFile != "":
+		keys = NewFileKeySet(cfg.JWKSFile)
+	case cfg.JWKSURL != "":
+		keys = NewRemoteKeySet(cfg.JWKSURL, nil)
+	default:
+		keys = NewRemoteKeySet(issuer+"/.well-known/jwks.json", nil)
+	}
+	return &CognitoProvider{
+		client:     client,
+		clientID:   cfg.ClientID,
+		userPoolID: cfg.UserPoolID,
+		verifier:   NewCognitoVerifier(issuer, cfg.ClientID, keys),
+	}
+}
+
+// SignUp creates a user in the user pool; Cognito emails the confirmation code
+func (p *CognitoProvider) SignUp(ctx context.Context, user *NewUser) (string, error) {
+	attributes := []types.AttributeType{
+		{Name: awssdk.String("email"), Value: awssdk.String(user.Email)},
+		{Name: awssdk.String("given_name"), Value: awssdk.String(user.FirstName)},
+		{Name: awssdk.String("family_name"), Value: awssdk.String(user.LastName)},
+		{Name: awssdk.String("phone_number"), Value: awssdk.String(user.Phone)},
+		{Name: awssdk.String("custom:user_type"), Value: awssdk.String(user.UserType)},
+		{Name: awssdk.String("custom:company_name"), Value: awssdk.String(user.CompanyName)},
+	}
+	if user.LandlordID != nil {
+		attributes = append(attributes, types.AttributeType{
+			Name:  awssdk.String("custom:landlord_id"),
+			Value: awssdk.String(user.LandlordID.String()),
+		})
+	}
+
+	result, err := p.client.SignUp(ctx, &cognitoidentityprovider.SignUpInput{
+		ClientId:       awssdk.String(p.clientID),
+		Username:       awssdk.String(user.Email),
+		Password:       awssdk.String(user.Password),
+		UserAttributes: attributes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign up user: %w", cognitoError(err))
+	}
+	return awssdk.ToString(result.UserSub), nil
+}
+
+// ConfirmSignUp confirms a user with the code Cognito emailed
+func (p *CognitoProvider) ConfirmSignUp(ctx context.Context, email, code string) error {
+	_, err := p.client.ConfirmSignUp(ctx, &cognitoidentityprovider.ConfirmSignUpInput{
+		ClientId:         awssdk.String(p.clientID),
+		Username:         awssdk.String(email),
+		ConfirmationCode: awssdk.String(code),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to confirm signup: %w", cognitoError(err))
+	}
+	return nil
+}
+
+// ResendConfirmationCode has Cognito email a new confirmation code. Unknown
+// emails are ignored, as the local provider does.
+func (p *CognitoProvider) ResendConfirmationCode(ctx context.Context, email string) error {
+	_, err := p.client.ResendConfirmationCode(ctx, &cognitoidentityprovider.ResendConfirmationCodeInput{
+		ClientId: awssdk.String(p.clientID),
+		Username: awssdk.String(email),
+	})
+	var notFound *types.UserNotFoundException
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resend confirmation code: %w", cognitoError(err))
+	}
+	return nil
+}
+
+// SignIn authenticates with the user password flow
+func (p *CognitoProvider) SignIn(ctx context.Context, email, password string) (*Session, error) {
+	result, err := p.client.InitiateAuth(ctx, &cognitoidentityprovider.InitiateAuthInput{
+		ClientId: awssdk.String(p.clientID),
+		AuthFlow: types.AuthFlowTypeUserPasswordAuth,
+		AuthParameters: map[string]string{
+			"USERNAME": email,
+			"PASSWORD": password,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign in: %w", cognitoError(err))
+	}
+	return p.session(ctx, result)
+}
+
+// Refresh issues a new access token; Cognito does not rotate refresh tokens
+func (p *CognitoProvider) Refresh(ctx context.Context, refreshToken string) (*Session, error) {
+	result, err := p.client.InitiateAuth(ctx, &cognitoidentityprovider.InitiateAuthInput{
+		ClientId: awssdk.String(p.clientID),
+		AuthFlow: types.AuthFlowTypeRefreshTokenAuth,
+		AuthParameters: map[string]string{
+			"REFRESH_TOKEN": refreshToken,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", cognitoError(err))
+	}
+	return p.session(ctx, result)
+}
+
+// session reads the tokens of a completed authentication and looks up who
+// they belong to
+func (p *CognitoProvider) session(ctx context.Context, result *cognitoidentityprovider.InitiateAuthOutput) (*Session, error) {
+	if result.AuthenticationResult == nil {
+		// A challenge such as NEW_PASSWORD_REQUIRED is pending
+		return nil, fmt.Errorf("sign in requires completing the %s challenge", result.ChallengeName)
+	}
+	tokens := result.AuthenticationResult
+	accessToken := awssdk.ToString(tokens.AccessToken)
+
+	userInfo, err := p.getUserInfo(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AccessToken:  accessToken,
+		RefreshToken: awssdk.ToString(tokens.RefreshToken),
+		ExpiresIn:    int(tokens.ExpiresIn),
+		UserID:       userInfo.UserID,
+		UserType:     userInfo.UserType,
+	}, nil
+}
+
+// SignOut signs the user out of every device
+func (p *CognitoProvider) SignOut(ctx context.Context, accessToken string) error {
+	_, err := p.client.GlobalSignOut(ctx, &cognitoidentityprovider.GlobalSignOutInput{
+		AccessToken: awssdk.String(accessToken),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign out: %w", cognitoError(err))
+	}
+	return nil
+}
+
+// RevokeUser signs a user out of every device. Cognito revokes their refresh
+// tokens, but access and ID tokens stay valid until they expire, so callers
+// must also stop honouring them.
+func (p *CognitoProvider) RevokeUser(ctx context.Context, userID string) error {
+	_, err := p.client.AdminUserGlobalSignOut(ctx, &cognitoidentityprovider.AdminUserGlobalSignOutInput{
+		UserPoolId: awssdk.String(p.userPoolID),
+		Username:   awssdk.String(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign out user: %w", cognitoError(err))
+	}
+	p.userInfo.remove(userID)
+	return nil
+}
+
+// DeleteUser deletes a user from the user pool
+func (p *CognitoProvider) DeleteUser(ctx context.Context, userID string) error {
+	_, err := p.client.AdminDeleteUser(ctx, &cognitoidentityprovider.AdminDeleteUserInput{
+		UserPoolId: awssdk.String(p.userPoolID),
+		Username:   awssdk.String(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", cognitoError(err))
+	}
+	p.userInfo.remove(userID)
+	return nil
+}
+
+// FindUser looks up the confirmed user of the pool with a verified email
+// address. Unverified addresses are ignored, since users can set them freely.
+func (p *CognitoProvider) FindUser(ctx context.Context, email string) (*domain.UserInfo, error) {
+	email = strings.TrimSpace(email)
+	result, err := p.client.ListUsers(ctx, &cognitoidentityprovider.ListUsersInput{
+		UserPoolId: awssdk.String(p.userPoolID),
+		Filter:     awssdk.String(fmt.Sprintf("email = %q", email)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", cognitoError(err))
+	}
+
+	var found *domain.UserInfo
+	for _, user := range result.Users {
+		if user.UserStatus != types.UserStatusTypeConfirmed {
+			continue
+		}
+		info := &domain.UserInfo{UserID: awssdk.ToString(user.Username)}
+		verified := false
+		for _, attr := range user.Attributes {
+			switch awssdk.ToString(attr.Name) {
+			case "sub":
+				info.UserID = awssdk.ToString(attr.Value)
+			case "email_verified":
+				verified = awssdk.ToString(attr.Value) == "true"
+			case "custom:user_type":
+				info.UserType = awssdk.ToString(attr.Value)
+			case "custom:landlord_id":
+				if id, err := uuid.Parse(awssdk.ToString(attr.Value)); err == nil {
+					info.LandlordID = &id
+				}
+			}
+		}
+		if !verified {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("more than one confirmed user has the email %s", email)
+		}
+		found = info
+	}
+	if found == nil {
+		return nil, ErrUserNotFound
+	}
+	return found, nil
+}
+
+// VerifyToken verifies a Cognito access or ID token and returns the caller's
+// claims. The user type comes from the user's groups or the custom:user_type
+// attribute. Access tokens carry no custom attributes unless a pre token
+// generation trigger adds them, so missing ones are looked up from Cognito
+// and cached briefly.
+func (p *CognitoProvider) VerifyToken(ctx context.Context, tokenString string) (*domain.UserClaims, error) {
+	claims, err := p.verifier.Verify(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	userClaims := &domain.UserClaims{
+		UserID:    claims.Subject,
+		UserType:  claims.ResolvedUserType(),
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if claims.LandlordID != "" {
+		id, err := uuid.Parse(claims.LandlordID)
+		if err != nil {
+			return nil, fmt.Errorf("token has an invalid landlord ID: %w", err)
+		}
+		userClaims.LandlordID = &id
+	}
+
+	if claims.TokenUse == TokenUseAccess && (userClaims.UserType == "" || userClaims.LandlordID == nil) {
+		info, err := p.cachedUserInfo(ctx, claims, tokenString)
+		if err != nil {
+			return nil, err
+		}
+		if userClaims.UserType == "" {
+			userClaims.UserType = info.UserType
+		}
+		if userClaims.LandlordID == nil {
+			userClaims.LandlordID = info.LandlordID
+		}
+	}
+	return userClaims, nil
+}
+
+// cachedUserInfo returns the attributes of an access token's user, looking
+// them up at most once per userInfoTTL or token lifetime
+func (p *CognitoProvider) cachedUserInfo(ctx context.Context, claims *CognitoClaims, accessToken string) (*domain.UserInfo, error) {
+	now := time.Now()
+	if info, ok := p.userInfo.get(claims.Subject, now); ok {
+		return info, nil
+	}
+
+	info, err := p.getUserInfo(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	expires := now.Add(userInfoTTL)
+	if claims.ExpiresAt.Time.Before(expires) {
+		expires = claims.ExpiresAt.Time
+	}
+	p.userInfo.put(claims.Subject, info, expires, now)
+	return info, nil
+}
+
+// getUserInfo retrieves user information from Cognito
+func (p *CognitoProvider) getUserInfo(ctx context.Context, accessToken string) (*domain.UserInfo, error) {
+	result, err := p.client.GetUser(ctx, &cognitoidentityprovider.GetUserInput{
+		AccessToken: awssdk.String(accessToken),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", cognitoError(err))
+	}
+
+	userInfo := &domain.UserInfo{
+		UserID: awssdk.ToString(result.Username),
+	}
+
+	// Extract user attributes. The sub identifies the user in tokens, so it
+	// is preferred over the username.
+	for _, attr := range result.UserAttributes {
+		switch awssdk.ToString(attr.Name) {
+		case "sub":
+			userInfo.UserID = awssdk.ToString(attr.Value)
+		case "custom:user_type":
+			userInfo.UserType = awssdk.ToString(attr.Value)
+		case "custom:landlord_id":
+			if id, err := uuid.Parse(awssdk.ToString(attr.Value)); err == nil {
+				userInfo.LandlordID = &id
+			}
+		}
+	}
+
+	return userInfo, nil
+}
+
+// cognitoError wraps the Cognito exceptions callers act on in the matching
+// provider error, keeping Cognito's message
+func cognitoError(err error) error {
+	var (
+		exists       *types.UsernameExistsException
+		mismatch     *types.CodeMismatchException
+		expired      *types.ExpiredCodeException
+		notConfirmed *types.UserNotConfirmedException
+		notAuth      *types.NotAuthorizedException
+		notFound     *types.UserNotFoundException
+		password     *types.InvalidPasswordException
+	)
+	switch {
+	case errors.As(err, &exists):
+		return fmt.Errorf("%w: %v", ErrUserExists, err)
+	case errors.As(err, &mismatch), errors.As(err, &expired):
+		return fmt.Errorf("%w: %v", ErrInvalidCode, err)
+	case errors.As(err, &notConfirmed):
+		return fmt.Errorf("%w: %v", ErrNotConfirmed, err)
+	case errors.As(err, &notAuth), errors.As(err, &notFound):
+		return fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	case errors.As(err, &password):
+		return fmt.Errorf("%w: %v", ErrInvalidPassword, err)
+	}
+	return err
+}
+
+// get returns a user's unexpired attributes
+func (c *userInfoCache) get(sub string, now time.Time) (*domain.UserInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[sub]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	return entry.info, true
+}
+
+// remove drops a user's cached attributes
+func (c *userInfoCache) remove(sub string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, sub)
+}
+
+// put caches a user's attributes. When the cache is full, expired entries
+// are dropped, and if that is not enough the cache starts over.
+func (c *userInfoCache) put(sub string, info *domain.UserInfo, expires, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]userInfoCacheEntry)
+	}
+
+	if _, ok := c.entries[sub]; !ok && len(c.entries) >= maxCachedUserInfo {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxCachedUserInfo {
+			clear(c.entries)
+		}
+	}
+	c.entries[sub] = userInfoCacheEntry{info: info, expires: expires}
+}
@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"dwell/internal/config"
+	"dwell/internal/domain"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// fakeCognito records sign-ups and fails calls with err. Methods a test does
+// not use panic through the nil embedded interface.
+type fakeCognito struct {
+	CognitoClient
+	err       error
+	signUp    *cognitoidentityprovider.SignUpInput
+	signedOut *cognitoidentityprovider.AdminUserGlobalSignOutInput
+	deleted   *cognitoidentityprovider.AdminDeleteUserInput
+	listed    *cognitoidentityprovider.ListUsersInput
+	users     []types.UserType
+}
+
+func (f *fakeCognito) SignUp(_ context.Context, params *cognitoidentityprovider.SignUpInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.SignUpOutput, error) {
+	f.signUp = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.SignUpOutput{UserSub: awssdk.String("new-sub")}, nil
+}
+
+func (f *fakeCognito) ConfirmSignUp(context.Context, *cognitoidentityprovider.ConfirmSignUpInput, ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ConfirmSignUpOutput, error) {
+	return nil, f.err
+}
+
+func (f *fakeCognito) ResendConfirmationCode(context.Context, *cognitoidentityprovider.ResendConfirmationCodeInput, ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ResendConfirmationCodeOutput, error) {
+	return nil, f.err
+}
+
+func (f *fakeCognito) AdminUserGlobalSignOut(_ context.Context, params *cognitoidentityprovider.AdminUserGlobalSignOutInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminUserGlobalSignOutOutput, error) {
+	f.signedOut = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.AdminUserGlobalSignOutOutput{}, nil
+}
+
+func (f *fakeCognito) AdminDeleteUser(_ context.Context, params *cognitoidentityprovider.AdminDeleteUserInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDeleteUserOutput, error) {
+	f.deleted = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.AdminDeleteUserOutput{}, nil
+}
+
+func (f *fakeCognito) ListUsers(_ context.Context, params *cognitoidentityprovider.ListUsersInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersOutput, error) {
+	f.listed = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognitoidentityprovider.ListUsersOutput{Users: f.users}, nil
+}
+
+func (f *fakeCognito) InitiateAuth(context.Context, *cognitoidentityprovider.InitiateAuthInput, ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.InitiateAuthOutput, error) {
+	return nil, f.err
+}
+
+func testCognitoConfig(jwksFile string) *config.CognitoConfig {
+	return &config.CognitoConfig{UserPoolID: "us-east-1_test", ClientID: testClientID, Region: "us-east-1", JWKSFile: jwksFile}
+}
+
+func TestCognitoProviderSignUp(t *testing.T) {
+	client := &fakeCognito{}
+	provider := NewCognitoProvider(client, testCognitoConfig(""))
+	landlordID := uuid.New()
+
+	userID, err := provider.SignUp(context.Background(), &NewUser{Email: "tenant@example.com", Password: "password123", UserType: "tenant", LandlordID: &landlordID})
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	if userID != "new-sub" {
+		t.Errorf("SignUp() = %q, want the user sub", userID)
+	}
+	var found bool
+	for _, attr := range client.signUp.UserAttributes {
+		if awssdk.ToString(attr.Name) == "custom:landlord_id" {
+			found = awssdk.ToString(attr.Value) == landlordID.String()
+		}
+	}
+	if !found {
+		t.Error("SignUp() did not set custom:landlord_id from the invitation")
+	}
+}
+
+func TestCognitoProviderRevokeUser(t *testing.T) {
+	client := &fakeCognito{}
+	provider := NewCognitoProvider(client, testCognitoConfig(""))
+	landlordID := uuid.New()
+	provider.userInfo.put("staff-sub", &domain.UserInfo{UserID: "staff-sub", UserType: "landlord", LandlordID: &landlordID}, time.Now().Add(time.Minute), time.Now())
+
+	if err := provider.RevokeUser(context.Background(), "staff-sub"); err != nil {
+		t.Fatalf("RevokeUser() error = %v", err)
+	}
+	if client.signedOut == nil || awssdk.ToString(client.signedOut.UserPoolId) != "us-east-1_test" || awssdk.ToString(client.signedOut.Username) != "staff-sub" {
+		t.Errorf("AdminUserGlobalSignOut input = %+v", client.signedOut)
+	}
+	if _, ok := provider.userInfo.get("staff-sub", time.Now()); ok {
+		t.Error("RevokeUser() kept the user's cached attributes")
+	}
+}
+
+func TestCognitoProviderDeleteUser(t *testing.T) {
+	client := &fakeCognito{}
+	provider := NewCognitoProvider(client, testCognitoConfig(""))
+
+	if err := provider.DeleteUser(context.Background(), "orphan-sub"); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if client.deleted == nil || awssdk.ToString(client.deleted.UserPoolId) != "us-east-1_test" || awssdk.ToString(client.deleted.Username) != "orphan-sub" {
+		t.Errorf("AdminDeleteUser input = %+v", client.deleted)
+	}
+}
+
+func TestCognitoProviderFindUser(t *testing.T) {
+	landlordID := uuid.New()
+	user := func(sub, verified string, status types.UserStatusType) types.UserType {
+		return types.UserType{
+			Username:   awssdk.String(sub),
+			UserStatus: status,
+			Attributes: []types.AttributeType{
+				{Name: awssdk.String("sub"), Value: awssdk.String(sub)},
+				{Name: awssdk.String("email_verified"), Value: awssdk.String(verified)},
+				{Name: awssdk.String("custom:user_type"), Value: awssdk.String("landlord")},
+				{Name: awssdk.String("custom:landlord_id"), Value: awssdk.String(landlordID.String())},
+			},
+		}
+	}
+
+	client := &fakeCognito{users: []types.UserType{
+		user("unverified-sub", "false", types.UserStatusTypeConfirmed),
+		user("unconfirmed-sub", "true", types.UserStatusTypeUnconfirmed),
+		user("owner-sub", "true", types.UserStatusTypeConfirmed),
+	}}
+	provider := NewCognitoProvider(client, testCognitoConfig(""))
+
+	info, err := provider.FindUser(context.Background(), " owner@example.com ")
+	if err != nil {
+		t.Fatalf("FindUser() error = %v", err)
+	}
+	if info.UserID != "owner-sub" || info.UserType != "landlord" || info.LandlordID == nil || *info.LandlordID != landlordID {
+		t.Errorf("FindUser() = %+v, want the confirmed user with a verified email", info)
+	}
+	if awssdk.ToString(client.listed.Filter) != `email = "owner@example.com"` || awssdk.ToString(client.listed.UserPoolId) != "us-east-1_test" {
+		t.Errorf("ListUsers input = %+v", client.listed)
+	}
+
+	client.users = client.users[:2]
+	if _, err := provider.FindUser(context.Background(), "owner@example.com"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("FindUser(unverified) error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestCognitoProviderErrors(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		err  error
+		call func(p *CognitoProvider) error
+		want error
+	}{
+		{"email taken", &types.UsernameExistsException{}, func(p *CognitoProvider) error {
+			_, err := p.SignUp(ctx, &NewUser{Email: "a@example.com"})
+			return err
+		}, ErrUserExists},
+		{"weak password", &types.InvalidPasswordException{}, func(p *CognitoProvider) error {
+			_, err := p.SignUp(ctx, &NewUser{Email: "a@example.com"})
+			return err
+		}, ErrInvalidPassword},
+		{"wrong code", &types.CodeMismatchException{}, func(p *CognitoProvider) error {
+			return p.ConfirmSignUp(ctx, "a@example.com", "000000")
+		}, ErrInvalidCode},
+		{"expired code", &types.ExpiredCodeException{}, func(p *CognitoProvider) error {
+			return p.ConfirmSignUp(ctx, "a@example.com", "000000")
+		}, ErrInvalidCode},
+		{"wrong password", &types.NotAuthorizedException{}, func(p *CognitoProvider) error {
+			_, err := p.SignIn(ctx, "a@example.com", "password123")
+			return err
+		}, ErrInvalidCredentials},
+		{"unconfirmed", &types.UserNotConfirmedException{}, func(p *CognitoProvider) error {
+			_, err := p.SignIn(ctx, "a@example.com", "password123")
+			return err
+		}, ErrNotConfirmed},
+		{"resend to unknown email", &types.UserNotFoundException{}, func(p *CognitoProvider) error {
+			return p.ResendConfirmationCode(ctx, "nobody@example.com")
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(NewCognitoProvider(&fakeCognito{err: tt.err}, testCognitoConfig("")))
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCognitoProviderVerifyToken(t *testing.T) {
+	key := newTestKey(t)
+	provider := NewCognitoProvider(&fakeCognito{}, testCognitoConfig(writeKeySet(t, keySetJSON(t, map[string]*rsa.PrivateKey{"k1": key}))))
+	landlordID := uuid.New()
+
+	// ID tokens carry the custom attributes
+	idToken := accessClaims()
+	delete(idToken, "client_id")
+	idToken["token_use"] = TokenUseID
+	idToken["aud"] = testClientID
+	idToken["cognito:groups"] = nil
+	idToken["custom:user_type"] = "tenant"
+	idToken["custom:landlord_id"] = landlordID.String()
+	claims, err := provider.VerifyToken(context.Background(), signToken(t, key, "k1", idToken))
+	if err != nil {
+		t.Fatalf("VerifyToken(id token) error = %v", err)
+	}
+	if claims.UserID != "6f1c2a9e-user" || claims.UserType != "tenant" || claims.LandlordID == nil || *claims.LandlordID != landlordID {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	// Access tokens only carry groups; the landlord comes from the user's
+	// attributes, cached here in place of a Cognito lookup
+	provider.userInfo.put("6f1c2a9e-user", &domain.UserInfo{UserID: "6f1c2a9e-user", UserType: "tenant", LandlordID: &landlordID}, time.Now().Add(time.Minute), time.Now())
+	access := accessClaims()
+	access["cognito:groups"] = []string{"landlord"}
+	claims, err = provider.VerifyToken(context.Background(), signToken(t, key, "k1", access))
+	if err != nil {
+		t.Fatalf("VerifyToken(access token) error = %v", err)
+	}
+	if claims.UserType != "landlord" || claims.LandlordID == nil || *claims.LandlordID != landlordID {
+		t.Errorf("claims = %+v, want the landlord group and cached landlord ID", claims)
+	}
+
+	other := accessClaims()
+	other["client_id"] = "someone-else"
+	if _, err := provider.VerifyToken(context.Background(), signToken(t, key, "k1", other)); err == nil {
+		t.Error("VerifyToken() accepted a token for another app client")
+	}
+	if _, err := provider.VerifyToken(context.Background(), signToken(t, key, "k1", jwt.MapClaims{"token_use": "access"})); err == nil {
+		t.Error("VerifyToken() accepted a token without issuer or expiry")
+	}
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_test"
+	testClientID = "test-client"
+)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return key
+}
+
+// keySetJSON renders the public halves of keys as a JSON Web Key Set
+func keySetJSON(t *testing.T, keys map[string]*rsa.PrivateKey) []byte {
+	t.Helper()
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range keys {
+		doc.Keys = append(doc.Keys, jsonWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return data
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return signed
+}
+
+func accessClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":            "6f1c2a9e-user",
+		"iss":            testIssuer,
+		"client_id":      testClientID,
+		"token_use":      TokenUseAccess,
+		"username":       "6f1c2a9e-user",
+		"cognito:groups": []string{"tenant"},
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func writeKeySet(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestCognitoVerifierVerify(t *testing.T) {
+	key := newTestKey(t)
+	other := newTestKey(t)
+	verifier := NewCognitoVerifier(testIssuer, testClientID, NewFileKeySet(writeKeySet(t, keySetJSON(t, map[string]*rsa.PrivateKey{"k1": key}))))
+
+	with := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := accessClaims()
+		for k, v := range changes {
+			if v == nil {
+				delete(claims, k)
+			} else {
+				claims[k] = v
+			}
+		}
+		return claims
+	}
+	idToken := with(jwt.MapClaims{"token_use": TokenUseID, "client_id": nil, "aud": testClientID, "custom:user_type": "landlord"})
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims()).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"access token", signToken(t, key, "k1", accessClaims()), ""},
+		{"id token", signToken(t, key, "k1", idToken), ""},
+		{"wrong issuer", signToken(t, key, "k1", with(jwt.MapClaims{"iss": "https://cognito-idp.us-east-1.amazonaws.com/other"})), "invalid issuer"},
+		{"other app client", signToken(t, key, "k1", with(jwt.MapClaims{"client_id": "other"})), "another app client"},
+		{"id token for other app client", signToken(t, key, "k1", with(jwt.MapClaims{"token_use": TokenUseID, "aud": "other"})), "another app client"},
+		{"refresh token use", signToken(t, key, "k1", with(jwt.MapClaims{"token_use": "refresh"})), "unexpected token use"},
+		{"expired", signToken(t, key, "k1", with(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})), "expired"},
+		{"no expiry", signToken(t, key, "k1", with(jwt.MapClaims{"exp": nil})), "exp claim is required"},
+		{"no subject", signToken(t, key, "k1", with(jwt.MapClaims{"sub": nil})), "no subject"},
+		{"signed by another key", signToken(t, other, "k1", accessClaims()), "verification error"},
+		{"unknown key ID", signToken(t, key, "k2", accessClaims()), "no signing key matches"},
+		{"HMAC signed", hmacToken, "signing method HS256 is invalid"},
+		{"garbage", "not-a-token", "malformed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Verify() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.Subject != "6f1c2a9e-user" {
+				t.Errorf("subject = %q", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestResolvedUserType(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims CognitoClaims
+		want   string
+	}{
+		{"group", CognitoClaims{Groups: []string{"tenant"}}, "tenant"},
+		{"group wins over attribute", CognitoClaims{Groups: []string{"tenant"}, UserType: "landlord"}, "tenant"},
+		{"landlord group first", CognitoClaims{Groups: []string{"tenant", "landlord"}}, "landlord"},
+		{"unrelated group", CognitoClaims{Groups: []string{"beta"}, UserType: "landlord"}, "landlord"},
+		{"neither", CognitoClaims{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claims.ResolvedUserType(); got != tt.want {
+				t.Errorf("ResolvedUserType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
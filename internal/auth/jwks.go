@@ -0,0 +1,219 @@
+// Package auth authenticates users. It manages accounts through a pluggable
+// identity provider and verifies the JSON Web Tokens that authenticate API
+// requests.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is returned when no key in the key set has a token's key ID
+var ErrUnknownKey = errors.New("no signing key matches key ID")
+
+const (
+	// keySetTTL is how long a loaded key set is used before it is reloaded
+	keySetTTL = time.Hour
+	// minKeySetReload bounds how often an unknown key ID can force a reload,
+	// so tokens with made-up key IDs cannot hammer the key server
+	minKeySetReload = time.Minute
+	// maxKeySetSize is the largest key set document read, in bytes
+	maxKeySetSize = 1 << 20
+	// keySetTimeout bounds a key set download
+	keySetTimeout = 10 * time.Second
+)
+
+// KeySet is a JSON Web Key Set of RSA signing keys, loaded from a URL or a
+// local file. Keys are cached, and a token signed with an unknown key ID
+// reloads the set so rotated keys are picked up. Only one reload runs at a
+// time, and cached keys are served while it does.
+type KeySet struct {
+	url        string
+	path       string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu       sync.Mutex
+	keys     map[string]*rsa.PublicKey
+	loadedAt time.Time
+	loading  *keySetLoad // the reload in progress, if any
+}
+
+// keySetLoad is a key set reload shared by every caller waiting on it
+type keySetLoad struct {
+	done chan struct{}
+	keys map[string]*rsa.PublicKey
+	err  error
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// NewRemoteKeySet returns a key set served at url, such as a Cognito user
+// pool's /.well-known/jwks.json. A nil httpClient uses a client with a
+// default timeout.
+func NewRemoteKeySet(url string, httpClient *http.Client) *KeySet {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: keySetTimeout}
+	}
+	return &KeySet{url: url, httpClient: httpClient, now: time.Now}
+}
+
+// NewFileKeySet returns a key set read from a JSON file, for running offline
+func NewFileKeySet(path string) *KeySet {
+	return &KeySet{path: path, now: time.Now}
+}
+
+// Key returns the public key with the given key ID
+func (k *KeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	now := k.now()
+	key, ok := k.keys[kid]
+	fresh := k.keys != nil && now.Sub(k.loadedAt) < keySetTTL
+	if ok && fresh {
+		k.mu.Unlock()
+		return key, nil
+	}
+	if fresh && now.Sub(k.loadedAt) < minKeySetReload {
+		k.mu.Unlock()
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	load := k.reload(now)
+	k.mu.Unlock()
+
+	if ok {
+		// Keep trusting the cached key while the set is refreshed, or while
+		// the key server is unreachable
+		return key, nil
+	}
+	select {
+	case <-load.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if load.err != nil {
+		return nil, load.err
+	}
+	key, ok = load.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// reload starts reloading the key set unless a reload is already running,
+// and returns it. The download is not tied to any one caller's context, so a
+// cancelled request does not fail the others waiting on it. k.mu must be held.
+func (k *KeySet) reload(now time.Time) *keySetLoad {
+	if k.loading != nil {
+		return k.loading
+	}
+	load := &keySetLoad{done: make(chan struct{})}
+	k.loading = load
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), keySetTimeout)
+		defer cancel()
+		load.keys, load.err = k.load(ctx)
+
+		k.mu.Lock()
+		if load.err == nil {
+			k.keys, k.loadedAt = load.keys, now
+		}
+		k.loading = nil
+		k.mu.Unlock()
+		close(load.done)
+	}()
+	return load
+}
+
+// load reads and parses the key set from its source
+func (k *KeySet) load(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	if k.path != "" {
+		data, err := os.ReadFile(k.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key set: %w", err)
+		}
+		return ParseKeySet(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build key set request: %w", err)
+	}
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("key set server returned %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySetSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key set: %w", err)
+	}
+	return ParseKeySet(data)
+}
+
+// ParseKeySet parses a JSON Web Key Set document, keeping its RSA signing
+// keys. Other key types are ignored.
+func ParseKeySet(data []byte) (map[string]*rsa.PublicKey, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse key set: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("key set has no RSA signing keys")
+	}
+	return keys, nil
+}
+
+// rsaPublicKey decodes the base64url modulus and exponent of an RSA key
+func (jwk *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if key.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("modulus is %d bits, want at least 2048", key.N.BitLen())
+	}
+	return key, nil
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRemoteKeySetRotation(t *testing.T) {
+	oldKey, newKey := newTestKey(t), newTestKey(t)
+	var current atomic.Value
+	current.Store(keySetJSON(t, map[string]*rsa.PrivateKey{"old": oldKey}))
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(current.Load().([]byte))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	keys := NewRemoteKeySet(server.URL, server.Client())
+	keys.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := keys.Key(ctx, "old"); err != nil {
+		t.Fatalf("Key(old) error = %v", err)
+	}
+	if _, err := keys.Key(ctx, "old"); err != nil || fetches.Load() != 1 {
+		t.Fatalf("cached Key(old) error = %v after %d fetches, want 1", err, fetches.Load())
+	}
+
+	// The pool rotates to a new key. Unknown key IDs only reload once a minute.
+	current.Store(keySetJSON(t, map[string]*rsa.PrivateKey{"new": newKey}))
+	if _, err := keys.Key(ctx, "new"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Key(new) within a minute error = %v, want ErrUnknownKey", err)
+	}
+	now = now.Add(minKeySetReload)
+	if _, err := keys.Key(ctx, "new"); err != nil {
+		t.Fatalf("Key(new) after rotation error = %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("fetched %d times, want 2", fetches.Load())
+	}
+	if _, err := keys.Key(ctx, "old"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Key(old) after rotation error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestRemoteKeySetKeepsCachedKeyWhenUnreachable(t *testing.T) {
+	key := newTestKey(t)
+	data := keySetJSON(t, map[string]*rsa.PrivateKey{"k1": key})
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	keys := NewRemoteKeySet(server.URL, server.Client())
+	keys.now = func() time.Time { return now }
+	if _, err := keys.Key(context.Background(), "k1"); err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+
+	down.Store(true)
+	now = now.Add(keySetTTL)
+	if _, err := keys.Key(context.Background(), "k1"); err != nil {
+		t.Errorf("Key() with the key server down error = %v, want the cached key", err)
+	}
+	if _, err := keys.Key(context.Background(), "k2"); err == nil || !strings.Contains(err.Error(), "returned 503") {
+		t.Errorf("Key(k2) error = %v, want the fetch failure", err)
+	}
+}
+
+func TestRemoteKeySetRefreshDoesNotBlock(t *testing.T) {
+	oldKey, newKey := newTestKey(t), newTestKey(t)
+	var current atomic.Value
+	current.Store(keySetJSON(t, map[string]*rsa.PrivateKey{"old": oldKey}))
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		w.Write(current.Load().([]byte))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	keys := NewRemoteKeySet(server.URL, server.Client())
+	keys.now = func() time.Time { return start }
+	ctx := context.Background()
+	if _, err := keys.Key(ctx, "old"); err != nil {
+		t.Fatalf("Key(old) error = %v", err)
+	}
+
+	// The set expires and the key server hangs on the reload
+	current.Store(keySetJSON(t, map[string]*rsa.PrivateKey{"old": oldKey, "new": newKey}))
+	keys.now = func() time.Time { return start.Add(keySetTTL) }
+
+	const waiters = 5
+	results := make(chan error, waiters)
+	for range waiters {
+		go func() {
+			_, err := keys.Key(ctx, "new")
+			results <- err
+		}()
+	}
+
+	// The cached key is served while the reload is stuck
+	done := make(chan error, 1)
+	go func() {
+		_, err := keys.Key(ctx, "old")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Key(old) during reload error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Key(old) blocked on the reload")
+	}
+
+	// A caller that gives up does not wait for the reload
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := keys.Key(cancelled, "new"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Key(new) with a cancelled context error = %v, want context.Canceled", err)
+	}
+
+	close(release)
+	for range waiters {
+		if err := <-results; err != nil {
+			t.Errorf("Key(new) error = %v", err)
+		}
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("fetched %d times, want 2", fetches.Load())
+	}
+}
+
+func TestParseKeySet(t *testing.T) {
+	key := newTestKey(t)
+	valid := keySetJSON(t, map[string]*rsa.PrivateKey{"k1": key})
+
+	keys, err := ParseKeySet(valid)
+	if err != nil {
+		t.Fatalf("ParseKeySet() error = %v", err)
+	}
+	if got := keys["k1"]; got == nil || got.N.Cmp(key.N) != 0 || got.E != key.E {
+		t.Errorf("ParseKeySet() = %+v, want the test key", keys)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"not JSON", `{`, "failed to parse"},
+		{"no RSA keys", `{"keys":[{"kty":"EC","kid":"e1","crv":"P-256"}]}`, "no RSA signing keys"},
+		{"encryption key only", `{"keys":[{"kty":"RSA","kid":"x","use":"enc","n":"AQAB","e":"AQAB"}]}`, "no RSA signing keys"},
+		{"short modulus", `{"keys":[{"kty":"RSA","kid":"x","n":"AQAB","e":"AQAB"}]}`, "want at least 2048"},
+		{"bad exponent", `{"keys":[{"kty":"RSA","kid":"x","n":"AQAB","e":"!!"}]}`, "invalid exponent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseKeySet([]byte(tt.data)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseKeySet() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
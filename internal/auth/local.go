@@ -0,0 +1,518 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// localIssuer is the issuer and audience of locally signed tokens
+	localIssuer = "dwell"
+	// defaultSecretKey is the placeholder JWT_SECRET_KEY of a fresh install
+	defaultSecretKey = "your-secret-key"
+	// confirmationCodeTTL is how long a confirmation code can be used
+	confirmationCodeTTL = 24 * time.Hour
+	// maxConfirmationAttempts bounds the guesses at one confirmation code
+	maxConfirmationAttempts = 5
+	// minPasswordLength matches the signup request validation
+	minPasswordLength = 8
+	// maxPasswordLength is the most bcrypt hashes; longer passwords would be
+	// silently truncated
+	maxPasswordLength = 72
+	// refreshTokenBytes is the entropy of a refresh token
+	refreshTokenBytes = 32
+)
+
+// UserStore persists the local provider's accounts and refresh tokens. Missing
+// rows are reported as repository.ErrNotFound and a taken email as
+// repository.ErrDuplicate; repository.AuthUserRepository implements it.
+type UserStore interface {
+	Create(ctx context.Context, user *domain.AuthUser) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.AuthUser, error)
+	GetByEmail(ctx context.Context, email string) (*domain.AuthUser, error)
+	Update(ctx context.Context, user *domain.AuthUser) error
+	RecordConfirmationAttempt(ctx context.Context, id uuid.UUID) (int, error)
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	UseRefreshToken(ctx context.Context, id uuid.UUID) error
+	RevokeRefreshFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// CodeSender delivers a confirmation code to the owner of a new account
+type CodeSender func(ctx context.Context, user *domain.AuthUser, code string) error
+
+// LocalProvider keeps accounts in Postgres. Passwords are hashed with bcrypt,
+// access tokens are HS256 JWTs signed with JWT_SECRET_KEY, and refresh tokens
+// are single use: each refresh rotates the token, and presenting a used one
+// revokes every token from that sign-in.
+type LocalProvider struct {
+	store       UserStore
+	sendCode    CodeSender
+	secret      []byte
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	autoConfirm bool
+	cost        int // bcrypt cost
+	now         func() time.Time
+}
+
+// localClaims are the claims of a locally signed access token. The user type
+// and landlord are read from the account on every request, so they are not
+// carried in the token. Revision is the account's revocation time when the
+// token was issued; signing out changes it and so rejects earlier tokens.
+type localClaims struct {
+	jwt.RegisteredClaims
+	TokenUse string `json:"token_use"`
+	Revision int64  `json:"rev,omitempty"`
+}
+
+// dummyPasswordHash is compared against when signing in with an unknown
+// email, so the response time does not reveal which emails have accounts
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// NewLocalProvider returns a provider backed by store, with token lifetimes
+// and the signing key taken from cfg.JWT
+func NewLocalProvider(store UserStore, sendCode CodeSender, cfg *config.Config) (*LocalProvider, error) {
+	switch {
+	case cfg.JWT.SecretKey == "":
+		return nil, errors.New("JWT_SECRET_KEY is required when AUTH_PROVIDER is local")
+	case cfg.JWT.Expiry <= 0:
+		return nil, errors.New("JWT_EXPIRATION_HOURS must be positive")
+	case cfg.JWT.RefreshExpiryDays <= 0:
+		return nil, errors.New("JWT_REFRESH_EXPIRATION_DAYS must be positive")
+	case sendCode == nil && !cfg.Auth.LocalAutoConfirm:
+		return nil, errors.New("a confirmation code sender is required unless AUTH_LOCAL_AUTO_CONFIRM is set")
+	}
+	if cfg.JWT.SecretKey == defaultSecretKey {
+		log.Printf("Warning: JWT_SECRET_KEY is the default value; anyone can forge access tokens. Set a random secret.")
+	}
+
+	return &LocalProvider{
+		store:       store,
+		sendCode:    sendCode,
+		secret:      []byte(cfg.JWT.SecretKey),
+		accessTTL:   time.Duration(cfg.JWT.Expiry) * time.Hour,
+		refreshTTL:  time.Duration(cfg.JWT.RefreshExpiryDays) * 24 * time.Hour,
+		autoConfirm: cfg.Auth.LocalAutoConfirm,
+		cost:        bcrypt.DefaultCost,
+		now:         time.Now,
+	}, nil
+}
+
+// SignUp creates an account and sends its confirmation code, unless accounts
+// are confirmed automatically
+func (p *LocalProvider) SignUp(ctx context.Context, newUser *NewUser) (string, error) {
+	if err := checkPassword(newUser.Password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), p.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &domain.AuthUser{
+		Email:        strings.TrimSpace(newUser.Email),
+		PasswordHash: string(hash),
+		FirstName:    newUser.FirstName,
+		LastName:     newUser.LastName,
+		Phone:        newUser.Phone,
+		CompanyName:  newUser.CompanyName,
+		UserType:     newUser.UserType,
+		LandlordID:   newUser.LandlordID,
+	}
+	var code string
+	if p.autoConfirm {
+		now := p.now()
+		user.ConfirmedAt = &now
+	} else if code, err = p.setConfirmationCode(user); err != nil {
+		return "", err
+	}
+
+	if err := p.store.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return "", ErrUserExists
+		}
+		return "", fmt.Errorf("failed to sign up user: %w", err)
+	}
+
+	if code != "" {
+		// The account exists either way; a failed send can be retried with
+		// a resend
+		if err := p.sendCode(ctx, user, code); err != nil {
+			log.Printf("Warning: failed to send confirmation code to account %s: %v", user.ID, err)
+		}
+	}
+	return user.ID.String(), nil
+}
+
+// ConfirmSignUp confirms an account. Each guess counts against the code's
+// attempt limit before it is checked.
+func (p *LocalProvider) ConfirmSignUp(ctx context.Context, email, code string) error {
+	user, err := p.store.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidCode
+		}
+		return fmt.Errorf("failed to confirm signup: %w", err)
+	}
+	if user.ConfirmedAt != nil {
+		return nil
+	}
+	if user.ConfirmationCodeHash == "" || user.ConfirmationExpiresAt == nil || !p.now().Before(*user.ConfirmationExpiresAt) {
+		return fmt.Errorf("%w: request a new code", ErrInvalidCode)
+	}
+
+	attempts, err := p.store.RecordConfirmationAttempt(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to confirm signup: %w", err)
+	}
+	if attempts > maxConfirmationAttempts {
+		return fmt.Errorf("%w: too many attempts, request a new code", ErrInvalidCode)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(strings.TrimSpace(code))), []byte(user.ConfirmationCodeHash)) != 1 {
+		return ErrInvalidCode
+	}
+
+	now := p.now()
+	user.ConfirmedAt = &now
+	user.ConfirmationCodeHash = ""
+	user.ConfirmationExpiresAt = nil
+	user.ConfirmationAttempts = 0
+	if err := p.store.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to confirm signup: %w", err)
+	}
+	return nil
+}
+
+// ResendConfirmationCode replaces an unconfirmed account's code and sends
+// the new one. Unknown and confirmed emails are ignored so the response does
+// not reveal which emails have accounts.
+func (p *LocalProvider) ResendConfirmationCode(ctx context.Context, email string) error {
+	user, err := p.store.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to resend confirmation code: %w", err)
+	}
+	if user.ConfirmedAt != nil || p.autoConfirm {
+		return nil
+	}
+
+	code, err := p.setConfirmationCode(user)
+	if err != nil {
+		return err
+	}
+	if err := p.store.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to resend confirmation code: %w", err)
+	}
+	if err := p.sendCode(ctx, user, code); err != nil {
+		return fmt.Errorf("failed to send confirmation code: %w", err)
+	}
+	return nil
+}
+
+// SignIn checks the password and starts a new refresh token family
+func (p *LocalProvider) SignIn(ctx context.Context, email, password string) (*Session, error) {
+	user, err := p.store.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to sign in: %w", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.ConfirmedAt == nil {
+		return nil, ErrNotConfirmed
+	}
+	return p.issueSession(ctx, user, uuid.New())
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token. A
+// token that was already used means it leaked, so its whole family is
+// revoked.
+func (p *LocalProvider) Refresh(ctx context.Context, refreshToken string) (*Session, error) {
+	token, err := p.store.GetRefreshToken(ctx, hashSecret(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	if token.RevokedAt != nil {
+		return nil, ErrInvalidToken
+	}
+	if token.UsedAt != nil {
+		return nil, p.revokeReusedFamily(ctx, token)
+	}
+	if !p.now().Before(token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	if err := p.store.UseRefreshToken(ctx, token.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Another request used or revoked it first
+			return nil, p.revokeReusedFamily(ctx, token)
+		}
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	user, err := p.store.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	return p.issueSession(ctx, user, token.FamilyID)
+}
+
+// revokeReusedFamily revokes every token from the sign-in a reused refresh
+// token belongs to
+func (p *LocalProvider) revokeReusedFamily(ctx context.Context, token *domain.RefreshToken) error {
+	log.Printf("Warning: refresh token reused for account %s; revoking its session", token.UserID)
+	if err := p.store.RevokeRefreshFamily(ctx, token.FamilyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return ErrInvalidToken
+}
+
+// SignOut rejects the user's access tokens issued so far and revokes their
+// refresh tokens, on every device
+func (p *LocalProvider) SignOut(ctx context.Context, accessToken string) error {
+	user, _, err := p.verify(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	return p.revoke(ctx, user)
+}
+
+// RevokeUser rejects a user's access tokens issued so far and revokes their
+// refresh tokens, on every device
+func (p *LocalProvider) RevokeUser(ctx context.Context, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+	user, err := p.store.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+	return p.revoke(ctx, user)
+}
+
+// DeleteUser deletes an account along with its refresh tokens
+func (p *LocalProvider) DeleteUser(ctx context.Context, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+	if err := p.store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
+// FindUser returns the confirmed account with the given email
+func (p *LocalProvider) FindUser(ctx context.Context, email string) (*domain.UserInfo, error) {
+	user, err := p.store.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	if user.ConfirmedAt == nil {
+		return nil, ErrUserNotFound
+	}
+	return &domain.UserInfo{UserID: user.ID.String(), UserType: user.UserType, LandlordID: user.LandlordID}, nil
+}
+
+// revoke stamps the user's token revocation time and revokes their refresh tokens
+func (p *LocalProvider) revoke(ctx context.Context, user *domain.AuthUser) error {
+	// Stored at the database's microsecond precision so it matches the
+	// revision carried by tokens issued afterwards
+	now := p.now().Truncate(time.Microsecond)
+	user.TokensRevokedAt = &now
+	if err := p.store.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to sign out: %w", err)
+	}
+	if err := p.store.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to sign out: %w", err)
+	}
+	return nil
+}
+
+// VerifyToken checks a locally signed access token. The account is loaded on
+// every call, so sign out and changes to the user type or landlord apply
+// immediately.
+func (p *LocalProvider) VerifyToken(ctx context.Context, tokenString string) (*domain.UserClaims, error) {
+	user, claims, err := p.verify(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.UserClaims{
+		UserID:     user.ID.String(),
+		UserType:   user.UserType,
+		LandlordID: user.LandlordID,
+		ExpiresAt:  claims.ExpiresAt.Time,
+	}, nil
+}
+
+// verify checks an access token's signature, issuer, audience and expiry and
+// that its account is confirmed and has not signed out since it was issued
+func (p *LocalProvider) verify(ctx context.Context, tokenString string) (*domain.AuthUser, *localClaims, error) {
+	claims := &localClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return p.secret, nil
+	},
+		jwt.WithValidMethods([]string{"HS256"}),
+		jwt.WithIssuer(localIssuer),
+		jwt.WithAudience(localIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.now),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.TokenUse != TokenUseAccess || claims.IssuedAt == nil {
+		return nil, nil, ErrInvalidToken
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	user, err := p.store.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidToken
+		}
+		return nil, nil, fmt.Errorf("failed to verify token: %w", err)
+	}
+	if user.ConfirmedAt == nil {
+		return nil, nil, ErrInvalidToken
+	}
+	if claims.Revision != tokenRevision(user) {
+		return nil, nil, fmt.Errorf("%w: token was revoked", ErrInvalidToken)
+	}
+	return user, claims, nil
+}
+
+// issueSession signs an access token and stores a new refresh token in the
+// given family
+func (p *LocalProvider) issueSession(ctx context.Context, user *domain.AuthUser, familyID uuid.UUID) (*Session, error) {
+	now := p.now()
+	claims := &localClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    localIssuer,
+			Subject:   user.ID.String(),
+			Audience:  jwt.ClaimStrings{localIssuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(p.accessTTL)),
+		},
+		TokenUse: TokenUseAccess,
+		Revision: tokenRevision(user),
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(p.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	raw := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+	err = p.store.CreateRefreshToken(ctx, &domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashSecret(refreshToken),
+		ExpiresAt: now.Add(p.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(p.accessTTL.Seconds()),
+		UserID:       user.ID.String(),
+		UserType:     user.UserType,
+	}, nil
+}
+
+// tokenRevision identifies the account's current sign out, in microseconds,
+// or is zero if it has never signed out
+func tokenRevision(user *domain.AuthUser) int64 {
+	if user.TokensRevokedAt == nil {
+		return 0
+	}
+	return user.TokensRevokedAt.UnixMicro()
+}
+
+// setConfirmationCode gives an account a fresh 6-digit confirmation code and
+// returns it; only its hash is kept
+func (p *LocalProvider) setConfirmationCode(user *domain.AuthUser) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate confirmation code: %w", err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	expires := p.now().Add(confirmationCodeTTL)
+	user.ConfirmationCodeHash = hashSecret(code)
+	user.ConfirmationExpiresAt = &expires
+	user.ConfirmationAttempts = 0
+	return code, nil
+}
+
+// checkPassword enforces the password length bcrypt can hash
+func checkPassword(password string) error {
+	switch {
+	case len(password) < minPasswordLength:
+		return fmt.Errorf("%w: must be at least %d characters", ErrInvalidPassword, minPasswordLength)
+	case len(password) > maxPasswordLength:
+		return fmt.Errorf("%w: must be at most %d bytes", ErrInvalidPassword, maxPasswordLength)
+	}
+	return nil
+}
+
+// hashSecret returns the hex SHA-256 of a code or token. Refresh tokens are
+// random enough for a fast hash, and confirmation codes are short-lived and
+// attempt-limited.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
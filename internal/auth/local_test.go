@@ -0,0 +1,542 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memStore is an in-memory UserStore
+type memStore struct {
+	mu     sync.Mutex
+	users  map[uuid.UUID]domain.AuthUser
+	tokens map[uuid.UUID]domain.RefreshToken
+}
+
+func newMemStore() *memStore {
+	return &memStore{users: map[uuid.UUID]domain.AuthUser{}, tokens: map[uuid.UUID]domain.RefreshToken{}}
+}
+
+func (m *memStore) Create(_ context.Context, user *domain.AuthUser) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if strings.EqualFold(u.Email, user.Email) {
+			return repository.ErrDuplicate
+		}
+	}
+	user.ID = uuid.New()
+	m.users[user.ID] = *user
+	return nil
+}
+
+func (m *memStore) GetByID(_ context.Context, id uuid.UUID) (*domain.AuthUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (m *memStore) GetByEmail(_ context.Context, email string) (*domain.AuthUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if strings.EqualFold(u.Email, email) {
+			return &u, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *memStore) Update(_ context.Context, user *domain.AuthUser) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[user.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	m.users[user.ID] = *user
+	return nil
+}
+
+func (m *memStore) RecordConfirmationAttempt(_ context.Context, id uuid.UUID) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	u.ConfirmationAttempts++
+	m.users[id] = u
+	return u.ConfirmationAttempts, nil
+}
+
+func (m *memStore) CreateRefreshToken(_ context.Context, token *domain.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token.ID = uuid.New()
+	m.tokens[token.ID] = *token
+	return nil
+}
+
+func (m *memStore) GetRefreshToken(_ context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return &t, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *memStore) UseRefreshToken(_ context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok || t.UsedAt != nil || t.RevokedAt != nil {
+		return repository.ErrNotFound
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	m.tokens[id] = t
+	return nil
+}
+
+func (m *memStore) RevokeRefreshFamily(_ context.Context, familyID uuid.UUID) error {
+	return m.revoke(func(t domain.RefreshToken) bool { return t.FamilyID == familyID })
+}
+
+func (m *memStore) RevokeUserRefreshTokens(_ context.Context, userID uuid.UUID) error {
+	return m.revoke(func(t domain.RefreshToken) bool { return t.UserID == userID })
+}
+
+func (m *memStore) Delete(_ context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.users, id)
+	for tokenID, t := range m.tokens {
+		if t.UserID == id {
+			delete(m.tokens, tokenID)
+		}
+	}
+	return nil
+}
+
+func (m *memStore) revoke(match func(domain.RefreshToken) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for id, t := range m.tokens {
+		if match(t) && t.RevokedAt == nil {
+			t.RevokedAt = &now
+			m.tokens[id] = t
+		}
+	}
+	return nil
+}
+
+// newTestLocalProvider returns a provider whose sent codes are recorded in
+// codes, keyed by email
+func newTestLocalProvider(t *testing.T, store UserStore) (*LocalProvider, map[string]string) {
+	t.Helper()
+	codes := map[string]string{}
+	cfg := &config.Config{JWT: config.JWTConfig{SecretKey: "test-secret", Expiry: 1, RefreshExpiryDays: 7}}
+	provider, err := NewLocalProvider(store, func(_ context.Context, user *domain.AuthUser, code string) error {
+		codes[user.Email] = code
+		return nil
+	}, cfg)
+	if err != nil {
+		t.Fatalf("NewLocalProvider() error = %v", err)
+	}
+	provider.cost = bcrypt.MinCost
+	return provider, codes
+}
+
+// signUpConfirmed creates and confirms an account
+func signUpConfirmed(t *testing.T, provider *LocalProvider, codes map[string]string, user *NewUser) string {
+	t.Helper()
+	ctx := context.Background()
+	userID, err := provider.SignUp(ctx, user)
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	if err := provider.ConfirmSignUp(ctx, user.Email, codes[user.Email]); err != nil {
+		t.Fatalf("ConfirmSignUp() error = %v", err)
+	}
+	return userID
+}
+
+func TestLocalProviderSignUpAndSignIn(t *testing.T) {
+	ctx := context.Background()
+	provider, codes := newTestLocalProvider(t, newMemStore())
+	landlordID := uuid.New()
+	user := &NewUser{Email: "Tenant@Example.com", Password: "password123", UserType: "tenant", LandlordID: &landlordID}
+
+	userID, err := provider.SignUp(ctx, user)
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	if len(codes[user.Email]) != 6 {
+		t.Fatalf("confirmation code = %q, want 6 digits", codes[user.Email])
+	}
+	if _, err := provider.SignUp(ctx, &NewUser{Email: "tenant@example.com", Password: "password123", UserType: "tenant"}); !errors.Is(err, ErrUserExists) {
+		t.Errorf("SignUp(same email) error = %v, want ErrUserExists", err)
+	}
+	if _, err := provider.SignIn(ctx, user.Email, user.Password); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("SignIn(unconfirmed) error = %v, want ErrNotConfirmed", err)
+	}
+
+	if err := provider.ConfirmSignUp(ctx, "tenant@example.com", codes[user.Email]); err != nil {
+		t.Fatalf("ConfirmSignUp() error = %v", err)
+	}
+	if _, err := provider.SignIn(ctx, user.Email, "wrong-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("SignIn(wrong password) error = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := provider.SignIn(ctx, "nobody@example.com", user.Password); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("SignIn(unknown email) error = %v, want ErrInvalidCredentials", err)
+	}
+
+	session, err := provider.SignIn(ctx, "tenant@example.com", user.Password)
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if session.UserID != userID || session.UserType != "tenant" || session.RefreshToken == "" || session.ExpiresIn != 3600 {
+		t.Errorf("session = %+v", session)
+	}
+
+	claims, err := provider.VerifyToken(ctx, session.AccessToken)
+	if err != nil {
+		t.Fatalf("VerifyToken() error = %v", err)
+	}
+	if claims.UserID != userID || claims.UserType != "tenant" || claims.LandlordID == nil || *claims.LandlordID != landlordID {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestLocalProviderPasswordPolicy(t *testing.T) {
+	provider, _ := newTestLocalProvider(t, newMemStore())
+	for _, password := range []string{"short", strings.Repeat("a", 73)} {
+		if _, err := provider.SignUp(context.Background(), &NewUser{Email: "a@example.com", Password: password, UserType: "landlord"}); !errors.Is(err, ErrInvalidPassword) {
+			t.Errorf("SignUp(%d character password) error = %v, want ErrInvalidPassword", len(password), err)
+		}
+	}
+}
+
+func TestLocalProviderConfirmationAttempts(t *testing.T) {
+	ctx := context.Background()
+	provider, codes := newTestLocalProvider(t, newMemStore())
+	email := "landlord@example.com"
+	if _, err := provider.SignUp(ctx, &NewUser{Email: email, Password: "password123", UserType: "landlord"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	code := codes[email]
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 0; i < maxConfirmationAttempts; i++ {
+		if err := provider.ConfirmSignUp(ctx, email, wrong); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("ConfirmSignUp(wrong code) error = %v, want ErrInvalidCode", err)
+		}
+	}
+	if err := provider.ConfirmSignUp(ctx, email, code); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("ConfirmSignUp(after %d wrong guesses) error = %v, want ErrInvalidCode", maxConfirmationAttempts, err)
+	}
+
+	// A new code resets the attempts
+	if err := provider.ResendConfirmationCode(ctx, email); err != nil {
+		t.Fatalf("ResendConfirmationCode() error = %v", err)
+	}
+	if err := provider.ConfirmSignUp(ctx, email, codes[email]); err != nil {
+		t.Fatalf("ConfirmSignUp(new code) error = %v", err)
+	}
+
+	if err := provider.ResendConfirmationCode(ctx, "nobody@example.com"); err != nil {
+		t.Errorf("ResendConfirmationCode(unknown email) error = %v, want nil", err)
+	}
+}
+
+func TestLocalProviderExpiredCode(t *testing.T) {
+	ctx := context.Background()
+	provider, codes := newTestLocalProvider(t, newMemStore())
+	email := "landlord@example.com"
+	if _, err := provider.SignUp(ctx, &NewUser{Email: email, Password: "password123", UserType: "landlord"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	provider.now = func() time.Time { return time.Now().Add(confirmationCodeTTL + time.Minute) }
+	if err := provider.ConfirmSignUp(ctx, email, codes[email]); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("ConfirmSignUp(expired code) error = %v, want ErrInvalidCode", err)
+	}
+}
+
+func TestLocalProviderAutoConfirm(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWT:  config.JWTConfig{SecretKey: "test-secret", Expiry: 1, RefreshExpiryDays: 7},
+		Auth: config.AuthConfig{Provider: ProviderLocal, LocalAutoConfirm: true},
+	}
+	provider, err := NewLocalProvider(newMemStore(), nil, cfg)
+	if err != nil {
+		t.Fatalf("NewLocalProvider() error = %v", err)
+	}
+	provider.cost = bcrypt.MinCost
+
+	if _, err := provider.SignUp(ctx, &NewUser{Email: "dev@example.com", Password: "password123", UserType: "landlord"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	if _, err := provider.SignIn(ctx, "dev@example.com", "password123"); err != nil {
+		t.Errorf("SignIn() error = %v, want auto-confirmed account", err)
+	}
+}
+
+func TestLocalProviderRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	provider, codes := newTestLocalProvider(t, store)
+	user := &NewUser{Email: "landlord@example.com", Password: "password123", UserType: "landlord"}
+	signUpConfirmed(t, provider, codes, user)
+
+	first, err := provider.SignIn(ctx, user.Email, user.Password)
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	second, err := provider.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatal("Refresh() did not rotate the refresh token")
+	}
+	if _, err := provider.VerifyToken(ctx, second.AccessToken); err != nil {
+		t.Errorf("VerifyToken(refreshed) error = %v", err)
+	}
+
+	// Replaying the used token revokes its whole family, including the
+	// token it was exchanged for
+	if _, err := provider.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Refresh(reused token) error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := provider.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Refresh(token of a revoked family) error = %v, want ErrInvalidToken", err)
+	}
+
+	// Other sign-ins are unaffected
+	other, err := provider.SignIn(ctx, user.Email, user.Password)
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if _, err := provider.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Errorf("Refresh(other session) error = %v", err)
+	}
+
+	if _, err := provider.Refresh(ctx, "not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Refresh(unknown token) error = %v, want ErrInvalidToken", err)
+	}
+
+	expired, err := provider.SignIn(ctx, user.Email, user.Password)
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	provider.now = func() time.Time { return time.Now().Add(8 * 24 * time.Hour) }
+	if _, err := provider.Refresh(ctx, expired.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Refresh(expired token) error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestLocalProviderSignOut(t *testing.T) {
+	ctx := context.Background()
+	provider, codes := newTestLocalProvider(t, newMemStore())
+	user := &NewUser{Email: "landlord@example.com", Password: "password123", UserType: "landlord"}
+	signUpConfirmed(t, provider, codes, user)
+
+	session, err := provider.SignIn(ctx, user.Email, user.Password)
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if err := provider.SignOut(ctx, session.AccessToken); err != nil {
+		t.Fatalf("SignOut() error = %v", err)
+	}
+	if _, err := provider.VerifyToken(ctx, session.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyToken(after sign out) error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := provider.Refresh(ctx, session.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Refresh(after sign out) error = %v, want ErrInvalidToken", err)
+	}
+
+	// Tokens issued after the sign out are accepted, even within the same second
+	session, err = provider.SignIn(ctx, user.Email, user.Password)
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if _, err := provider.VerifyToken(ctx, session.AccessToken); err != nil {
+		t.Errorf("VerifyToken(new session) error = %v", err)
+	}
+}
+
+func TestLocalProviderRevokeUser(t *testing.T) {
+	ctx := context.Background()
+	provider, codes := newTestLocalProvider(t, newMemStore())
+	user := &NewUser{Email: "staff@example.com", Password: "password123", UserType: "landlord"}
+	userID := signUpConfirmed(t, provider, codes, user)
+
+	session, err := provider.SignIn(ctx, user.Email, user.Password)
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if err := provider.RevokeUser(ctx, userID); err != nil {
+		t.Fatalf("RevokeUser() error = %v", err)
+	}
+	if _, err := provider.VerifyToken(ctx, session.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyToken(after revoke) error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := provider.Refresh(ctx, session.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Refresh(after revoke) error = %v, want ErrInvalidToken", err)
+	}
+
+	session, err = provider.SignIn(ctx, user.Email, user.Password)
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if _, err := provider.VerifyToken(ctx, session.AccessToken); err != nil {
+		t.Errorf("VerifyToken(signed in after revoke) error = %v", err)
+	}
+
+	if err := provider.RevokeUser(ctx, "not-a-uuid"); err == nil {
+		t.Error("RevokeUser(invalid ID) error = nil")
+	}
+}
+
+func TestLocalProviderDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	provider, codes := newTestLocalProvider(t, newMemStore())
+	user := &NewUser{Email: "orphan@example.com", Password: "password123", UserType: "tenant"}
+	userID := signUpConfirmed(t, provider, codes, user)
+
+	if err := provider.DeleteUser(ctx, userID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, err := provider.SignIn(ctx, user.Email, user.Password); err == nil {
+		t.Error("SignIn(after delete) error = nil")
+	}
+	// The email can be used again
+	if _, err := provider.SignUp(ctx, user); err != nil {
+		t.Errorf("SignUp(after delete) error = %v", err)
+	}
+}
+
+func TestLocalProviderFindUser(t *testing.T) {
+	ctx := context.Background()
+	provider, codes := newTestLocalProvider(t, newMemStore())
+	userID := signUpConfirmed(t, provider, codes, &NewUser{Email: "owner@example.com", Password: "password123", UserType: "landlord"})
+	if _, err := provider.SignUp(ctx, &NewUser{Email: "pending@example.com", Password: "password123", UserType: "landlord"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	info, err := provider.FindUser(ctx, "Owner@example.com")
+	if err != nil {
+		t.Fatalf("FindUser() error = %v", err)
+	}
+	if info.UserID != userID || info.UserType != "landlord" {
+		t.Errorf("FindUser() = %+v, want user %s", info, userID)
+	}
+	for _, email := range []string{"pending@example.com", "nobody@example.com"} {
+		if _, err := provider.FindUser(ctx, email); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("FindUser(%s) error = %v, want ErrUserNotFound", email, err)
+		}
+	}
+}
+
+func TestLocalProviderVerifyToken(t *testing.T) {
+	ctx := context.Background()
+	provider, codes := newTestLocalProvider(t, newMemStore())
+	userID := signUpConfirmed(t, provider, codes, &NewUser{Email: "landlord@example.com", Password: "password123", UserType: "landlord"})
+
+	sign := func(secret string, method jwt.SigningMethod, changes jwt.MapClaims) string {
+		now := time.Now()
+		claims := jwt.MapClaims{
+			"iss": localIssuer, "aud": localIssuer, "sub": userID, "token_use": TokenUseAccess,
+			"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(claims, k)
+			} else {
+				claims[k] = v
+			}
+		}
+		signed, err := jwt.NewWithClaims(method, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"valid", sign("test-secret", jwt.SigningMethodHS256, nil), true},
+		{"wrong secret", sign("other-secret", jwt.SigningMethodHS256, nil), false},
+		{"HS512", sign("test-secret", jwt.SigningMethodHS512, nil), false},
+		{"wrong issuer", sign("test-secret", jwt.SigningMethodHS256, jwt.MapClaims{"iss": "someone-else"}), false},
+		{"wrong audience", sign("test-secret", jwt.SigningMethodHS256, jwt.MapClaims{"aud": "someone-else"}), false},
+		{"expired", sign("test-secret", jwt.SigningMethodHS256, jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}), false},
+		{"no expiry", sign("test-secret", jwt.SigningMethodHS256, jwt.MapClaims{"exp": nil}), false},
+		{"no issued at", sign("test-secret", jwt.SigningMethodHS256, jwt.MapClaims{"iat": nil}), false},
+		{"not an access token", sign("test-secret", jwt.SigningMethodHS256, jwt.MapClaims{"token_use": TokenUseID}), false},
+		{"unknown user", sign("test-secret", jwt.SigningMethodHS256, jwt.MapClaims{"sub": uuid.NewString()}), false},
+		{"garbage", "not-a-token", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.VerifyToken(ctx, tt.token)
+			if tt.valid && err != nil {
+				t.Errorf("VerifyToken() error = %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("VerifyToken() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestNewLocalProviderConfig(t *testing.T) {
+	send := func(context.Context, *domain.AuthUser, string) error { return nil }
+	tests := []struct {
+		name string
+		jwt  config.JWTConfig
+		send CodeSender
+	}{
+		{"no secret", config.JWTConfig{Expiry: 1, RefreshExpiryDays: 7}, send},
+		{"no access lifetime", config.JWTConfig{SecretKey: "s", RefreshExpiryDays: 7}, send},
+		{"no refresh lifetime", config.JWTConfig{SecretKey: "s", Expiry: 1}, send},
+		{"no code sender", config.JWTConfig{SecretKey: "s", Expiry: 1, RefreshExpiryDays: 7}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLocalProvider(newMemStore(), tt.send, &config.Config{JWT: tt.jwt}); err == nil {
+				t.Error("NewLocalProvider() error = nil, want a configuration error")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"dwell/internal/aws"
+	"dwell/internal/config"
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Provider names accepted in AUTH_PROVIDER
+const (
+	ProviderCognito = "cognito"
+	ProviderLocal   = "local"
+)
+
+var (
+	// ErrUserExists is returned when signing up with an email that already has an account
+	ErrUserExists = errors.New("an account with this email already exists")
+	// ErrInvalidCredentials is returned for a wrong email or password
+	ErrInvalidCredentials = errors.New("incorrect email or password")
+	// ErrNotConfirmed is returned when signing in before confirming the account
+	ErrNotConfirmed = errors.New("account is not confirmed")
+	// ErrInvalidCode is returned for a wrong, expired or exhausted confirmation code
+	ErrInvalidCode = errors.New("invalid or expired confirmation code")
+	// ErrInvalidPassword is returned when a password does not meet the password policy
+	ErrInvalidPassword = errors.New("password does not meet the password policy")
+	// ErrInvalidToken is returned for a malformed, expired or revoked token
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrUserNotFound is returned when no confirmed account has an email address
+	ErrUserNotFound = errors.New("no confirmed account with this email")
+)
+
+// Provider manages user accounts and the tokens that authenticate them
+type Provider interface {
+	// SignUp creates an unconfirmed account and returns its user ID
+	SignUp(ctx context.Context, user *NewUser) (string, error)
+	// ConfirmSignUp confirms an account with the code sent at signup
+	ConfirmSignUp(ctx context.Context, email, code string) error
+	// ResendConfirmationCode sends a new confirmation code
+	ResendConfirmationCode(ctx context.Context, email string) error
+	// SignIn checks a password and starts a session
+	SignIn(ctx context.Context, email, password string) (*Session, error)
+	// Refresh issues a new access token for a refresh token. The refresh
+	// token in the returned session is empty unless it was rotated.
+	Refresh(ctx context.Context, refreshToken string) (*Session, error)
+	// SignOut revokes the user's tokens
+	SignOut(ctx context.Context, accessToken string) error
+	// RevokeUser revokes a user's tokens on every device without needing one
+	// of them, e.g. when the user is removed from a landlord account
+	RevokeUser(ctx context.Context, userID string) error
+	// DeleteUser removes an account, e.g. one whose signup could not be
+	// completed
+	DeleteUser(ctx context.Context, userID string) error
+	// VerifyToken checks a token and returns the caller it identifies
+	VerifyToken(ctx context.Context, token string) (*domain.UserClaims, error)
+	// FindUser returns the confirmed account whose verified email address is
+	// email, e.g. to record the owner of a landlord account that predates
+	// teams
+	FindUser(ctx context.Context, email string) (*domain.UserInfo, error)
+}
+
+// NewUser is an account to create
+type NewUser struct {
+	Email       string
+	Password    string
+	FirstName   string
+	LastName    string
+	Phone       string
+	CompanyName string
+	UserType    string
+	LandlordID  *uuid.UUID // set when the user joins an existing landlord account
+}
+
+// Session holds the tokens issued at sign-in or refresh
+type Session struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // access token lifetime in seconds
+	UserID       string
+	UserType     string
+}
+
+// NewProvider returns the provider selected by cfg.Auth.Provider. The local
+// provider keeps accounts in store and delivers confirmation codes with
+// sendCode.
+func NewProvider(cfg *config.Config, awsClients *aws.Clients, store UserStore, sendCode CodeSender) (Provider, error) {
+	switch cfg.Auth.Provider {
+	case "", ProviderCognito:
+		return NewCognitoProvider(awsClients.GetCognitoClient(), &cfg.AWS.Cognito), nil
+	case ProviderLocal:
+		return NewLocalProvider(store, sendCode, cfg)
+	default:
+		return nil, fmt.Errorf("unknown auth provider %q", cfg.Auth.Provider)
+	}
+}
@@ -0,0 +1,212 @@
+// Package authz decides what an authenticated user may do. Each role is
+// granted actions on resources by a declarative policy; landlord-side staff
+// may additionally be limited to a set of properties.
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ErrForbidden is returned when the caller may not perform an action
+var ErrForbidden = errors.New("access denied")
+
+// Roles
+const (
+	RoleOwner            = "owner"
+	RolePropertyManager  = "property_manager"
+	RoleLeasingAgent     = "leasing_agent"
+	RoleMaintenanceStaff = "maintenance_staff"
+	RoleBookkeeper       = "bookkeeper"
+	RoleTenant           = "tenant"
+	RoleContractor       = "contractor"
+)
+
+// Resource is a kind of record a permission applies to
+type Resource string
+
+// Resources
+const (
+	ResourceProperties     Resource = "properties"
+	ResourceTenants        Resource = "tenants"
+	ResourceContractors    Resource = "contractors"
+	ResourceMaintenance    Resource = "maintenance"
+	ResourceTriage         Resource = "maintenance_triage"
+	ResourceJobs           Resource = "contractor_jobs" // maintenance requests assigned to the calling contractor
+	ResourcePayments       Resource = "payments"
+	ResourceLateFeePolicy  Resource = "late_fee_policy"
+	ResourceFiles          Resource = "files"
+	ResourceAI             Resource = "ai"       // the caller's own assistant conversations and actions
+	ResourceAIUsage        Resource = "ai_usage" // account-wide AI history, analytics and budget
+	ResourceAITips         Resource = "ai_tips"
+	ResourceTeam           Resource = "team"            // staff members of the landlord account and their invitations
+	ResourceOwnership      Resource = "ownership"       // who owns the landlord account
+	ResourceTenantMessages Resource = "tenant_messages" // free-form emails to tenants
+)
+
+// Action is an operation on a resource
+type Action string
+
+// Actions
+const (
+	ActionRead   Action = "read"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var (
+	readOnly  = []Action{ActionRead}
+	readWrite = []Action{ActionRead, ActionCreate, ActionUpdate}
+	allAccess = []Action{ActionRead, ActionCreate, ActionUpdate, ActionDelete}
+)
+
+// policy grants each role its actions on each resource. Anything not listed is
+// denied. Tenants and contractors are further limited to their own records by
+// the services.
+var policy = map[string]map[Resource][]Action{
+	RoleOwner: {
+		ResourceProperties:     allAccess,
+		ResourceTenants:        allAccess,
+		ResourceContractors:    allAccess,
+		ResourceMaintenance:    allAccess,
+		ResourceTriage:         readWrite,
+		ResourcePayments:       allAccess,
+		ResourceLateFeePolicy:  {ActionRead, ActionUpdate},
+		ResourceFiles:          allAccess,
+		ResourceAI:             allAccess,
+		ResourceAIUsage:        {ActionRead, ActionUpdate},
+		ResourceAITips:         readOnly,
+		ResourceTeam:           allAccess,
+		ResourceOwnership:      {ActionUpdate},
+		ResourceTenantMessages: {ActionCreate},
+	},
+	RolePropertyManager: {
+		ResourceProperties:     {ActionRead, ActionUpdate},
+		ResourceTenants:        readWrite,
+		ResourceContractors:    readWrite,
+		ResourceMaintenance:    allAccess,
+		ResourceTriage:         readWrite,
+		ResourcePayments:       readWrite,
+		ResourceLateFeePolicy:  readOnly,
+		ResourceFiles:          allAccess,
+		ResourceAI:             allAccess,
+		ResourceAIUsage:        readOnly,
+		ResourceAITips:         readOnly,
+		ResourceTeam:           readOnly,
+		ResourceTenantMessages: {ActionCreate},
+	},
+	RoleLeasingAgent: {
+		ResourceProperties:     readOnly,
+		ResourceTenants:        readWrite,
+		ResourcePayments:       readOnly,
+		ResourceMaintenance:    readOnly,
+		ResourceFiles:          {ActionRead, ActionCreate},
+		ResourceAI:             allAccess,
+		ResourceAITips:         readOnly,
+		ResourceTenantMessages: {ActionCreate},
+	},
+	RoleMaintenanceStaff: {
+		ResourceProperties:  readOnly,
+		ResourceTenants:     readOnly,
+		ResourceContractors: readWrite,
+		ResourceMaintenance: allAccess,
+		ResourceTriage:      readWrite,
+		ResourceFiles:       {ActionRead, ActionCreate},
+		ResourceAI:          allAccess,
+		ResourceAITips:      readOnly,
+	},
+	RoleBookkeeper: {
+		ResourceProperties:    readOnly,
+		ResourceTenants:       readOnly,
+		ResourcePayments:      readWrite,
+		ResourceLateFeePolicy: readOnly,
+		ResourceFiles:         {ActionRead, ActionCreate},
+		ResourceAI:            allAccess,
+		ResourceAIUsage:       readOnly,
+		ResourceAITips:        readOnly,
+	},
+	// Tenants reach the photos of their own requests through the maintenance
+	// routes. The file routes check the landlord account and property scope
+	// but not a tenant's own records, so tenants get none of them.
+	RoleTenant: {
+		ResourceProperties:  readOnly,
+		ResourceMaintenance: readWrite,
+		ResourcePayments:    readOnly,
+		ResourceAI:          allAccess,
+	},
+	RoleContractor: {
+		ResourceJobs: {ActionRead, ActionUpdate},
+	},
+}
+
+// RoleOf returns the caller's role. Users without an explicit role get the
+// default for their user type: landlords own their account.
+func RoleOf(claims *domain.UserClaims) string {
+	if claims == nil {
+		return ""
+	}
+	if claims.Role != "" {
+		return claims.Role
+	}
+	switch claims.UserType {
+	case "landlord":
+		return RoleOwner
+	case "tenant":
+		return RoleTenant
+	case "contractor":
+		return RoleContractor
+	}
+	return ""
+}
+
+// Allowed reports whether the policy grants role the action on resource
+func Allowed(role string, resource Resource, action Action) bool {
+	return slices.Contains(policy[role][resource], action)
+}
+
+// Authorize checks that the caller's role grants the action on resource
+func Authorize(claims *domain.UserClaims, resource Resource, action Action) error {
+	role := RoleOf(claims)
+	if role == "" {
+		return fmt.Errorf("%w: unknown role", ErrForbidden)
+	}
+	if !Allowed(role, resource, action) {
+		return fmt.Errorf("%w: %s role cannot %s %s", ErrForbidden, role, action, resource)
+	}
+	return nil
+}
+
+// CheckLandlord checks that the caller acts within the given landlord account
+func CheckLandlord(claims *domain.UserClaims, landlordID uuid.UUID) error {
+	if claims == nil || claims.LandlordID == nil || *claims.LandlordID != landlordID {
+		return fmt.Errorf("%w: resource belongs to another landlord account", ErrForbidden)
+	}
+	return nil
+}
+
+// PropertyScope returns the properties landlord-side staff are limited to, or
+// nil if the caller is not limited by property. Owners and staff assigned to
+// every property are never limited, and tenants and contractors are limited
+// to their own records elsewhere. Staff without assignments get an empty,
+// non-nil scope that matches nothing.
+func PropertyScope(claims *domain.UserClaims) []uuid.UUID {
+	if claims == nil || claims.UserType != "landlord" || claims.AllProperties || RoleOf(claims) == RoleOwner {
+		return nil
+	}
+	if claims.PropertyIDs == nil {
+		return []uuid.UUID{}
+	}
+	return claims.PropertyIDs
+}
+
+// CanAccessProperty reports whether a property is within the caller's property scope
+func CanAccessProperty(claims *domain.UserClaims, propertyID uuid.UUID) bool {
+	scope := PropertyScope(claims)
+	return scope == nil || slices.Contains(scope, propertyID)
+}
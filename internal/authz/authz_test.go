@@ -0,0 +1,214 @@
+package authz
+
+import (
+	"errors"
+	"testing"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func claimsFor(userType, role string, landlordID uuid.UUID, propertyIDs ...uuid.UUID) *domain.UserClaims {
+	return &domain.UserClaims{
+		UserID:      "user-" + userType + role,
+		UserType:    userType,
+		Role:        role,
+		LandlordID:  &landlordID,
+		PropertyIDs: propertyIDs,
+	}
+}
+
+func TestRoleOf(t *testing.T) {
+	landlordID := uuid.New()
+	tests := []struct {
+		name   string
+		claims *domain.UserClaims
+		want   string
+	}{
+		{"nil claims", nil, ""},
+		{"landlord defaults to owner", claimsFor("landlord", "", landlordID), RoleOwner},
+		{"tenant", claimsFor("tenant", "", landlordID), RoleTenant},
+		{"contractor", claimsFor("contractor", "", landlordID), RoleContractor},
+		{"explicit staff role", claimsFor("landlord", RoleLeasingAgent, landlordID), RoleLeasingAgent},
+		{"unknown user type", claimsFor("admin", "", landlordID), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoleOf(tt.claims); got != tt.want {
+				t.Errorf("RoleOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	landlordID := uuid.New()
+	owner := claimsFor("landlord", "", landlordID)
+	manager := claimsFor("landlord", RolePropertyManager, landlordID)
+	agent := claimsFor("landlord", RoleLeasingAgent, landlordID)
+	staff := claimsFor("landlord", RoleMaintenanceStaff, landlordID)
+	bookkeeper := claimsFor("landlord", RoleBookkeeper, landlordID)
+	tenant := claimsFor("tenant", "", landlordID)
+	contractor := claimsFor("contractor", "", landlordID)
+
+	tests := []struct {
+		name     string
+		claims   *domain.UserClaims
+		resource Resource
+		action   Action
+		allowed  bool
+	}{
+		{"owner creates properties", owner, ResourceProperties, ActionCreate, true},
+		{"owner deletes properties", owner, ResourceProperties, ActionDelete, true},
+		{"owner updates late fee policy", owner, ResourceLateFeePolicy, ActionUpdate, true},
+		{"owner updates AI budget", owner, ResourceAIUsage, ActionUpdate, true},
+		{"owner has no contractor portal", owner, ResourceJobs, ActionRead, false},
+
+		{"manager updates properties", manager, ResourceProperties, ActionUpdate, true},
+		{"manager cannot create properties", manager, ResourceProperties, ActionCreate, false},
+		{"manager cannot delete properties", manager, ResourceProperties, ActionDelete, false},
+		{"manager records payments", manager, ResourcePayments, ActionUpdate, true},
+		{"manager cannot delete payments", manager, ResourcePayments, ActionDelete, false},
+		{"manager reads late fee policy", manager, ResourceLateFeePolicy, ActionRead, true},
+		{"manager cannot update late fee policy", manager, ResourceLateFeePolicy, ActionUpdate, false},
+		{"manager cannot update AI budget", manager, ResourceAIUsage, ActionUpdate, false},
+
+		{"agent adds tenants", agent, ResourceTenants, ActionCreate, true},
+		{"agent reads payments", agent, ResourcePayments, ActionRead, true},
+		{"agent cannot record payments", agent, ResourcePayments, ActionUpdate, false},
+		{"agent cannot update maintenance", agent, ResourceMaintenance, ActionUpdate, false},
+		{"agent cannot read contractors", agent, ResourceContractors, ActionRead, false},
+		{"agent cannot read AI usage", agent, ResourceAIUsage, ActionRead, false},
+
+		{"staff update maintenance", staff, ResourceMaintenance, ActionUpdate, true},
+		{"staff triage maintenance", staff, ResourceTriage, ActionCreate, true},
+		{"staff add contractors", staff, ResourceContractors, ActionCreate, true},
+		{"staff cannot read payments", staff, ResourcePayments, ActionRead, false},
+		{"staff cannot update tenants", staff, ResourceTenants, ActionUpdate, false},
+
+		{"bookkeeper records payments", bookkeeper, ResourcePayments, ActionUpdate, true},
+		{"bookkeeper reads tenants", bookkeeper, ResourceTenants, ActionRead, true},
+		{"bookkeeper cannot update tenants", bookkeeper, ResourceTenants, ActionUpdate, false},
+		{"bookkeeper cannot read maintenance", bookkeeper, ResourceMaintenance, ActionRead, false},
+
+		{"owner invites staff", owner, ResourceTeam, ActionCreate, true},
+		{"owner removes staff", owner, ResourceTeam, ActionDelete, true},
+		{"owner transfers ownership", owner, ResourceOwnership, ActionUpdate, true},
+		{"manager reads the team", manager, ResourceTeam, ActionRead, true},
+		{"manager cannot invite staff", manager, ResourceTeam, ActionCreate, false},
+		{"manager cannot transfer ownership", manager, ResourceOwnership, ActionUpdate, false},
+		{"agent cannot read the team", agent, ResourceTeam, ActionRead, false},
+		{"tenant cannot read the team", tenant, ResourceTeam, ActionRead, false},
+
+		{"owner messages tenants", owner, ResourceTenantMessages, ActionCreate, true},
+		{"manager messages tenants", manager, ResourceTenantMessages, ActionCreate, true},
+		{"agent messages tenants", agent, ResourceTenantMessages, ActionCreate, true},
+		{"staff cannot message tenants", staff, ResourceTenantMessages, ActionCreate, false},
+		{"bookkeeper cannot message tenants", bookkeeper, ResourceTenantMessages, ActionCreate, false},
+		{"tenant cannot message tenants", tenant, ResourceTenantMessages, ActionCreate, false},
+
+		{"tenant files maintenance", tenant, ResourceMaintenance, ActionCreate, true},
+		{"tenant reads payments", tenant, ResourcePayments, ActionRead, true},
+		{"tenant cannot create charges", tenant, ResourcePayments, ActionCreate, false},
+		{"tenant cannot delete maintenance photos", tenant, ResourceMaintenance, ActionDelete, false},
+		{"tenant cannot read tenants", tenant, ResourceTenants, ActionRead, false},
+		{"tenant cannot triage", tenant, ResourceTriage, ActionRead, false},
+		{"tenant cannot read files", tenant, ResourceFiles, ActionRead, false},
+		{"tenant cannot upload files", tenant, ResourceFiles, ActionCreate, false},
+
+		{"contractor reads jobs", contractor, ResourceJobs, ActionRead, true},
+		{"contractor updates jobs", contractor, ResourceJobs, ActionUpdate, true},
+		{"contractor cannot read maintenance", contractor, ResourceMaintenance, ActionRead, false},
+		{"contractor cannot use the assistant", contractor, ResourceAI, ActionCreate, false},
+		{"contractor cannot upload files", contractor, ResourceFiles, ActionCreate, false},
+
+		{"unknown role", claimsFor("landlord", "auditor", landlordID), ResourceProperties, ActionRead, false},
+		{"nil claims", nil, ResourceProperties, ActionRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.claims, tt.resource, tt.action)
+			if tt.allowed && err != nil {
+				t.Errorf("Authorize() error = %v, want nil", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("Authorize() error = %v, want ErrForbidden", err)
+			}
+		})
+	}
+}
+
+func TestCheckLandlord(t *testing.T) {
+	landlordID, otherID := uuid.New(), uuid.New()
+	tests := []struct {
+		name       string
+		claims     *domain.UserClaims
+		landlordID uuid.UUID
+		allowed    bool
+	}{
+		{"owner of the account", claimsFor("landlord", "", landlordID), landlordID, true},
+		{"staff of the account", claimsFor("landlord", RolePropertyManager, landlordID), landlordID, true},
+		{"tenant of the account", claimsFor("tenant", "", landlordID), landlordID, true},
+		{"owner of another account", claimsFor("landlord", "", otherID), landlordID, false},
+		{"staff of another account", claimsFor("landlord", RolePropertyManager, otherID), landlordID, false},
+		{"tenant of another account", claimsFor("tenant", "", otherID), landlordID, false},
+		{"contractor of another account", claimsFor("contractor", "", otherID), landlordID, false},
+		{"no landlord", &domain.UserClaims{UserType: "landlord"}, landlordID, false},
+		{"nil claims", nil, landlordID, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckLandlord(tt.claims, tt.landlordID)
+			if tt.allowed && err != nil {
+				t.Errorf("CheckLandlord() error = %v, want nil", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("CheckLandlord() error = %v, want ErrForbidden", err)
+			}
+		})
+	}
+}
+
+func TestPropertyScope(t *testing.T) {
+	landlordID := uuid.New()
+	assigned, other := uuid.New(), uuid.New()
+	allProperties := claimsFor("landlord", RoleBookkeeper, landlordID)
+	allProperties.AllProperties = true
+	tests := []struct {
+		name      string
+		claims    *domain.UserClaims
+		scoped    bool
+		canSee    []uuid.UUID
+		cannotSee []uuid.UUID
+	}{
+		{"owner is unscoped", claimsFor("landlord", "", landlordID, assigned), false, []uuid.UUID{assigned, other}, nil},
+		{"explicit owner is unscoped", claimsFor("landlord", RoleOwner, landlordID), false, []uuid.UUID{assigned, other}, nil},
+		{"manager sees assigned properties", claimsFor("landlord", RolePropertyManager, landlordID, assigned), true, []uuid.UUID{assigned}, []uuid.UUID{other}},
+		{"staff assigned every property are unscoped", allProperties, false, []uuid.UUID{assigned, other}, nil},
+		{"staff without assignments see nothing", claimsFor("landlord", RoleMaintenanceStaff, landlordID), true, nil, []uuid.UUID{assigned, other}},
+		{"tenants are not property scoped", claimsFor("tenant", "", landlordID), false, []uuid.UUID{assigned, other}, nil},
+		{"contractors are not property scoped", claimsFor("contractor", "", landlordID), false, []uuid.UUID{assigned, other}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PropertyScope(tt.claims) != nil; got != tt.scoped {
+				t.Errorf("PropertyScope() scoped = %v, want %v", got, tt.scoped)
+			}
+			for _, id := range tt.canSee {
+				if !CanAccessProperty(tt.claims, id) {
+					t.Errorf("CanAccessProperty(%s) = false, want true", id)
+				}
+			}
+			for _, id := range tt.cannotSee {
+				if CanAccessProperty(tt.claims, id) {
+					t.Errorf("CanAccessProperty(%s) = true, want false", id)
+				}
+			}
+		})
+	}
+}
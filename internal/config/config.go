@@ -2,15 +2,21 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	AWS      AWSConfig
-	JWT      JWTConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	AWS       AWSConfig
+	JWT       JWTConfig
+	Auth      AuthConfig
+	AI        AIConfig
+	Billing   BillingConfig
+	Scheduler SchedulerConfig
 }
 
 type ServerConfig struct {
@@ -25,6 +31,8 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// AutoMigrate applies pending migrations on boot
+	AutoMigrate bool
 }
 
 type AWSConfig struct {
@@ -43,6 +51,12 @@ type CognitoConfig struct {
 	ClientID       string
 	ClientSecret   string
 	Region         string
+	// JWKSURL overrides where token signing keys are fetched from, e.g. a
+	// stub server; by default they come from the user pool
+	JWKSURL string
+	// JWKSFile loads token signing keys from a local file instead, for
+	// running offline
+	JWKSFile string
 }
 
 type S3Config struct {
@@ -69,6 +83,73 @@ type SESConfig struct {
 type JWTConfig struct {
 	SecretKey string
 	Expiry    int // in hours
+	// RefreshExpiryDays is how long a local refresh token stays valid
+	RefreshExpiryDays int
+}
+
+type AuthConfig struct {
+	// Provider selects the identity provider: cognito, or local to keep
+	// accounts in Postgres and sign tokens with JWT_SECRET_KEY
+	Provider string
+	// LocalAutoConfirm confirms local accounts at signup without an emailed
+	// code, for development
+	LocalAutoConfirm bool
+}
+
+type AIConfig struct {
+	// Provider selects the model backend: bedrock, openai or fake
+	Provider string
+	// ContextTokenBudget caps the estimated tokens of prior turns sent with a
+	// thread message; older turns are summarized
+	ContextTokenBudget int
+	// PriceTableFile is a JSON file of per-model token prices merged over
+	// the built-in table
+	PriceTableFile string
+	// StrictPricing refuses to start when the configured model has no price
+	StrictPricing bool
+	OpenAI        OpenAIConfig
+	// Budget defaults apply to landlords without a saved AI budget. A limit
+	// of 0 means no limit.
+	MonthlyTokenBudget     int
+	MonthlyCostBudget      float64
+	TenantDailyTokenCap    int
+	BudgetSoftLimitPercent int
+	// TipsCacheTTL is how long generated tips are reused per category; 0
+	// disables the cache
+	TipsCacheTTL time.Duration
+	// AutoTriage has the model triage maintenance requests as tenants file
+	// them
+	AutoTriage bool
+}
+
+// OpenAIConfig points the openai provider at an OpenAI-compatible chat
+// completions server, such as a local model server
+type OpenAIConfig struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+}
+
+type BillingConfig struct {
+	// LateFeeGraceDays applies to landlords without a late fee policy
+	LateFeeGraceDays int
+	// RentReminderDays is how many days before the due date tenants are
+	// reminded of a pending charge
+	RentReminderDays int
+}
+
+// SchedulerConfig holds the background job schedules as cron expressions (UTC).
+// A schedule of "off" disables that job.
+type SchedulerConfig struct {
+	Enabled              bool
+	OverdueSweepSchedule string
+	RentChargeSchedule   string
+	// The reminder and alert jobs send for a fixed day offset, so they
+	// should run once a day
+	RentReminderSchedule string
+	LeaseExpirySchedule  string
+	// Orphaned file cleanup is off unless configured
+	OrphanedFileSchedule string
 }
 
 func Load() (*Config, error) {
@@ -87,6 +168,7 @@ func Load() (*Config, error) {
 			Password: getEnv("DB_PASSWORD", "password"),
 			DBName:   getEnv("DB_NAME", "dwell"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			AutoMigrate: getEnvBool("DB_AUTO_MIGRATE", false),
 		},
 		AWS: AWSConfig{
 			Region:          getEnv("AWS_REGION", "us-east-1"),
@@ -97,6 +179,8 @@ func Load() (*Config, error) {
 				ClientID:     getEnv("COGNITO_CLIENT_ID", ""),
 				ClientSecret: getEnv("COGNITO_CLIENT_SECRET", ""),
 				Region:       getEnv("COGNITO_REGION", "us-east-1"),
+				JWKSURL:      getEnv("COGNITO_JWKS_URL", ""),
+				JWKSFile:     getEnv("COGNITO_JWKS_FILE", ""),
 			},
 			S3: S3Config{
 				BucketName: getEnv("S3_BUCKET_NAME", ""),
@@ -116,8 +200,42 @@ func Load() (*Config, error) {
 			},
 		},
 		JWT: JWTConfig{
-			SecretKey: getEnv("JWT_SECRET_KEY", "your-secret-key"),
-			Expiry:    24, // 24 hours
+			SecretKey:         getEnv("JWT_SECRET_KEY", "your-secret-key"),
+			Expiry:            getEnvInt("JWT_EXPIRATION_HOURS", 24),
+			RefreshExpiryDays: getEnvInt("JWT_REFRESH_EXPIRATION_DAYS", 7),
+		},
+		Auth: AuthConfig{
+			Provider:         getEnv("AUTH_PROVIDER", "cognito"),
+			LocalAutoConfirm: getEnvBool("AUTH_LOCAL_AUTO_CONFIRM", false),
+		},
+		AI: AIConfig{
+			Provider:               getEnv("AI_PROVIDER", "bedrock"),
+			ContextTokenBudget:     getEnvInt("AI_CONTEXT_TOKEN_BUDGET", 4000),
+			PriceTableFile:         getEnv("AI_PRICE_TABLE_FILE", ""),
+			StrictPricing:          getEnvBool("AI_STRICT_PRICING", false),
+			MonthlyTokenBudget:     getEnvInt("AI_MONTHLY_TOKEN_BUDGET", 0),
+			MonthlyCostBudget:      getEnvFloat("AI_MONTHLY_COST_BUDGET", 0),
+			TenantDailyTokenCap:    getEnvInt("AI_TENANT_DAILY_TOKEN_CAP", 0),
+			BudgetSoftLimitPercent: getEnvInt("AI_BUDGET_SOFT_LIMIT_PERCENT", 80),
+			TipsCacheTTL:           getEnvDuration("AI_TIPS_CACHE_TTL", 24*time.Hour),
+			AutoTriage:             getEnvBool("AI_AUTO_TRIAGE", true),
+			OpenAI: OpenAIConfig{
+				BaseURL: getEnv("AI_OPENAI_BASE_URL", "http://localhost:11434/v1"),
+				APIKey:  getEnv("AI_OPENAI_API_KEY", ""),
+				Model:   getEnv("AI_OPENAI_MODEL", ""),
+			},
+		},
+		Billing: BillingConfig{
+			LateFeeGraceDays: getEnvInt("LATE_FEE_GRACE_DAYS", 5),
+			RentReminderDays: getEnvInt("RENT_REMINDER_DAYS", 3),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:              getEnvBool("SCHEDULER_ENABLED", true),
+			OverdueSweepSchedule: getEnv("OVERDUE_SWEEP_SCHEDULE", "0 * * * *"),
+			RentChargeSchedule:   getEnv("RENT_CHARGE_SCHEDULE", "0 2 * * *"),
+			RentReminderSchedule: getEnv("RENT_REMINDER_SCHEDULE", "0 14 * * *"),
+			LeaseExpirySchedule:  getEnv("LEASE_EXPIRY_SCHEDULE", "0 14 * * *"),
+			OrphanedFileSchedule: getEnv("ORPHANED_FILE_SCHEDULE", "off"),
 		},
 	}, nil
 }
@@ -129,3 +247,39 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -3,7 +3,6 @@ package controllers
 import (
 	"net/http"
 
-	"dwell/internal/middleware"
 	"dwell/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -21,7 +20,7 @@ func NewAIController(aiService *services.AIService) *AIController {
 
 // QueryAI handles AI chatbot queries
 // @Summary Query AI chatbot
-// @Description Ask a question to the AI property management assistant
+// @Description Ask a question to the AI property management assistant. The assistant may look up account data and propose actions, returned in actions, that run only once confirmed through /ai/actions/{id}/confirm.
 // @Tags AI Chatbot
 // @Accept json
 // @Produce json
@@ -30,9 +29,15 @@ func NewAIController(aiService *services.AIService) *AIController {
 // @Success 200 {object} services.AIQueryResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /ai/query [post]
 func (c *AIController) QueryAI(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
 	var req services.AIQueryRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{
@@ -42,103 +47,533 @@ func (c *AIController) QueryAI(ctx *gin.Context) {
 		return
 	}
 
-	// Get user information from context
-	userClaims, exists := middleware.GetUserClaimsFromContext(ctx)
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "User not authenticated",
-			Message: "Access token not found",
-		})
+	response, err := c.aiService.QueryAI(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to process AI query")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// StreamQueryAI handles AI chatbot queries with a streamed answer
+// @Summary Query AI chatbot (streaming)
+// @Description Ask a question and receive the answer as Server-Sent Events: "delta" events carry {"text": ...} as the answer is generated, then a "done" event carries the stored message with its usage and cost. An "error" event ends a failed stream. Errors before streaming starts are returned as JSON.
+// @Tags AI Chatbot
+// @Accept json
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param request body services.AIQueryRequest true "AI query request"
+// @Success 200 {object} services.AIQueryResponse "Final done event"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/query/stream [post]
+func (c *AIController) StreamQueryAI(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
 
-	// Set user type and landlord ID from context
-	req.UserType = userClaims.UserType
-	if userClaims.LandlordID != nil {
-		req.LandlordID = userClaims.LandlordID.String()
-	} else {
+	var req services.AIQueryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Landlord ID required",
-			Message: "User must be associated with a landlord",
+			Error:   "Invalid request data",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// Call AI service
-	response, err := c.aiService.QueryAI(ctx, &req)
+	// Headers are only sent with the first event so that failures before the
+	// model starts answering get a regular JSON error response
+	streaming := false
+	send := func(event string, data interface{}) {
+		if !streaming {
+			streaming = true
+			ctx.Header("Content-Type", "text/event-stream")
+			ctx.Header("Cache-Control", "no-cache")
+			ctx.Header("Connection", "keep-alive")
+			ctx.Header("X-Accel-Buffering", "no")
+			ctx.Status(http.StatusOK)
+		}
+		ctx.SSEvent(event, data)
+		ctx.Writer.Flush()
+	}
+
+	// The request context is cancelled when the client disconnects, which
+	// stops generation
+	reqCtx := ctx.Request.Context()
+	response, err := c.aiService.StreamQueryAI(reqCtx, userClaims, &req, func(text string) error {
+		send("delta", gin.H{"text": text})
+		return reqCtx.Err()
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+		if reqCtx.Err() != nil {
+			return
+		}
+		if !streaming {
+			handleServiceError(ctx, err, "Failed to process AI query")
+			return
+		}
+		send("error", ErrorResponse{
 			Error:   "Failed to process AI query",
 			Message: err.Error(),
 		})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	send("done", response)
 }
 
-// GetPropertyManagementTips returns AI-generated tips for property management
-// @Summary Get property management tips
-// @Description Get AI-generated tips for a specific property management category
+// CreateThread starts a multi-turn conversation
+// @Summary Create AI thread
+// @Description Start a conversation with the AI assistant. Threads are private to the user who creates them; the title defaults to the first question.
 // @Tags AI Chatbot
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param category query string true "Category of tips (e.g., tenant_management, maintenance, legal_compliance)"
-// @Success 200 {object} PropertyManagementTipsResponse
+// @Param request body services.CreateAIThreadRequest true "Thread"
+// @Success 201 {object} domain.AIThread
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
-// @Router /ai/tips [get]
-func (c *AIController) GetPropertyManagementTips(ctx *gin.Context) {
-	// Get user information from context
-	userClaims, exists := middleware.GetUserClaimsFromContext(ctx)
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "User not authenticated",
-			Message: "Access token not found",
+// @Router /ai/threads [post]
+func (c *AIController) CreateThread(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.CreateAIThreadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// Ensure user is a landlord
-	if userClaims.UserType != "landlord" {
-		ctx.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "Only landlords can access property management tips",
+	thread, err := c.aiService.CreateThread(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to create AI thread")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, thread)
+}
+
+// GetThreads lists the caller's conversations
+// @Summary List AI threads
+// @Description List the current user's AI conversations, most recently active first
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Maximum number of threads to return (default: 50)"
+// @Param offset query int false "Number of threads to skip (default: 0)"
+// @Success 200 {object} services.AIThreadListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/threads [get]
+func (c *AIController) GetThreads(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.AIThreadListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// Get category from query parameter
-	category := ctx.Query("category")
-	if category == "" {
+	response, err := c.aiService.ListThreads(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list AI threads")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetThread returns a conversation with its messages
+// @Summary Get AI thread
+// @Description Get one of the current user's AI conversations with its messages, oldest first
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Thread ID"
+// @Success 200 {object} services.AIThreadDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/threads/{id} [get]
+func (c *AIController) GetThread(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.aiService.GetThread(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get AI thread")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// PostThreadMessage asks a follow-up question in a conversation
+// @Summary Post AI thread message
+// @Description Ask a question in a conversation. Earlier turns are sent to the model with the question; the oldest turns are summarized once they exceed the context budget.
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Thread ID"
+// @Param request body services.AIQueryRequest true "AI query request"
+// @Success 200 {object} services.AIQueryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/threads/{id}/messages [post]
+func (c *AIController) PostThreadMessage(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req services.AIQueryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Category required",
-			Message: "Category parameter is required",
+			Error:   "Invalid request data",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// Get landlord ID
-	if userClaims.LandlordID == nil {
+	response, err := c.aiService.PostThreadMessage(ctx, userClaims, id, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to process AI query")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeleteThread deletes a conversation
+// @Summary Delete AI thread
+// @Description Delete one of the current user's AI conversations and its messages
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Thread ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/threads/{id} [delete]
+func (c *AIController) DeleteThread(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.aiService.DeleteThread(ctx, userClaims, id); err != nil {
+		handleServiceError(ctx, err, "Failed to delete AI thread")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Message: "Thread deleted successfully",
+	})
+}
+
+// GetActions lists actions the assistant has taken or proposed
+// @Summary List AI actions
+// @Description List tool calls made by the AI assistant, newest first. Landlords see every action under their account; tenants only their own.
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (pending_confirmation, confirmed, executed, failed, rejected, expired)"
+// @Param limit query int false "Maximum number of actions to return (default: 50)"
+// @Param offset query int false "Number of actions to skip (default: 0)"
+// @Success 200 {object} services.AIActionListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/actions [get]
+func (c *AIController) GetActions(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.AIActionListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Landlord ID required",
-			Message: "User must be associated with a landlord",
+			Error:   "Invalid request data",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// Call AI service
-	tips, err := c.aiService.GetPropertyManagementTips(ctx, userClaims.LandlordID.String(), category)
+	response, err := c.aiService.ListActions(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list AI actions")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ConfirmAction runs an action the assistant proposed
+// @Summary Confirm AI action
+// @Description Confirm and run an action the AI assistant proposed to the current user, such as filing a maintenance request or emailing a tenant. Actions expire 24 hours after they are proposed.
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Action ID"
+// @Success 200 {object} domain.AIToolInvocation
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/actions/{id}/confirm [post]
+func (c *AIController) ConfirmAction(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	action, err := c.aiService.ConfirmAction(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to run AI action")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, action)
+}
+
+// RejectAction discards an action the assistant proposed
+// @Summary Reject AI action
+// @Description Reject an action the AI assistant proposed to the current user without running it
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Action ID"
+// @Success 200 {object} domain.AIToolInvocation
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/actions/{id}/reject [post]
+func (c *AIController) RejectAction(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	action, err := c.aiService.RejectAction(ctx, userClaims, id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to generate tips",
+		handleServiceError(ctx, err, "Failed to reject AI action")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, action)
+}
+
+// GetBudget returns the landlord's AI budget
+// @Summary Get AI budget
+// @Description Get the current landlord's monthly AI token and cost limits, the daily token cap per tenant and the share of a monthly limit at which they are warned. Landlords without a saved budget get the configured default. Limits of 0 mean no limit.
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.AIBudget
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/budget [get]
+func (c *AIController) GetBudget(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	budget, err := c.aiService.GetBudget(ctx, userClaims)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get AI budget")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, budget)
+}
+
+// UpdateBudget replaces the landlord's AI budget
+// @Summary Update AI budget
+// @Description Set the monthly AI token and cost limits for the landlord's account (UTC calendar month), the daily token cap per tenant and the soft limit percentage at which the landlord is emailed a warning. Queries are refused with 429 once a limit is used up.
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.UpdateAIBudgetRequest true "AI budget"
+// @Success 200 {object} domain.AIBudget
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/budget [put]
+func (c *AIController) UpdateBudget(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.UpdateAIBudgetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	budget, err := c.aiService.UpdateBudget(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to update AI budget")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, budget)
+}
+
+// GetBudgetUsage returns consumption against the AI budget
+// @Summary Get AI budget usage
+// @Description Get this month's AI usage against the landlord's budget and, with tenant_id, that tenant's usage today against the daily cap. Tenants get only their own usage today.
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tenant_id query string false "Also report this tenant's usage today (landlords only)"
+// @Success 200 {object} services.AIBudgetUsageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/budget/usage [get]
+func (c *AIController) GetBudgetUsage(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.AIBudgetUsageRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
 			Message: err.Error(),
 		})
 		return
 	}
 
+	response, err := c.aiService.GetBudgetUsage(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get AI budget usage")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetPropertyManagementTips returns AI-generated tips for property management
+// @Summary Get property management tips
+// @Description Get AI-generated tips for a specific property management category. Each tip has a title, body, category, difficulty (easy, moderate or hard) and estimated cost in USD. Tips are cached per category, and cached tips do not count against the AI budget.
+// @Tags AI Chatbot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param category query string true "Category of tips (e.g., tenant_management, maintenance, legal_compliance)"
+// @Success 200 {object} PropertyManagementTipsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ai/tips [get]
+func (c *AIController) GetPropertyManagementTips(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Get category from query parameter
+	category := ctx.Query("category")
+	if category == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Category required",
+			Message: "Category parameter is required",
+		})
+		return
+	}
+
+	tips, err := c.aiService.GetPropertyManagementTips(ctx, userClaims, category)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to generate tips")
+		return
+	}
+
 	ctx.JSON(http.StatusOK, PropertyManagementTipsResponse{
 		Category: category,
 		Tips:     tips,
@@ -148,107 +583,254 @@ func (c *AIController) GetPropertyManagementTips(ctx *gin.Context) {
 
 // GetAIChatHistory returns the chat history for the current user
 // @Summary Get AI chat history
-// @Description Get the user's AI chat conversation history
+// @Description Get stored AI chat messages, newest first. Landlords see every conversation under their account and may filter by tenant; tenants see only their own.
 // @Tags AI Chatbot
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param tenant_id query string false "Filter by tenant ID (landlords only)"
+// @Param from query string false "Only messages on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Only messages on or before this date (YYYY-MM-DD)"
 // @Param limit query int false "Maximum number of messages to return (default: 50)"
 // @Param offset query int false "Number of messages to skip (default: 0)"
-// @Success 200 {object} AIChatHistoryResponse
+// @Success 200 {object} services.AIChatHistoryResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /ai/history [get]
 func (c *AIController) GetAIChatHistory(ctx *gin.Context) {
-	// Get user information from context
-	_, exists := middleware.GetUserClaimsFromContext(ctx)
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "User not authenticated",
-			Message: "Access token not found",
-		})
+	userClaims, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
 
-	// Get query parameters
-	_ = ctx.DefaultQuery("limit", "50")
-	_ = ctx.DefaultQuery("offset", "0")
+	var req services.AIChatHistoryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
 
-	// TODO: Implement chat history retrieval from database
-	// This would typically involve a repository layer to fetch AI chat messages
+	response, err := c.aiService.GetChatHistory(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get AI chat history")
+		return
+	}
 
-	// For now, return empty response
-	ctx.JSON(http.StatusOK, AIChatHistoryResponse{
-		Messages: []interface{}{},
-		Total:    0,
-		Limit:    50,
-		Offset:   0,
-	})
+	ctx.JSON(http.StatusOK, response)
 }
 
 // GetAIAnalytics returns analytics about AI usage
 // @Summary Get AI usage analytics
-// @Description Get analytics about AI chatbot usage for the current user/landlord
+// @Description Get query, token and cost totals, daily usage and popular question topics for the current landlord's account (or, for tenants, their own questions) over a window ending today
 // @Tags AI Chatbot
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param period query string false "Time period for analytics (day, week, month, year) - default: month"
-// @Success 200 {object} AIAnalyticsResponse
+// @Param tenant_id query string false "Filter by tenant ID (landlords only)"
+// @Success 200 {object} services.AIAnalyticsResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /ai/analytics [get]
 func (c *AIController) GetAIAnalytics(ctx *gin.Context) {
-	// Get user information from context
-	_, exists := middleware.GetUserClaimsFromContext(ctx)
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "User not authenticated",
-			Message: "Access token not found",
-		})
+	userClaims, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
 
-	// Get period from query parameter
-	period := ctx.DefaultQuery("period", "month")
+	var req services.AIAnalyticsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
 
-	// TODO: Implement AI analytics retrieval from database
-	// This would involve aggregating data from AI chat messages
+	response, err := c.aiService.GetAnalytics(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get AI analytics")
+		return
+	}
 
-	// For now, return placeholder analytics
-	ctx.JSON(http.StatusOK, AIAnalyticsResponse{
-		Period:        period,
-		TotalQueries:  0,
-		TotalTokens:   0,
-		TotalCost:     0.0,
-		AverageTokens: 0,
-		PopularTopics: []string{},
-		UsageByDay:    map[string]int{},
-	})
+	ctx.JSON(http.StatusOK, response)
 }
 
 // Response types
 type PropertyManagementTipsResponse struct {
-	Category string   `json:"category"`
-	Tips     []string `json:"tips"`
-	Count    int      `json:"count"`
-}
-
-type AIChatHistoryResponse struct {
-	Messages []interface{} `json:"messages"`
-	Total    int           `json:"total"`
-	Limit    int           `json:"limit"`
-	Offset   int           `json:"offset"`
-}
-
-type AIAnalyticsResponse struct {
-	Period        string         `json:"period"`
-	TotalQueries  int            `json:"total_queries"`
-	TotalTokens   int            `json:"total_tokens"`
-	TotalCost     float64        `json:"total_cost"`
-	AverageTokens int            `json:"average_tokens"`
-	PopularTopics []string       `json:"popular_topics"`
-	UsageByDay    map[string]int `json:"usage_by_day"`
+	Category string                           `json:"category"`
+	Tips     []services.PropertyManagementTip `json:"tips"`
+	Count    int                              `json:"count"`
+}
+
+// TriageMaintenanceRequest has the AI triage a maintenance request now
+// @Summary Triage maintenance request
+// @Description Ask the AI to suggest a category, priority, cost range and contractor for a maintenance request. The suggestion is stored for review and replaces any pending one; it is not applied until approved. Landlord only.
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Success 201 {object} domain.MaintenanceTriage
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id}/triage [post]
+func (c *AIController) TriageMaintenanceRequest(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	suggestion, err := c.aiService.TriageMaintenanceRequest(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to triage maintenance request")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, suggestion)
+}
+
+// GetTriage lists a maintenance request's AI triage suggestions
+// @Summary List maintenance triage suggestions
+// @Description List the AI triage suggestions for a maintenance request, newest first (landlord only)
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Success 200 {object} services.MaintenanceTriageListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id}/triage [get]
+func (c *AIController) GetTriage(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.aiService.ListTriage(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list triage suggestions")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ApproveTriage applies a pending AI triage suggestion
+// @Summary Approve maintenance triage suggestion
+// @Description Apply a pending suggestion's category, priority, contractor and cost estimate (the middle of its range) to the maintenance request. The optional note is recorded in the request history. Landlord only.
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param triage_id path string true "Triage suggestion ID"
+// @Param request body services.ReviewTriageRequest false "Review note"
+// @Success 200 {object} services.ApproveTriageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id}/triage/{triage_id}/approve [post]
+func (c *AIController) ApproveTriage(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	triageID, ok := parseIDParam(ctx, "triage_id")
+	if !ok {
+		return
+	}
+
+	var req services.ReviewTriageRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request data",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	response, err := c.aiService.ApproveTriage(ctx, userClaims, id, triageID, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to approve triage suggestion")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RejectTriage discards a pending AI triage suggestion
+// @Summary Reject maintenance triage suggestion
+// @Description Discard a pending AI triage suggestion without changing the maintenance request (landlord only)
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param triage_id path string true "Triage suggestion ID"
+// @Success 200 {object} domain.MaintenanceTriage
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id}/triage/{triage_id}/reject [post]
+func (c *AIController) RejectTriage(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	triageID, ok := parseIDParam(ctx, "triage_id")
+	if !ok {
+		return
+	}
+
+	suggestion, err := c.aiService.RejectTriage(ctx, userClaims, id, triageID)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to reject triage suggestion")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, suggestion)
 }
@@ -31,10 +31,7 @@ func (c *AuthController) SignUp(ctx *gin.Context) {
 
 	response, err := c.authService.SignUp(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Registration failed",
-			Message: err.Error(),
-		})
+		handleServiceError(ctx, err, "Registration failed")
 		return
 	}
 
@@ -54,15 +51,33 @@ func (c *AuthController) ConfirmSignUp(ctx *gin.Context) {
 
 	err := c.authService.ConfirmSignUp(ctx, req.Email, req.ConfirmationCode)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Confirmation failed",
+		handleServiceError(ctx, err, "Confirmation failed")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Message: "User registration confirmed successfully",
+	})
+}
+
+// ResendConfirmationCode sends a new registration confirmation code
+func (c *AuthController) ResendConfirmationCode(ctx *gin.Context) {
+	var req ResendConfirmationCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
 			Message: err.Error(),
 		})
 		return
 	}
 
+	if err := c.authService.ResendConfirmationCode(ctx, req.Email); err != nil {
+		handleServiceError(ctx, err, "Failed to resend confirmation code")
+		return
+	}
+
 	ctx.JSON(http.StatusOK, SuccessResponse{
-		Message: "User registration confirmed successfully",
+		Message: "If the account is awaiting confirmation, a new code has been sent",
 	})
 }
 
@@ -181,6 +196,10 @@ type ConfirmSignUpRequest struct {
 	ConfirmationCode string `json:"confirmation_code" binding:"required"`
 }
 
+type ResendConfirmationCodeRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -0,0 +1,473 @@
+package controllers
+
+import (
+	"net/http"
+
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ContractorController struct {
+	contractorService  *services.ContractorService
+	maintenanceService *services.MaintenanceService
+}
+
+func NewContractorController(contractorService *services.ContractorService, maintenanceService *services.MaintenanceService) *ContractorController {
+	return &ContractorController{
+		contractorService:  contractorService,
+		maintenanceService: maintenanceService,
+	}
+}
+
+// GetContractors lists the landlord's contractors
+// @Summary List contractors
+// @Description List the current landlord's contractors with optional filters
+// @Tags Contractors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param specialization query string false "Filter by specialization"
+// @Param is_active query bool false "Filter by active status"
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param offset query int false "Offset for pagination (default: 0)"
+// @Success 200 {object} services.ContractorListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/contractors [get]
+func (c *ContractorController) GetContractors(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.ContractorListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.contractorService.ListContractors(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list contractors")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetContractor returns a single contractor
+// @Summary Get contractor
+// @Description Get one of the landlord's contractors
+// @Tags Contractors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Contractor ID"
+// @Success 200 {object} domain.Contractor
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/contractors/{id} [get]
+func (c *ContractorController) GetContractor(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	contractor, err := c.contractorService.GetContractor(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get contractor")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, contractor)
+}
+
+// CreateContractor adds a contractor
+// @Summary Add contractor
+// @Description Add a contractor to the landlord's roster, optionally emailing them a signup invitation
+// @Tags Contractors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.CreateContractorRequest true "Contractor details"
+// @Success 201 {object} domain.Contractor
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/contractors [post]
+func (c *ContractorController) CreateContractor(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.CreateContractorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	contractor, err := c.contractorService.CreateContractor(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to create contractor")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, contractor)
+}
+
+// InviteContractor emails a signup invitation to a contractor
+// @Summary Invite contractor
+// @Description Email the contractor an invitation code that links their signup to this contractor record. Any previous invitation is revoked.
+// @Tags Contractors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Contractor ID"
+// @Success 201 {object} services.ContractorInvitationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/contractors/{id}/invite [post]
+func (c *ContractorController) InviteContractor(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.contractorService.InviteContractor(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to invite contractor")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// GetJobs lists the maintenance requests assigned to the calling contractor
+// @Summary List contractor jobs
+// @Description List the maintenance requests assigned to the current contractor
+// @Tags Contractor Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status" Enums(open, in_progress, completed, cancelled)
+// @Param priority query string false "Filter by priority" Enums(low, medium, high, emergency)
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param offset query int false "Offset for pagination (default: 0)"
+// @Success 200 {object} services.MaintenanceListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /contractor/jobs [get]
+func (c *ContractorController) GetJobs(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.MaintenanceListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.maintenanceService.ListRequests(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list jobs")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetJob returns a job assigned to the calling contractor
+// @Summary Get contractor job
+// @Description Get a maintenance request assigned to the current contractor with its history and photos
+// @Tags Contractor Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Success 200 {object} services.MaintenanceDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /contractor/jobs/{id} [get]
+func (c *ContractorController) GetJob(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.maintenanceService.GetRequest(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get job")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// AcceptJob accepts an assigned job
+// @Summary Accept job
+// @Description Accept a maintenance request the landlord assigned to the current contractor
+// @Tags Contractor Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Success 200 {object} services.MaintenanceDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /contractor/jobs/{id}/accept [post]
+func (c *ContractorController) AcceptJob(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.maintenanceService.AcceptJob(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to accept job")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeclineJob declines an assigned job
+// @Summary Decline job
+// @Description Decline a maintenance request; the contractor is unassigned and the reason is recorded in its history
+// @Tags Contractor Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param request body services.DeclineJobRequest true "Reason for declining"
+// @Success 200 {object} domain.MaintenanceRequest
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /contractor/jobs/{id}/decline [post]
+func (c *ContractorController) DeclineJob(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req services.DeclineJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	request, err := c.maintenanceService.DeclineJob(ctx, userClaims, id, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to decline job")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+// PostJobUpdate posts a progress update on an accepted job
+// @Summary Post job update
+// @Description Move an accepted job to in_progress or completed, or add a progress note
+// @Tags Contractor Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param request body services.ContractorJobUpdateRequest true "Status and/or note"
+// @Success 200 {object} services.MaintenanceDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /contractor/jobs/{id}/updates [post]
+func (c *ContractorController) PostJobUpdate(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req services.ContractorJobUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.maintenanceService.PostJobUpdate(ctx, userClaims, id, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to update job")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UploadJobPhotos attaches after photos to an accepted job
+// @Summary Upload job photos
+// @Description Upload one or more after photos (max 10, 10 MB each) to an accepted job
+// @Tags Contractor Portal
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param photos formData file true "Photo files (repeat the field for multiple photos)"
+// @Param description formData string false "Photo description"
+// @Success 201 {array} services.MaintenancePhotoResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /contractor/jobs/{id}/photos [post]
+func (c *ContractorController) UploadJobPhotos(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil || len(form.File["photos"]) == 0 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "File upload failed",
+			Message: "No photos provided or invalid form",
+		})
+		return
+	}
+
+	req := &services.UploadMaintenancePhotosRequest{
+		Files:       form.File["photos"],
+		Description: ctx.PostForm("description"),
+	}
+
+	photos, err := c.maintenanceService.UploadPhotos(ctx, userClaims, id, req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to upload photos")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, photos)
+}
+
+// SubmitJobCost records the hours and materials spent on a job
+// @Summary Submit job cost
+// @Description Record the hours worked and materials used on an accepted job. The actual cost is the hours at the contractor's hourly rate plus materials.
+// @Tags Contractor Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param request body services.ContractorJobCostRequest true "Hours and materials"
+// @Success 200 {object} services.MaintenanceDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /contractor/jobs/{id}/cost [put]
+func (c *ContractorController) SubmitJobCost(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req services.ContractorJobCostRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.maintenanceService.SubmitJobCost(ctx, userClaims, id, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to submit job cost")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+	"dwell/internal/middleware"
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentUser returns the authenticated user's claims, writing a 401 if absent
+func currentUser(ctx *gin.Context) (*domain.UserClaims, bool) {
+	userClaims, exists := middleware.GetUserClaimsFromContext(ctx)
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "User not authenticated",
+			Message: "Access token not found",
+		})
+		return nil, false
+	}
+	return userClaims, true
+}
+
+// authorizeLandlord checks that the caller acts within the given landlord
+// account, writing a 403 if not
+func authorizeLandlord(ctx *gin.Context, userClaims *domain.UserClaims, landlordID string) bool {
+	id, err := uuid.Parse(landlordID)
+	if err == nil {
+		err = authz.CheckLandlord(userClaims, id)
+	} else {
+		err = fmt.Errorf("%w: invalid landlord ID", authz.ErrForbidden)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Access denied",
+			Message: err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// parseIDParam parses a UUID path parameter, writing a 400 if it is malformed
+func parseIDParam(ctx *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid ID",
+			Message: name + " must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// handleServiceError maps service errors to HTTP responses
+func handleServiceError(ctx *gin.Context, err error, failure string) {
+	var validationErr *services.ValidationError
+	var quotaErr *services.QuotaError
+	switch {
+	case errors.As(err, &validationErr):
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: validationErr.Message,
+		})
+	case errors.Is(err, services.ErrInvalidCursor):
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Access denied",
+			Message: err.Error(),
+		})
+	case errors.As(err, &quotaErr):
+		if wait := math.Ceil(time.Until(quotaErr.ResetAt).Seconds()); wait > 0 {
+			ctx.Header("Retry-After", strconv.Itoa(int(wait)))
+		}
+		ctx.JSON(http.StatusTooManyRequests, ErrorResponse{
+			Error:   "Quota exceeded",
+			Message: quotaErr.Message,
+		})
+	case errors.Is(err, services.ErrConflict):
+		ctx.JSON(http.StatusConflict, ErrorResponse{
+			Error:   failure,
+			Message: err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   failure,
+			Message: err.Error(),
+		})
+	}
+}
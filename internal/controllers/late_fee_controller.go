@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"net/http"
+
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LateFeeController struct {
+	lateFeeService *services.LateFeeService
+}
+
+func NewLateFeeController(lateFeeService *services.LateFeeService) *LateFeeController {
+	return &LateFeeController{
+		lateFeeService: lateFeeService,
+	}
+}
+
+// GetPolicy returns the landlord's late fee policy
+// @Summary Get late fee policy
+// @Description Get the current landlord's late fee policy. Landlords without a saved policy get a disabled policy with the default grace period.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.LateFeePolicy
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/late-fee-policy [get]
+func (c *LateFeeController) GetPolicy(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	policy, err := c.lateFeeService.GetPolicy(ctx, userClaims)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get late fee policy")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
+
+// UpdatePolicy replaces the landlord's late fee policy
+// @Summary Update late fee policy
+// @Description Set the grace period and the flat, percentage and daily late fees charged on overdue payments, with an optional cap per charge
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.UpdateLateFeePolicyRequest true "Late fee policy"
+// @Success 200 {object} domain.LateFeePolicy
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/late-fee-policy [put]
+func (c *LateFeeController) UpdatePolicy(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.UpdateLateFeePolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	policy, err := c.lateFeeService.UpdatePolicy(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to update late fee policy")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
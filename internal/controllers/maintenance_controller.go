@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"net/http"
+
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceController struct {
+	maintenanceService *services.MaintenanceService
+}
+
+func NewMaintenanceController(maintenanceService *services.MaintenanceService) *MaintenanceController {
+	return &MaintenanceController{
+		maintenanceService: maintenanceService,
+	}
+}
+
+// CreateRequest files a maintenance request
+// @Summary Create maintenance request
+// @Description Tenants file a request for the property they rent; landlords file one for a property by ID
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.CreateMaintenanceRequest true "Maintenance request details"
+// @Success 201 {object} services.MaintenanceDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests [post]
+func (c *MaintenanceController) CreateRequest(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.CreateMaintenanceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.maintenanceService.CreateRequest(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to create maintenance request")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// GetRequests lists maintenance requests
+// @Summary List maintenance requests
+// @Description Landlords see all requests for their properties; tenants see their own
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param property_id query string false "Filter by property"
+// @Param contractor_id query string false "Filter by assigned contractor"
+// @Param status query string false "Filter by status (open, in_progress, completed, cancelled)"
+// @Param priority query string false "Filter by priority (low, medium, high, emergency)"
+// @Param category query string false "Filter by category"
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param offset query int false "Offset for pagination (default: 0)"
+// @Success 200 {object} services.MaintenanceListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests [get]
+func (c *MaintenanceController) GetRequests(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.MaintenanceListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.maintenanceService.ListRequests(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list maintenance requests")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetRequest returns a single maintenance request
+// @Summary Get maintenance request
+// @Description Get a maintenance request with its status history and before/after photos
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Success 200 {object} services.MaintenanceDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id} [get]
+func (c *MaintenanceController) GetRequest(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.maintenanceService.GetRequest(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get maintenance request")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateRequest updates a maintenance request
+// @Summary Update maintenance request
+// @Description Landlords assign contractors, set costs and move the request through open, in_progress, completed and cancelled. Tenants may edit or cancel their own open requests. Completed and cancelled requests cannot change status.
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param request body services.UpdateMaintenanceRequest true "Fields to update"
+// @Success 200 {object} services.MaintenanceDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id} [put]
+func (c *MaintenanceController) UpdateRequest(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req services.UpdateMaintenanceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.maintenanceService.UpdateRequest(ctx, userClaims, id, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to update maintenance request")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UploadPhotos attaches photos to a maintenance request
+// @Summary Upload maintenance photos
+// @Description Upload one or more images (max 10, 10 MB each) to a maintenance request
+// @Tags Maintenance
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param photos formData file true "Photo files (repeat the field for multiple photos)"
+// @Param description formData string false "Photo description"
+// @Param is_before formData bool false "Whether the photos show the issue before work started"
+// @Success 201 {array} services.MaintenancePhotoResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id}/photos [post]
+func (c *MaintenanceController) UploadPhotos(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil || len(form.File["photos"]) == 0 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "File upload failed",
+			Message: "No photos provided or invalid form",
+		})
+		return
+	}
+
+	req := &services.UploadMaintenancePhotosRequest{
+		Files:       form.File["photos"],
+		Description: ctx.PostForm("description"),
+		IsBefore:    ctx.PostForm("is_before") == "true",
+	}
+
+	photos, err := c.maintenanceService.UploadPhotos(ctx, userClaims, id, req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to upload photos")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, photos)
+}
+
+// GetPhotos lists the photos of a maintenance request
+// @Summary List maintenance photos
+// @Description Get a maintenance request's photos grouped into before and after, with presigned download URLs
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Success 200 {object} services.MaintenancePhotoGroups
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id}/photos [get]
+func (c *MaintenanceController) GetPhotos(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	photos, err := c.maintenanceService.ListPhotos(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list photos")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, photos)
+}
+
+// DeletePhoto removes a photo from a maintenance request
+// @Summary Delete maintenance photo
+// @Description Delete a maintenance photo and its stored file (landlord only)
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Maintenance request ID"
+// @Param photo_id path string true "Photo ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance/requests/{id}/photos/{photo_id} [delete]
+func (c *MaintenanceController) DeletePhoto(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	photoID, ok := parseIDParam(ctx, "photo_id")
+	if !ok {
+		return
+	}
+
+	if err := c.maintenanceService.DeletePhoto(ctx, userClaims, id, photoID); err != nil {
+		handleServiceError(ctx, err, "Failed to delete photo")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Message: "Photo deleted successfully",
+	})
+}
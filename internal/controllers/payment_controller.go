@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"net/http"
+
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PaymentController struct {
+	paymentService *services.PaymentService
+}
+
+func NewPaymentController(paymentService *services.PaymentService) *PaymentController {
+	return &PaymentController{
+		paymentService: paymentService,
+	}
+}
+
+// GetPayments lists payment charges
+// @Summary List payments
+// @Description Landlords see charges for all their tenants; tenants see their own
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tenant_id query string false "Filter by tenant (landlords only)"
+// @Param property_id query string false "Filter by property"
+// @Param status query string false "Filter by status (pending, paid, overdue, cancelled)"
+// @Param payment_type query string false "Filter by payment type"
+// @Param due_from query string false "Due on or after (YYYY-MM-DD)"
+// @Param due_to query string false "Due on or before (YYYY-MM-DD)"
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param offset query int false "Offset for pagination (default: 0)"
+// @Success 200 {object} services.PaymentListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments [get]
+func (c *PaymentController) GetPayments(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.PaymentListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.paymentService.ListPayments(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list payments")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetPayment returns a single charge
+// @Summary Get payment
+// @Description Get a charge with the payments received against it
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} services.PaymentDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments/{id} [get]
+func (c *PaymentController) GetPayment(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.paymentService.GetPayment(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get payment")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreateCharge adds a one-off charge
+// @Summary Create charge
+// @Description Add a one-off charge (deposit, repair, utility, etc.) for a tenant
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.CreateChargeRequest true "Charge details"
+// @Success 201 {object} domain.Payment
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments [post]
+func (c *PaymentController) CreateCharge(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.CreateChargeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	payment, err := c.paymentService.CreateCharge(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to create charge")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, payment)
+}
+
+// RecordPayment records money received against a charge
+// @Summary Record payment
+// @Description Record a full or partial payment against a charge. The charge is marked paid once fully covered.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Param request body services.RecordPaymentRequest true "Payment details"
+// @Success 201 {object} services.PaymentDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments/{id}/receipts [post]
+func (c *PaymentController) RecordPayment(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req services.RecordPaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.paymentService.RecordPayment(ctx, userClaims, id, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to record payment")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// CancelCharge cancels a charge
+// @Summary Cancel charge
+// @Description Cancel a charge that has not received any payments
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments/{id}/cancel [post]
+func (c *PaymentController) CancelCharge(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	payment, err := c.paymentService.CancelCharge(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to cancel charge")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payment)
+}
+
+// GenerateCharges generates monthly rent charges from tenant leases
+// @Summary Generate rent charges
+// @Description Create monthly rent charges from lease terms up to a date (default: end of the current month). Partial months are prorated and existing periods are skipped.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.GenerateChargesRequest false "Tenant and cut-off date"
+// @Success 200 {object} services.GenerateChargesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments/generate [post]
+func (c *PaymentController) GenerateCharges(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.GenerateChargesRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request data",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	response, err := c.paymentService.GenerateRentCharges(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to generate charges")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetLedger returns a tenant's ledger
+// @Summary Get tenant ledger
+// @Description Get a tenant's charges and payments in date order with running balances. Tenants always get their own ledger.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tenant_id query string false "Tenant ID (required for landlords)"
+// @Success 200 {object} services.LedgerResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments/ledger [get]
+func (c *PaymentController) GetLedger(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var tenantID *uuid.UUID
+	if raw := ctx.Query("tenant_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid ID",
+				Message: "tenant_id must be a valid UUID",
+			})
+			return
+		}
+		tenantID = &id
+	}
+
+	ledger, err := c.paymentService.GetLedger(ctx, userClaims, tenantID)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get ledger")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ledger)
+}
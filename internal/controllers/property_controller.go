@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"net/http"
+
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PropertyController struct {
+	propertyService *services.PropertyService
+}
+
+func NewPropertyController(propertyService *services.PropertyService) *PropertyController {
+	return &PropertyController{
+		propertyService: propertyService,
+	}
+}
+
+// GetProperties lists properties visible to the current user
+// @Summary List properties
+// @Description Landlords get their properties with filtering, sorting and cursor pagination; tenants get the property they currently rent
+// @Tags Properties
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param city query string false "Filter by city"
+// @Param state query string false "Filter by state"
+// @Param property_type query string false "Filter by property type"
+// @Param is_available query bool false "Filter by availability"
+// @Param sort_by query string false "Sort field (created_at, name, city, monthly_rent) - default: created_at"
+// @Param order query string false "Sort order (asc, desc) - default: asc"
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} services.PropertyListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /properties [get]
+func (c *PropertyController) GetProperties(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.PropertyListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.propertyService.ListProperties(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list properties")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetProperty returns a single property
+// @Summary Get property
+// @Description Get a property by ID
+// @Tags Properties
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Property ID"
+// @Success 200 {object} domain.Property
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /properties/{id} [get]
+func (c *PropertyController) GetProperty(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	property, err := c.propertyService.GetProperty(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get property")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, property)
+}
+
+// CreateProperty creates a new property
+// @Summary Create property
+// @Description Create a property owned by the current landlord
+// @Tags Properties
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.CreatePropertyRequest true "Property details"
+// @Success 201 {object} domain.Property
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /properties [post]
+func (c *PropertyController) CreateProperty(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.CreatePropertyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	property, err := c.propertyService.CreateProperty(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to create property")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, property)
+}
+
+// UpdateProperty updates an existing property
+// @Summary Update property
+// @Description Partially update a property owned by the current landlord
+// @Tags Properties
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Property ID"
+// @Param request body services.UpdatePropertyRequest true "Fields to update"
+// @Success 200 {object} domain.Property
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /properties/{id} [put]
+func (c *PropertyController) UpdateProperty(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req services.UpdatePropertyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	property, err := c.propertyService.UpdateProperty(ctx, userClaims, id, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to update property")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, property)
+}
+
+// DeleteProperty deletes a property
+// @Summary Delete property
+// @Description Delete a property owned by the current landlord. Properties with a current tenant cannot be deleted.
+// @Tags Properties
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Property ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /properties/{id} [delete]
+func (c *PropertyController) DeleteProperty(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.propertyService.DeleteProperty(ctx, userClaims, id); err != nil {
+		handleServiceError(ctx, err, "Failed to delete property")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Message: "Property deleted successfully",
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"dwell/internal/authz"
 	"dwell/internal/middleware"
 	"dwell/internal/services"
 
@@ -30,7 +31,7 @@ func NewS3Controller(s3Service *services.S3Service) *S3Controller {
 // @Security BearerAuth
 // @Param file formData file true "File to upload"
 // @Param landlord_id formData string true "Landlord ID"
-// @Param category formData string true "File category (maintenance_photo, property_photo, document)"
+// @Param category formData string true "File category (property_photo, document); maintenance photos are uploaded through the maintenance endpoints"
 // @Param entity_id formData string true "ID of the related entity"
 // @Param description formData string false "File description"
 // @Param is_before_photo formData bool false "For maintenance photos: indicates if this is a before photo"
@@ -76,12 +77,9 @@ func (c *S3Controller) UploadFile(ctx *gin.Context) {
 		return
 	}
 
-	// Verify user has access to the landlord
-	if userClaims.LandlordID == nil || userClaims.LandlordID.String() != landlordID {
-		ctx.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You can only upload files for your own landlord account",
-		})
+	// Verify the user may store files under the entity
+	if err := c.s3Service.AuthorizeFiles(ctx, userClaims, authz.ActionCreate, landlordID, category, entityID); err != nil {
+		handleServiceError(ctx, err, "File upload failed")
 		return
 	}
 
@@ -141,12 +139,12 @@ func (c *S3Controller) DeleteFile(ctx *gin.Context) {
 		return
 	}
 
-	// Verify user has access to the landlord
-	if userClaims.LandlordID == nil || userClaims.LandlordID.String() != req.LandlordID {
-		ctx.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You can only delete files for your own landlord account",
-		})
+	// Verify user has access to the landlord and may delete the file
+	if !authorizeLandlord(ctx, userClaims, req.LandlordID) {
+		return
+	}
+	if err := c.s3Service.AuthorizeFileKey(ctx, userClaims, authz.ActionDelete, req.FileKey); err != nil {
+		handleServiceError(ctx, err, "File deletion failed")
 		return
 	}
 
@@ -205,12 +203,9 @@ func (c *S3Controller) ListFiles(ctx *gin.Context) {
 		return
 	}
 
-	// Verify user has access to the landlord
-	if userClaims.LandlordID == nil || userClaims.LandlordID.String() != landlordID {
-		ctx.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "Access denied",
-			Message: "You can only list files for your own landlord account",
-		})
+	// Verify the user may read the entity's files
+	if err := c.s3Service.AuthorizeFiles(ctx, userClaims, authz.ActionRead, landlordID, category, entityID); err != nil {
+		handleServiceError(ctx, err, "Failed to list files")
 		return
 	}
 
@@ -243,7 +238,7 @@ func (c *S3Controller) ListFiles(ctx *gin.Context) {
 // @Router /files/signed-url [get]
 func (c *S3Controller) GetSignedURL(ctx *gin.Context) {
 	// Get user information from context
-	_, exists := middleware.GetUserClaimsFromContext(ctx)
+	userClaims, exists := middleware.GetUserClaimsFromContext(ctx)
 	if !exists {
 		ctx.JSON(http.StatusUnauthorized, ErrorResponse{
 			Error:   "User not authenticated",
@@ -272,8 +267,11 @@ func (c *S3Controller) GetSignedURL(ctx *gin.Context) {
 		}
 	}
 
-	// TODO: Verify user has access to the file
-	// This would typically involve checking file metadata or database records
+	// Verify the user may read the file
+	if err := c.s3Service.AuthorizeFileKey(ctx, userClaims, authz.ActionRead, fileKey); err != nil {
+		handleServiceError(ctx, err, "Failed to generate signed URL")
+		return
+	}
 
 	// Generate signed URL
 	signedURL, err := c.s3Service.GetSignedURL(ctx, fileKey, time.Duration(expires)*time.Second)
@@ -307,7 +305,7 @@ func (c *S3Controller) GetSignedURL(ctx *gin.Context) {
 // @Router /files/metadata [get]
 func (c *S3Controller) GetFileMetadata(ctx *gin.Context) {
 	// Get user information from context
-	_, exists := middleware.GetUserClaimsFromContext(ctx)
+	userClaims, exists := middleware.GetUserClaimsFromContext(ctx)
 	if !exists {
 		ctx.JSON(http.StatusUnauthorized, ErrorResponse{
 			Error:   "User not authenticated",
@@ -326,8 +324,11 @@ func (c *S3Controller) GetFileMetadata(ctx *gin.Context) {
 		return
 	}
 
-	// TODO: Verify user has access to the file
-	// This would typically involve checking file metadata or database records
+	// Verify the user may read the file
+	if err := c.s3Service.AuthorizeFileKey(ctx, userClaims, authz.ActionRead, fileKey); err != nil {
+		handleServiceError(ctx, err, "Failed to get file metadata")
+		return
+	}
 
 	// Get file metadata
 	metadata, err := c.s3Service.GetFileMetadata(ctx, fileKey)
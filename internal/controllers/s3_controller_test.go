@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+	"dwell/internal/middleware"
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// newFileRouter serves the file routes as the router does, with claims set
+// directly instead of from a token. Every request in these tests is refused
+// before S3 or the database is reached, so the service has neither.
+func newFileRouter(claims *domain.UserClaims) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	c := NewS3Controller(services.NewS3Service(nil, nil, nil))
+
+	r := gin.New()
+	files := r.Group("/files", func(ctx *gin.Context) {
+		ctx.Set(middleware.UserClaimsKey, claims)
+	})
+	files.POST("/upload", middleware.Require(authz.ResourceFiles, authz.ActionCreate), c.UploadFile)
+	files.DELETE("/delete", middleware.Require(authz.ResourceFiles, authz.ActionDelete), c.DeleteFile)
+	files.GET("/list", middleware.Require(authz.ResourceFiles, authz.ActionRead), c.ListFiles)
+	files.GET("/signed-url", middleware.Require(authz.ResourceFiles, authz.ActionRead), c.GetSignedURL)
+	files.GET("/metadata", middleware.Require(authz.ResourceFiles, authz.ActionRead), c.GetFileMetadata)
+	return r
+}
+
+func TestFileRoutesRefuseOutOfScopeFiles(t *testing.T) {
+	landlordID, otherID := uuid.New(), uuid.New()
+	tenantID := uuid.New()
+	ownKey := landlordID.String() + "/maintenance_photo/" + uuid.NewString() + "/sink.jpg"
+	photoKey := landlordID.String() + "/" + services.MaintenancePhotoCategory + "/" + uuid.NewString() + "/leak.jpg"
+	tenantKey := landlordID.String() + "/document/" + tenantID.String() + "/lease.pdf"
+	otherKey := otherID.String() + "/document/" + uuid.NewString() + "/lease.pdf"
+	listOwn := "landlord_id=" + landlordID.String() + "&category=document&entity_id=" + tenantID.String()
+
+	tenant := &domain.UserClaims{UserID: "tenant-1", UserType: "tenant", LandlordID: &landlordID}
+	owner := &domain.UserClaims{UserID: "owner-1", UserType: "landlord", LandlordID: &landlordID}
+	otherStaff := &domain.UserClaims{UserID: "staff-2", UserType: "landlord", Role: authz.RolePropertyManager, AllProperties: true, LandlordID: &otherID}
+
+	tests := []struct {
+		name   string
+		claims *domain.UserClaims
+		method string
+		target string
+	}{
+		{"tenant lists their own account's files", tenant, http.MethodGet, "/files/list?" + listOwn},
+		{"tenant signs a key under their own record", tenant, http.MethodGet, "/files/signed-url?file_key=" + tenantKey},
+		{"tenant reads metadata of a request photo", tenant, http.MethodGet, "/files/metadata?file_key=" + ownKey},
+		{"tenant uploads", tenant, http.MethodPost, "/files/upload"},
+		{"staff of another account list files", otherStaff, http.MethodGet, "/files/list?" + listOwn},
+		{"staff of another account sign a key", otherStaff, http.MethodGet, "/files/signed-url?file_key=" + ownKey},
+		{"staff of another account read metadata", otherStaff, http.MethodGet, "/files/metadata?file_key=" + ownKey},
+		{"owner signs another account's key", owner, http.MethodGet, "/files/signed-url?file_key=" + otherKey},
+		{"owner reads another account's metadata", owner, http.MethodGet, "/files/metadata?file_key=" + otherKey},
+		{"owner signs a legacy maintenance photo", owner, http.MethodGet, "/files/signed-url?file_key=" + ownKey},
+		{"owner reads a maintenance photo's metadata", owner, http.MethodGet, "/files/metadata?file_key=" + photoKey},
+		{"owner lists maintenance photos", owner, http.MethodGet, "/files/list?landlord_id=" + landlordID.String() + "&category=" + services.MaintenancePhotoCategory + "&entity_id=" + uuid.NewString()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newFileRouter(tt.claims).ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.target, w.Code, http.StatusForbidden, w.Body.String())
+			}
+		})
+	}
+
+	// Deleting a maintenance photo object would leave its photo row behind
+	body := `{"landlord_id":"` + landlordID.String() + `","file_key":"` + photoKey + `"}`
+	req := httptest.NewRequest(http.MethodDelete, "/files/delete", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	newFileRouter(owner).ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("DELETE /files/delete of a maintenance photo = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
+
+func TestUploadRefusesMaintenancePhotoCategory(t *testing.T) {
+	landlordID := uuid.New()
+	owner := &domain.UserClaims{UserID: "owner-1", UserType: "landlord", LandlordID: &landlordID}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("landlord_id", landlordID.String())
+	form.WriteField("category", services.MaintenancePhotoCategory)
+	form.WriteField("entity_id", uuid.NewString())
+	part, _ := form.CreateFormFile("file", "sink.jpg")
+	part.Write([]byte("jpeg"))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/files/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	newFileRouter(owner).ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("POST /files/upload = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
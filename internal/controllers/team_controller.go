@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"net/http"
+
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeamController struct {
+	teamService *services.TeamService
+}
+
+func NewTeamController(teamService *services.TeamService) *TeamController {
+	return &TeamController{
+		teamService: teamService,
+	}
+}
+
+// GetMembers lists the landlord account's team members
+// @Summary List team members
+// @Description List the staff of the current landlord account, including the owner
+// @Tags Team
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role query string false "Filter by role" Enums(owner, property_manager, leasing_agent, maintenance_staff, bookkeeper)
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param offset query int false "Offset for pagination (default: 0)"
+// @Success 200 {object} services.TeamMemberListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/team [get]
+func (c *TeamController) GetMembers(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.TeamMemberListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.teamService.ListMembers(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list team members")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetMember returns a single team member
+// @Summary Get team member
+// @Description Get one of the landlord account's team members
+// @Tags Team
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team member ID"
+// @Success 200 {object} domain.TeamMember
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/team/{id} [get]
+func (c *TeamController) GetMember(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	member, err := c.teamService.GetMember(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get team member")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// UpdateMember changes a team member's role or property assignments
+// @Summary Update team member
+// @Description Change a staff member's role or the properties they are assigned to. Changes apply to their next request.
+// @Tags Team
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team member ID"
+// @Param request body services.UpdateTeamMemberRequest true "Fields to change"
+// @Success 200 {object} domain.TeamMember
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/team/{id} [put]
+func (c *TeamController) UpdateMember(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req services.UpdateTeamMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	member, err := c.teamService.UpdateMember(ctx, userClaims, id, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to update team member")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a team member
+// @Summary Remove team member
+// @Description Remove a staff member from the landlord account. Their sessions are revoked and their tokens stop working immediately.
+// @Tags Team
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team member ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/team/{id} [delete]
+func (c *TeamController) RemoveMember(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.teamService.RemoveMember(ctx, userClaims, id); err != nil {
+		handleServiceError(ctx, err, "Failed to remove team member")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Message: "Team member removed successfully",
+	})
+}
+
+// InviteMember emails a signup invitation to a staff member
+// @Summary Invite team member
+// @Description Email an invitation code that adds the signup to this landlord account with the given role and property assignments. Any previous invitation to the same address is revoked.
+// @Tags Team
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.InviteTeamMemberRequest true "Invitation details"
+// @Success 201 {object} services.TeamInvitationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/team/invitations [post]
+func (c *TeamController) InviteMember(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.InviteTeamMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.teamService.InviteMember(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to invite team member")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// GetInvitations lists outstanding team invitations
+// @Summary List team invitations
+// @Description List the landlord account's staff invitations that have not been accepted, revoked or expired
+// @Tags Team
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.TeamInvitation
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/team/invitations [get]
+func (c *TeamController) GetInvitations(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	invitations, err := c.teamService.ListInvitations(ctx, userClaims)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list team invitations")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation revokes an outstanding team invitation
+// @Summary Revoke team invitation
+// @Description Revoke a staff invitation so its code can no longer be used to sign up
+// @Tags Team
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/team/invitations/{id} [delete]
+func (c *TeamController) RevokeInvitation(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.teamService.RevokeInvitation(ctx, userClaims, id); err != nil {
+		handleServiceError(ctx, err, "Failed to revoke team invitation")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Message: "Invitation revoked successfully",
+	})
+}
+
+// TransferOwnership makes another team member the account owner
+// @Summary Transfer ownership
+// @Description Make another team member the owner of the landlord account. The current owner stays on as a property manager for every property.
+// @Tags Team
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.TransferOwnershipRequest true "New owner"
+// @Success 200 {object} services.TransferOwnershipResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/team/transfer-ownership [post]
+func (c *TeamController) TransferOwnership(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.TransferOwnershipRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.teamService.TransferOwnership(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to transfer ownership")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"net/http"
+
+	"dwell/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantController struct {
+	tenantService *services.TenantService
+}
+
+func NewTenantController(tenantService *services.TenantService) *TenantController {
+	return &TenantController{
+		tenantService: tenantService,
+	}
+}
+
+// GetTenants lists the landlord's tenant roster
+// @Summary List tenants
+// @Description List the current landlord's tenants with optional filters
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param property_id query string false "Only the tenant currently renting this property"
+// @Param email query string false "Filter by email"
+// @Param search query string false "Case-insensitive match on name or email"
+// @Param is_active query bool false "Filter by active status"
+// @Param lease_ending_within_days query int false "Only tenants whose lease ends within N days from today"
+// @Param limit query int false "Page size (default: 50, max: 200)"
+// @Param offset query int false "Offset for pagination (default: 0)"
+// @Success 200 {object} services.TenantListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/tenants [get]
+func (c *TenantController) GetTenants(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.TenantListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.tenantService.ListTenants(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to list tenants")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetTenant returns a single tenant
+// @Summary Get tenant
+// @Description Get a tenant and the property they currently rent
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} services.TenantDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/tenants/{id} [get]
+func (c *TenantController) GetTenant(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.tenantService.GetTenant(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to get tenant")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreateTenant adds a tenant to a property
+// @Summary Add tenant
+// @Description Add a tenant to one of the landlord's available properties, optionally emailing them a signup invitation
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.CreateTenantRequest true "Tenant and lease details"
+// @Success 201 {object} services.TenantDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/tenants [post]
+func (c *TenantController) CreateTenant(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.CreateTenantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request data",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := c.tenantService.CreateTenant(ctx, userClaims, &req)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to create tenant")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// InviteTenant emails a signup invitation to a tenant
+// @Summary Invite tenant
+// @Description Email the tenant an invitation code that links their signup to this tenant record. Any previous invitation is revoked.
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tenant ID"
+// @Success 201 {object} services.TenantInvitationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/tenants/{id}/invite [post]
+func (c *TenantController) InviteTenant(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	response, err := c.tenantService.InviteTenant(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to invite tenant")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// DeactivateTenant deactivates a tenant
+// @Summary Deactivate tenant
+// @Description Mark a tenant inactive, release their property and revoke outstanding invitations
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} domain.Tenant
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /landlord/tenants/{id}/deactivate [post]
+func (c *TenantController) DeactivateTenant(ctx *gin.Context) {
+	userClaims, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	tenant, err := c.tenantService.DeactivateTenant(ctx, userClaims, id)
+	if err != nil {
+		handleServiceError(ctx, err, "Failed to deactivate tenant")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tenant)
+}
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the pg_advisory_lock key used to serialize migration runs
+// across replicas sharing the same database.
+const migrationLockID int64 = 727465116
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator applies the embedded SQL migrations and records them in schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the embedded migrations
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations in order and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the given number of most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	rolledBack := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && rolledBack < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, false); err != nil {
+				return err
+			}
+			rolledBack++
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Redo rolls back and re-applies the most recently applied migration. Other
+// pending migrations are left alone, and the lock is held throughout so no
+// other migrator runs in between.
+func (m *Migrator) Redo(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		mig, ok := m.lastApplied(done)
+		if !ok {
+			return fmt.Errorf("no applied migration to redo")
+		}
+		if err := m.apply(ctx, conn, mig, false); err != nil {
+			return err
+		}
+		return m.apply(ctx, conn, mig, true)
+	})
+}
+
+// lastApplied returns the applied migration with the highest version
+func (m *Migrator) lastApplied(done map[int64]time.Time) (Migration, bool) {
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if _, ok := done[m.migrations[i].Version]; ok {
+			return m.migrations[i], true
+		}
+	}
+	return Migration{}, false
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			status := MigrationStatus{Version: mig.Version, Name: mig.Name}
+			if appliedAt, ok := done[mig.Version]; ok {
+				at := appliedAt
+				status.Applied = true
+				status.AppliedAt = &at
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock.
+// Session-level advisory locks are tied to a connection, so the lock, the work
+// and the unlock must all happen on the same *sql.Conn.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			log.Printf("Failed to release migration lock: %v", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedVersions returns the applied migration versions and when they ran
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		done[version] = appliedAt
+	}
+	return done, rows.Err()
+}
+
+// apply runs a single migration in either direction inside a transaction
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, up bool) error {
+	direction, body := "up", mig.UpSQL
+	if !up {
+		direction, body = "down", mig.DownSQL
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("migration %04d_%s has no %s script", mig.Version, mig.Name, direction)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return fmt.Errorf("migration %04d_%s %s failed: %w", mig.Version, mig.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", mig.Version, mig.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %04d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	log.Printf("Migration %04d_%s %s applied", mig.Version, mig.Name, direction)
+	return nil
+}
+
+// loadMigrations reads <version>_<name>.(up|down).sql files from dir, sorted by version
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		version, name, up, err := parseMigrationFilename(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+		} else if mig.Name != name {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, mig.Name, name)
+		}
+
+		if up {
+			mig.UpSQL = string(body)
+		} else {
+			mig.DownSQL = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.UpSQL == "" {
+			return nil, fmt.Errorf("migration %04d_%s is missing its up script", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// parseMigrationFilename splits "0001_initial_schema.up.sql" into its parts
+func parseMigrationFilename(filename string) (int64, string, bool, error) {
+	var up bool
+	base := filename
+	switch {
+	case strings.HasSuffix(base, ".up.sql"):
+		up = true
+		base = strings.TrimSuffix(base, ".up.sql")
+	case strings.HasSuffix(base, ".down.sql"):
+		base = strings.TrimSuffix(base, ".down.sql")
+	default:
+		return 0, "", false, fmt.Errorf("invalid migration filename %q: must end in .up.sql or .down.sql", filename)
+	}
+
+	versionStr, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", false, fmt.Errorf("invalid migration filename %q: expected <version>_<name>", filename)
+	}
+
+	version, err := strconv.ParseInt(versionStr, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", false, fmt.Errorf("invalid migration filename %q: bad version", filename)
+	}
+
+	return version, name, up, nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestParseMigrationFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		version  int64
+		name     string
+		up       bool
+		wantErr  bool
+	}{
+		{"0001_initial_schema.up.sql", 1, "initial_schema", true, false},
+		{"0012_add_index.down.sql", 12, "add_index", false, false},
+		{"0001_initial_schema.sql", 0, "", false, true},
+		{"initial_schema.up.sql", 0, "", false, true},
+		{"0000_zero.up.sql", 0, "", false, true},
+		{"0003_.up.sql", 0, "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			version, name, up, err := parseMigrationFilename(tt.filename)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %s, got nil", tt.filename)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if version != tt.version || name != tt.name || up != tt.up {
+				t.Errorf("got (%d, %s, %v), want (%d, %s, %v)", version, name, up, tt.version, tt.name, tt.up)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_second.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+		"migrations/0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"migrations/0001_first.up.sql":    {Data: []byte("CREATE TABLE a ();")},
+	}
+
+	migrations, err := loadMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Errorf("migrations not sorted by version: %d, %d", migrations[0].Version, migrations[1].Version)
+	}
+	if migrations[1].DownSQL != "DROP TABLE b;" {
+		t.Errorf("expected down script to be loaded, got %q", migrations[1].DownSQL)
+	}
+
+	// A down script without its up script is rejected
+	fsys["migrations/0003_orphan.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE c;")}
+	if _, err := loadMigrations(fsys, "migrations"); err == nil {
+		t.Error("expected error for migration without up script, got nil")
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("embedded migrations failed to load: %v", err)
+	}
+	for i, mig := range migrations {
+		if mig.Version != int64(i+1) {
+			t.Errorf("expected contiguous versions, migration %d has version %d", i, mig.Version)
+		}
+		if mig.DownSQL == "" {
+			t.Errorf("migration %04d_%s is missing a down script", mig.Version, mig.Name)
+		}
+	}
+}
+
+func TestLastApplied(t *testing.T) {
+	m := &Migrator{migrations: []Migration{{Version: 1, Name: "first"}, {Version: 2, Name: "second"}, {Version: 3, Name: "third"}}}
+
+	// Redo targets the newest applied migration, not a pending one after it
+	mig, ok := m.lastApplied(map[int64]time.Time{1: {}, 2: {}})
+	if !ok || mig.Version != 2 {
+		t.Errorf("lastApplied() = %d, %v, want 2, true", mig.Version, ok)
+	}
+
+	if _, ok := m.lastApplied(map[int64]time.Time{}); ok {
+		t.Error("lastApplied() with nothing applied returned ok")
+	}
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS ai_chat_messages;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS maintenance_photos;
+DROP TABLE IF EXISTS maintenance_requests;
+DROP TABLE IF EXISTS contractors;
+DROP TABLE IF EXISTS properties;
+DROP TABLE IF EXISTS tenants;
+DROP TABLE IF EXISTS landlords;
@@ -0,0 +1,201 @@
+-- Initial Dwell Property Management schema
+-- Mirrors the structs in internal/domain. Every table carries the BaseEntity
+-- columns (id, created_at, updated_at) plus a nullable deleted_at used for
+-- soft deletes by the repository layer.
+
+CREATE EXTENSION IF NOT EXISTS "pgcrypto";
+
+-- Landlords (property owners/managers)
+CREATE TABLE IF NOT EXISTS landlords (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email            VARCHAR(255) NOT NULL,
+    first_name       VARCHAR(100) NOT NULL DEFAULT '',
+    last_name        VARCHAR(100) NOT NULL DEFAULT '',
+    phone            VARCHAR(50)  NOT NULL DEFAULT '',
+    company_name     VARCHAR(255) NOT NULL DEFAULT '',
+    business_address TEXT         NOT NULL DEFAULT '',
+    tax_id           VARCHAR(50)  NOT NULL DEFAULT '',
+    is_active        BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at       TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_landlords_email ON landlords (LOWER(email)) WHERE deleted_at IS NULL;
+
+-- Tenants (property renters)
+CREATE TABLE IF NOT EXISTS tenants (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id       UUID          NOT NULL REFERENCES landlords (id),
+    email             VARCHAR(255)  NOT NULL,
+    first_name        VARCHAR(100)  NOT NULL DEFAULT '',
+    last_name         VARCHAR(100)  NOT NULL DEFAULT '',
+    phone             VARCHAR(50)   NOT NULL DEFAULT '',
+    emergency_contact TEXT          NOT NULL DEFAULT '',
+    lease_start_date  DATE          NOT NULL,
+    lease_end_date    DATE          NOT NULL,
+    monthly_rent      NUMERIC(12,2) NOT NULL DEFAULT 0,
+    security_deposit  NUMERIC(12,2) NOT NULL DEFAULT 0,
+    is_active         BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at        TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenants_landlord_id ON tenants (landlord_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_landlord_email ON tenants (landlord_id, LOWER(email)) WHERE deleted_at IS NULL;
+
+-- Properties
+CREATE TABLE IF NOT EXISTS properties (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id       UUID          NOT NULL REFERENCES landlords (id),
+    name              VARCHAR(255)  NOT NULL,
+    address           TEXT          NOT NULL DEFAULT '',
+    city              VARCHAR(100)  NOT NULL DEFAULT '',
+    state             VARCHAR(50)   NOT NULL DEFAULT '',
+    zip_code          VARCHAR(20)   NOT NULL DEFAULT '',
+    property_type     VARCHAR(50)   NOT NULL DEFAULT '',
+    bedrooms          INTEGER       NOT NULL DEFAULT 0,
+    bathrooms         INTEGER       NOT NULL DEFAULT 0,
+    square_footage    INTEGER       NOT NULL DEFAULT 0,
+    year_built        INTEGER       NOT NULL DEFAULT 0,
+    monthly_rent      NUMERIC(12,2) NOT NULL DEFAULT 0,
+    security_deposit  NUMERIC(12,2) NOT NULL DEFAULT 0,
+    is_available      BOOLEAN       NOT NULL DEFAULT TRUE,
+    current_tenant_id UUID          REFERENCES tenants (id),
+    created_at        TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_properties_landlord_id ON properties (landlord_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_properties_current_tenant_id ON properties (current_tenant_id) WHERE deleted_at IS NULL;
+
+-- Contractors (service providers)
+CREATE TABLE IF NOT EXISTS contractors (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id    UUID          NOT NULL REFERENCES landlords (id),
+    company_name   VARCHAR(255)  NOT NULL DEFAULT '',
+    contact_person VARCHAR(255)  NOT NULL DEFAULT '',
+    email          VARCHAR(255)  NOT NULL DEFAULT '',
+    phone          VARCHAR(50)   NOT NULL DEFAULT '',
+    specialization VARCHAR(100)  NOT NULL DEFAULT '',
+    license_number VARCHAR(100)  NOT NULL DEFAULT '',
+    insurance_info TEXT          NOT NULL DEFAULT '',
+    hourly_rate    NUMERIC(12,2) NOT NULL DEFAULT 0,
+    is_active      BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_contractors_landlord_id ON contractors (landlord_id) WHERE deleted_at IS NULL;
+
+-- Maintenance requests
+CREATE TABLE IF NOT EXISTS maintenance_requests (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id    UUID          NOT NULL REFERENCES landlords (id),
+    property_id    UUID          NOT NULL REFERENCES properties (id),
+    tenant_id      UUID          NOT NULL REFERENCES tenants (id),
+    title          VARCHAR(255)  NOT NULL,
+    description    TEXT          NOT NULL DEFAULT '',
+    priority       VARCHAR(20)   NOT NULL DEFAULT 'medium',
+    status         VARCHAR(20)   NOT NULL DEFAULT 'open',
+    category       VARCHAR(50)   NOT NULL DEFAULT '',
+    requested_date TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    completed_date TIMESTAMPTZ,
+    estimated_cost NUMERIC(12,2),
+    actual_cost    NUMERIC(12,2),
+    contractor_id  UUID          REFERENCES contractors (id),
+    notes          TEXT          NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at     TIMESTAMPTZ,
+    CONSTRAINT chk_maintenance_priority CHECK (priority IN ('low', 'medium', 'high', 'emergency')),
+    CONSTRAINT chk_maintenance_status CHECK (status IN ('open', 'in_progress', 'completed', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_requests_landlord_id ON maintenance_requests (landlord_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_maintenance_requests_property_id ON maintenance_requests (property_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_maintenance_requests_tenant_id ON maintenance_requests (tenant_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_maintenance_requests_status ON maintenance_requests (landlord_id, status) WHERE deleted_at IS NULL;
+
+-- Maintenance photos (S3 objects attached to maintenance requests)
+CREATE TABLE IF NOT EXISTS maintenance_photos (
+    id                     UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    maintenance_request_id UUID         NOT NULL REFERENCES maintenance_requests (id),
+    photo_url              TEXT         NOT NULL DEFAULT '',
+    photo_key              TEXT         NOT NULL,
+    description            TEXT         NOT NULL DEFAULT '',
+    is_before              BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at             TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at             TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at             TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_photos_request_id ON maintenance_photos (maintenance_request_id) WHERE deleted_at IS NULL;
+
+-- Payments (rent and other charges)
+CREATE TABLE IF NOT EXISTS payments (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id      UUID          NOT NULL REFERENCES landlords (id),
+    property_id      UUID          NOT NULL REFERENCES properties (id),
+    tenant_id        UUID          NOT NULL REFERENCES tenants (id),
+    amount           NUMERIC(12,2) NOT NULL,
+    payment_type     VARCHAR(50)   NOT NULL DEFAULT 'rent',
+    payment_method   VARCHAR(50)   NOT NULL DEFAULT '',
+    due_date         DATE          NOT NULL,
+    paid_date        TIMESTAMPTZ,
+    status           VARCHAR(20)   NOT NULL DEFAULT 'pending',
+    reference_number VARCHAR(100)  NOT NULL DEFAULT '',
+    notes            TEXT          NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at       TIMESTAMPTZ,
+    CONSTRAINT chk_payment_status CHECK (status IN ('pending', 'paid', 'overdue', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_landlord_id ON payments (landlord_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_payments_tenant_id ON payments (tenant_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_payments_status_due_date ON payments (status, due_date) WHERE deleted_at IS NULL;
+
+-- AI chat messages
+CREATE TABLE IF NOT EXISTS ai_chat_messages (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id UUID           NOT NULL REFERENCES landlords (id),
+    tenant_id   UUID           REFERENCES tenants (id),
+    user_type   VARCHAR(20)    NOT NULL,
+    question    TEXT           NOT NULL,
+    answer      TEXT           NOT NULL DEFAULT '',
+    model_used  VARCHAR(255)   NOT NULL DEFAULT '',
+    tokens_used INTEGER        NOT NULL DEFAULT 0,
+    cost        NUMERIC(12,6)  NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_chat_messages_landlord_created ON ai_chat_messages (landlord_id, created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_ai_chat_messages_tenant_id ON ai_chat_messages (tenant_id) WHERE deleted_at IS NULL;
+
+-- Notifications
+CREATE TABLE IF NOT EXISTS notifications (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id         UUID         NOT NULL REFERENCES landlords (id),
+    recipient_id        UUID         NOT NULL,
+    recipient_type      VARCHAR(20)  NOT NULL,
+    type                VARCHAR(50)  NOT NULL,
+    title               VARCHAR(255) NOT NULL,
+    message             TEXT         NOT NULL DEFAULT '',
+    is_read             BOOLEAN      NOT NULL DEFAULT FALSE,
+    read_at             TIMESTAMPTZ,
+    related_entity_id   UUID,
+    related_entity_type VARCHAR(50)  NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at          TIMESTAMPTZ,
+    CONSTRAINT chk_notification_recipient_type CHECK (recipient_type IN ('landlord', 'tenant', 'contractor'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient ON notifications (recipient_id, is_read) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_landlord_id ON notifications (landlord_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tenants_user_id;
+
+ALTER TABLE tenants DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,4 @@
+-- Link tenant rows to the identity-provider user (Cognito sub) that signs in as them
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS user_id VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_user_id ON tenants (user_id) WHERE user_id <> '' AND deleted_at IS NULL;
@@ -0,0 +1,16 @@
+-- Email invitations that let a tenant link their signup to an existing tenant row
+CREATE TABLE IF NOT EXISTS tenant_invitations (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id UUID         NOT NULL REFERENCES landlords (id),
+    tenant_id   UUID         NOT NULL REFERENCES tenants (id),
+    email       VARCHAR(255) NOT NULL,
+    code_hash   VARCHAR(64)  NOT NULL,
+    expires_at  TIMESTAMPTZ  NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_invitations_code_hash ON tenant_invitations (code_hash);
+CREATE INDEX IF NOT EXISTS idx_tenant_invitations_tenant_id ON tenant_invitations (tenant_id);
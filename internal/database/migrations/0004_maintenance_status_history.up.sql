@@ -0,0 +1,16 @@
+-- Audit trail of maintenance request status transitions and field changes
+CREATE TABLE IF NOT EXISTS maintenance_status_history (
+    id                     UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    maintenance_request_id UUID         NOT NULL REFERENCES maintenance_requests (id),
+    landlord_id            UUID         NOT NULL REFERENCES landlords (id),
+    from_status            VARCHAR(20)  NOT NULL DEFAULT '',
+    to_status              VARCHAR(20)  NOT NULL,
+    changed_by             VARCHAR(255) NOT NULL,
+    changed_by_type        VARCHAR(20)  NOT NULL,
+    note                   TEXT         NOT NULL DEFAULT '',
+    changes                JSONB        NOT NULL DEFAULT '{}',
+    created_at             TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_status_history_request
+    ON maintenance_status_history (maintenance_request_id, created_at);
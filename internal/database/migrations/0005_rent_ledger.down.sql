@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS payment_receipts;
+DROP INDEX IF EXISTS idx_payments_rent_period;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS period_end,
+    DROP COLUMN IF EXISTS period_start,
+    DROP COLUMN IF EXISTS amount_paid;
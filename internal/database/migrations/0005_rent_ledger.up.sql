@@ -0,0 +1,29 @@
+-- Rent ledger: charge periods, partial payments and receipts
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS amount_paid  NUMERIC(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS period_start DATE,
+    ADD COLUMN IF NOT EXISTS period_end   DATE;
+
+UPDATE payments SET amount_paid = amount WHERE status = 'paid';
+
+-- One rent charge per tenant per period makes charge generation idempotent
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_rent_period
+    ON payments (tenant_id, period_start) WHERE payment_type = 'rent' AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS payment_receipts (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id      UUID          NOT NULL REFERENCES landlords (id),
+    tenant_id        UUID          NOT NULL REFERENCES tenants (id),
+    payment_id       UUID          NOT NULL REFERENCES payments (id),
+    amount           NUMERIC(12,2) NOT NULL,
+    payment_method   VARCHAR(50)   NOT NULL DEFAULT '',
+    reference_number VARCHAR(100)  NOT NULL DEFAULT '',
+    received_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    notes            TEXT          NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_payment_receipt_amount CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_receipts_tenant ON payment_receipts (landlord_id, tenant_id, received_at);
+CREATE INDEX IF NOT EXISTS idx_payment_receipts_payment ON payment_receipts (payment_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_payments_late_fee_parent;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS parent_payment_id;
+
+DROP TABLE IF EXISTS late_fee_policies;
@@ -0,0 +1,21 @@
+-- Landlord late fee policies and late fee charges linked to the charge they penalize
+CREATE TABLE IF NOT EXISTS late_fee_policies (
+    landlord_id       UUID PRIMARY KEY REFERENCES landlords (id),
+    enabled           BOOLEAN       NOT NULL DEFAULT FALSE,
+    grace_period_days INTEGER       NOT NULL DEFAULT 5,
+    flat_amount       NUMERIC(12,2) NOT NULL DEFAULT 0,
+    percentage        NUMERIC(5,2)  NOT NULL DEFAULT 0,
+    daily_amount      NUMERIC(12,2) NOT NULL DEFAULT 0,
+    max_amount        NUMERIC(12,2) NOT NULL DEFAULT 0,
+    created_at        TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_late_fee_grace CHECK (grace_period_days >= 0),
+    CONSTRAINT chk_late_fee_amounts CHECK (flat_amount >= 0 AND percentage >= 0 AND daily_amount >= 0 AND max_amount >= 0)
+);
+
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS parent_payment_id UUID REFERENCES payments (id);
+
+-- At most one late fee per charge; daily fees grow the existing row
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_late_fee_parent
+    ON payments (parent_payment_id) WHERE payment_type = 'late_fee' AND deleted_at IS NULL;
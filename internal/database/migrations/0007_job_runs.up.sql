@@ -0,0 +1,16 @@
+-- Run history for the in-process job scheduler. The unique slot makes each
+-- scheduled run happen on exactly one replica.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name     VARCHAR(100) NOT NULL,
+    scheduled_at TIMESTAMPTZ  NOT NULL,
+    started_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ,
+    status       VARCHAR(20)  NOT NULL DEFAULT 'running',
+    error        TEXT         NOT NULL DEFAULT '',
+    instance     VARCHAR(255) NOT NULL DEFAULT '',
+    CONSTRAINT chk_job_run_status CHECK (status IN ('running', 'succeeded', 'failed')),
+    CONSTRAINT uq_job_runs_slot UNIQUE (job_name, scheduled_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_started_at ON job_runs (started_at DESC);
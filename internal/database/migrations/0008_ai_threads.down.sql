@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_ai_chat_messages_thread;
+
+ALTER TABLE ai_chat_messages
+    DROP COLUMN IF EXISTS thread_id;
+
+DROP TABLE IF EXISTS ai_threads;
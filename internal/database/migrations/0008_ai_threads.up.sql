@@ -0,0 +1,24 @@
+-- Multi-turn AI conversations. Turns that no longer fit the model context are
+-- folded into summary; summarized_messages counts how many.
+CREATE TABLE IF NOT EXISTS ai_threads (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id         UUID         NOT NULL REFERENCES landlords (id),
+    tenant_id           UUID         REFERENCES tenants (id),
+    user_id             VARCHAR(255) NOT NULL,
+    user_type           VARCHAR(20)  NOT NULL,
+    title               VARCHAR(255) NOT NULL DEFAULT '',
+    summary             TEXT         NOT NULL DEFAULT '',
+    summarized_messages INTEGER      NOT NULL DEFAULT 0,
+    created_at          TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at          TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_threads_owner
+    ON ai_threads (landlord_id, user_id, updated_at DESC) WHERE deleted_at IS NULL;
+
+ALTER TABLE ai_chat_messages
+    ADD COLUMN IF NOT EXISTS thread_id UUID REFERENCES ai_threads (id);
+
+CREATE INDEX IF NOT EXISTS idx_ai_chat_messages_thread
+    ON ai_chat_messages (thread_id, created_at) WHERE deleted_at IS NULL;
@@ -0,0 +1,22 @@
+-- Audit trail of tools the AI assistant used or proposed while answering a
+-- chat message. Actions wait in pending_confirmation until the user confirms.
+CREATE TABLE IF NOT EXISTS ai_tool_invocations (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id  UUID         NOT NULL REFERENCES landlords (id),
+    message_id   UUID         NOT NULL REFERENCES ai_chat_messages (id),
+    user_id      VARCHAR(255) NOT NULL,
+    user_type    VARCHAR(20)  NOT NULL,
+    tool_name    VARCHAR(100) NOT NULL,
+    input        JSONB        NOT NULL DEFAULT '{}',
+    status       VARCHAR(30)  NOT NULL,
+    result       JSONB,
+    error        TEXT         NOT NULL DEFAULT '',
+    confirmed_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_tool_invocations_message
+    ON ai_tool_invocations (message_id);
+CREATE INDEX IF NOT EXISTS idx_ai_tool_invocations_landlord_created
+    ON ai_tool_invocations (landlord_id, created_at DESC);
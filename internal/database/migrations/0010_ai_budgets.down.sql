@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ai_chat_messages_landlord_spend;
+DROP TABLE IF EXISTS ai_budget_alerts;
+DROP TABLE IF EXISTS ai_budgets;
@@ -0,0 +1,26 @@
+-- Landlord AI spend budgets. A limit of 0 means no limit; landlords without a
+-- row use the configured defaults.
+CREATE TABLE IF NOT EXISTS ai_budgets (
+    landlord_id              UUID PRIMARY KEY REFERENCES landlords (id),
+    monthly_token_limit      BIGINT        NOT NULL DEFAULT 0,
+    monthly_cost_limit       NUMERIC(12,2) NOT NULL DEFAULT 0,
+    tenant_daily_token_limit INTEGER       NOT NULL DEFAULT 0,
+    soft_limit_percent       INTEGER       NOT NULL DEFAULT 80,
+    created_at               TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at               TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_ai_budget_limits CHECK (monthly_token_limit >= 0 AND monthly_cost_limit >= 0 AND tenant_daily_token_limit >= 0),
+    CONSTRAINT chk_ai_budget_soft_limit CHECK (soft_limit_percent BETWEEN 1 AND 100)
+);
+
+-- Budget alerts already sent, so each alert goes out once per landlord and month
+CREATE TABLE IF NOT EXISTS ai_budget_alerts (
+    landlord_id UUID        NOT NULL REFERENCES landlords (id),
+    period      DATE        NOT NULL,
+    kind        VARCHAR(30) NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (landlord_id, period, kind)
+);
+
+-- Spend includes deleted messages, which the existing partial index skips
+CREATE INDEX IF NOT EXISTS idx_ai_chat_messages_landlord_spend
+    ON ai_chat_messages (landlord_id, created_at) INCLUDE (tenant_id, tokens_used, cost);
@@ -0,0 +1,29 @@
+-- AI triage suggestions for maintenance requests. Suggestions are never
+-- applied automatically: the landlord approves or rejects the latest one.
+CREATE TABLE IF NOT EXISTS maintenance_triage_suggestions (
+    id                     UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    maintenance_request_id UUID          NOT NULL REFERENCES maintenance_requests (id),
+    landlord_id            UUID          NOT NULL REFERENCES landlords (id),
+    status                 VARCHAR(20)   NOT NULL,
+    category               VARCHAR(50)   NOT NULL DEFAULT '',
+    priority               VARCHAR(20)   NOT NULL DEFAULT '',
+    is_emergency           BOOLEAN       NOT NULL DEFAULT FALSE,
+    emergency_reason       TEXT          NOT NULL DEFAULT '',
+    estimated_cost_min     NUMERIC(10,2),
+    estimated_cost_max     NUMERIC(10,2),
+    contractor_id          UUID          REFERENCES contractors (id),
+    reasoning              TEXT          NOT NULL DEFAULT '',
+    photos_reviewed        INTEGER       NOT NULL DEFAULT 0,
+    message_id             UUID          REFERENCES ai_chat_messages (id),
+    error                  TEXT          NOT NULL DEFAULT '',
+    reviewed_by            VARCHAR(255)  NOT NULL DEFAULT '',
+    reviewed_at            TIMESTAMPTZ,
+    created_at             TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at             TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_maintenance_triage_cost CHECK (estimated_cost_min IS NULL OR estimated_cost_max IS NULL OR estimated_cost_min <= estimated_cost_max)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_triage_request
+    ON maintenance_triage_suggestions (maintenance_request_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_maintenance_triage_landlord_status
+    ON maintenance_triage_suggestions (landlord_id, status);
@@ -0,0 +1,44 @@
+-- Accounts for the built-in identity provider (AUTH_PROVIDER=local). Cognito
+-- deployments leave these tables empty.
+CREATE TABLE IF NOT EXISTS auth_users (
+    id                      UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email                   VARCHAR(255) NOT NULL,
+    password_hash           TEXT         NOT NULL,
+    first_name              VARCHAR(100) NOT NULL DEFAULT '',
+    last_name               VARCHAR(100) NOT NULL DEFAULT '',
+    phone                   VARCHAR(50)  NOT NULL DEFAULT '',
+    company_name            VARCHAR(255) NOT NULL DEFAULT '',
+    user_type               VARCHAR(20)  NOT NULL,
+    -- Set from a tenant invitation or by an administrator, like Cognito's
+    -- custom:landlord_id attribute
+    landlord_id             UUID REFERENCES landlords (id) ON DELETE SET NULL,
+    confirmed_at            TIMESTAMPTZ,
+    -- SHA-256 of the pending confirmation code, empty once confirmed
+    confirmation_code_hash  VARCHAR(64)  NOT NULL DEFAULT '',
+    confirmation_expires_at TIMESTAMPTZ,
+    confirmation_attempts   INTEGER      NOT NULL DEFAULT 0,
+    -- Access tokens issued at or before this time are no longer accepted
+    tokens_revoked_at       TIMESTAMPTZ,
+    created_at              TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_users_email ON auth_users (LOWER(email));
+
+-- Refresh tokens are single use. Each sign-in starts a family; refreshing
+-- marks the token used and issues the next one in the same family, and a used
+-- token coming back revokes the whole family.
+CREATE TABLE IF NOT EXISTS auth_refresh_tokens (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    family_id   UUID        NOT NULL,
+    token_hash  VARCHAR(64) NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_refresh_tokens_hash ON auth_refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_auth_refresh_tokens_family ON auth_refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_auth_refresh_tokens_user ON auth_refresh_tokens (user_id) WHERE revoked_at IS NULL;
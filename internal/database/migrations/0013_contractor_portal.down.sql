@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_maintenance_requests_contractor_id;
+
+ALTER TABLE maintenance_requests DROP COLUMN IF EXISTS actual_hours;
+ALTER TABLE maintenance_requests DROP COLUMN IF EXISTS contractor_status;
+
+DROP TABLE IF EXISTS contractor_invitations;
+
+DROP INDEX IF EXISTS idx_contractors_user_id;
+
+ALTER TABLE contractors DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,29 @@
+-- Link contractor rows to the identity-provider user that signs in as them
+ALTER TABLE contractors ADD COLUMN IF NOT EXISTS user_id VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contractors_user_id ON contractors (user_id) WHERE user_id <> '' AND deleted_at IS NULL;
+
+-- Email invitations that let a contractor link their signup to an existing contractor row
+CREATE TABLE IF NOT EXISTS contractor_invitations (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id   UUID         NOT NULL REFERENCES landlords (id),
+    contractor_id UUID         NOT NULL REFERENCES contractors (id),
+    email         VARCHAR(255) NOT NULL,
+    code_hash     VARCHAR(64)  NOT NULL,
+    expires_at    TIMESTAMPTZ  NOT NULL,
+    accepted_at   TIMESTAMPTZ,
+    revoked_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contractor_invitations_code_hash ON contractor_invitations (code_hash);
+CREATE INDEX IF NOT EXISTS idx_contractor_invitations_contractor_id ON contractor_invitations (contractor_id);
+
+-- The assigned contractor's response to the job and the hours they report.
+-- contractor_status is empty while no contractor is assigned.
+ALTER TABLE maintenance_requests ADD COLUMN IF NOT EXISTS contractor_status VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE maintenance_requests ADD COLUMN IF NOT EXISTS actual_hours NUMERIC(8,2);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_requests_contractor_id
+    ON maintenance_requests (contractor_id) WHERE contractor_id IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS team_invitations;
+
+DROP TABLE IF EXISTS team_members;
@@ -0,0 +1,41 @@
+-- Landlord-side users of a landlord account: its owner and invited staff.
+-- Accounts without an owner row predate teams; their landlord users are owners.
+CREATE TABLE IF NOT EXISTS team_members (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id    UUID         NOT NULL REFERENCES landlords (id),
+    user_id        VARCHAR(255) NOT NULL,
+    email          VARCHAR(255) NOT NULL DEFAULT '',
+    first_name     VARCHAR(100) NOT NULL DEFAULT '',
+    last_name      VARCHAR(100) NOT NULL DEFAULT '',
+    role           VARCHAR(30)  NOT NULL,
+    all_properties BOOLEAN      NOT NULL DEFAULT FALSE,
+    property_ids   UUID[]       NOT NULL DEFAULT '{}',
+    removed_at     TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- A user belongs to at most one landlord account, which has at most one owner
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members (user_id) WHERE removed_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_members_owner ON team_members (landlord_id) WHERE role = 'owner' AND removed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_team_members_landlord_id ON team_members (landlord_id);
+
+-- Email invitations for staff to sign up into a landlord account
+CREATE TABLE IF NOT EXISTS team_invitations (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    landlord_id    UUID         NOT NULL REFERENCES landlords (id),
+    email          VARCHAR(255) NOT NULL,
+    role           VARCHAR(30)  NOT NULL,
+    all_properties BOOLEAN      NOT NULL DEFAULT FALSE,
+    property_ids   UUID[]       NOT NULL DEFAULT '{}',
+    invited_by     VARCHAR(255) NOT NULL,
+    code_hash      VARCHAR(64)  NOT NULL,
+    expires_at     TIMESTAMPTZ  NOT NULL,
+    accepted_at    TIMESTAMPTZ,
+    revoked_at     TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_invitations_code_hash ON team_invitations (code_hash);
+CREATE INDEX IF NOT EXISTS idx_team_invitations_landlord_email ON team_invitations (landlord_id, LOWER(email));
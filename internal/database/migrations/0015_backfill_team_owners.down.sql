@@ -0,0 +1,2 @@
+-- The recorded owners are ordinary memberships and are kept on rollback.
+SELECT 1;
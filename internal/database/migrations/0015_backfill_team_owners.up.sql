@@ -0,0 +1,20 @@
+-- Record the owners of landlord accounts that predate teams. Landlord users
+-- without a membership are no longer trusted on their landlord ID, so each
+-- account needs its owner row. Here that is the confirmed built-in account
+-- with the landlord's email and landlord ID; Cognito deployments record their
+-- owners with `dwell team backfill-owners`.
+INSERT INTO team_members (landlord_id, user_id, email, first_name, last_name, role, all_properties)
+SELECT l.id, u.id::text, l.email, l.first_name, l.last_name, 'owner', TRUE
+FROM landlords l
+JOIN auth_users u
+  ON u.landlord_id = l.id
+ AND LOWER(u.email) = LOWER(l.email)
+ AND u.user_type = 'landlord'
+ AND u.confirmed_at IS NOT NULL
+WHERE l.deleted_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM team_members m
+      WHERE m.landlord_id = l.id AND m.role = 'owner' AND m.removed_at IS NULL)
+  AND NOT EXISTS (
+      SELECT 1 FROM team_members m
+      WHERE m.user_id = u.id::text AND m.removed_at IS NULL);
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,19 +16,53 @@ type BaseEntity struct {
 
 // UserClaims represents JWT token claims
 type UserClaims struct {
-	UserID     string     `json:"user_id"`
-	UserType   string     `json:"user_type"`
-	LandlordID *uuid.UUID `json:"landlord_id,omitempty"`
-	ExpiresAt  time.Time  `json:"expires_at"`
+	UserID        string      `json:"user_id"`
+	UserType      string      `json:"user_type"`
+	Role          string      `json:"role,omitempty"` // empty for the default role of the user type
+	LandlordID    *uuid.UUID  `json:"landlord_id,omitempty"`
+	PropertyIDs   []uuid.UUID `json:"property_ids,omitempty"`   // properties assigned to landlord staff
+	AllProperties bool        `json:"all_properties,omitempty"` // landlord staff assigned to every property
+	ExpiresAt     time.Time   `json:"expires_at"`
 }
 
-// UserInfo represents user information from Cognito
+// UserInfo represents user information from the identity provider
 type UserInfo struct {
 	UserID     string     `json:"user_id"`
 	UserType   string     `json:"user_type"`
 	LandlordID *uuid.UUID `json:"landlord_id,omitempty"`
 }
 
+// AuthUser is an account managed by the built-in identity provider
+type AuthUser struct {
+	BaseEntity
+	Email                 string     `json:"email" db:"email"`
+	PasswordHash          string     `json:"-" db:"password_hash"`
+	FirstName             string     `json:"first_name" db:"first_name"`
+	LastName              string     `json:"last_name" db:"last_name"`
+	Phone                 string     `json:"phone" db:"phone"`
+	CompanyName           string     `json:"company_name" db:"company_name"`
+	UserType              string     `json:"user_type" db:"user_type"`
+	LandlordID            *uuid.UUID `json:"landlord_id,omitempty" db:"landlord_id"`
+	ConfirmedAt           *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	ConfirmationCodeHash  string     `json:"-" db:"confirmation_code_hash"`
+	ConfirmationExpiresAt *time.Time `json:"-" db:"confirmation_expires_at"`
+	ConfirmationAttempts  int        `json:"-" db:"confirmation_attempts"`
+	TokensRevokedAt       *time.Time `json:"-" db:"tokens_revoked_at"` // access tokens issued before then are rejected
+}
+
+// RefreshToken is a single-use refresh token of the built-in identity
+// provider. Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	FamilyID  uuid.UUID  `json:"family_id" db:"family_id"` // tokens descended from one sign-in
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
 // Landlord represents a property owner/manager
 type Landlord struct {
 	BaseEntity
@@ -45,6 +80,7 @@ type Landlord struct {
 type Tenant struct {
 	BaseEntity
 	LandlordID      uuid.UUID `json:"landlord_id" db:"landlord_id"`
+	UserID          string    `json:"user_id,omitempty" db:"user_id"` // identity provider user linked to this tenant
 	Email           string    `json:"email" db:"email"`
 	FirstName       string    `json:"first_name" db:"first_name"`
 	LastName        string    `json:"last_name" db:"last_name"`
@@ -81,6 +117,7 @@ type Property struct {
 type Contractor struct {
 	BaseEntity
 	LandlordID      uuid.UUID `json:"landlord_id" db:"landlord_id"`
+	UserID          string    `json:"user_id,omitempty" db:"user_id"` // identity provider user linked to this contractor
 	CompanyName     string    `json:"company_name" db:"company_name"`
 	ContactPerson   string    `json:"contact_person" db:"contact_person"`
 	Email           string    `json:"email" db:"email"`
@@ -108,9 +145,26 @@ type MaintenanceRequest struct {
 	EstimatedCost   *float64  `json:"estimated_cost,omitempty" db:"estimated_cost"`
 	ActualCost      *float64  `json:"actual_cost,omitempty" db:"actual_cost"`
 	ContractorID    *uuid.UUID `json:"contractor_id,omitempty" db:"contractor_id"`
+	ContractorStatus string   `json:"contractor_status,omitempty" db:"contractor_status"` // pending, accepted, declined
+	ActualHours     *float64  `json:"actual_hours,omitempty" db:"actual_hours"`
 	Notes           string    `json:"notes" db:"notes"`
 }
 
+// Maintenance request statuses
+const (
+	MaintenanceStatusOpen       = "open"
+	MaintenanceStatusInProgress = "in_progress"
+	MaintenanceStatusCompleted  = "completed"
+	MaintenanceStatusCancelled  = "cancelled"
+)
+
+// Contractor responses to an assigned maintenance request
+const (
+	ContractorStatusPending  = "pending"
+	ContractorStatusAccepted = "accepted"
+	ContractorStatusDeclined = "declined"
+)
+
 // MaintenancePhoto represents photos attached to maintenance requests
 type MaintenancePhoto struct {
 	BaseEntity
@@ -135,6 +189,46 @@ type Payment struct {
 	Status          string    `json:"status" db:"status"` // pending, paid, overdue, cancelled
 	ReferenceNumber string    `json:"reference_number" db:"reference_number"`
 	Notes           string    `json:"notes" db:"notes"`
+	AmountPaid      float64    `json:"amount_paid" db:"amount_paid"`
+	PeriodStart     *time.Time `json:"period_start,omitempty" db:"period_start"` // rent period covered by the charge
+	PeriodEnd       *time.Time `json:"period_end,omitempty" db:"period_end"`
+	ParentPaymentID *uuid.UUID `json:"parent_payment_id,omitempty" db:"parent_payment_id"` // charge a late fee was assessed on
+}
+
+// Payment statuses
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusPaid      = "paid"
+	PaymentStatusOverdue   = "overdue"
+	PaymentStatusCancelled = "cancelled"
+)
+
+// LateFeePolicy describes how a landlord charges late fees. The fee on a
+// charge is FlatAmount plus Percentage of the charge, plus DailyAmount for
+// every day past the grace period, capped at MaxAmount when it is set.
+type LateFeePolicy struct {
+	LandlordID      uuid.UUID `json:"landlord_id" db:"landlord_id"`
+	Enabled         bool      `json:"enabled" db:"enabled"`
+	GracePeriodDays int       `json:"grace_period_days" db:"grace_period_days"`
+	FlatAmount      float64   `json:"flat_amount" db:"flat_amount"`
+	Percentage      float64   `json:"percentage" db:"percentage"`
+	DailyAmount     float64   `json:"daily_amount" db:"daily_amount"`
+	MaxAmount       float64   `json:"max_amount" db:"max_amount"` // 0 means no cap
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentReceipt records money received against a payment charge
+type PaymentReceipt struct {
+	BaseEntity
+	LandlordID      uuid.UUID `json:"landlord_id" db:"landlord_id"`
+	TenantID        uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	PaymentID       uuid.UUID `json:"payment_id" db:"payment_id"`
+	Amount          float64   `json:"amount" db:"amount"`
+	PaymentMethod   string    `json:"payment_method" db:"payment_method"`
+	ReferenceNumber string    `json:"reference_number" db:"reference_number"`
+	ReceivedAt      time.Time `json:"received_at" db:"received_at"`
+	Notes           string    `json:"notes" db:"notes"`
 }
 
 // AI Chat Message represents a conversation with the AI chatbot
@@ -142,6 +236,7 @@ type AIChatMessage struct {
 	BaseEntity
 	LandlordID      uuid.UUID `json:"landlord_id" db:"landlord_id"`
 	TenantID        *uuid.UUID `json:"tenant_id,omitempty" db:"tenant_id"`
+	ThreadID        *uuid.UUID `json:"thread_id,omitempty" db:"thread_id"`
 	UserType        string    `json:"user_type" db:"user_type"` // landlord, tenant
 	Question        string    `json:"question" db:"question"`
 	Answer          string    `json:"answer" db:"answer"`
@@ -150,6 +245,90 @@ type AIChatMessage struct {
 	Cost            float64   `json:"cost" db:"cost"`
 }
 
+// AIThread is a multi-turn conversation between one user and the assistant.
+// The oldest SummarizedMessages turns are replaced by Summary when the thread
+// is sent to the model.
+type AIThread struct {
+	BaseEntity
+	LandlordID         uuid.UUID  `json:"landlord_id" db:"landlord_id"`
+	TenantID           *uuid.UUID `json:"tenant_id,omitempty" db:"tenant_id"`
+	UserID             string     `json:"user_id" db:"user_id"`
+	UserType           string     `json:"user_type" db:"user_type"` // landlord, tenant
+	Title              string     `json:"title" db:"title"`
+	Summary            string     `json:"summary,omitempty" db:"summary"`
+	SummarizedMessages int        `json:"summarized_messages" db:"summarized_messages"`
+}
+
+// AIToolInvocation records a tool the AI assistant used, or proposed, while
+// answering a chat message. Tools that change data wait for the user to
+// confirm them.
+type AIToolInvocation struct {
+	BaseEntity
+	LandlordID  uuid.UUID       `json:"landlord_id" db:"landlord_id"`
+	MessageID   uuid.UUID       `json:"message_id" db:"message_id"`
+	UserID      string          `json:"user_id" db:"user_id"`
+	UserType    string          `json:"user_type" db:"user_type"`
+	ToolName    string          `json:"tool_name" db:"tool_name"`
+	Input       json.RawMessage `json:"input" db:"input"`
+	Status      string          `json:"status" db:"status"`
+	Result      json.RawMessage `json:"result,omitempty" db:"result"`
+	Error       string          `json:"error,omitempty" db:"error"`
+	ConfirmedAt *time.Time      `json:"confirmed_at,omitempty" db:"confirmed_at"`
+}
+
+// AI tool invocation statuses
+const (
+	AIToolStatusPending   = "pending_confirmation"
+	AIToolStatusConfirmed = "confirmed" // claimed for execution
+	AIToolStatusExecuted  = "executed"
+	AIToolStatusFailed    = "failed"
+	AIToolStatusRejected  = "rejected"
+	AIToolStatusExpired   = "expired"
+)
+
+// MaintenanceTriage is an AI suggestion for how to handle a maintenance
+// request. It is only applied once the landlord approves it.
+type MaintenanceTriage struct {
+	BaseEntity
+	MaintenanceRequestID uuid.UUID  `json:"maintenance_request_id" db:"maintenance_request_id"`
+	LandlordID           uuid.UUID  `json:"landlord_id" db:"landlord_id"`
+	Status               string     `json:"status" db:"status"`
+	Category             string     `json:"category" db:"category"`
+	Priority             string     `json:"priority" db:"priority"`
+	IsEmergency          bool       `json:"is_emergency" db:"is_emergency"`
+	EmergencyReason      string     `json:"emergency_reason,omitempty" db:"emergency_reason"`
+	EstimatedCostMin     *float64   `json:"estimated_cost_min,omitempty" db:"estimated_cost_min"`
+	EstimatedCostMax     *float64   `json:"estimated_cost_max,omitempty" db:"estimated_cost_max"`
+	ContractorID         *uuid.UUID `json:"contractor_id,omitempty" db:"contractor_id"`
+	Reasoning            string     `json:"reasoning" db:"reasoning"`
+	PhotosReviewed       int        `json:"photos_reviewed" db:"photos_reviewed"`
+	MessageID            *uuid.UUID `json:"message_id,omitempty" db:"message_id"` // chat message recording the model usage
+	Error                string     `json:"error,omitempty" db:"error"`
+	ReviewedBy           string     `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt           *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+}
+
+// Maintenance triage statuses
+const (
+	TriageStatusPending    = "pending"
+	TriageStatusApproved   = "approved"
+	TriageStatusRejected   = "rejected"
+	TriageStatusSuperseded = "superseded" // replaced by a newer suggestion before review
+	TriageStatusFailed     = "failed"
+)
+
+// AIBudget caps a landlord's AI usage per calendar month (UTC), and each of
+// their tenants' usage per day. A limit of 0 means no limit.
+type AIBudget struct {
+	LandlordID            uuid.UUID `json:"landlord_id" db:"landlord_id"`
+	MonthlyTokenLimit     int64     `json:"monthly_token_limit" db:"monthly_token_limit"`
+	MonthlyCostLimit      float64   `json:"monthly_cost_limit" db:"monthly_cost_limit"`
+	TenantDailyTokenLimit int       `json:"tenant_daily_token_limit" db:"tenant_daily_token_limit"`
+	SoftLimitPercent      int       `json:"soft_limit_percent" db:"soft_limit_percent"` // warn the landlord at this share of a monthly limit
+	CreatedAt             time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
+}
+
 // Notification represents system notifications
 type Notification struct {
 	BaseEntity
@@ -164,3 +343,77 @@ type Notification struct {
 	RelatedEntityID *uuid.UUID `json:"related_entity_id,omitempty" db:"related_entity_id"`
 	RelatedEntityType string   `json:"related_entity_type,omitempty" db:"related_entity_type"`
 }
+
+// TenantInvitation represents an emailed invitation for a tenant to create a login
+type TenantInvitation struct {
+	BaseEntity
+	LandlordID uuid.UUID  `json:"landlord_id" db:"landlord_id"`
+	TenantID   uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	Email      string     `json:"email" db:"email"`
+	CodeHash   string     `json:"-" db:"code_hash"` // SHA-256 of the code sent by email
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// ContractorInvitation represents an emailed invitation for a contractor to create a login
+type ContractorInvitation struct {
+	BaseEntity
+	LandlordID   uuid.UUID  `json:"landlord_id" db:"landlord_id"`
+	ContractorID uuid.UUID  `json:"contractor_id" db:"contractor_id"`
+	Email        string     `json:"email" db:"email"`
+	CodeHash     string     `json:"-" db:"code_hash"` // SHA-256 of the code sent by email
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt   *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// TeamMember is a landlord-side user of a landlord account: its owner or a
+// staff member limited to the assigned properties
+type TeamMember struct {
+	BaseEntity
+	LandlordID    uuid.UUID   `json:"landlord_id" db:"landlord_id"`
+	UserID        string      `json:"user_id" db:"user_id"`
+	Email         string      `json:"email" db:"email"`
+	FirstName     string      `json:"first_name" db:"first_name"`
+	LastName      string      `json:"last_name" db:"last_name"`
+	Role          string      `json:"role" db:"role"`
+	AllProperties bool        `json:"all_properties" db:"all_properties"`
+	PropertyIDs   []uuid.UUID `json:"property_ids" db:"property_ids"`
+	RemovedAt     *time.Time  `json:"removed_at,omitempty" db:"removed_at"`
+}
+
+// TeamInvitation represents an emailed invitation for staff to join a landlord account
+type TeamInvitation struct {
+	BaseEntity
+	LandlordID    uuid.UUID   `json:"landlord_id" db:"landlord_id"`
+	Email         string      `json:"email" db:"email"`
+	Role          string      `json:"role" db:"role"`
+	AllProperties bool        `json:"all_properties" db:"all_properties"`
+	PropertyIDs   []uuid.UUID `json:"property_ids" db:"property_ids"`
+	InvitedBy     string      `json:"invited_by" db:"invited_by"`
+	CodeHash      string      `json:"-" db:"code_hash"` // SHA-256 of the code sent by email
+	ExpiresAt     time.Time   `json:"expires_at" db:"expires_at"`
+	AcceptedAt    *time.Time  `json:"accepted_at,omitempty" db:"accepted_at"`
+	RevokedAt     *time.Time  `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// MaintenanceStatusChange records a change to a maintenance request and who made it
+type MaintenanceStatusChange struct {
+	ID                   uuid.UUID              `json:"id" db:"id"`
+	MaintenanceRequestID uuid.UUID              `json:"maintenance_request_id" db:"maintenance_request_id"`
+	LandlordID           uuid.UUID              `json:"landlord_id" db:"landlord_id"`
+	FromStatus           string                 `json:"from_status,omitempty" db:"from_status"` // empty when the request was created
+	ToStatus             string                 `json:"to_status" db:"to_status"`
+	ChangedBy            string                 `json:"changed_by" db:"changed_by"`
+	ChangedByType        string                 `json:"changed_by_type" db:"changed_by_type"`
+	Note                 string                 `json:"note,omitempty" db:"note"`
+	Changes              map[string]FieldChange `json:"changes,omitempty" db:"changes"`
+	CreatedAt            time.Time              `json:"created_at" db:"created_at"`
+}
+
+// FieldChange holds the previous and new value of a changed field
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
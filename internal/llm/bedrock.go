@@ -0,0 +1,214 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// bedrockAnthropicVersion is the Messages API version Bedrock expects in
+// Anthropic request bodies
+const bedrockAnthropicVersion = "bedrock-2023-05-31"
+
+// BedrockProvider runs Anthropic Claude models on AWS Bedrock
+type BedrockProvider struct {
+	client *bedrockruntime.Client
+	model  string
+}
+
+// claudeRequest is the Anthropic-on-Bedrock request body
+type claudeRequest struct {
+	AnthropicVersion string            `json:"anthropic_version"`
+	MaxTokens        int               `json:"max_tokens"`
+	Messages         []Message         `json:"messages"`
+	System           string            `json:"system,omitempty"`
+	Tools            []Tool            `json:"tools,omitempty"`
+	ToolChoice       *claudeToolChoice `json:"tool_choice,omitempty"`
+}
+
+// claudeToolChoice forces the model to call a particular tool, or no tool
+type claudeToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// claudeResponse is the Anthropic-on-Bedrock response body
+type claudeResponse struct {
+	Content    []Block `json:"content"`
+	StopReason string  `json:"stop_reason"`
+	Usage      Usage   `json:"usage"`
+}
+
+// claudeStreamEvent is one Anthropic streaming event. Only the fields used to
+// assemble the answer and its usage are decoded.
+type claudeStreamEvent struct {
+	Type    string `json:"type"`
+	Message *struct {
+		Usage Usage `json:"usage"`
+	} `json:"message,omitempty"`
+	Delta *struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta,omitempty"`
+	Usage *Usage `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func NewBedrockProvider(client *bedrockruntime.Client, model string) *BedrockProvider {
+	return &BedrockProvider{
+		client: client,
+		model:  model,
+	}
+}
+
+// Model returns the Bedrock model ID
+func (p *BedrockProvider) Model() string {
+	return p.model
+}
+
+// Complete invokes the model and decodes its response
+func (p *BedrockProvider) Complete(ctx context.Context, req *Request) (*Response, error) {
+	body, err := claudeRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     awssdk.String(p.model),
+		Body:        body,
+		ContentType: awssdk.String("application/json"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke Bedrock model: %w", err)
+	}
+
+	var resp claudeResponse
+	if err := json.Unmarshal(result.Body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &Response{Content: resp.Content, StopReason: resp.StopReason, Usage: resp.Usage}, nil
+}
+
+// Stream invokes the model with a streamed response
+func (p *BedrockProvider) Stream(ctx context.Context, req *Request, onDelta DeltaFunc) (*Response, error) {
+	body, err := claudeRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := p.client.InvokeModelWithResponseStream(ctx, &bedrockruntime.InvokeModelWithResponseStreamInput{
+		ModelId:     awssdk.String(p.model),
+		Body:        body,
+		ContentType: awssdk.String("application/json"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke Bedrock model: %w", err)
+	}
+	stream := output.GetStream()
+	defer stream.Close()
+
+	resp := &Response{}
+	var answer strings.Builder
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-stream.Events():
+			if !ok {
+				if err := stream.Err(); err != nil {
+					return nil, fmt.Errorf("Bedrock response stream failed: %w", err)
+				}
+				return nil, errors.New("Bedrock response stream ended before the answer was complete")
+			}
+			chunk, ok := event.(*types.ResponseStreamMemberChunk)
+			if !ok {
+				continue
+			}
+
+			delta, done, err := applyStreamEvent(resp, chunk.Value.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			if delta != "" {
+				answer.WriteString(delta)
+				if err := onDelta(delta); err != nil {
+					return nil, err
+				}
+			}
+			if done {
+				resp.Content = []Block{{Type: BlockText, Text: answer.String()}}
+				return resp, nil
+			}
+		}
+	}
+}
+
+// claudeRequestBody encodes an Anthropic-on-Bedrock request
+func claudeRequestBody(req *Request) ([]byte, error) {
+	out := &claudeRequest{
+		AnthropicVersion: bedrockAnthropicVersion,
+		MaxTokens:        req.MaxTokens,
+		Messages:         req.Messages,
+		System:           req.System,
+		Tools:            req.Tools,
+	}
+	switch req.ToolChoice {
+	case "":
+	case ToolChoiceNone:
+		out.ToolChoice = &claudeToolChoice{Type: "none"}
+	default:
+		out.ToolChoice = &claudeToolChoice{Type: "tool", Name: req.ToolChoice}
+	}
+	body, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return body, nil
+}
+
+// applyStreamEvent folds one streaming event into resp's usage and stop
+// reason. It returns any answer text the event carries and whether the event
+// ends the message.
+func applyStreamEvent(resp *Response, payload []byte) (string, bool, error) {
+	var event claudeStreamEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return "", false, fmt.Errorf("failed to unmarshal stream event: %w", err)
+	}
+
+	switch event.Type {
+	case "message_start":
+		if event.Message != nil {
+			resp.Usage = event.Message.Usage
+		}
+	case "content_block_delta":
+		if event.Delta != nil && event.Delta.Type == "text_delta" {
+			return event.Delta.Text, false, nil
+		}
+	case "message_delta":
+		if event.Delta != nil && event.Delta.StopReason != "" {
+			resp.StopReason = event.Delta.StopReason
+		}
+		// Output tokens are cumulative
+		if event.Usage != nil {
+			resp.Usage.OutputTokens = event.Usage.OutputTokens
+		}
+	case "message_stop":
+		return "", true, nil
+	case "error":
+		if event.Error != nil {
+			return "", false, fmt.Errorf("model stream error (%s): %s", event.Error.Type, event.Error.Message)
+		}
+		return "", false, errors.New("model stream error")
+	}
+	return "", false, nil
+}
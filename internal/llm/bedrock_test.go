@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestApplyStreamEvent(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":42,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Rent is "}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"due on the 1st."}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":9}}`,
+		`{"type":"message_stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":42,"outputTokenCount":9}}`,
+	}
+
+	resp := &Response{}
+	var answer strings.Builder
+	for i, payload := range events {
+		delta, done, err := applyStreamEvent(resp, []byte(payload))
+		if err != nil {
+			t.Fatalf("event %d: error = %v", i, err)
+		}
+		answer.WriteString(delta)
+		if done != (i == len(events)-1) {
+			t.Errorf("event %d: done = %v", i, done)
+		}
+	}
+
+	if got := answer.String(); got != "Rent is due on the 1st." {
+		t.Errorf("answer = %q", got)
+	}
+	if resp.Usage.InputTokens != 42 || resp.Usage.OutputTokens != 9 {
+		t.Errorf("usage = %+v, want 42 input and 9 output tokens", resp.Usage)
+	}
+	if resp.StopReason != StopEndTurn {
+		t.Errorf("stop reason = %q, want %q", resp.StopReason, StopEndTurn)
+	}
+
+	if _, _, err := applyStreamEvent(resp, []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)); err == nil {
+		t.Error("applyStreamEvent() expected an error for an error event")
+	}
+	if _, _, err := applyStreamEvent(resp, []byte(`not json`)); err == nil {
+		t.Error("applyStreamEvent() expected an error for malformed JSON")
+	}
+}
+
+func TestClaudeRequestBody(t *testing.T) {
+	body, err := claudeRequestBody(&Request{
+		System: "Be brief.",
+		Messages: []Message{
+			Text(RoleUser, "Is rent due?"),
+			{Role: RoleAssistant, Content: []Block{{Type: BlockToolUse, ID: "toolu_1", Name: "get_payment_balance", Input: json.RawMessage(`{}`)}}},
+			{Role: RoleUser, Content: []Block{{Type: BlockToolResult, ToolUseID: "toolu_1", Result: `{"balance":0}`}}},
+		},
+		Tools:     []Tool{{Name: "get_payment_balance", Description: "Balance", InputSchema: json.RawMessage(`{"type":"object"}`)}},
+		MaxTokens: 100,
+	})
+	if err != nil {
+		t.Fatalf("claudeRequestBody() error = %v", err)
+	}
+
+	want := `{"anthropic_version":"bedrock-2023-05-31","max_tokens":100,"messages":[` +
+		`{"role":"user","content":[{"type":"text","text":"Is rent due?"}]},` +
+		`{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"get_payment_balance","input":{}}]},` +
+		`{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"{\"balance\":0}"}]}],` +
+		`"system":"Be brief.","tools":[{"name":"get_payment_balance","description":"Balance","input_schema":{"type":"object"}}]}`
+	if string(body) != want {
+		t.Errorf("claudeRequestBody() =\n%s\nwant\n%s", body, want)
+	}
+
+	body, err = claudeRequestBody(&Request{Messages: []Message{Text(RoleUser, "Tips?")}, ToolChoice: "record_tips", MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("claudeRequestBody() error = %v", err)
+	}
+	if !strings.Contains(string(body), `"tool_choice":{"type":"tool","name":"record_tips"}`) {
+		t.Errorf("claudeRequestBody() = %s, want a forced tool choice", body)
+	}
+
+	body, err = claudeRequestBody(&Request{Messages: []Message{Text(RoleUser, "Tips?")}, ToolChoice: ToolChoiceNone, MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("claudeRequestBody() error = %v", err)
+	}
+	if !strings.Contains(string(body), `"tool_choice":{"type":"none"}`) {
+		t.Errorf("claudeRequestBody() = %s, want no tool calls allowed", body)
+	}
+}
@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// FakeModel is the model ID reported by Fake
+const FakeModel = "fake"
+
+// fakeFallbackAnswer is returned once a Fake's script is used up
+const fakeFallbackAnswer = "This is a placeholder answer from the fake model provider."
+
+// Fake is a deterministic provider that replays scripted responses in order
+// and records every request it receives. Once the script is used up it
+// answers with a fixed placeholder, unless it was created with Strict.
+type Fake struct {
+	mu       sync.Mutex
+	script   []fakeStep
+	strict   bool
+	requests []Request
+}
+
+type fakeStep struct {
+	resp *Response
+	err  error
+}
+
+// NewFake returns a fake provider with an empty script
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// Strict makes the fake fail once its script is used up instead of falling
+// back to a placeholder answer
+func (f *Fake) Strict() *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.strict = true
+	return f
+}
+
+// Reply appends a response to the script
+func (f *Fake) Reply(resp *Response) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.script = append(f.script, fakeStep{resp: resp})
+	return f
+}
+
+// ReplyText appends a plain text answer to the script. Its usage counts ten
+// input tokens and one output token per word of the answer.
+func (f *Fake) ReplyText(text string) *Fake {
+	return f.Reply(fakeTextResponse(text))
+}
+
+// Fail appends an error to the script
+func (f *Fake) Fail(err error) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.script = append(f.script, fakeStep{err: err})
+	return f
+}
+
+// Requests returns the requests received so far
+func (f *Fake) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+// Model returns FakeModel
+func (f *Fake) Model() string {
+	return FakeModel
+}
+
+// Complete returns the next scripted response
+func (f *Fake) Complete(ctx context.Context, req *Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.next(req)
+}
+
+// Stream returns the next scripted response, passing its text to onDelta one
+// word at a time
+func (f *Fake) Stream(ctx context.Context, req *Request, onDelta DeltaFunc) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp, err := f.next(req)
+	if err != nil {
+		return nil, err
+	}
+
+	text := resp.Text()
+	for len(text) > 0 {
+		end := strings.IndexByte(text[1:], ' ') + 1
+		if end == 0 {
+			end = len(text)
+		}
+		if err := onDelta(text[:end]); err != nil {
+			return nil, err
+		}
+		text = text[end:]
+	}
+	return resp, nil
+}
+
+func (f *Fake) next(req *Request) (*Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, *req)
+
+	if len(f.script) == 0 {
+		if f.strict {
+			return nil, errors.New("fake provider: no scripted response left")
+		}
+		return fakeTextResponse(fakeFallbackAnswer), nil
+	}
+	step := f.script[0]
+	f.script = f.script[1:]
+	return step.resp, step.err
+}
+
+func fakeTextResponse(text string) *Response {
+	return &Response{
+		Content:    []Block{{Type: BlockText, Text: text}},
+		StopReason: StopEndTurn,
+		Usage:      Usage{InputTokens: 10, OutputTokens: len(strings.Fields(text))},
+	}
+}
@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFake(t *testing.T) {
+	boom := errors.New("throttled")
+	f := NewFake().Strict().ReplyText("Rent is due on the 1st.").Fail(boom)
+	ctx := context.Background()
+
+	var deltas []string
+	resp, err := f.Stream(ctx, &Request{System: "first"}, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if want := []string{"Rent", " is", " due", " on", " the", " 1st."}; !reflect.DeepEqual(deltas, want) {
+		t.Errorf("deltas = %q, want %q", deltas, want)
+	}
+	if resp.Text() != "Rent is due on the 1st." || resp.Usage.OutputTokens != 6 {
+		t.Errorf("response = %+v", resp)
+	}
+
+	if _, err := f.Complete(ctx, &Request{System: "second"}); !errors.Is(err, boom) {
+		t.Errorf("Complete() error = %v, want %v", err, boom)
+	}
+	if _, err := f.Complete(ctx, &Request{System: "third"}); err == nil {
+		t.Error("Complete() expected an error once a strict script is used up")
+	}
+
+	var systems []string
+	for _, req := range f.Requests() {
+		systems = append(systems, req.System)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(systems, want) {
+		t.Errorf("recorded requests = %v, want %v", systems, want)
+	}
+
+	if resp, err := NewFake().Complete(ctx, &Request{}); err != nil || resp.Text() != fakeFallbackAnswer {
+		t.Errorf("Complete() on an empty script = %v, %v", resp, err)
+	}
+}
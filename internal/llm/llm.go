@@ -0,0 +1,179 @@
+// Package llm talks to large language models behind a provider-neutral
+// interface. Conversations use Anthropic-style content blocks; providers with
+// a different wire format translate to and from them.
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"dwell/internal/aws"
+	"dwell/internal/config"
+)
+
+// Provider names accepted in AI_PROVIDER
+const (
+	ProviderBedrock = "bedrock"
+	ProviderOpenAI  = "openai"
+	ProviderFake    = "fake"
+)
+
+// Conversation roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Content block types
+const (
+	BlockText       = "text"
+	BlockToolUse    = "tool_use"
+	BlockToolResult = "tool_result"
+	BlockImage      = "image"
+)
+
+// Reasons a response ended
+const (
+	StopEndTurn   = "end_turn"
+	StopToolUse   = "tool_use"
+	StopMaxTokens = "max_tokens"
+)
+
+// Provider generates responses from a model
+type Provider interface {
+	// Complete returns the model's full response to a conversation
+	Complete(ctx context.Context, req *Request) (*Response, error)
+	// Stream passes answer text to onDelta as it is generated and returns the
+	// assembled response once the model finishes
+	Stream(ctx context.Context, req *Request, onDelta DeltaFunc) (*Response, error)
+	// Model is the ID of the model responses come from
+	Model() string
+}
+
+// DeltaFunc receives streamed answer text. Returning an error stops the stream.
+type DeltaFunc func(text string) error
+
+// ToolChoiceNone as a request's ToolChoice has the model answer without
+// calling a tool. The tools stay defined, so earlier tool turns in the
+// conversation remain valid.
+const ToolChoiceNone = "none"
+
+// Request is a conversation to send to a model
+type Request struct {
+	System   string
+	Messages []Message
+	Tools    []Tool
+	// ToolChoice names a tool the model must call, or is ToolChoiceNone to
+	// keep it from calling any. Empty lets the model decide whether to call
+	// a tool.
+	ToolChoice string
+	MaxTokens  int
+}
+
+// Message is one conversation turn
+type Message struct {
+	Role    string  `json:"role"`
+	Content []Block `json:"content"`
+}
+
+// Block is a text, image, tool_use or tool_result content block
+type Block struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Result    string          `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+	Source    *ImageSource    `json:"source,omitempty"`
+}
+
+// ImageSource is the base64-encoded data of an image block
+type ImageSource struct {
+	Type      string `json:"type"` // always base64
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+// Tool describes a tool the model may call
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// Response is a model's reply
+type Response struct {
+	Content    []Block
+	StopReason string
+	Usage      Usage
+}
+
+// Usage counts the tokens a request consumed. InputTokens excludes tokens
+// read from or written to the prompt cache.
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+}
+
+// Add accumulates the usage of another request
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheReadInputTokens += other.CacheReadInputTokens
+	u.CacheCreationInputTokens += other.CacheCreationInputTokens
+}
+
+// Total returns every token counted, cached or not
+func (u Usage) Total() int {
+	return u.InputTokens + u.OutputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+}
+
+// Text returns a message with a single text block
+func Text(role, text string) Message {
+	return Message{Role: role, Content: []Block{{Type: BlockText, Text: text}}}
+}
+
+// Image returns an image block holding data of the given media type, such as
+// image/jpeg
+func Image(mediaType string, data []byte) Block {
+	return Block{Type: BlockImage, Source: &ImageSource{
+		Type:      "base64",
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(data),
+	}}
+}
+
+// Text returns the text blocks of a response joined together
+func (r *Response) Text() string {
+	var parts []string
+	for _, b := range r.Content {
+		if b.Type == BlockText && b.Text != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// NewProvider returns the provider selected by cfg.AI.Provider
+func NewProvider(cfg *config.Config, awsClients *aws.Clients) (Provider, error) {
+	switch cfg.AI.Provider {
+	case "", ProviderBedrock:
+		return NewBedrockProvider(awsClients.GetBedrockClient(), cfg.AWS.Bedrock.Model), nil
+	case ProviderOpenAI:
+		if cfg.AI.OpenAI.Model == "" {
+			return nil, fmt.Errorf("AI_OPENAI_MODEL is required when AI_PROVIDER is %s", ProviderOpenAI)
+		}
+		return NewOpenAIProvider(cfg.AI.OpenAI.BaseURL, cfg.AI.OpenAI.APIKey, cfg.AI.OpenAI.Model, nil), nil
+	case ProviderFake:
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", cfg.AI.Provider)
+	}
+}
@@ -0,0 +1,390 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// openAITimeout bounds a whole request, including a streamed answer
+const openAITimeout = 5 * time.Minute
+
+// OpenAIProvider talks to any server implementing the OpenAI chat completions
+// API, such as a local model server
+type OpenAIProvider struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+type openAIRequest struct {
+	Model         string               `json:"model"`
+	Messages      []openAIMessage      `json:"messages"`
+	Tools         []openAITool         `json:"tools,omitempty"`
+	ToolChoice    *openAIToolChoice    `json:"tool_choice,omitempty"`
+	MaxTokens     int                  `json:"max_tokens,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    openAIContent    `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openAIContent is message content. It is sent as a plain string, or as text
+// and image parts when the message carries images.
+type openAIContent struct {
+	Text  string
+	Parts []openAIContentPart
+}
+
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+func (c openAIContent) MarshalJSON() ([]byte, error) {
+	if len(c.Parts) > 0 {
+		return json.Marshal(c.Parts)
+	}
+	return json.Marshal(c.Text)
+}
+
+func (c *openAIContent) UnmarshalJSON(data []byte) error {
+	*c = openAIContent{}
+	switch {
+	case string(data) == "null":
+		return nil
+	case len(data) > 0 && data[0] == '[':
+		if err := json.Unmarshal(data, &c.Parts); err != nil {
+			return err
+		}
+		for _, part := range c.Parts {
+			if part.Type == "text" {
+				c.Text += part.Text
+			}
+		}
+		return nil
+	default:
+		return json.Unmarshal(data, &c.Text)
+	}
+}
+
+type openAITool struct {
+	Type     string             `json:"type"`
+	Function openAIFunctionSpec `json:"function"`
+}
+
+type openAIToolChoice struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+// MarshalJSON writes the none choice as the bare string the API expects
+func (c openAIToolChoice) MarshalJSON() ([]byte, error) {
+	if c.Type == ToolChoiceNone {
+		return []byte(`"none"`), nil
+	}
+	type choice openAIToolChoice
+	return json.Marshal(choice(c))
+}
+
+type openAIFunctionSpec struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+}
+
+// usage converts chat completion usage, where cached tokens are part of the
+// prompt tokens
+func (u *openAIUsage) usage() Usage {
+	cached := u.PromptTokensDetails.CachedTokens
+	return Usage{
+		InputTokens:          u.PromptTokens - cached,
+		OutputTokens:         u.CompletionTokens,
+		CacheReadInputTokens: cached,
+	}
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message      openAIMessage `json:"message"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+	Usage openAIUsage `json:"usage"`
+}
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+type openAIErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewOpenAIProvider returns a provider for the chat completions API at
+// baseURL, e.g. http://localhost:11434/v1. A nil httpClient uses a client
+// with a default timeout.
+func NewOpenAIProvider(baseURL, apiKey, model string, httpClient *http.Client) *OpenAIProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: openAITimeout}
+	}
+	return &OpenAIProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: httpClient,
+	}
+}
+
+// Model returns the model name sent with each request
+func (p *OpenAIProvider) Model() string {
+	return p.model
+}
+
+// Complete sends a chat completion request
+func (p *OpenAIProvider) Complete(ctx context.Context, req *Request) (*Response, error) {
+	httpResp, err := p.post(ctx, p.chatRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	var resp openAIResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("model returned no choices")
+	}
+
+	choice := resp.Choices[0]
+	out := &Response{
+		StopReason: openAIStopReason(choice.FinishReason),
+		Usage:      resp.Usage.usage(),
+	}
+	if choice.Message.Content.Text != "" {
+		out.Content = append(out.Content, Block{Type: BlockText, Text: choice.Message.Content.Text})
+	}
+	for _, call := range choice.Message.ToolCalls {
+		input := json.RawMessage(call.Function.Arguments)
+		if !json.Valid(input) {
+			input = json.RawMessage("{}")
+		}
+		out.Content = append(out.Content, Block{Type: BlockToolUse, ID: call.ID, Name: call.Function.Name, Input: input})
+	}
+	if len(choice.Message.ToolCalls) > 0 {
+		out.StopReason = StopToolUse
+	}
+	return out, nil
+}
+
+// Stream sends a streamed chat completion request and reads its server-sent
+// events. Only answer text is streamed; tool calls are not supported.
+func (p *OpenAIProvider) Stream(ctx context.Context, req *Request, onDelta DeltaFunc) (*Response, error) {
+	body := p.chatRequest(req)
+	body.Tools = nil
+	body.ToolChoice = nil
+	body.Stream = true
+	body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+
+	httpResp, err := p.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	resp := &Response{StopReason: StopEndTurn}
+	var answer strings.Builder
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			resp.Content = []Block{{Type: BlockText, Text: answer.String()}}
+			return resp, nil
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream event: %w", err)
+		}
+		if chunk.Usage != nil {
+			resp.Usage = chunk.Usage.usage()
+		}
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != "" {
+				resp.StopReason = openAIStopReason(choice.FinishReason)
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			answer.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("model response stream failed: %w", err)
+	}
+	return nil, errors.New("model response stream ended before the answer was complete")
+}
+
+// post sends a chat completion request and returns the response if it succeeded
+func (p *OpenAIProvider) post(ctx context.Context, body *openAIRequest) (*http.Response, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build model request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call model server: %w", err)
+	}
+	if httpResp.StatusCode/100 != 2 {
+		defer httpResp.Body.Close()
+		raw, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		var apiErr openAIErrorResponse
+		if json.Unmarshal(raw, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("model server returned %d: %s", httpResp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("model server returned %d", httpResp.StatusCode)
+	}
+	return httpResp, nil
+}
+
+// chatRequest translates a conversation into chat completion messages. Text
+// blocks are joined into the message content, images become image_url parts
+// with data URLs, tool_use blocks become tool calls and each tool_result
+// becomes a tool message.
+func (p *OpenAIProvider) chatRequest(req *Request) *openAIRequest {
+	out := &openAIRequest{Model: p.model, MaxTokens: req.MaxTokens}
+	if req.System != "" {
+		out.Messages = append(out.Messages, openAIMessage{Role: "system", Content: openAIContent{Text: req.System}})
+	}
+
+	for _, m := range req.Messages {
+		msg := openAIMessage{Role: m.Role}
+		var text []string
+		var images []openAIContentPart
+		for _, b := range m.Content {
+			switch b.Type {
+			case BlockText:
+				text = append(text, b.Text)
+			case BlockImage:
+				if b.Source != nil {
+					images = append(images, openAIContentPart{
+						Type:     "image_url",
+						ImageURL: &openAIImageURL{URL: "data:" + b.Source.MediaType + ";base64," + b.Source.Data},
+					})
+				}
+			case BlockToolUse:
+				call := openAIToolCall{ID: b.ID, Type: "function"}
+				call.Function.Name = b.Name
+				call.Function.Arguments = string(b.Input)
+				msg.ToolCalls = append(msg.ToolCalls, call)
+			case BlockToolResult:
+				out.Messages = append(out.Messages, openAIMessage{Role: "tool", Content: openAIContent{Text: b.Result}, ToolCallID: b.ToolUseID})
+			}
+		}
+		if len(text) > 0 || len(images) > 0 || len(msg.ToolCalls) > 0 {
+			msg.Content.Text = strings.Join(text, "\n\n")
+			if len(images) > 0 {
+				if msg.Content.Text != "" {
+					msg.Content.Parts = append(msg.Content.Parts, openAIContentPart{Type: "text", Text: msg.Content.Text})
+				}
+				msg.Content.Parts = append(msg.Content.Parts, images...)
+			}
+			out.Messages = append(out.Messages, msg)
+		}
+	}
+
+	for _, t := range req.Tools {
+		out.Tools = append(out.Tools, openAITool{
+			Type:     "function",
+			Function: openAIFunctionSpec{Name: t.Name, Description: t.Description, Parameters: t.InputSchema},
+		})
+	}
+	switch req.ToolChoice {
+	case "":
+	case ToolChoiceNone:
+		out.ToolChoice = &openAIToolChoice{Type: ToolChoiceNone}
+	default:
+		out.ToolChoice = &openAIToolChoice{Type: "function"}
+		out.ToolChoice.Function.Name = req.ToolChoice
+	}
+	return out
+}
+
+// openAIStopReason maps a chat completion finish reason to a stop reason
+func openAIStopReason(finishReason string) string {
+	switch finishReason {
+	case "tool_calls", "function_call":
+		return StopToolUse
+	case "length":
+		return StopMaxTokens
+	default:
+		return StopEndTurn
+	}
+}
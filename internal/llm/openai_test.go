@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIProvider_Complete(t *testing.T) {
+	var got openAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Let me check.","tool_calls":[`+
+			`{"id":"call_1","type":"function","function":{"name":"get_payment_balance","arguments":"{\"tenant_id\":\"t1\"}"}}]},`+
+			`"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":30,"completion_tokens":12}}`)
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(server.URL+"/v1/", "secret", "llama3", nil)
+	resp, err := p.Complete(context.Background(), &Request{
+		System: "Be brief.",
+		Messages: []Message{
+			Text(RoleUser, "What do I owe?"),
+			{Role: RoleAssistant, Content: []Block{{Type: BlockToolUse, ID: "call_0", Name: "find_tenants", Input: json.RawMessage(`{"query":"ada"}`)}}},
+			{Role: RoleUser, Content: []Block{{Type: BlockToolResult, ToolUseID: "call_0", Result: `{"tenants":[]}`}}},
+		},
+		Tools:      []Tool{{Name: "get_payment_balance", Description: "Balance", InputSchema: json.RawMessage(`{"type":"object"}`)}},
+		ToolChoice: "get_payment_balance",
+		MaxTokens:  200,
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if got.Model != "llama3" || got.MaxTokens != 200 || got.Stream {
+		t.Errorf("request = %+v", got)
+	}
+	roles := make([]string, len(got.Messages))
+	for i, m := range got.Messages {
+		roles[i] = m.Role
+	}
+	if want := []string{"system", "user", "assistant", "tool"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("message roles = %v, want %v", roles, want)
+	}
+	if call := got.Messages[2].ToolCalls; len(call) != 1 || call[0].Function.Arguments != `{"query":"ada"}` {
+		t.Errorf("assistant tool calls = %+v", call)
+	}
+	if m := got.Messages[3]; m.ToolCallID != "call_0" || m.Content.Text != `{"tenants":[]}` {
+		t.Errorf("tool message = %+v", m)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Type != "function" || got.Tools[0].Function.Name != "get_payment_balance" {
+		t.Errorf("tools = %+v", got.Tools)
+	}
+	if got.ToolChoice == nil || got.ToolChoice.Type != "function" || got.ToolChoice.Function.Name != "get_payment_balance" {
+		t.Errorf("tool choice = %+v", got.ToolChoice)
+	}
+
+	if resp.StopReason != StopToolUse {
+		t.Errorf("stop reason = %q, want %q", resp.StopReason, StopToolUse)
+	}
+	if resp.Text() != "Let me check." {
+		t.Errorf("text = %q", resp.Text())
+	}
+	if len(resp.Content) != 2 || resp.Content[1].Name != "get_payment_balance" || string(resp.Content[1].Input) != `{"tenant_id":"t1"}` {
+		t.Errorf("content = %+v", resp.Content)
+	}
+	if resp.Usage != (Usage{InputTokens: 30, OutputTokens: 12}) {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestOpenAIProvider_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("request did not ask for a stream with usage: %+v", req)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"choices":[{"delta":{"role":"assistant"}}]}`,
+			`{"choices":[{"delta":{"content":"Rent is "}}]}`,
+			`{"choices":[{"delta":{"content":"due on the 1st."},"finish_reason":"stop"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":20,"completion_tokens":7}}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(server.URL, "", "llama3", nil)
+	var deltas []string
+	resp, err := p.Stream(context.Background(), &Request{Messages: []Message{Text(RoleUser, "When is rent due?")}}, func(text string) error {
+		deltas = append(deltas, text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if want := []string{"Rent is ", "due on the 1st."}; !reflect.DeepEqual(deltas, want) {
+		t.Errorf("deltas = %q, want %q", deltas, want)
+	}
+	if resp.Text() != "Rent is due on the 1st." || resp.StopReason != StopEndTurn {
+		t.Errorf("response = %+v", resp)
+	}
+	if resp.Usage != (Usage{InputTokens: 20, OutputTokens: 7}) {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestOpenAIProvider_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"message":"model \"llama9\" not found"}}`)
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider(server.URL, "", "llama9", nil)
+	_, err := p.Complete(context.Background(), &Request{Messages: []Message{Text(RoleUser, "Hi")}})
+	if err == nil || !strings.Contains(err.Error(), `404: model "llama9" not found`) {
+		t.Errorf("Complete() error = %v", err)
+	}
+}
+
+func TestOpenAIChatRequestImages(t *testing.T) {
+	p := NewOpenAIProvider("http://localhost", "", "llava", nil)
+	body, err := json.Marshal(p.chatRequest(&Request{Messages: []Message{
+		{Role: RoleUser, Content: []Block{{Type: BlockText, Text: "What is wrong here?"}, Image("image/png", []byte("png"))}},
+	}}))
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+
+	want := `"messages":[{"role":"user","content":[{"type":"text","text":"What is wrong here?"},` +
+		`{"type":"image_url","image_url":{"url":"data:image/png;base64,cG5n"}}]}]`
+	if !strings.Contains(string(body), want) {
+		t.Errorf("request = %s\nwant it to contain %s", body, want)
+	}
+
+	var decoded openAIRequest
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("unmarshal request: %v", err)
+	}
+	if decoded.Messages[0].Content.Text != "What is wrong here?" || len(decoded.Messages[0].Content.Parts) != 2 {
+		t.Errorf("decoded content = %+v", decoded.Messages[0].Content)
+	}
+}
+
+func TestOpenAIChatRequestToolChoiceNone(t *testing.T) {
+	p := NewOpenAIProvider("http://localhost", "", "llama3", nil)
+	body, err := json.Marshal(p.chatRequest(&Request{
+		Messages:   []Message{Text(RoleUser, "Tips?")},
+		Tools:      []Tool{{Name: "record_tips", InputSchema: json.RawMessage(`{"type":"object"}`)}},
+		ToolChoice: ToolChoiceNone,
+	}))
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	if !strings.Contains(string(body), `"tool_choice":"none"`) {
+		t.Errorf("request = %s, want no tool calls allowed", body)
+	}
+}
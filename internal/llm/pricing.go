@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// ErrUnknownModelPrice is returned when a model has no entry in the price table
+var ErrUnknownModelPrice = errors.New("no price configured for model")
+
+// Price is what a model charges in US dollars per million tokens
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read"`
+	CacheWrite float64 `json:"cache_write"`
+}
+
+// PriceTable holds token prices keyed by model ID
+type PriceTable struct {
+	prices map[string]Price
+}
+
+// defaultPrices are the on-demand Bedrock prices (us-east-1) for the Claude
+// models Dwell has been run with. Deployments on other models or with
+// negotiated rates supply their own table.
+var defaultPrices = map[string]Price{
+	"anthropic.claude-3-haiku-20240307-v1:0":    {Input: 0.25, Output: 1.25},
+	"anthropic.claude-3-sonnet-20240229-v1:0":   {Input: 3, Output: 15},
+	"anthropic.claude-3-opus-20240229-v1:0":     {Input: 15, Output: 75},
+	"anthropic.claude-3-5-haiku-20241022-v1:0":  {Input: 0.8, Output: 4, CacheRead: 0.08, CacheWrite: 1},
+	"anthropic.claude-3-5-sonnet-20240620-v1:0": {Input: 3, Output: 15},
+	"anthropic.claude-3-5-sonnet-20241022-v2:0": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"anthropic.claude-3-7-sonnet-20250219-v1:0": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	FakeModel: {},
+}
+
+// crossRegionPrefixes are the inference profile prefixes Bedrock puts in
+// front of a model ID; they do not change the price
+var crossRegionPrefixes = []string{"us.", "eu.", "apac.", "global."}
+
+// DefaultPriceTable returns the built-in prices
+func DefaultPriceTable() *PriceTable {
+	return NewPriceTable(nil)
+}
+
+// NewPriceTable returns the built-in prices with the given entries added or
+// replacing them
+func NewPriceTable(overrides map[string]Price) *PriceTable {
+	t := &PriceTable{prices: make(map[string]Price, len(defaultPrices)+len(overrides))}
+	for model, price := range defaultPrices {
+		t.prices[model] = price
+	}
+	for model, price := range overrides {
+		t.prices[model] = price
+	}
+	return t
+}
+
+// LoadPriceTable reads a JSON object mapping model IDs to prices, for example
+// {"llama3": {"input": 0, "output": 0}}, and merges it over the built-in
+// prices. An empty path returns the built-in prices.
+func LoadPriceTable(path string) (*PriceTable, error) {
+	if path == "" {
+		return DefaultPriceTable(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table: %w", err)
+	}
+	var overrides map[string]Price
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse price table %s: %w", path, err)
+	}
+	for model, price := range overrides {
+		if price.Input < 0 || price.Output < 0 || price.CacheRead < 0 || price.CacheWrite < 0 {
+			return nil, fmt.Errorf("price table %s: negative price for %s", path, model)
+		}
+	}
+	return NewPriceTable(overrides), nil
+}
+
+// Lookup returns the price of a model. Cross-region inference profile IDs
+// such as us.anthropic.claude-3-5-haiku-20241022-v1:0 match the underlying
+// model.
+func (t *PriceTable) Lookup(model string) (Price, bool) {
+	if price, ok := t.prices[model]; ok {
+		return price, true
+	}
+	for _, prefix := range crossRegionPrefixes {
+		if base, ok := strings.CutPrefix(model, prefix); ok {
+			price, ok := t.prices[base]
+			return price, ok
+		}
+	}
+	return Price{}, false
+}
+
+// Check reports whether a model has a price
+func (t *PriceTable) Check(model string) error {
+	if _, ok := t.Lookup(model); !ok {
+		return fmt.Errorf("%w %q", ErrUnknownModelPrice, model)
+	}
+	return nil
+}
+
+// Cost returns the US dollar cost of usage on a model, rounded to a millionth
+// of a dollar. Models without a price cost nothing and return
+// ErrUnknownModelPrice.
+func (t *PriceTable) Cost(model string, usage Usage) (float64, error) {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownModelPrice, model)
+	}
+	cost := float64(usage.InputTokens)*price.Input +
+		float64(usage.OutputTokens)*price.Output +
+		float64(usage.CacheReadInputTokens)*price.CacheRead +
+		float64(usage.CacheCreationInputTokens)*price.CacheWrite
+	return math.Round(cost) / 1e6, nil
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPriceTable_Cost(t *testing.T) {
+	table := DefaultPriceTable()
+
+	tests := []struct {
+		name  string
+		model string
+		usage Usage
+		want  float64
+	}{
+		{"claude 3 sonnet", "anthropic.claude-3-sonnet-20240229-v1:0", Usage{InputTokens: 1000, OutputTokens: 500}, 0.0105},
+		{"haiku is cheaper", "anthropic.claude-3-haiku-20240307-v1:0", Usage{InputTokens: 1000, OutputTokens: 500}, 0.000875},
+		{"cache tokens", "anthropic.claude-3-5-sonnet-20241022-v2:0",
+			Usage{InputTokens: 100, OutputTokens: 100, CacheReadInputTokens: 10000, CacheCreationInputTokens: 2000}, 0.0123},
+		{"cross-region profile", "us.anthropic.claude-3-5-haiku-20241022-v1:0", Usage{InputTokens: 1_000_000}, 0.8},
+		{"fake is free", FakeModel, Usage{InputTokens: 1000, OutputTokens: 1000}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.Cost(tt.model, tt.usage)
+			if err != nil {
+				t.Fatalf("Cost() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Cost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := table.Cost("llama3", Usage{InputTokens: 10}); !errors.Is(err, ErrUnknownModelPrice) {
+		t.Errorf("Cost() for an unpriced model error = %v, want ErrUnknownModelPrice", err)
+	}
+	if err := table.Check("eu.llama3"); !errors.Is(err, ErrUnknownModelPrice) {
+		t.Errorf("Check() for an unpriced profile error = %v, want ErrUnknownModelPrice", err)
+	}
+}
+
+func TestLoadPriceTable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prices.json")
+	if err := os.WriteFile(path, []byte(`{
+		"llama3": {"input": 0.1, "output": 0.2},
+		"anthropic.claude-3-sonnet-20240229-v1:0": {"input": 2.4, "output": 12}
+	}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := LoadPriceTable(path)
+	if err != nil {
+		t.Fatalf("LoadPriceTable() error = %v", err)
+	}
+	if price, ok := table.Lookup("llama3"); !ok || price.Input != 0.1 || price.Output != 0.2 {
+		t.Errorf("Lookup(llama3) = %+v, %v", price, ok)
+	}
+	if price, _ := table.Lookup("anthropic.claude-3-sonnet-20240229-v1:0"); price.Input != 2.4 {
+		t.Errorf("file price did not override the built-in price: %+v", price)
+	}
+	if err := table.Check("anthropic.claude-3-haiku-20240307-v1:0"); err != nil {
+		t.Errorf("built-in prices were dropped: %v", err)
+	}
+
+	if table, err := LoadPriceTable(""); err != nil || table.Check(FakeModel) != nil {
+		t.Errorf("LoadPriceTable(\"\") = %v, %v", table, err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{"llama3": {"input": -1}}`), 0o600)
+	if _, err := LoadPriceTable(bad); err == nil {
+		t.Error("LoadPriceTable() expected an error for a negative price")
+	}
+	if _, err := LoadPriceTable(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadPriceTable() expected an error for a missing file")
+	}
+}
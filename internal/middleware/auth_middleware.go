@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"dwell/internal/authz"
 	"dwell/internal/domain"
 	"dwell/internal/services"
 
@@ -42,7 +43,7 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 
 		// Validate token
-		claims, err := authService.ValidateToken(token)
+		claims, err := authService.ValidateToken(c.Request.Context(), token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Invalid token",
@@ -58,8 +59,8 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 	}
 }
 
-// RequireLandlord middleware ensures the user is a landlord
-func RequireLandlord() gin.HandlerFunc {
+// Require middleware ensures the user's role grants the action on the resource
+func Require(resource authz.Resource, action authz.Action) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userClaims, exists := GetUserClaimsFromContext(c)
 		if !exists {
@@ -71,62 +72,10 @@ func RequireLandlord() gin.HandlerFunc {
 			return
 		}
 
-		if userClaims.UserType != "landlord" {
+		if err := authz.Authorize(userClaims, resource, action); err != nil {
 			c.JSON(http.StatusForbidden, gin.H{
 				"error":   "Access denied",
-				"message": "This endpoint requires landlord privileges",
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// RequireTenant middleware ensures the user is a tenant
-func RequireTenant() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userClaims, exists := GetUserClaimsFromContext(c)
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "User not authenticated",
-				"message": "Access token not found",
-			})
-			c.Abort()
-			return
-		}
-
-		if userClaims.UserType != "tenant" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "Access denied",
-				"message": "This endpoint requires tenant privileges",
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// RequireLandlordOrTenant middleware ensures the user is either a landlord or tenant
-func RequireLandlordOrTenant() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userClaims, exists := GetUserClaimsFromContext(c)
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "User not authenticated",
-				"message": "Access token not found",
-			})
-			c.Abort()
-			return
-		}
-
-		if userClaims.UserType != "landlord" && userClaims.UserType != "tenant" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "Access denied",
-				"message": "This endpoint requires landlord or tenant privileges",
+				"message": err.Error(),
 			})
 			c.Abort()
 			return
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const aiBudgetColumns = `landlord_id, monthly_token_limit, monthly_cost_limit, tenant_daily_token_limit,
+	soft_limit_percent, created_at, updated_at`
+
+type AIBudgetRepository struct {
+	db DBTX
+}
+
+func NewAIBudgetRepository(db DBTX) *AIBudgetRepository {
+	return &AIBudgetRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *AIBudgetRepository) WithTx(tx *sql.Tx) *AIBudgetRepository {
+	return &AIBudgetRepository{db: tx}
+}
+
+// Get returns a landlord's AI budget
+func (r *AIBudgetRepository) Get(ctx context.Context, landlordID uuid.UUID) (*domain.AIBudget, error) {
+	query := `SELECT ` + aiBudgetColumns + ` FROM ai_budgets WHERE landlord_id = $1`
+	b, err := scanAIBudget(r.db.QueryRowContext(ctx, query, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return b, nil
+}
+
+// Upsert creates or replaces a landlord's AI budget
+func (r *AIBudgetRepository) Upsert(ctx context.Context, b *domain.AIBudget) error {
+	query := `INSERT INTO ai_budgets (landlord_id, monthly_token_limit, monthly_cost_limit,
+		tenant_daily_token_limit, soft_limit_percent)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (landlord_id) DO UPDATE SET monthly_token_limit = EXCLUDED.monthly_token_limit,
+			monthly_cost_limit = EXCLUDED.monthly_cost_limit,
+			tenant_daily_token_limit = EXCLUDED.tenant_daily_token_limit,
+			soft_limit_percent = EXCLUDED.soft_limit_percent, updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		b.LandlordID, b.MonthlyTokenLimit, b.MonthlyCostLimit, b.TenantDailyTokenLimit, b.SoftLimitPercent,
+	).Scan(&b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save AI budget: %w", err)
+	}
+	return nil
+}
+
+// RecordAlert marks a budget alert as sent for a landlord and period. It
+// returns false if that alert was already recorded.
+func (r *AIBudgetRepository) RecordAlert(ctx context.Context, landlordID uuid.UUID, period time.Time, kind string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO ai_budget_alerts (landlord_id, period, kind) VALUES ($1, $2, $3)
+		ON CONFLICT (landlord_id, period, kind) DO NOTHING`, landlordID, period, kind)
+	if err != nil {
+		return false, fmt.Errorf("failed to record AI budget alert: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	return n > 0, nil
+}
+
+func scanAIBudget(row rowScanner) (*domain.AIBudget, error) {
+	var b domain.AIBudget
+	err := row.Scan(
+		&b.LandlordID, &b.MonthlyTokenLimit, &b.MonthlyCostLimit, &b.TenantDailyTokenLimit,
+		&b.SoftLimitPercent, &b.CreatedAt, &b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
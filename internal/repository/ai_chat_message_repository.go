@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const aiChatMessageColumns = `id, landlord_id, tenant_id, thread_id, user_type, question, answer, model_used,
+	tokens_used, cost, created_at, updated_at`
+
+type AIChatMessageRepository struct {
+	db DBTX
+}
+
+// AIChatMessageFilter narrows chat message list queries. LandlordID is required.
+type AIChatMessageFilter struct {
+	LandlordID    uuid.UUID
+	TenantID      *uuid.UUID
+	PropertyIDs   []uuid.UUID // when non-nil, limits results to tenants currently renting these properties
+	ThreadID      *uuid.UUID
+	UserType      string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// AIUsageDay aggregates chat usage for one UTC day
+type AIUsageDay struct {
+	Day     time.Time
+	Queries int
+	Tokens  int
+	Cost    float64
+}
+
+// AIUsageTotals aggregates chat usage over a period
+type AIUsageTotals struct {
+	Queries int
+	Tokens  int64
+	Cost    float64
+}
+
+func NewAIChatMessageRepository(db DBTX) *AIChatMessageRepository {
+	return &AIChatMessageRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *AIChatMessageRepository) WithTx(tx *sql.Tx) *AIChatMessageRepository {
+	return &AIChatMessageRepository{db: tx}
+}
+
+// Create inserts a new chat message
+func (r *AIChatMessageRepository) Create(ctx context.Context, m *domain.AIChatMessage) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+
+	query := `INSERT INTO ai_chat_messages (id, landlord_id, tenant_id, thread_id, user_type, question,
+		answer, model_used, tokens_used, cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		m.ID, m.LandlordID, m.TenantID, m.ThreadID, m.UserType, m.Question, m.Answer,
+		m.ModelUsed, m.TokensUsed, m.Cost,
+	).Scan(&m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create AI chat message: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a chat message owned by the given landlord
+func (r *AIChatMessageRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.AIChatMessage, error) {
+	query := `SELECT ` + aiChatMessageColumns + ` FROM ai_chat_messages
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	m, err := scanAIChatMessage(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return m, nil
+}
+
+// Update persists the mutable chat message fields
+func (r *AIChatMessageRepository) Update(ctx context.Context, m *domain.AIChatMessage) error {
+	query := `UPDATE ai_chat_messages SET answer = $3, model_used = $4, tokens_used = $5, cost = $6,
+		updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		m.ID, m.LandlordID, m.Answer, m.ModelUsed, m.TokensUsed, m.Cost,
+	).Scan(&m.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a chat message owned by the given landlord
+func (r *AIChatMessageRepository) Delete(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE ai_chat_messages SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete AI chat message: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// DeleteByThread soft-deletes every message in a thread
+func (r *AIChatMessageRepository) DeleteByThread(ctx context.Context, landlordID, threadID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE ai_chat_messages SET deleted_at = NOW(), updated_at = NOW()
+		WHERE thread_id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, threadID, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete AI thread messages: %w", err)
+	}
+	return nil
+}
+
+// ListByThread returns every message in a thread, oldest first
+func (r *AIChatMessageRepository) ListByThread(ctx context.Context, landlordID, threadID uuid.UUID) ([]domain.AIChatMessage, error) {
+	query := `SELECT ` + aiChatMessageColumns + ` FROM ai_chat_messages
+		WHERE thread_id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, threadID, landlordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AI thread messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []domain.AIChatMessage{}
+	for rows.Next() {
+		m, err := scanAIChatMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan AI chat message: %w", err)
+		}
+		messages = append(messages, *m)
+	}
+	return messages, rows.Err()
+}
+
+// List returns chat messages matching the filter, newest first, along with the total count
+func (r *AIChatMessageRepository) List(ctx context.Context, filter AIChatMessageFilter, opts ListOptions) ([]domain.AIChatMessage, int, error) {
+	w := aiChatMessageWhere(filter)
+
+	total, err := countRows(ctx, r.db, "ai_chat_messages", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + aiChatMessageColumns + ` FROM ai_chat_messages` + w.sql() + ` ORDER BY created_at DESC, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list AI chat messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []domain.AIChatMessage{}
+	for rows.Next() {
+		m, err := scanAIChatMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan AI chat message: %w", err)
+		}
+		messages = append(messages, *m)
+	}
+	return messages, total, rows.Err()
+}
+
+// UsageByDay returns query, token and cost totals per UTC day for messages
+// matching the filter, oldest day first. Days without messages are omitted.
+func (r *AIChatMessageRepository) UsageByDay(ctx context.Context, filter AIChatMessageFilter) ([]AIUsageDay, error) {
+	w := aiChatMessageWhere(filter)
+	query := `SELECT (created_at AT TIME ZONE 'UTC')::date AS day, COUNT(*), COALESCE(SUM(tokens_used), 0),
+		COALESCE(SUM(cost), 0)
+		FROM ai_chat_messages` + w.sql() + ` GROUP BY day ORDER BY day`
+
+	rows, err := r.db.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate AI usage: %w", err)
+	}
+	defer rows.Close()
+
+	days := []AIUsageDay{}
+	for rows.Next() {
+		var d AIUsageDay
+		if err := rows.Scan(&d.Day, &d.Queries, &d.Tokens, &d.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan AI usage: %w", err)
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// RecentQuestions returns the question text of up to limit messages matching
+// the filter, newest first
+func (r *AIChatMessageRepository) RecentQuestions(ctx context.Context, filter AIChatMessageFilter, limit int) ([]string, error) {
+	w := aiChatMessageWhere(filter)
+	w.args = append(w.args, limit)
+	query := fmt.Sprintf(`SELECT question FROM ai_chat_messages%s ORDER BY created_at DESC LIMIT $%d`, w.sql(), len(w.args))
+
+	rows, err := r.db.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AI questions: %w", err)
+	}
+	defer rows.Close()
+
+	questions := []string{}
+	for rows.Next() {
+		var q string
+		if err := rows.Scan(&q); err != nil {
+			return nil, fmt.Errorf("failed to scan AI question: %w", err)
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+// SpendSince totals the usage of a landlord's messages created at or after
+// since, optionally for one tenant. Deleted messages still count: their tokens
+// were consumed.
+func (r *AIChatMessageRepository) SpendSince(ctx context.Context, landlordID uuid.UUID, tenantID *uuid.UUID, since time.Time) (AIUsageTotals, error) {
+	w := &whereBuilder{}
+	w.add("landlord_id = $%d", landlordID)
+	w.add("created_at >= $%d", since)
+	if tenantID != nil {
+		w.add("tenant_id = $%d", *tenantID)
+	}
+	query := `SELECT COUNT(*), COALESCE(SUM(tokens_used), 0), COALESCE(SUM(cost), 0) FROM ai_chat_messages` + w.sql()
+
+	var totals AIUsageTotals
+	if err := r.db.QueryRowContext(ctx, query, w.args...).Scan(&totals.Queries, &totals.Tokens, &totals.Cost); err != nil {
+		return AIUsageTotals{}, fmt.Errorf("failed to total AI usage: %w", err)
+	}
+	return totals, nil
+}
+
+func aiChatMessageWhere(filter AIChatMessageFilter) *whereBuilder {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.TenantID != nil {
+		w.add("tenant_id = $%d", *filter.TenantID)
+	}
+	if filter.PropertyIDs != nil {
+		w.add("tenant_id IN (SELECT current_tenant_id FROM properties WHERE id = ANY($%d) AND deleted_at IS NULL)", pq.Array(filter.PropertyIDs))
+	}
+	if filter.ThreadID != nil {
+		w.add("thread_id = $%d", *filter.ThreadID)
+	}
+	if filter.UserType != "" {
+		w.add("user_type = $%d", filter.UserType)
+	}
+	if filter.CreatedAfter != nil {
+		w.add("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		w.add("created_at < $%d", *filter.CreatedBefore)
+	}
+	return w
+}
+
+func scanAIChatMessage(row rowScanner) (*domain.AIChatMessage, error) {
+	var m domain.AIChatMessage
+	err := row.Scan(
+		&m.ID, &m.LandlordID, &m.TenantID, &m.ThreadID, &m.UserType, &m.Question, &m.Answer, &m.ModelUsed,
+		&m.TokensUsed, &m.Cost, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
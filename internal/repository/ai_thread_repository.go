@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const aiThreadColumns = `id, landlord_id, tenant_id, user_id, user_type, title, summary,
+	summarized_messages, created_at, updated_at`
+
+type AIThreadRepository struct {
+	db DBTX
+}
+
+func NewAIThreadRepository(db DBTX) *AIThreadRepository {
+	return &AIThreadRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *AIThreadRepository) WithTx(tx *sql.Tx) *AIThreadRepository {
+	return &AIThreadRepository{db: tx}
+}
+
+// Create inserts a new thread
+func (r *AIThreadRepository) Create(ctx context.Context, t *domain.AIThread) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+
+	query := `INSERT INTO ai_threads (id, landlord_id, tenant_id, user_id, user_type, title)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.ID, t.LandlordID, t.TenantID, t.UserID, t.UserType, t.Title,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create AI thread: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a thread owned by the given landlord
+func (r *AIThreadRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.AIThread, error) {
+	query := `SELECT ` + aiThreadColumns + ` FROM ai_threads
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	t, err := scanAIThread(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return t, nil
+}
+
+// GetByIDForUpdate is GetByID with a row lock, for use inside a transaction
+func (r *AIThreadRepository) GetByIDForUpdate(ctx context.Context, landlordID, id uuid.UUID) (*domain.AIThread, error) {
+	query := `SELECT ` + aiThreadColumns + ` FROM ai_threads
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL FOR UPDATE`
+	t, err := scanAIThread(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return t, nil
+}
+
+// Update persists the thread title and summary and bumps updated_at, which
+// orders threads by latest activity
+func (r *AIThreadRepository) Update(ctx context.Context, t *domain.AIThread) error {
+	query := `UPDATE ai_threads SET title = $3, summary = $4, summarized_messages = $5, updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.ID, t.LandlordID, t.Title, t.Summary, t.SummarizedMessages,
+	).Scan(&t.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a thread owned by the given landlord
+func (r *AIThreadRepository) Delete(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE ai_threads SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete AI thread: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// ListByUser returns one user's threads, most recently active first, along
+// with the total count
+func (r *AIThreadRepository) ListByUser(ctx context.Context, landlordID uuid.UUID, userID string, opts ListOptions) ([]domain.AIThread, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("landlord_id = $%d", landlordID)
+	w.add("user_id = $%d", userID)
+
+	total, err := countRows(ctx, r.db, "ai_threads", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + aiThreadColumns + ` FROM ai_threads` + w.sql() + ` ORDER BY updated_at DESC, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list AI threads: %w", err)
+	}
+	defer rows.Close()
+
+	threads := []domain.AIThread{}
+	for rows.Next() {
+		t, err := scanAIThread(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan AI thread: %w", err)
+		}
+		threads = append(threads, *t)
+	}
+	return threads, total, rows.Err()
+}
+
+func scanAIThread(row rowScanner) (*domain.AIThread, error) {
+	var t domain.AIThread
+	err := row.Scan(
+		&t.ID, &t.LandlordID, &t.TenantID, &t.UserID, &t.UserType, &t.Title, &t.Summary,
+		&t.SummarizedMessages, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const aiToolInvocationColumns = `id, landlord_id, message_id, user_id, user_type, tool_name, input,
+	status, result, error, confirmed_at, created_at, updated_at`
+
+type AIToolInvocationRepository struct {
+	db DBTX
+}
+
+// AIToolInvocationFilter narrows tool invocation list queries. LandlordID is required.
+type AIToolInvocationFilter struct {
+	LandlordID uuid.UUID
+	UserID     string
+	MessageID  *uuid.UUID
+	Status     string
+}
+
+func NewAIToolInvocationRepository(db DBTX) *AIToolInvocationRepository {
+	return &AIToolInvocationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *AIToolInvocationRepository) WithTx(tx *sql.Tx) *AIToolInvocationRepository {
+	return &AIToolInvocationRepository{db: tx}
+}
+
+// Create inserts a new tool invocation
+func (r *AIToolInvocationRepository) Create(ctx context.Context, inv *domain.AIToolInvocation) error {
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.New()
+	}
+	input := inv.Input
+	if len(input) == 0 {
+		input = json.RawMessage("{}")
+	}
+
+	query := `INSERT INTO ai_tool_invocations (id, landlord_id, message_id, user_id, user_type, tool_name,
+		input, status, result, error, confirmed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		inv.ID, inv.LandlordID, inv.MessageID, inv.UserID, inv.UserType, inv.ToolName,
+		[]byte(input), nullJSON(inv.Result), inv.Error, inv.ConfirmedAt,
+	).Scan(&inv.CreatedAt, &inv.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create AI tool invocation: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a tool invocation owned by the given landlord
+func (r *AIToolInvocationRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.AIToolInvocation, error) {
+	query := `SELECT ` + aiToolInvocationColumns + ` FROM ai_tool_invocations
+		WHERE id = $1 AND landlord_id = $2`
+	inv, err := scanAIToolInvocation(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return inv, nil
+}
+
+// Transition moves an invocation from one status to another and returns
+// ErrNotFound if it was not in the from status, so only one caller can claim
+// a pending action
+func (r *AIToolInvocationRepository) Transition(ctx context.Context, inv *domain.AIToolInvocation, from string) error {
+	query := `UPDATE ai_tool_invocations SET status = $4, result = $5, error = $6, confirmed_at = $7,
+		updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND status = $3
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		inv.ID, inv.LandlordID, from, inv.Status, nullJSON(inv.Result), inv.Error, inv.ConfirmedAt,
+	).Scan(&inv.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// List returns tool invocations matching the filter, newest first, along with the total count
+func (r *AIToolInvocationRepository) List(ctx context.Context, filter AIToolInvocationFilter, opts ListOptions) ([]domain.AIToolInvocation, int, error) {
+	w := &whereBuilder{}
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.UserID != "" {
+		w.add("user_id = $%d", filter.UserID)
+	}
+	if filter.MessageID != nil {
+		w.add("message_id = $%d", *filter.MessageID)
+	}
+	if filter.Status != "" {
+		w.add("status = $%d", filter.Status)
+	}
+
+	total, err := countRows(ctx, r.db, "ai_tool_invocations", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + aiToolInvocationColumns + ` FROM ai_tool_invocations` + w.sql() + ` ORDER BY created_at DESC, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list AI tool invocations: %w", err)
+	}
+	defer rows.Close()
+
+	invocations := []domain.AIToolInvocation{}
+	for rows.Next() {
+		inv, err := scanAIToolInvocation(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan AI tool invocation: %w", err)
+		}
+		invocations = append(invocations, *inv)
+	}
+	return invocations, total, rows.Err()
+}
+
+// nullJSON stores an empty raw message as SQL NULL
+func nullJSON(v json.RawMessage) interface{} {
+	if len(v) == 0 {
+		return nil
+	}
+	return []byte(v)
+}
+
+func scanAIToolInvocation(row rowScanner) (*domain.AIToolInvocation, error) {
+	var inv domain.AIToolInvocation
+	var input, result []byte
+	err := row.Scan(
+		&inv.ID, &inv.LandlordID, &inv.MessageID, &inv.UserID, &inv.UserType, &inv.ToolName, &input,
+		&inv.Status, &result, &inv.Error, &inv.ConfirmedAt, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inv.Input = json.RawMessage(input)
+	if len(result) > 0 {
+		inv.Result = json.RawMessage(result)
+	}
+	return &inv, nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const authUserColumns = `id, email, password_hash, first_name, last_name, phone, company_name, user_type,
+	landlord_id, confirmed_at, confirmation_code_hash, confirmation_expires_at, confirmation_attempts,
+	tokens_revoked_at, created_at, updated_at`
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at`
+
+// AuthUserRepository stores the accounts and refresh tokens of the built-in
+// identity provider
+type AuthUserRepository struct {
+	db DBTX
+}
+
+func NewAuthUserRepository(db DBTX) *AuthUserRepository {
+	return &AuthUserRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *AuthUserRepository) WithTx(tx *sql.Tx) *AuthUserRepository {
+	return &AuthUserRepository{db: tx}
+}
+
+// Create inserts a new account. A taken email returns ErrDuplicate.
+func (r *AuthUserRepository) Create(ctx context.Context, u *domain.AuthUser) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+
+	query := `INSERT INTO auth_users (id, email, password_hash, first_name, last_name, phone, company_name,
+		user_type, landlord_id, confirmed_at, confirmation_code_hash, confirmation_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		u.ID, u.Email, u.PasswordHash, u.FirstName, u.LastName, u.Phone, u.CompanyName,
+		u.UserType, u.LandlordID, u.ConfirmedAt, u.ConfirmationCodeHash, u.ConfirmationExpiresAt,
+	).Scan(&u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create account: %w", duplicate(err))
+	}
+	return nil
+}
+
+// GetByID returns an account by ID
+func (r *AuthUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.AuthUser, error) {
+	query := `SELECT ` + authUserColumns + ` FROM auth_users WHERE id = $1`
+	u, err := scanAuthUser(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return u, nil
+}
+
+// GetByEmail returns an account by email address (case-insensitive)
+func (r *AuthUserRepository) GetByEmail(ctx context.Context, email string) (*domain.AuthUser, error) {
+	query := `SELECT ` + authUserColumns + ` FROM auth_users WHERE LOWER(email) = LOWER($1)`
+	u, err := scanAuthUser(r.db.QueryRowContext(ctx, query, email))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return u, nil
+}
+
+// Update persists an account's password, profile, confirmation state and
+// token revocation time
+func (r *AuthUserRepository) Update(ctx context.Context, u *domain.AuthUser) error {
+	query := `UPDATE auth_users SET password_hash = $2, first_name = $3, last_name = $4, phone = $5,
+		company_name = $6, user_type = $7, landlord_id = $8, confirmed_at = $9, confirmation_code_hash = $10,
+		confirmation_expires_at = $11, confirmation_attempts = $12, tokens_revoked_at = $13, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		u.ID, u.PasswordHash, u.FirstName, u.LastName, u.Phone, u.CompanyName, u.UserType, u.LandlordID,
+		u.ConfirmedAt, u.ConfirmationCodeHash, u.ConfirmationExpiresAt, u.ConfirmationAttempts, u.TokensRevokedAt,
+	).Scan(&u.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// RecordConfirmationAttempt counts a confirmation code guess and returns how
+// many have been made. Counting happens before the code is checked, so
+// concurrent guesses cannot get past the limit.
+func (r *AuthUserRepository) RecordConfirmationAttempt(ctx context.Context, id uuid.UUID) (int, error) {
+	var attempts int
+	err := r.db.QueryRowContext(ctx,
+		`UPDATE auth_users SET confirmation_attempts = confirmation_attempts + 1, updated_at = NOW()
+		WHERE id = $1 RETURNING confirmation_attempts`, id,
+	).Scan(&attempts)
+	if err != nil {
+		return 0, notFound(err)
+	}
+	return attempts, nil
+}
+
+// CreateRefreshToken stores a new refresh token
+func (r *AuthUserRepository) CreateRefreshToken(ctx context.Context, t *domain.RefreshToken) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO auth_refresh_tokens (id, user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING created_at`,
+		t.ID, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt,
+	).Scan(&t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshToken returns a refresh token by hash, whether or not it is still
+// usable
+func (r *AuthUserRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var t domain.RefreshToken
+	err := r.db.QueryRowContext(ctx,
+		`SELECT `+refreshTokenColumns+` FROM auth_refresh_tokens WHERE token_hash = $1`, tokenHash,
+	).Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &t, nil
+}
+
+// UseRefreshToken marks a refresh token used. It returns ErrNotFound if the
+// token was already used or revoked, so only one refresh can succeed.
+func (r *AuthUserRepository) UseRefreshToken(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE auth_refresh_tokens SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to use refresh token: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// RevokeRefreshFamily revokes every token descended from one sign-in
+func (r *AuthUserRepository) RevokeRefreshFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE auth_refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes all of an account's refresh tokens
+func (r *AuthUserRepository) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE auth_refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an account; its refresh tokens are deleted with it
+func (r *AuthUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM auth_users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func scanAuthUser(row rowScanner) (*domain.AuthUser, error) {
+	var u domain.AuthUser
+	err := row.Scan(
+		&u.ID, &u.Email, &u.PasswordHash, &u.FirstName, &u.LastName, &u.Phone, &u.CompanyName, &u.UserType,
+		&u.LandlordID, &u.ConfirmedAt, &u.ConfirmationCodeHash, &u.ConfirmationExpiresAt, &u.ConfirmationAttempts,
+		&u.TokensRevokedAt, &u.CreatedAt, &u.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const contractorInvitationColumns = `id, landlord_id, contractor_id, email, code_hash, expires_at,
+	accepted_at, revoked_at, created_at, updated_at`
+
+type ContractorInvitationRepository struct {
+	db DBTX
+}
+
+func NewContractorInvitationRepository(db DBTX) *ContractorInvitationRepository {
+	return &ContractorInvitationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *ContractorInvitationRepository) WithTx(tx *sql.Tx) *ContractorInvitationRepository {
+	return &ContractorInvitationRepository{db: tx}
+}
+
+// Create inserts a new invitation
+func (r *ContractorInvitationRepository) Create(ctx context.Context, inv *domain.ContractorInvitation) error {
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.New()
+	}
+
+	query := `INSERT INTO contractor_invitations (id, landlord_id, contractor_id, email, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		inv.ID, inv.LandlordID, inv.ContractorID, inv.Email, inv.CodeHash, inv.ExpiresAt,
+	).Scan(&inv.CreatedAt, &inv.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create contractor invitation: %w", duplicate(err))
+	}
+	return nil
+}
+
+// GetPendingByCodeHash returns an unexpired invitation that has been neither
+// accepted nor revoked. It is not landlord-scoped because it is used during
+// signup, before the user has any claims.
+func (r *ContractorInvitationRepository) GetPendingByCodeHash(ctx context.Context, codeHash string) (*domain.ContractorInvitation, error) {
+	query := `SELECT ` + contractorInvitationColumns + ` FROM contractor_invitations
+		WHERE code_hash = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()`
+	inv, err := scanContractorInvitation(r.db.QueryRowContext(ctx, query, codeHash))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return inv, nil
+}
+
+// MarkAccepted stamps a pending invitation as accepted
+func (r *ContractorInvitationRepository) MarkAccepted(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE contractor_invitations SET accepted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to accept contractor invitation: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// RevokePending revokes every outstanding invitation for a contractor
+func (r *ContractorInvitationRepository) RevokePending(ctx context.Context, landlordID, contractorID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE contractor_invitations SET revoked_at = NOW(), updated_at = NOW()
+		WHERE contractor_id = $1 AND landlord_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL`,
+		contractorID, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke contractor invitations: %w", err)
+	}
+	return nil
+}
+
+func scanContractorInvitation(row rowScanner) (*domain.ContractorInvitation, error) {
+	var inv domain.ContractorInvitation
+	err := row.Scan(
+		&inv.ID, &inv.LandlordID, &inv.ContractorID, &inv.Email, &inv.CodeHash, &inv.ExpiresAt,
+		&inv.AcceptedAt, &inv.RevokedAt, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
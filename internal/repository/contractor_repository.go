@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const contractorColumns = `id, landlord_id, user_id, company_name, contact_person, email, phone, specialization,
+	license_number, insurance_info, hourly_rate, is_active, created_at, updated_at`
+
+type ContractorRepository struct {
+	db DBTX
+}
+
+// ContractorFilter narrows contractor list queries. LandlordID is required.
+type ContractorFilter struct {
+	LandlordID     uuid.UUID
+	Specialization string
+	IsActive       *bool
+}
+
+func NewContractorRepository(db DBTX) *ContractorRepository {
+	return &ContractorRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *ContractorRepository) WithTx(tx *sql.Tx) *ContractorRepository {
+	return &ContractorRepository{db: tx}
+}
+
+// Create inserts a new contractor
+func (r *ContractorRepository) Create(ctx context.Context, c *domain.Contractor) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+
+	query := `INSERT INTO contractors (id, landlord_id, user_id, company_name, contact_person, email, phone,
+		specialization, license_number, insurance_info, hourly_rate, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		c.ID, c.LandlordID, c.UserID, c.CompanyName, c.ContactPerson, c.Email, c.Phone,
+		c.Specialization, c.LicenseNumber, c.InsuranceInfo, c.HourlyRate, c.IsActive,
+	).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create contractor: %w", duplicate(err))
+	}
+	return nil
+}
+
+// GetByID returns a contractor owned by the given landlord
+func (r *ContractorRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.Contractor, error) {
+	query := `SELECT ` + contractorColumns + ` FROM contractors
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	c, err := scanContractor(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return c, nil
+}
+
+// GetByUserID returns the contractor row linked to an identity-provider user
+func (r *ContractorRepository) GetByUserID(ctx context.Context, landlordID uuid.UUID, userID string) (*domain.Contractor, error) {
+	query := `SELECT ` + contractorColumns + ` FROM contractors
+		WHERE user_id = $1 AND user_id <> '' AND landlord_id = $2 AND deleted_at IS NULL`
+	c, err := scanContractor(r.db.QueryRowContext(ctx, query, userID, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return c, nil
+}
+
+// Update persists all mutable contractor fields
+func (r *ContractorRepository) Update(ctx context.Context, c *domain.Contractor) error {
+	query := `UPDATE contractors SET user_id = $3, company_name = $4, contact_person = $5, email = $6,
+		phone = $7, specialization = $8, license_number = $9, insurance_info = $10, hourly_rate = $11,
+		is_active = $12, updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		c.ID, c.LandlordID, c.UserID, c.CompanyName, c.ContactPerson, c.Email, c.Phone,
+		c.Specialization, c.LicenseNumber, c.InsuranceInfo, c.HourlyRate, c.IsActive,
+	).Scan(&c.UpdatedAt)
+	if err != nil {
+		return notFound(duplicate(err))
+	}
+	return nil
+}
+
+// Delete soft-deletes a contractor owned by the given landlord
+func (r *ContractorRepository) Delete(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE contractors SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete contractor: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns contractors matching the filter along with the total count
+func (r *ContractorRepository) List(ctx context.Context, filter ContractorFilter, opts ListOptions) ([]domain.Contractor, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.Specialization != "" {
+		w.add("LOWER(specialization) = LOWER($%d)", filter.Specialization)
+	}
+	if filter.IsActive != nil {
+		w.add("is_active = $%d", *filter.IsActive)
+	}
+
+	total, err := countRows(ctx, r.db, "contractors", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + contractorColumns + ` FROM contractors` + w.sql() + ` ORDER BY company_name, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list contractors: %w", err)
+	}
+	defer rows.Close()
+
+	contractors := []domain.Contractor{}
+	for rows.Next() {
+		c, err := scanContractor(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan contractor: %w", err)
+		}
+		contractors = append(contractors, *c)
+	}
+	return contractors, total, rows.Err()
+}
+
+func scanContractor(row rowScanner) (*domain.Contractor, error) {
+	var c domain.Contractor
+	err := row.Scan(
+		&c.ID, &c.LandlordID, &c.UserID, &c.CompanyName, &c.ContactPerson, &c.Email, &c.Phone, &c.Specialization,
+		&c.LicenseNumber, &c.InsuranceInfo, &c.HourlyRate, &c.IsActive, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
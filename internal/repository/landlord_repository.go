@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const landlordColumns = `id, email, first_name, last_name, phone, company_name,
+	business_address, tax_id, is_active, created_at, updated_at`
+
+type LandlordRepository struct {
+	db DBTX
+}
+
+// LandlordFilter narrows landlord list queries
+type LandlordFilter struct {
+	Email    string
+	IsActive *bool
+}
+
+func NewLandlordRepository(db DBTX) *LandlordRepository {
+	return &LandlordRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *LandlordRepository) WithTx(tx *sql.Tx) *LandlordRepository {
+	return &LandlordRepository{db: tx}
+}
+
+// Create inserts a new landlord
+func (r *LandlordRepository) Create(ctx context.Context, l *domain.Landlord) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+
+	query := `INSERT INTO landlords (id, email, first_name, last_name, phone, company_name,
+		business_address, tax_id, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		l.ID, l.Email, l.FirstName, l.LastName, l.Phone, l.CompanyName,
+		l.BusinessAddress, l.TaxID, l.IsActive,
+	).Scan(&l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create landlord: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a landlord by ID
+func (r *LandlordRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Landlord, error) {
+	query := `SELECT ` + landlordColumns + ` FROM landlords WHERE id = $1 AND deleted_at IS NULL`
+	l, err := scanLandlord(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return l, nil
+}
+
+// GetByEmail returns a landlord by email address (case-insensitive)
+func (r *LandlordRepository) GetByEmail(ctx context.Context, email string) (*domain.Landlord, error) {
+	query := `SELECT ` + landlordColumns + ` FROM landlords WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL`
+	l, err := scanLandlord(r.db.QueryRowContext(ctx, query, email))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return l, nil
+}
+
+// Update persists all mutable landlord fields
+func (r *LandlordRepository) Update(ctx context.Context, l *domain.Landlord) error {
+	query := `UPDATE landlords SET email = $2, first_name = $3, last_name = $4, phone = $5,
+		company_name = $6, business_address = $7, tax_id = $8, is_active = $9, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		l.ID, l.Email, l.FirstName, l.LastName, l.Phone, l.CompanyName,
+		l.BusinessAddress, l.TaxID, l.IsActive,
+	).Scan(&l.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a landlord
+func (r *LandlordRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE landlords SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete landlord: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns landlords matching the filter along with the total count
+func (r *LandlordRepository) List(ctx context.Context, filter LandlordFilter, opts ListOptions) ([]domain.Landlord, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	if filter.Email != "" {
+		w.add("LOWER(email) = LOWER($%d)", filter.Email)
+	}
+	if filter.IsActive != nil {
+		w.add("is_active = $%d", *filter.IsActive)
+	}
+
+	total, err := countRows(ctx, r.db, "landlords", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + landlordColumns + ` FROM landlords` + w.sql() + ` ORDER BY created_at DESC` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list landlords: %w", err)
+	}
+	defer rows.Close()
+
+	landlords := []domain.Landlord{}
+	for rows.Next() {
+		l, err := scanLandlord(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan landlord: %w", err)
+		}
+		landlords = append(landlords, *l)
+	}
+	return landlords, total, rows.Err()
+}
+
+func scanLandlord(row rowScanner) (*domain.Landlord, error) {
+	var l domain.Landlord
+	err := row.Scan(
+		&l.ID, &l.Email, &l.FirstName, &l.LastName, &l.Phone, &l.CompanyName,
+		&l.BusinessAddress, &l.TaxID, &l.IsActive, &l.CreatedAt, &l.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const lateFeePolicyColumns = `landlord_id, enabled, grace_period_days, flat_amount, percentage,
+	daily_amount, max_amount, created_at, updated_at`
+
+type LateFeePolicyRepository struct {
+	db DBTX
+}
+
+func NewLateFeePolicyRepository(db DBTX) *LateFeePolicyRepository {
+	return &LateFeePolicyRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *LateFeePolicyRepository) WithTx(tx *sql.Tx) *LateFeePolicyRepository {
+	return &LateFeePolicyRepository{db: tx}
+}
+
+// Get returns a landlord's late fee policy
+func (r *LateFeePolicyRepository) Get(ctx context.Context, landlordID uuid.UUID) (*domain.LateFeePolicy, error) {
+	query := `SELECT ` + lateFeePolicyColumns + ` FROM late_fee_policies WHERE landlord_id = $1`
+	p, err := scanLateFeePolicy(r.db.QueryRowContext(ctx, query, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+// Upsert creates or replaces a landlord's late fee policy
+func (r *LateFeePolicyRepository) Upsert(ctx context.Context, p *domain.LateFeePolicy) error {
+	query := `INSERT INTO late_fee_policies (landlord_id, enabled, grace_period_days, flat_amount,
+		percentage, daily_amount, max_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (landlord_id) DO UPDATE SET enabled = EXCLUDED.enabled,
+			grace_period_days = EXCLUDED.grace_period_days, flat_amount = EXCLUDED.flat_amount,
+			percentage = EXCLUDED.percentage, daily_amount = EXCLUDED.daily_amount,
+			max_amount = EXCLUDED.max_amount, updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		p.LandlordID, p.Enabled, p.GracePeriodDays, p.FlatAmount,
+		p.Percentage, p.DailyAmount, p.MaxAmount,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save late fee policy: %w", err)
+	}
+	return nil
+}
+
+func scanLateFeePolicy(row rowScanner) (*domain.LateFeePolicy, error) {
+	var p domain.LateFeePolicy
+	err := row.Scan(
+		&p.LandlordID, &p.Enabled, &p.GracePeriodDays, &p.FlatAmount, &p.Percentage,
+		&p.DailyAmount, &p.MaxAmount, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const maintenancePhotoColumns = `id, maintenance_request_id, photo_url, photo_key, description, is_before,
+	created_at, updated_at`
+
+// MaintenancePhotoRepository has no landlord_id column of its own; callers are
+// expected to authorize access to the parent maintenance request first.
+type MaintenancePhotoRepository struct {
+	db DBTX
+}
+
+// MaintenancePhotoFilter narrows photo list queries. MaintenanceRequestID is required.
+type MaintenancePhotoFilter struct {
+	MaintenanceRequestID uuid.UUID
+	IsBefore             *bool
+}
+
+func NewMaintenancePhotoRepository(db DBTX) *MaintenancePhotoRepository {
+	return &MaintenancePhotoRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *MaintenancePhotoRepository) WithTx(tx *sql.Tx) *MaintenancePhotoRepository {
+	return &MaintenancePhotoRepository{db: tx}
+}
+
+// Create inserts a new maintenance photo
+func (r *MaintenancePhotoRepository) Create(ctx context.Context, p *domain.MaintenancePhoto) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+
+	query := `INSERT INTO maintenance_photos (id, maintenance_request_id, photo_url, photo_key, description, is_before)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		p.ID, p.MaintenanceRequestID, p.PhotoURL, p.PhotoKey, p.Description, p.IsBefore,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create maintenance photo: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a photo belonging to the given maintenance request
+func (r *MaintenancePhotoRepository) GetByID(ctx context.Context, maintenanceRequestID, id uuid.UUID) (*domain.MaintenancePhoto, error) {
+	query := `SELECT ` + maintenancePhotoColumns + ` FROM maintenance_photos
+		WHERE id = $1 AND maintenance_request_id = $2 AND deleted_at IS NULL`
+	p, err := scanMaintenancePhoto(r.db.QueryRowContext(ctx, query, id, maintenanceRequestID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+// Update persists the mutable photo fields
+func (r *MaintenancePhotoRepository) Update(ctx context.Context, p *domain.MaintenancePhoto) error {
+	query := `UPDATE maintenance_photos SET photo_url = $3, description = $4, is_before = $5, updated_at = NOW()
+		WHERE id = $1 AND maintenance_request_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		p.ID, p.MaintenanceRequestID, p.PhotoURL, p.Description, p.IsBefore,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a photo belonging to the given maintenance request
+func (r *MaintenancePhotoRepository) Delete(ctx context.Context, maintenanceRequestID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE maintenance_photos SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND maintenance_request_id = $2 AND deleted_at IS NULL`, id, maintenanceRequestID)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance photo: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns photos matching the filter along with the total count
+func (r *MaintenancePhotoRepository) List(ctx context.Context, filter MaintenancePhotoFilter, opts ListOptions) ([]domain.MaintenancePhoto, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("maintenance_request_id = $%d", filter.MaintenanceRequestID)
+	if filter.IsBefore != nil {
+		w.add("is_before = $%d", *filter.IsBefore)
+	}
+
+	total, err := countRows(ctx, r.db, "maintenance_photos", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + maintenancePhotoColumns + ` FROM maintenance_photos` + w.sql() + ` ORDER BY created_at, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list maintenance photos: %w", err)
+	}
+	defer rows.Close()
+
+	photos := []domain.MaintenancePhoto{}
+	for rows.Next() {
+		p, err := scanMaintenancePhoto(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan maintenance photo: %w", err)
+		}
+		photos = append(photos, *p)
+	}
+	return photos, total, rows.Err()
+}
+
+// ReferencedKeys returns which of the given storage keys belong to a photo
+// that has not been deleted
+func (r *MaintenancePhotoRepository) ReferencedKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT photo_key FROM maintenance_photos WHERE photo_key = ANY($1) AND deleted_at IS NULL`, pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up maintenance photo keys: %w", err)
+	}
+	defer rows.Close()
+
+	referenced := make(map[string]bool, len(keys))
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance photo key: %w", err)
+		}
+		referenced[key] = true
+	}
+	return referenced, rows.Err()
+}
+
+func scanMaintenancePhoto(row rowScanner) (*domain.MaintenancePhoto, error) {
+	var p domain.MaintenancePhoto
+	err := row.Scan(
+		&p.ID, &p.MaintenanceRequestID, &p.PhotoURL, &p.PhotoKey, &p.Description, &p.IsBefore,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const maintenanceRequestColumns = `id, landlord_id, property_id, tenant_id, title, description, priority,
+	status, category, requested_date, completed_date, estimated_cost, actual_cost, contractor_id,
+	contractor_status, actual_hours, notes, created_at, updated_at`
+
+type MaintenanceRequestRepository struct {
+	db DBTX
+}
+
+// MaintenanceRequestFilter narrows maintenance request list queries. LandlordID is required.
+type MaintenanceRequestFilter struct {
+	LandlordID   uuid.UUID
+	PropertyID   *uuid.UUID
+	PropertyIDs  []uuid.UUID // when non-nil, limits results to these properties
+	TenantID     *uuid.UUID
+	ContractorID *uuid.UUID
+	Status       string
+	Statuses     []string // matches any of the given statuses
+	Priority     string
+	Category     string
+}
+
+func NewMaintenanceRequestRepository(db DBTX) *MaintenanceRequestRepository {
+	return &MaintenanceRequestRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *MaintenanceRequestRepository) WithTx(tx *sql.Tx) *MaintenanceRequestRepository {
+	return &MaintenanceRequestRepository{db: tx}
+}
+
+// Create inserts a new maintenance request
+func (r *MaintenanceRequestRepository) Create(ctx context.Context, m *domain.MaintenanceRequest) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+
+	query := `INSERT INTO maintenance_requests (id, landlord_id, property_id, tenant_id, title, description,
+		priority, status, category, requested_date, completed_date, estimated_cost, actual_cost,
+		contractor_id, contractor_status, actual_hours, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		m.ID, m.LandlordID, m.PropertyID, m.TenantID, m.Title, m.Description,
+		m.Priority, m.Status, m.Category, m.RequestedDate, m.CompletedDate, m.EstimatedCost, m.ActualCost,
+		m.ContractorID, m.ContractorStatus, m.ActualHours, m.Notes,
+	).Scan(&m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create maintenance request: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a maintenance request owned by the given landlord
+func (r *MaintenanceRequestRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.MaintenanceRequest, error) {
+	query := `SELECT ` + maintenanceRequestColumns + ` FROM maintenance_requests
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	m, err := scanMaintenanceRequest(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return m, nil
+}
+
+// GetByIDForUpdate returns a maintenance request and locks its row until the transaction ends
+func (r *MaintenanceRequestRepository) GetByIDForUpdate(ctx context.Context, landlordID, id uuid.UUID) (*domain.MaintenanceRequest, error) {
+	query := `SELECT ` + maintenanceRequestColumns + ` FROM maintenance_requests
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		FOR UPDATE`
+	m, err := scanMaintenanceRequest(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return m, nil
+}
+
+// Update persists all mutable maintenance request fields
+func (r *MaintenanceRequestRepository) Update(ctx context.Context, m *domain.MaintenanceRequest) error {
+	query := `UPDATE maintenance_requests SET title = $3, description = $4, priority = $5, status = $6,
+		category = $7, completed_date = $8, estimated_cost = $9, actual_cost = $10, contractor_id = $11,
+		contractor_status = $12, actual_hours = $13, notes = $14, updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		m.ID, m.LandlordID, m.Title, m.Description, m.Priority, m.Status,
+		m.Category, m.CompletedDate, m.EstimatedCost, m.ActualCost, m.ContractorID,
+		m.ContractorStatus, m.ActualHours, m.Notes,
+	).Scan(&m.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a maintenance request owned by the given landlord
+func (r *MaintenanceRequestRepository) Delete(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE maintenance_requests SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance request: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns maintenance requests matching the filter along with the total count
+func (r *MaintenanceRequestRepository) List(ctx context.Context, filter MaintenanceRequestFilter, opts ListOptions) ([]domain.MaintenanceRequest, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.PropertyID != nil {
+		w.add("property_id = $%d", *filter.PropertyID)
+	}
+	if filter.PropertyIDs != nil {
+		w.add("property_id = ANY($%d)", pq.Array(filter.PropertyIDs))
+	}
+	if filter.TenantID != nil {
+		w.add("tenant_id = $%d", *filter.TenantID)
+	}
+	if filter.ContractorID != nil {
+		w.add("contractor_id = $%d", *filter.ContractorID)
+	}
+	if filter.Status != "" {
+		w.add("status = $%d", filter.Status)
+	}
+	if len(filter.Statuses) > 0 {
+		w.add("status = ANY($%d)", pq.Array(filter.Statuses))
+	}
+	if filter.Priority != "" {
+		w.add("priority = $%d", filter.Priority)
+	}
+	if filter.Category != "" {
+		w.add("LOWER(category) = LOWER($%d)", filter.Category)
+	}
+
+	total, err := countRows(ctx, r.db, "maintenance_requests", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + maintenanceRequestColumns + ` FROM maintenance_requests` + w.sql() +
+		` ORDER BY requested_date DESC, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list maintenance requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []domain.MaintenanceRequest{}
+	for rows.Next() {
+		m, err := scanMaintenanceRequest(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan maintenance request: %w", err)
+		}
+		requests = append(requests, *m)
+	}
+	return requests, total, rows.Err()
+}
+
+func scanMaintenanceRequest(row rowScanner) (*domain.MaintenanceRequest, error) {
+	var m domain.MaintenanceRequest
+	err := row.Scan(
+		&m.ID, &m.LandlordID, &m.PropertyID, &m.TenantID, &m.Title, &m.Description, &m.Priority,
+		&m.Status, &m.Category, &m.RequestedDate, &m.CompletedDate, &m.EstimatedCost, &m.ActualCost,
+		&m.ContractorID, &m.ContractorStatus, &m.ActualHours, &m.Notes, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const maintenanceStatusChangeColumns = `id, maintenance_request_id, landlord_id, from_status, to_status,
+	changed_by, changed_by_type, note, changes, created_at`
+
+// MaintenanceStatusHistoryRepository stores the append-only audit trail of
+// maintenance request changes
+type MaintenanceStatusHistoryRepository struct {
+	db DBTX
+}
+
+func NewMaintenanceStatusHistoryRepository(db DBTX) *MaintenanceStatusHistoryRepository {
+	return &MaintenanceStatusHistoryRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *MaintenanceStatusHistoryRepository) WithTx(tx *sql.Tx) *MaintenanceStatusHistoryRepository {
+	return &MaintenanceStatusHistoryRepository{db: tx}
+}
+
+// Create appends a history entry
+func (r *MaintenanceStatusHistoryRepository) Create(ctx context.Context, h *domain.MaintenanceStatusChange) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+
+	changes, err := json.Marshal(h.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode maintenance changes: %w", err)
+	}
+	if h.Changes == nil {
+		changes = []byte("{}")
+	}
+
+	query := `INSERT INTO maintenance_status_history (id, maintenance_request_id, landlord_id, from_status,
+		to_status, changed_by, changed_by_type, note, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		h.ID, h.MaintenanceRequestID, h.LandlordID, h.FromStatus,
+		h.ToStatus, h.ChangedBy, h.ChangedByType, h.Note, changes,
+	).Scan(&h.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record maintenance history: %w", err)
+	}
+	return nil
+}
+
+// ListByRequest returns the history of a maintenance request, oldest first
+func (r *MaintenanceStatusHistoryRepository) ListByRequest(ctx context.Context, landlordID, requestID uuid.UUID) ([]domain.MaintenanceStatusChange, error) {
+	query := `SELECT ` + maintenanceStatusChangeColumns + ` FROM maintenance_status_history
+		WHERE maintenance_request_id = $1 AND landlord_id = $2
+		ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, requestID, landlordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []domain.MaintenanceStatusChange{}
+	for rows.Next() {
+		h, err := scanMaintenanceStatusChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance history: %w", err)
+		}
+		history = append(history, *h)
+	}
+	return history, rows.Err()
+}
+
+func scanMaintenanceStatusChange(row rowScanner) (*domain.MaintenanceStatusChange, error) {
+	var h domain.MaintenanceStatusChange
+	var changes []byte
+	err := row.Scan(
+		&h.ID, &h.MaintenanceRequestID, &h.LandlordID, &h.FromStatus, &h.ToStatus,
+		&h.ChangedBy, &h.ChangedByType, &h.Note, &changes, &h.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		if err := json.Unmarshal(changes, &h.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode maintenance changes: %w", err)
+		}
+	}
+	return &h, nil
+}
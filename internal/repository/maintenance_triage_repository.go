@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const maintenanceTriageColumns = `id, maintenance_request_id, landlord_id, status, category, priority,
+	is_emergency, emergency_reason, estimated_cost_min, estimated_cost_max, contractor_id, reasoning,
+	photos_reviewed, message_id, error, reviewed_by, reviewed_at, created_at, updated_at`
+
+type MaintenanceTriageRepository struct {
+	db DBTX
+}
+
+func NewMaintenanceTriageRepository(db DBTX) *MaintenanceTriageRepository {
+	return &MaintenanceTriageRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *MaintenanceTriageRepository) WithTx(tx *sql.Tx) *MaintenanceTriageRepository {
+	return &MaintenanceTriageRepository{db: tx}
+}
+
+// Create inserts a new triage suggestion
+func (r *MaintenanceTriageRepository) Create(ctx context.Context, t *domain.MaintenanceTriage) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+
+	query := `INSERT INTO maintenance_triage_suggestions (id, maintenance_request_id, landlord_id, status,
+		category, priority, is_emergency, emergency_reason, estimated_cost_min, estimated_cost_max,
+		contractor_id, reasoning, photos_reviewed, message_id, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.ID, t.MaintenanceRequestID, t.LandlordID, t.Status, t.Category, t.Priority,
+		t.IsEmergency, t.EmergencyReason, t.EstimatedCostMin, t.EstimatedCostMax,
+		t.ContractorID, t.Reasoning, t.PhotosReviewed, t.MessageID, t.Error,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create maintenance triage: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a triage suggestion for a landlord's maintenance request
+func (r *MaintenanceTriageRepository) GetByID(ctx context.Context, landlordID, requestID, id uuid.UUID) (*domain.MaintenanceTriage, error) {
+	query := `SELECT ` + maintenanceTriageColumns + ` FROM maintenance_triage_suggestions
+		WHERE id = $1 AND maintenance_request_id = $2 AND landlord_id = $3`
+	t, err := scanMaintenanceTriage(r.db.QueryRowContext(ctx, query, id, requestID, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return t, nil
+}
+
+// ListByRequest returns a maintenance request's triage suggestions, newest first
+func (r *MaintenanceTriageRepository) ListByRequest(ctx context.Context, landlordID, requestID uuid.UUID) ([]domain.MaintenanceTriage, error) {
+	query := `SELECT ` + maintenanceTriageColumns + ` FROM maintenance_triage_suggestions
+		WHERE maintenance_request_id = $1 AND landlord_id = $2
+		ORDER BY created_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, query, requestID, landlordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance triage: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []domain.MaintenanceTriage{}
+	for rows.Next() {
+		t, err := scanMaintenanceTriage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance triage: %w", err)
+		}
+		suggestions = append(suggestions, *t)
+	}
+	return suggestions, rows.Err()
+}
+
+// HasSuggestion reports whether a maintenance request has a suggestion that
+// is pending review or was approved or rejected. Failed attempts do not count.
+func (r *MaintenanceTriageRepository) HasSuggestion(ctx context.Context, landlordID, requestID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM maintenance_triage_suggestions
+			WHERE maintenance_request_id = $1 AND landlord_id = $2 AND status IN ($3, $4, $5))`,
+		requestID, landlordID, domain.TriageStatusPending, domain.TriageStatusApproved, domain.TriageStatusRejected,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check maintenance triage suggestions: %w", err)
+	}
+	return exists, nil
+}
+
+// SupersedePending marks a maintenance request's pending suggestions as
+// superseded, so only the newest suggestion can be reviewed
+func (r *MaintenanceTriageRepository) SupersedePending(ctx context.Context, landlordID, requestID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE maintenance_triage_suggestions SET status = $3, updated_at = NOW()
+		WHERE maintenance_request_id = $1 AND landlord_id = $2 AND status = $4`,
+		requestID, landlordID, domain.TriageStatusSuperseded, domain.TriageStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to supersede maintenance triage: %w", err)
+	}
+	return nil
+}
+
+// Transition moves a suggestion from one status to another, recording who
+// reviewed it. It returns ErrNotFound if the suggestion was not in the from
+// status, so only one review can succeed.
+func (r *MaintenanceTriageRepository) Transition(ctx context.Context, t *domain.MaintenanceTriage, from string) error {
+	query := `UPDATE maintenance_triage_suggestions SET status = $4, reviewed_by = $5, reviewed_at = $6,
+		updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND status = $3
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.ID, t.LandlordID, from, t.Status, t.ReviewedBy, t.ReviewedAt,
+	).Scan(&t.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+func scanMaintenanceTriage(row rowScanner) (*domain.MaintenanceTriage, error) {
+	var t domain.MaintenanceTriage
+	err := row.Scan(
+		&t.ID, &t.MaintenanceRequestID, &t.LandlordID, &t.Status, &t.Category, &t.Priority,
+		&t.IsEmergency, &t.EmergencyReason, &t.EstimatedCostMin, &t.EstimatedCostMax, &t.ContractorID,
+		&t.Reasoning, &t.PhotosReviewed, &t.MessageID, &t.Error, &t.ReviewedBy, &t.ReviewedAt,
+		&t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const notificationColumns = `id, landlord_id, recipient_id, recipient_type, type, title, message, is_read,
+	read_at, related_entity_id, related_entity_type, created_at, updated_at`
+
+type NotificationRepository struct {
+	db DBTX
+}
+
+// NotificationFilter narrows notification list queries. LandlordID is required.
+type NotificationFilter struct {
+	LandlordID    uuid.UUID
+	RecipientID   *uuid.UUID
+	RecipientType string
+	Type          string
+	IsRead        *bool
+}
+
+func NewNotificationRepository(db DBTX) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *NotificationRepository) WithTx(tx *sql.Tx) *NotificationRepository {
+	return &NotificationRepository{db: tx}
+}
+
+// Create inserts a new notification
+func (r *NotificationRepository) Create(ctx context.Context, n *domain.Notification) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+
+	query := `INSERT INTO notifications (id, landlord_id, recipient_id, recipient_type, type, title, message,
+		is_read, read_at, related_entity_id, related_entity_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		n.ID, n.LandlordID, n.RecipientID, n.RecipientType, n.Type, n.Title, n.Message,
+		n.IsRead, n.ReadAt, n.RelatedEntityID, n.RelatedEntityType,
+	).Scan(&n.CreatedAt, &n.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a notification owned by the given landlord
+func (r *NotificationRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	n, err := scanNotification(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return n, nil
+}
+
+// Update persists the mutable notification fields
+func (r *NotificationRepository) Update(ctx context.Context, n *domain.Notification) error {
+	query := `UPDATE notifications SET title = $3, message = $4, is_read = $5, read_at = $6, updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		n.ID, n.LandlordID, n.Title, n.Message, n.IsRead, n.ReadAt,
+	).Scan(&n.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// MarkRead marks a notification addressed to the recipient as read
+func (r *NotificationRepository) MarkRead(ctx context.Context, recipientID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET is_read = TRUE, read_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND recipient_id = $2 AND deleted_at IS NULL`, id, recipientID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// Delete soft-deletes a notification owned by the given landlord
+func (r *NotificationRepository) Delete(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns notifications matching the filter, newest first, along with the total count
+func (r *NotificationRepository) List(ctx context.Context, filter NotificationFilter, opts ListOptions) ([]domain.Notification, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.RecipientID != nil {
+		w.add("recipient_id = $%d", *filter.RecipientID)
+	}
+	if filter.RecipientType != "" {
+		w.add("recipient_type = $%d", filter.RecipientType)
+	}
+	if filter.Type != "" {
+		w.add("type = $%d", filter.Type)
+	}
+	if filter.IsRead != nil {
+		w.add("is_read = $%d", *filter.IsRead)
+	}
+
+	total, err := countRows(ctx, r.db, "notifications", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + notificationColumns + ` FROM notifications` + w.sql() + ` ORDER BY created_at DESC, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []domain.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, *n)
+	}
+	return notifications, total, rows.Err()
+}
+
+func scanNotification(row rowScanner) (*domain.Notification, error) {
+	var n domain.Notification
+	err := row.Scan(
+		&n.ID, &n.LandlordID, &n.RecipientID, &n.RecipientType, &n.Type, &n.Title, &n.Message, &n.IsRead,
+		&n.ReadAt, &n.RelatedEntityID, &n.RelatedEntityType, &n.CreatedAt, &n.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const paymentReceiptColumns = `id, landlord_id, tenant_id, payment_id, amount, payment_method,
+	reference_number, received_at, notes, created_at, updated_at`
+
+type PaymentReceiptRepository struct {
+	db DBTX
+}
+
+func NewPaymentReceiptRepository(db DBTX) *PaymentReceiptRepository {
+	return &PaymentReceiptRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *PaymentReceiptRepository) WithTx(tx *sql.Tx) *PaymentReceiptRepository {
+	return &PaymentReceiptRepository{db: tx}
+}
+
+// Create inserts a new receipt
+func (r *PaymentReceiptRepository) Create(ctx context.Context, rc *domain.PaymentReceipt) error {
+	if rc.ID == uuid.Nil {
+		rc.ID = uuid.New()
+	}
+
+	query := `INSERT INTO payment_receipts (id, landlord_id, tenant_id, payment_id, amount, payment_method,
+		reference_number, received_at, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rc.ID, rc.LandlordID, rc.TenantID, rc.PaymentID, rc.Amount, rc.PaymentMethod,
+		rc.ReferenceNumber, rc.ReceivedAt, rc.Notes,
+	).Scan(&rc.CreatedAt, &rc.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment receipt: %w", err)
+	}
+	return nil
+}
+
+// ListByPayment returns the receipts recorded against a charge, oldest first
+func (r *PaymentReceiptRepository) ListByPayment(ctx context.Context, landlordID, paymentID uuid.UUID) ([]domain.PaymentReceipt, error) {
+	query := `SELECT ` + paymentReceiptColumns + ` FROM payment_receipts
+		WHERE landlord_id = $1 AND payment_id = $2
+		ORDER BY received_at, id`
+	return r.list(ctx, query, landlordID, paymentID)
+}
+
+// ListByTenant returns every receipt for a tenant, oldest first
+func (r *PaymentReceiptRepository) ListByTenant(ctx context.Context, landlordID, tenantID uuid.UUID) ([]domain.PaymentReceipt, error) {
+	query := `SELECT ` + paymentReceiptColumns + ` FROM payment_receipts
+		WHERE landlord_id = $1 AND tenant_id = $2
+		ORDER BY received_at, id`
+	return r.list(ctx, query, landlordID, tenantID)
+}
+
+func (r *PaymentReceiptRepository) list(ctx context.Context, query string, args ...interface{}) ([]domain.PaymentReceipt, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts := []domain.PaymentReceipt{}
+	for rows.Next() {
+		rc, err := scanPaymentReceipt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment receipt: %w", err)
+		}
+		receipts = append(receipts, *rc)
+	}
+	return receipts, rows.Err()
+}
+
+func scanPaymentReceipt(row rowScanner) (*domain.PaymentReceipt, error) {
+	var rc domain.PaymentReceipt
+	err := row.Scan(
+		&rc.ID, &rc.LandlordID, &rc.TenantID, &rc.PaymentID, &rc.Amount, &rc.PaymentMethod,
+		&rc.ReferenceNumber, &rc.ReceivedAt, &rc.Notes, &rc.CreatedAt, &rc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const paymentColumns = `id, landlord_id, property_id, tenant_id, amount, payment_type, payment_method,
+	due_date, paid_date, status, reference_number, notes, amount_paid, period_start, period_end,
+	parent_payment_id, created_at, updated_at`
+
+type PaymentRepository struct {
+	db DBTX
+}
+
+// PaymentFilter narrows payment list queries. LandlordID is required.
+type PaymentFilter struct {
+	LandlordID  uuid.UUID
+	PropertyID  *uuid.UUID
+	PropertyIDs []uuid.UUID // when non-nil, limits results to these properties
+	TenantID    *uuid.UUID
+	Status      string
+	PaymentType string
+	DueFrom     *time.Time
+	DueTo       *time.Time
+}
+
+func NewPaymentRepository(db DBTX) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *PaymentRepository) WithTx(tx *sql.Tx) *PaymentRepository {
+	return &PaymentRepository{db: tx}
+}
+
+// Create inserts a new payment
+func (r *PaymentRepository) Create(ctx context.Context, p *domain.Payment) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+
+	query := `INSERT INTO payments (id, landlord_id, property_id, tenant_id, amount, payment_type,
+		payment_method, due_date, paid_date, status, reference_number, notes, amount_paid,
+		period_start, period_end, parent_payment_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		p.ID, p.LandlordID, p.PropertyID, p.TenantID, p.Amount, p.PaymentType,
+		p.PaymentMethod, p.DueDate, p.PaidDate, p.Status, p.ReferenceNumber, p.Notes, p.AmountPaid,
+		p.PeriodStart, p.PeriodEnd, p.ParentPaymentID,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment: %w", err)
+	}
+	return nil
+}
+
+// CreateRentCharge inserts a rent charge unless one already exists for the
+// tenant and period. It reports whether a row was inserted.
+func (r *PaymentRepository) CreateRentCharge(ctx context.Context, p *domain.Payment) (bool, error) {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+
+	query := `INSERT INTO payments (id, landlord_id, property_id, tenant_id, amount, payment_type,
+		due_date, status, notes, period_start, period_end)
+		VALUES ($1, $2, $3, $4, $5, 'rent', $6, $7, $8, $9, $10)
+		ON CONFLICT (tenant_id, period_start) WHERE payment_type = 'rent' AND deleted_at IS NULL DO NOTHING
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		p.ID, p.LandlordID, p.PropertyID, p.TenantID, p.Amount,
+		p.DueDate, p.Status, p.Notes, p.PeriodStart, p.PeriodEnd,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create rent charge: %w", err)
+	}
+	p.PaymentType = "rent"
+	return true, nil
+}
+
+// GetByID returns a payment owned by the given landlord
+func (r *PaymentRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	p, err := scanPayment(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+// GetByIDForUpdate returns a payment and locks its row until the transaction ends
+func (r *PaymentRepository) GetByIDForUpdate(ctx context.Context, landlordID, id uuid.UUID) (*domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		FOR UPDATE`
+	p, err := scanPayment(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+// ListByTenant returns every non-cancelled charge for a tenant ordered by due date
+func (r *PaymentRepository) ListByTenant(ctx context.Context, landlordID, tenantID uuid.UUID) ([]domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments
+		WHERE landlord_id = $1 AND tenant_id = $2 AND status <> 'cancelled' AND deleted_at IS NULL
+		ORDER BY due_date, created_at, id`
+	return r.list(ctx, query, landlordID, tenantID)
+}
+
+// MarkOverdue flips a landlord's pending charges due before the given date to
+// overdue and returns the charges that changed
+func (r *PaymentRepository) MarkOverdue(ctx context.Context, landlordID uuid.UUID, dueBefore time.Time) ([]domain.Payment, error) {
+	query := `UPDATE payments SET status = 'overdue', updated_at = NOW()
+		WHERE landlord_id = $1 AND status = 'pending' AND due_date < $2 AND deleted_at IS NULL
+		RETURNING ` + paymentColumns
+	return r.list(ctx, query, landlordID, dueBefore)
+}
+
+// ListOverdueCharges returns a landlord's overdue charges other than late fees
+func (r *PaymentRepository) ListOverdueCharges(ctx context.Context, landlordID uuid.UUID) ([]domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments
+		WHERE landlord_id = $1 AND status = 'overdue' AND payment_type <> 'late_fee' AND deleted_at IS NULL
+		ORDER BY due_date, id`
+	return r.list(ctx, query, landlordID)
+}
+
+// GetLateFeeForUpdate returns the late fee assessed on a charge and locks its
+// row until the transaction ends
+func (r *PaymentRepository) GetLateFeeForUpdate(ctx context.Context, landlordID, parentID uuid.UUID) (*domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments
+		WHERE landlord_id = $1 AND parent_payment_id = $2 AND payment_type = 'late_fee' AND deleted_at IS NULL
+		FOR UPDATE`
+	p, err := scanPayment(r.db.QueryRowContext(ctx, query, landlordID, parentID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+// Update persists all mutable payment fields
+func (r *PaymentRepository) Update(ctx context.Context, p *domain.Payment) error {
+	query := `UPDATE payments SET amount = $3, payment_type = $4, payment_method = $5, due_date = $6,
+		paid_date = $7, status = $8, reference_number = $9, notes = $10, amount_paid = $11,
+		updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		p.ID, p.LandlordID, p.Amount, p.PaymentType, p.PaymentMethod, p.DueDate,
+		p.PaidDate, p.Status, p.ReferenceNumber, p.Notes, p.AmountPaid,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a payment owned by the given landlord
+func (r *PaymentRepository) Delete(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE payments SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete payment: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns payments matching the filter along with the total count
+func (r *PaymentRepository) List(ctx context.Context, filter PaymentFilter, opts ListOptions) ([]domain.Payment, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.PropertyID != nil {
+		w.add("property_id = $%d", *filter.PropertyID)
+	}
+	if filter.PropertyIDs != nil {
+		w.add("property_id = ANY($%d)", pq.Array(filter.PropertyIDs))
+	}
+	if filter.TenantID != nil {
+		w.add("tenant_id = $%d", *filter.TenantID)
+	}
+	if filter.Status != "" {
+		w.add("status = $%d", filter.Status)
+	}
+	if filter.PaymentType != "" {
+		w.add("payment_type = $%d", filter.PaymentType)
+	}
+	if filter.DueFrom != nil {
+		w.add("due_date >= $%d", *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		w.add("due_date <= $%d", *filter.DueTo)
+	}
+
+	total, err := countRows(ctx, r.db, "payments", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + paymentColumns + ` FROM payments` + w.sql() + ` ORDER BY due_date DESC, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []domain.Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, *p)
+	}
+	return payments, total, rows.Err()
+}
+
+func (r *PaymentRepository) list(ctx context.Context, query string, args ...interface{}) ([]domain.Payment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []domain.Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, *p)
+	}
+	return payments, rows.Err()
+}
+
+func scanPayment(row rowScanner) (*domain.Payment, error) {
+	var p domain.Payment
+	err := row.Scan(
+		&p.ID, &p.LandlordID, &p.PropertyID, &p.TenantID, &p.Amount, &p.PaymentType, &p.PaymentMethod,
+		&p.DueDate, &p.PaidDate, &p.Status, &p.ReferenceNumber, &p.Notes, &p.AmountPaid,
+		&p.PeriodStart, &p.PeriodEnd, &p.ParentPaymentID, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const propertyColumns = `id, landlord_id, name, address, city, state, zip_code, property_type,
+	bedrooms, bathrooms, square_footage, year_built, monthly_rent, security_deposit,
+	is_available, current_tenant_id, created_at, updated_at`
+
+// propertySortColumns whitelists the columns properties can be sorted by
+var propertySortColumns = map[string]sortColumn{
+	"created_at":   {column: "created_at", cast: "timestamptz"},
+	"name":         {column: "name", cast: "text"},
+	"city":         {column: "city", cast: "text"},
+	"monthly_rent": {column: "monthly_rent", cast: "numeric"},
+}
+
+type PropertyRepository struct {
+	db DBTX
+}
+
+// PropertyFilter narrows property list queries. LandlordID is required.
+type PropertyFilter struct {
+	LandlordID      uuid.UUID
+	IDs             []uuid.UUID // when non-nil, limits results to these properties
+	City            string
+	State           string
+	PropertyType    string
+	IsAvailable     *bool
+	CurrentTenantID *uuid.UUID
+}
+
+func NewPropertyRepository(db DBTX) *PropertyRepository {
+	return &PropertyRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *PropertyRepository) WithTx(tx *sql.Tx) *PropertyRepository {
+	return &PropertyRepository{db: tx}
+}
+
+// Create inserts a new property
+func (r *PropertyRepository) Create(ctx context.Context, p *domain.Property) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+
+	query := `INSERT INTO properties (id, landlord_id, name, address, city, state, zip_code, property_type,
+		bedrooms, bathrooms, square_footage, year_built, monthly_rent, security_deposit,
+		is_available, current_tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		p.ID, p.LandlordID, p.Name, p.Address, p.City, p.State, p.ZipCode, p.PropertyType,
+		p.Bedrooms, p.Bathrooms, p.SquareFootage, p.YearBuilt, p.MonthlyRent, p.SecurityDeposit,
+		p.IsAvailable, p.CurrentTenantID,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create property: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a property owned by the given landlord
+func (r *PropertyRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.Property, error) {
+	query := `SELECT ` + propertyColumns + ` FROM properties
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	p, err := scanProperty(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+// GetByCurrentTenant returns the property currently rented by the given tenant
+func (r *PropertyRepository) GetByCurrentTenant(ctx context.Context, landlordID, tenantID uuid.UUID) (*domain.Property, error) {
+	query := `SELECT ` + propertyColumns + ` FROM properties
+		WHERE current_tenant_id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	p, err := scanProperty(r.db.QueryRowContext(ctx, query, tenantID, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+// Update persists all mutable property fields
+func (r *PropertyRepository) Update(ctx context.Context, p *domain.Property) error {
+	query := `UPDATE properties SET name = $3, address = $4, city = $5, state = $6, zip_code = $7,
+		property_type = $8, bedrooms = $9, bathrooms = $10, square_footage = $11, year_built = $12,
+		monthly_rent = $13, security_deposit = $14, is_available = $15, current_tenant_id = $16,
+		updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		p.ID, p.LandlordID, p.Name, p.Address, p.City, p.State, p.ZipCode,
+		p.PropertyType, p.Bedrooms, p.Bathrooms, p.SquareFootage, p.YearBuilt,
+		p.MonthlyRent, p.SecurityDeposit, p.IsAvailable, p.CurrentTenantID,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		return notFound(err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a property owned by the given landlord
+func (r *PropertyRepository) Delete(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE properties SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete property: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns properties matching the filter along with the total count
+func (r *PropertyRepository) List(ctx context.Context, filter PropertyFilter, opts ListOptions) ([]domain.Property, int, error) {
+	w := propertyWhere(filter)
+
+	total, err := countRows(ctx, r.db, "properties", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + propertyColumns + ` FROM properties` + w.sql() + ` ORDER BY created_at DESC, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list properties: %w", err)
+	}
+	defer rows.Close()
+
+	properties := []domain.Property{}
+	for rows.Next() {
+		p, err := scanProperty(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan property: %w", err)
+		}
+		properties = append(properties, *p)
+	}
+	return properties, total, rows.Err()
+}
+
+// ListPage returns one keyset-paginated page of properties matching the filter.
+// Supported SortBy values are created_at (default), name, city and monthly_rent.
+func (r *PropertyRepository) ListPage(ctx context.Context, filter PropertyFilter, opts PageOptions) (*Page[domain.Property], error) {
+	sortBy := opts.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	col, ok := propertySortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", opts.SortBy)
+	}
+	cursorKey := sortBy
+	if opts.SortDesc {
+		cursorKey += " desc"
+	}
+
+	w := propertyWhere(filter)
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor, cursorKey)
+		if err != nil {
+			return nil, err
+		}
+		w.addKeyset(col, opts.SortDesc, c)
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	limit := ListOptions{Limit: opts.Limit}.normalize().Limit
+	args := append(w.args, limit+1)
+	query := `SELECT ` + propertyColumns + ` FROM properties` + w.sql() + orderBy(col, opts.SortDesc) +
+		fmt.Sprintf(" LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list properties: %w", err)
+	}
+	defer rows.Close()
+
+	page := &Page[domain.Property]{Items: []domain.Property{}}
+	for rows.Next() {
+		p, err := scanProperty(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan property: %w", err)
+		}
+		page.Items = append(page.Items, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(cursorKey, propertySortValue(&last, sortBy), last.ID)
+	}
+	return page, nil
+}
+
+// propertyWhere builds the WHERE conditions shared by List and ListPage
+func propertyWhere(filter PropertyFilter) *whereBuilder {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.IDs != nil {
+		w.add("id = ANY($%d)", pq.Array(filter.IDs))
+	}
+	if filter.City != "" {
+		w.add("LOWER(city) = LOWER($%d)", filter.City)
+	}
+	if filter.State != "" {
+		w.add("LOWER(state) = LOWER($%d)", filter.State)
+	}
+	if filter.PropertyType != "" {
+		w.add("property_type = $%d", filter.PropertyType)
+	}
+	if filter.IsAvailable != nil {
+		w.add("is_available = $%d", *filter.IsAvailable)
+	}
+	if filter.CurrentTenantID != nil {
+		w.add("current_tenant_id = $%d", *filter.CurrentTenantID)
+	}
+	return w
+}
+
+// propertySortValue renders the sort column value of p for use in a cursor
+func propertySortValue(p *domain.Property, sortBy string) string {
+	switch sortBy {
+	case "name":
+		return p.Name
+	case "city":
+		return p.City
+	case "monthly_rent":
+		return strconv.FormatFloat(p.MonthlyRent, 'f', -1, 64)
+	default:
+		return p.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func scanProperty(row rowScanner) (*domain.Property, error) {
+	var p domain.Property
+	err := row.Scan(
+		&p.ID, &p.LandlordID, &p.Name, &p.Address, &p.City, &p.State, &p.ZipCode, &p.PropertyType,
+		&p.Bedrooms, &p.Bathrooms, &p.SquareFootage, &p.YearBuilt, &p.MonthlyRent, &p.SecurityDeposit,
+		&p.IsAvailable, &p.CurrentTenantID, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
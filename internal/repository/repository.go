@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"dwell/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrNotFound is returned when a row does not exist, has been soft-deleted or
+// belongs to a different landlord than the one the caller is scoped to.
+var ErrNotFound = errors.New("record not found")
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded or
+// was issued for a different sort order.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// ErrDuplicate is returned when an insert or update violates a unique constraint
+var ErrDuplicate = errors.New("duplicate record")
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Repositories holds all repository instances
+type Repositories struct {
+	Landlords             *LandlordRepository
+	Tenants               *TenantRepository
+	Properties            *PropertyRepository
+	Contractors           *ContractorRepository
+	MaintenanceRequests   *MaintenanceRequestRepository
+	MaintenancePhotos     *MaintenancePhotoRepository
+	MaintenanceHistory    *MaintenanceStatusHistoryRepository
+	Payments              *PaymentRepository
+	PaymentReceipts       *PaymentReceiptRepository
+	LateFeePolicies       *LateFeePolicyRepository
+	AIChatMessages        *AIChatMessageRepository
+	AIThreads             *AIThreadRepository
+	AIToolInvocations     *AIToolInvocationRepository
+	AIBudgets             *AIBudgetRepository
+	MaintenanceTriage     *MaintenanceTriageRepository
+	Notifications         *NotificationRepository
+	TenantInvitations     *TenantInvitationRepository
+	ContractorInvitations *ContractorInvitationRepository
+	TeamMembers           *TeamMemberRepository
+	TeamInvitations       *TeamInvitationRepository
+	AuthUsers             *AuthUserRepository
+
+	db *sql.DB
+}
+
+// NewRepositories creates all repositories backed by the given connection
+func NewRepositories(conn *database.Connection) *Repositories {
+	db := conn.GetDB()
+	return &Repositories{
+		Landlords:             NewLandlordRepository(db),
+		Tenants:               NewTenantRepository(db),
+		Properties:            NewPropertyRepository(db),
+		Contractors:           NewContractorRepository(db),
+		MaintenanceRequests:   NewMaintenanceRequestRepository(db),
+		MaintenancePhotos:     NewMaintenancePhotoRepository(db),
+		MaintenanceHistory:    NewMaintenanceStatusHistoryRepository(db),
+		Payments:              NewPaymentRepository(db),
+		PaymentReceipts:       NewPaymentReceiptRepository(db),
+		LateFeePolicies:       NewLateFeePolicyRepository(db),
+		AIChatMessages:        NewAIChatMessageRepository(db),
+		AIThreads:             NewAIThreadRepository(db),
+		AIToolInvocations:     NewAIToolInvocationRepository(db),
+		AIBudgets:             NewAIBudgetRepository(db),
+		MaintenanceTriage:     NewMaintenanceTriageRepository(db),
+		Notifications:         NewNotificationRepository(db),
+		TenantInvitations:     NewTenantInvitationRepository(db),
+		ContractorInvitations: NewContractorInvitationRepository(db),
+		TeamMembers:           NewTeamMemberRepository(db),
+		TeamInvitations:       NewTeamInvitationRepository(db),
+		AuthUsers:             NewAuthUserRepository(db),
+		db:                    db,
+	}
+}
+
+// RunInTx executes fn inside a transaction on the repositories' database.
+// Use the WithTx methods to bind individual repositories to tx.
+func (r *Repositories) RunInTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return RunInTx(ctx, r.db, fn)
+}
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx so repositories can be used
+// inside a transaction via WithTx.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ListOptions controls pagination for list queries
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+// normalize clamps the limit and offset to sane values
+func (o ListOptions) normalize() ListOptions {
+	if o.Limit <= 0 {
+		o.Limit = DefaultLimit
+	}
+	if o.Limit > MaxLimit {
+		o.Limit = MaxLimit
+	}
+	if o.Offset < 0 {
+		o.Offset = 0
+	}
+	return o
+}
+
+// PageOptions controls keyset (cursor) pagination for list queries
+type PageOptions struct {
+	Limit    int
+	Cursor   string
+	SortBy   string
+	SortDesc bool
+}
+
+// Page is a single page of keyset-paginated results
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// cursor identifies the last row of a page by its sort value and ID
+type cursor struct {
+	SortBy string    `json:"s"`
+	Value  string    `json:"v"`
+	ID     uuid.UUID `json:"id"`
+}
+
+func encodeCursor(sortBy, value string, id uuid.UUID) string {
+	raw, _ := json.Marshal(cursor{SortBy: sortBy, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded, sortBy string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.SortBy != sortBy {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// sortColumn describes a column that can be used for keyset pagination
+type sortColumn struct {
+	column string
+	cast   string // Postgres type the cursor value is cast to
+}
+
+// addKeyset appends the "after cursor" condition for the given sort column
+func (w *whereBuilder) addKeyset(col sortColumn, desc bool, c *cursor) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	w.args = append(w.args, c.Value, c.ID)
+	w.clauses = append(w.clauses, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)",
+		col.column, op, len(w.args)-1, col.cast, len(w.args)))
+}
+
+// orderBy renders the ORDER BY clause for keyset pagination
+func orderBy(col sortColumn, desc bool) string {
+	if desc {
+		return fmt.Sprintf(" ORDER BY %s DESC, id DESC", col.column)
+	}
+	return fmt.Sprintf(" ORDER BY %s ASC, id ASC", col.column)
+}
+
+// RunInTx executes fn inside a database transaction, rolling back on error
+func RunInTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// whereBuilder accumulates SQL conditions with positional arguments
+type whereBuilder struct {
+	clauses []string
+	args    []interface{}
+}
+
+// add appends a condition. The clause must contain a single %d verb which is
+// replaced with the positional parameter number of arg.
+func (w *whereBuilder) add(clause string, arg interface{}) {
+	w.args = append(w.args, arg)
+	w.clauses = append(w.clauses, fmt.Sprintf(clause, len(w.args)))
+}
+
+// addRaw appends a condition that takes no arguments
+func (w *whereBuilder) addRaw(clause string) {
+	w.clauses = append(w.clauses, clause)
+}
+
+// sql renders the WHERE clause, or an empty string if there are no conditions
+func (w *whereBuilder) sql() string {
+	if len(w.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.clauses, " AND ")
+}
+
+// paginate appends LIMIT/OFFSET placeholders and returns the suffix and args
+func (w *whereBuilder) paginate(opts ListOptions) (string, []interface{}) {
+	opts = opts.normalize()
+	args := append(append([]interface{}{}, w.args...), opts.Limit, opts.Offset)
+	return fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args)), args
+}
+
+// likeEscaper escapes LIKE wildcards using Postgres' default escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes s match literally inside a LIKE pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// countRows runs a COUNT(*) for the given table and conditions
+func countRows(ctx context.Context, db DBTX, table string, w *whereBuilder) (int, error) {
+	var total int
+	query := "SELECT COUNT(*) FROM " + table + w.sql()
+	if err := db.QueryRowContext(ctx, query, w.args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", table, err)
+	}
+	return total, nil
+}
+
+// checkAffected converts a zero-row update/delete into ErrNotFound
+func checkAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// notFound maps sql.ErrNoRows to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// duplicate maps a Postgres unique_violation to ErrDuplicate
+func duplicate(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return fmt.Errorf("%w: %s", ErrDuplicate, pqErr.Constraint)
+	}
+	return err
+}
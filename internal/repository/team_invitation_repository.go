@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const teamInvitationColumns = `id, landlord_id, email, role, all_properties, property_ids, invited_by,
+	code_hash, expires_at, accepted_at, revoked_at, created_at, updated_at`
+
+type TeamInvitationRepository struct {
+	db DBTX
+}
+
+func NewTeamInvitationRepository(db DBTX) *TeamInvitationRepository {
+	return &TeamInvitationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *TeamInvitationRepository) WithTx(tx *sql.Tx) *TeamInvitationRepository {
+	return &TeamInvitationRepository{db: tx}
+}
+
+// Create inserts a new invitation
+func (r *TeamInvitationRepository) Create(ctx context.Context, inv *domain.TeamInvitation) error {
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.New()
+	}
+	if inv.PropertyIDs == nil {
+		inv.PropertyIDs = []uuid.UUID{}
+	}
+
+	query := `INSERT INTO team_invitations (id, landlord_id, email, role, all_properties, property_ids,
+		invited_by, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		inv.ID, inv.LandlordID, inv.Email, inv.Role, inv.AllProperties, pq.Array(inv.PropertyIDs),
+		inv.InvitedBy, inv.CodeHash, inv.ExpiresAt,
+	).Scan(&inv.CreatedAt, &inv.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create team invitation: %w", duplicate(err))
+	}
+	return nil
+}
+
+// GetPendingByCodeHash returns an unexpired invitation that has been neither
+// accepted nor revoked. It is not landlord-scoped because it is used during
+// signup, before the user has any claims.
+func (r *TeamInvitationRepository) GetPendingByCodeHash(ctx context.Context, codeHash string) (*domain.TeamInvitation, error) {
+	query := `SELECT ` + teamInvitationColumns + ` FROM team_invitations
+		WHERE code_hash = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()`
+	inv, err := scanTeamInvitation(r.db.QueryRowContext(ctx, query, codeHash))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return inv, nil
+}
+
+// ListPending returns a landlord account's unexpired invitations that have
+// been neither accepted nor revoked, newest first
+func (r *TeamInvitationRepository) ListPending(ctx context.Context, landlordID uuid.UUID) ([]domain.TeamInvitation, error) {
+	query := `SELECT ` + teamInvitationColumns + ` FROM team_invitations
+		WHERE landlord_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, query, landlordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []domain.TeamInvitation{}
+	for rows.Next() {
+		inv, err := scanTeamInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team invitation: %w", err)
+		}
+		invitations = append(invitations, *inv)
+	}
+	return invitations, rows.Err()
+}
+
+// MarkAccepted stamps a pending invitation as accepted
+func (r *TeamInvitationRepository) MarkAccepted(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE team_invitations SET accepted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to accept team invitation: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// Revoke revokes one outstanding invitation of the given landlord account
+func (r *TeamInvitationRepository) Revoke(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE team_invitations SET revoked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke team invitation: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// RevokePending revokes every outstanding invitation sent to an email address
+func (r *TeamInvitationRepository) RevokePending(ctx context.Context, landlordID uuid.UUID, email string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE team_invitations SET revoked_at = NOW(), updated_at = NOW()
+		WHERE landlord_id = $1 AND LOWER(email) = LOWER($2) AND accepted_at IS NULL AND revoked_at IS NULL`,
+		landlordID, email)
+	if err != nil {
+		return fmt.Errorf("failed to revoke team invitations: %w", err)
+	}
+	return nil
+}
+
+func scanTeamInvitation(row rowScanner) (*domain.TeamInvitation, error) {
+	var inv domain.TeamInvitation
+	err := row.Scan(
+		&inv.ID, &inv.LandlordID, &inv.Email, &inv.Role, &inv.AllProperties, pq.Array(&inv.PropertyIDs),
+		&inv.InvitedBy, &inv.CodeHash, &inv.ExpiresAt, &inv.AcceptedAt, &inv.RevokedAt, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
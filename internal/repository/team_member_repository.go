@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const teamMemberColumns = `id, landlord_id, user_id, email, first_name, last_name, role,
+	all_properties, property_ids, removed_at, created_at, updated_at`
+
+type TeamMemberRepository struct {
+	db DBTX
+}
+
+// TeamMemberFilter narrows team member list queries. Removed members are never listed.
+type TeamMemberFilter struct {
+	LandlordID uuid.UUID
+	Role       string
+}
+
+func NewTeamMemberRepository(db DBTX) *TeamMemberRepository {
+	return &TeamMemberRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *TeamMemberRepository) WithTx(tx *sql.Tx) *TeamMemberRepository {
+	return &TeamMemberRepository{db: tx}
+}
+
+// Create inserts a new team member
+func (r *TeamMemberRepository) Create(ctx context.Context, m *domain.TeamMember) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	if m.PropertyIDs == nil {
+		m.PropertyIDs = []uuid.UUID{}
+	}
+
+	query := `INSERT INTO team_members (id, landlord_id, user_id, email, first_name, last_name, role,
+		all_properties, property_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		m.ID, m.LandlordID, m.UserID, m.Email, m.FirstName, m.LastName, m.Role,
+		m.AllProperties, pq.Array(m.PropertyIDs),
+	).Scan(&m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create team member: %w", duplicate(err))
+	}
+	return nil
+}
+
+// GetByID returns a current member of the given landlord account
+func (r *TeamMemberRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.TeamMember, error) {
+	query := `SELECT ` + teamMemberColumns + ` FROM team_members
+		WHERE id = $1 AND landlord_id = $2 AND removed_at IS NULL`
+	m, err := scanTeamMember(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return m, nil
+}
+
+// GetByIDForUpdate is GetByID with a row lock, for use inside a transaction
+func (r *TeamMemberRepository) GetByIDForUpdate(ctx context.Context, landlordID, id uuid.UUID) (*domain.TeamMember, error) {
+	query := `SELECT ` + teamMemberColumns + ` FROM team_members
+		WHERE id = $1 AND landlord_id = $2 AND removed_at IS NULL FOR UPDATE`
+	m, err := scanTeamMember(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return m, nil
+}
+
+// GetByUserID returns the current membership of an identity-provider user. It
+// is not landlord-scoped because it is used to resolve the caller's landlord.
+func (r *TeamMemberRepository) GetByUserID(ctx context.Context, userID string) (*domain.TeamMember, error) {
+	query := `SELECT ` + teamMemberColumns + ` FROM team_members
+		WHERE user_id = $1 AND removed_at IS NULL`
+	m, err := scanTeamMember(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return m, nil
+}
+
+// GetOwner returns the owner of a landlord account. Accounts that predate
+// teams have no owner row.
+func (r *TeamMemberRepository) GetOwner(ctx context.Context, landlordID uuid.UUID) (*domain.TeamMember, error) {
+	query := `SELECT ` + teamMemberColumns + ` FROM team_members
+		WHERE landlord_id = $1 AND role = 'owner' AND removed_at IS NULL`
+	m, err := scanTeamMember(r.db.QueryRowContext(ctx, query, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return m, nil
+}
+
+// Update persists a member's name, role and property assignments
+func (r *TeamMemberRepository) Update(ctx context.Context, m *domain.TeamMember) error {
+	if m.PropertyIDs == nil {
+		m.PropertyIDs = []uuid.UUID{}
+	}
+
+	query := `UPDATE team_members SET email = $3, first_name = $4, last_name = $5, role = $6,
+		all_properties = $7, property_ids = $8, updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND removed_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		m.ID, m.LandlordID, m.Email, m.FirstName, m.LastName, m.Role,
+		m.AllProperties, pq.Array(m.PropertyIDs),
+	).Scan(&m.UpdatedAt)
+	if err != nil {
+		return notFound(duplicate(err))
+	}
+	return nil
+}
+
+// Remove stamps a member of the given landlord account as removed
+func (r *TeamMemberRepository) Remove(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE team_members SET removed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND removed_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns current team members matching the filter along with the total count
+func (r *TeamMemberRepository) List(ctx context.Context, filter TeamMemberFilter, opts ListOptions) ([]domain.TeamMember, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("removed_at IS NULL")
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.Role != "" {
+		w.add("role = $%d", filter.Role)
+	}
+
+	total, err := countRows(ctx, r.db, "team_members", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + teamMemberColumns + ` FROM team_members` + w.sql() + ` ORDER BY created_at, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []domain.TeamMember{}
+	for rows.Next() {
+		m, err := scanTeamMember(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan team member: %w", err)
+		}
+		members = append(members, *m)
+	}
+	return members, total, rows.Err()
+}
+
+func scanTeamMember(row rowScanner) (*domain.TeamMember, error) {
+	var m domain.TeamMember
+	err := row.Scan(
+		&m.ID, &m.LandlordID, &m.UserID, &m.Email, &m.FirstName, &m.LastName, &m.Role,
+		&m.AllProperties, pq.Array(&m.PropertyIDs), &m.RemovedAt, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const tenantInvitationColumns = `id, landlord_id, tenant_id, email, code_hash, expires_at,
+	accepted_at, revoked_at, created_at, updated_at`
+
+type TenantInvitationRepository struct {
+	db DBTX
+}
+
+func NewTenantInvitationRepository(db DBTX) *TenantInvitationRepository {
+	return &TenantInvitationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *TenantInvitationRepository) WithTx(tx *sql.Tx) *TenantInvitationRepository {
+	return &TenantInvitationRepository{db: tx}
+}
+
+// Create inserts a new invitation
+func (r *TenantInvitationRepository) Create(ctx context.Context, inv *domain.TenantInvitation) error {
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.New()
+	}
+
+	query := `INSERT INTO tenant_invitations (id, landlord_id, tenant_id, email, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		inv.ID, inv.LandlordID, inv.TenantID, inv.Email, inv.CodeHash, inv.ExpiresAt,
+	).Scan(&inv.CreatedAt, &inv.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant invitation: %w", duplicate(err))
+	}
+	return nil
+}
+
+// GetPendingByCodeHash returns an unexpired invitation that has been neither
+// accepted nor revoked. It is not landlord-scoped because it is used during
+// signup, before the user has any claims.
+func (r *TenantInvitationRepository) GetPendingByCodeHash(ctx context.Context, codeHash string) (*domain.TenantInvitation, error) {
+	query := `SELECT ` + tenantInvitationColumns + ` FROM tenant_invitations
+		WHERE code_hash = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()`
+	inv, err := scanTenantInvitation(r.db.QueryRowContext(ctx, query, codeHash))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return inv, nil
+}
+
+// MarkAccepted stamps a pending invitation as accepted
+func (r *TenantInvitationRepository) MarkAccepted(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE tenant_invitations SET accepted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to accept tenant invitation: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// RevokePending revokes every outstanding invitation for a tenant
+func (r *TenantInvitationRepository) RevokePending(ctx context.Context, landlordID, tenantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tenant_invitations SET revoked_at = NOW(), updated_at = NOW()
+		WHERE tenant_id = $1 AND landlord_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL`,
+		tenantID, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke tenant invitations: %w", err)
+	}
+	return nil
+}
+
+func scanTenantInvitation(row rowScanner) (*domain.TenantInvitation, error) {
+	var inv domain.TenantInvitation
+	err := row.Scan(
+		&inv.ID, &inv.LandlordID, &inv.TenantID, &inv.Email, &inv.CodeHash, &inv.ExpiresAt,
+		&inv.AcceptedAt, &inv.RevokedAt, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const tenantColumns = `id, landlord_id, user_id, email, first_name, last_name, phone, emergency_contact,
+	lease_start_date, lease_end_date, monthly_rent, security_deposit, is_active, created_at, updated_at`
+
+type TenantRepository struct {
+	db DBTX
+}
+
+// TenantFilter narrows tenant list queries. LandlordID is required.
+type TenantFilter struct {
+	LandlordID      uuid.UUID
+	PropertyID      *uuid.UUID  // tenant currently renting the property
+	PropertyIDs     []uuid.UUID // when non-nil, tenants currently renting any of these properties
+	Email           string
+	Search          string // case-insensitive substring of name or email
+	IsActive        *bool
+	LeaseEndsAfter  *time.Time
+	LeaseEndsBefore *time.Time
+}
+
+func NewTenantRepository(db DBTX) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *TenantRepository) WithTx(tx *sql.Tx) *TenantRepository {
+	return &TenantRepository{db: tx}
+}
+
+// Create inserts a new tenant
+func (r *TenantRepository) Create(ctx context.Context, t *domain.Tenant) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+
+	query := `INSERT INTO tenants (id, landlord_id, user_id, email, first_name, last_name, phone, emergency_contact,
+		lease_start_date, lease_end_date, monthly_rent, security_deposit, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.ID, t.LandlordID, t.UserID, t.Email, t.FirstName, t.LastName, t.Phone, t.EmergencyContact,
+		t.LeaseStartDate, t.LeaseEndDate, t.MonthlyRent, t.SecurityDeposit, t.IsActive,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", duplicate(err))
+	}
+	return nil
+}
+
+// GetByID returns a tenant owned by the given landlord
+func (r *TenantRepository) GetByID(ctx context.Context, landlordID, id uuid.UUID) (*domain.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`
+	t, err := scanTenant(r.db.QueryRowContext(ctx, query, id, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return t, nil
+}
+
+// GetByUserID returns the active tenant row linked to an identity-provider user
+func (r *TenantRepository) GetByUserID(ctx context.Context, landlordID uuid.UUID, userID string) (*domain.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants
+		WHERE user_id = $1 AND user_id <> '' AND landlord_id = $2 AND deleted_at IS NULL`
+	t, err := scanTenant(r.db.QueryRowContext(ctx, query, userID, landlordID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return t, nil
+}
+
+// Update persists all mutable tenant fields
+func (r *TenantRepository) Update(ctx context.Context, t *domain.Tenant) error {
+	query := `UPDATE tenants SET user_id = $3, email = $4, first_name = $5, last_name = $6, phone = $7,
+		emergency_contact = $8, lease_start_date = $9, lease_end_date = $10, monthly_rent = $11,
+		security_deposit = $12, is_active = $13, updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.ID, t.LandlordID, t.UserID, t.Email, t.FirstName, t.LastName, t.Phone, t.EmergencyContact,
+		t.LeaseStartDate, t.LeaseEndDate, t.MonthlyRent, t.SecurityDeposit, t.IsActive,
+	).Scan(&t.UpdatedAt)
+	if err != nil {
+		return notFound(duplicate(err))
+	}
+	return nil
+}
+
+// Delete soft-deletes a tenant owned by the given landlord
+func (r *TenantRepository) Delete(ctx context.Context, landlordID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE tenants SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND landlord_id = $2 AND deleted_at IS NULL`, id, landlordID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	return checkAffected(result)
+}
+
+// List returns tenants matching the filter along with the total count
+func (r *TenantRepository) List(ctx context.Context, filter TenantFilter, opts ListOptions) ([]domain.Tenant, int, error) {
+	w := &whereBuilder{}
+	w.addRaw("deleted_at IS NULL")
+	w.add("landlord_id = $%d", filter.LandlordID)
+	if filter.PropertyID != nil {
+		w.add("id IN (SELECT current_tenant_id FROM properties WHERE id = $%d AND deleted_at IS NULL)", *filter.PropertyID)
+	}
+	if filter.PropertyIDs != nil {
+		w.add("id IN (SELECT current_tenant_id FROM properties WHERE id = ANY($%d) AND deleted_at IS NULL)", pq.Array(filter.PropertyIDs))
+	}
+	if filter.Email != "" {
+		w.add("LOWER(email) = LOWER($%d)", filter.Email)
+	}
+	if filter.Search != "" {
+		w.add("(first_name || ' ' || last_name || ' ' || email) ILIKE '%%' || $%d || '%%'", escapeLike(filter.Search))
+	}
+	if filter.IsActive != nil {
+		w.add("is_active = $%d", *filter.IsActive)
+	}
+	if filter.LeaseEndsAfter != nil {
+		w.add("lease_end_date >= $%d", *filter.LeaseEndsAfter)
+	}
+	if filter.LeaseEndsBefore != nil {
+		w.add("lease_end_date <= $%d", *filter.LeaseEndsBefore)
+	}
+
+	total, err := countRows(ctx, r.db, "tenants", w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffix, args := w.paginate(opts)
+	query := `SELECT ` + tenantColumns + ` FROM tenants` + w.sql() + ` ORDER BY last_name, first_name, id` + suffix
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []domain.Tenant{}
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, *t)
+	}
+	return tenants, total, rows.Err()
+}
+
+func scanTenant(row rowScanner) (*domain.Tenant, error) {
+	var t domain.Tenant
+	err := row.Scan(
+		&t.ID, &t.LandlordID, &t.UserID, &t.Email, &t.FirstName, &t.LastName, &t.Phone, &t.EmergencyContact,
+		&t.LeaseStartDate, &t.LeaseEndDate, &t.MonthlyRent, &t.SecurityDeposit, &t.IsActive,
+		&t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"dwell/internal/authz"
 	"dwell/internal/controllers"
 	"dwell/internal/middleware"
 	"dwell/internal/services"
@@ -33,6 +34,7 @@ func NewRouter(services *services.Services) *gin.Engine {
 			authController := controllers.NewAuthController(services.GetAuthService())
 			auth.POST("/signup", authController.SignUp)
 			auth.POST("/confirm", authController.ConfirmSignUp)
+			auth.POST("/confirm/resend", authController.ResendConfirmationCode)
 			auth.POST("/signin", authController.SignIn)
 			auth.POST("/refresh", authController.RefreshToken)
 			
@@ -50,10 +52,22 @@ func NewRouter(services *services.Services) *gin.Engine {
 		ai.Use(middleware.AuthMiddleware(services.GetAuthService()))
 		{
 			aiController := controllers.NewAIController(services.GetAIService())
-			ai.POST("/query", aiController.QueryAI)
-			ai.GET("/tips", aiController.GetPropertyManagementTips)
-			ai.GET("/history", aiController.GetAIChatHistory)
-			ai.GET("/analytics", aiController.GetAIAnalytics)
+			ai.POST("/query", middleware.Require(authz.ResourceAI, authz.ActionCreate), aiController.QueryAI)
+			ai.POST("/query/stream", middleware.Require(authz.ResourceAI, authz.ActionCreate), aiController.StreamQueryAI)
+			ai.GET("/tips", middleware.Require(authz.ResourceAITips, authz.ActionRead), aiController.GetPropertyManagementTips)
+			ai.GET("/history", middleware.Require(authz.ResourceAI, authz.ActionRead), aiController.GetAIChatHistory)
+			ai.GET("/analytics", middleware.Require(authz.ResourceAI, authz.ActionRead), aiController.GetAIAnalytics)
+			ai.POST("/threads", middleware.Require(authz.ResourceAI, authz.ActionCreate), aiController.CreateThread)
+			ai.GET("/threads", middleware.Require(authz.ResourceAI, authz.ActionRead), aiController.GetThreads)
+			ai.GET("/threads/:id", middleware.Require(authz.ResourceAI, authz.ActionRead), aiController.GetThread)
+			ai.DELETE("/threads/:id", middleware.Require(authz.ResourceAI, authz.ActionDelete), aiController.DeleteThread)
+			ai.POST("/threads/:id/messages", middleware.Require(authz.ResourceAI, authz.ActionCreate), aiController.PostThreadMessage)
+			ai.GET("/actions", middleware.Require(authz.ResourceAI, authz.ActionRead), aiController.GetActions)
+			ai.POST("/actions/:id/confirm", middleware.Require(authz.ResourceAI, authz.ActionUpdate), aiController.ConfirmAction)
+			ai.POST("/actions/:id/reject", middleware.Require(authz.ResourceAI, authz.ActionUpdate), aiController.RejectAction)
+			ai.GET("/budget", middleware.Require(authz.ResourceAIUsage, authz.ActionRead), aiController.GetBudget)
+			ai.PUT("/budget", middleware.Require(authz.ResourceAIUsage, authz.ActionUpdate), aiController.UpdateBudget)
+			ai.GET("/budget/usage", middleware.Require(authz.ResourceAIUsage, authz.ActionRead), aiController.GetBudgetUsage)
 		}
 
 		// File management routes (protected)
@@ -61,38 +75,60 @@ func NewRouter(services *services.Services) *gin.Engine {
 		files.Use(middleware.AuthMiddleware(services.GetAuthService()))
 		{
 			s3Controller := controllers.NewS3Controller(services.GetS3Service())
-			files.POST("/upload", s3Controller.UploadFile)
-			files.DELETE("/delete", s3Controller.DeleteFile)
-			files.GET("/list", s3Controller.ListFiles)
-			files.GET("/signed-url", s3Controller.GetSignedURL)
-			files.GET("/metadata", s3Controller.GetFileMetadata)
+			files.POST("/upload", middleware.Require(authz.ResourceFiles, authz.ActionCreate), s3Controller.UploadFile)
+			files.DELETE("/delete", middleware.Require(authz.ResourceFiles, authz.ActionDelete), s3Controller.DeleteFile)
+			files.GET("/list", middleware.Require(authz.ResourceFiles, authz.ActionRead), s3Controller.ListFiles)
+			files.GET("/signed-url", middleware.Require(authz.ResourceFiles, authz.ActionRead), s3Controller.GetSignedURL)
+			files.GET("/metadata", middleware.Require(authz.ResourceFiles, authz.ActionRead), s3Controller.GetFileMetadata)
 		}
 
-		// Landlord-specific routes (protected, landlord only)
+		// Landlord account routes (protected, permissions per route)
 		landlord := v1.Group("/landlord")
-		landlord.Use(
-			middleware.AuthMiddleware(services.GetAuthService()),
-			middleware.RequireLandlord(),
-		)
+		landlord.Use(middleware.AuthMiddleware(services.GetAuthService()))
 		{
+			propertyController := controllers.NewPropertyController(services.GetPropertyService())
+			landlord.GET("/properties", middleware.Require(authz.ResourceProperties, authz.ActionRead), propertyController.GetProperties)
+			landlord.POST("/properties", middleware.Require(authz.ResourceProperties, authz.ActionCreate), propertyController.CreateProperty)
+			landlord.PUT("/properties/:id", middleware.Require(authz.ResourceProperties, authz.ActionUpdate), propertyController.UpdateProperty)
+			landlord.DELETE("/properties/:id", middleware.Require(authz.ResourceProperties, authz.ActionDelete), propertyController.DeleteProperty)
+
+			tenantController := controllers.NewTenantController(services.GetTenantService())
+			landlord.GET("/tenants", middleware.Require(authz.ResourceTenants, authz.ActionRead), tenantController.GetTenants)
+			landlord.POST("/tenants", middleware.Require(authz.ResourceTenants, authz.ActionCreate), tenantController.CreateTenant)
+			landlord.GET("/tenants/:id", middleware.Require(authz.ResourceTenants, authz.ActionRead), tenantController.GetTenant)
+			landlord.POST("/tenants/:id/invite", middleware.Require(authz.ResourceTenants, authz.ActionUpdate), tenantController.InviteTenant)
+			landlord.POST("/tenants/:id/deactivate", middleware.Require(authz.ResourceTenants, authz.ActionUpdate), tenantController.DeactivateTenant)
+
+			contractorController := controllers.NewContractorController(services.GetContractorService(), services.GetMaintenanceService())
+			landlord.GET("/contractors", middleware.Require(authz.ResourceContractors, authz.ActionRead), contractorController.GetContractors)
+			landlord.POST("/contractors", middleware.Require(authz.ResourceContractors, authz.ActionCreate), contractorController.CreateContractor)
+			landlord.GET("/contractors/:id", middleware.Require(authz.ResourceContractors, authz.ActionRead), contractorController.GetContractor)
+			landlord.POST("/contractors/:id/invite", middleware.Require(authz.ResourceContractors, authz.ActionUpdate), contractorController.InviteContractor)
+
+			teamController := controllers.NewTeamController(services.GetTeamService())
+			landlord.GET("/team", middleware.Require(authz.ResourceTeam, authz.ActionRead), teamController.GetMembers)
+			landlord.POST("/team/invitations", middleware.Require(authz.ResourceTeam, authz.ActionCreate), teamController.InviteMember)
+			landlord.GET("/team/invitations", middleware.Require(authz.ResourceTeam, authz.ActionRead), teamController.GetInvitations)
+			landlord.DELETE("/team/invitations/:id", middleware.Require(authz.ResourceTeam, authz.ActionDelete), teamController.RevokeInvitation)
+			landlord.POST("/team/transfer-ownership", middleware.Require(authz.ResourceOwnership, authz.ActionUpdate), teamController.TransferOwnership)
+			landlord.GET("/team/:id", middleware.Require(authz.ResourceTeam, authz.ActionRead), teamController.GetMember)
+			landlord.PUT("/team/:id", middleware.Require(authz.ResourceTeam, authz.ActionUpdate), teamController.UpdateMember)
+			landlord.DELETE("/team/:id", middleware.Require(authz.ResourceTeam, authz.ActionDelete), teamController.RemoveMember)
+
+			lateFeeController := controllers.NewLateFeeController(services.GetLateFeeService())
+			landlord.GET("/late-fee-policy", middleware.Require(authz.ResourceLateFeePolicy, authz.ActionRead), lateFeeController.GetPolicy)
+			landlord.PUT("/late-fee-policy", middleware.Require(authz.ResourceLateFeePolicy, authz.ActionUpdate), lateFeeController.UpdatePolicy)
+
 			// TODO: Add landlord controller
 			// landlordController := controllers.NewLandlordController(services.GetLandlordService())
 			// landlord.GET("/dashboard", landlordController.GetDashboard)
-			// landlord.GET("/properties", landlordController.GetProperties)
-			// landlord.POST("/properties", landlordController.CreateProperty)
-			// landlord.PUT("/properties/:id", landlordController.UpdateProperty)
-			// landlord.DELETE("/properties/:id", landlordController.DeleteProperty)
-			// landlord.GET("/tenants", landlordController.GetTenants)
 			// landlord.GET("/payments", landlordController.GetPayments)
 			// landlord.GET("/maintenance", landlordController.GetMaintenanceRequests)
 		}
 
-		// Tenant-specific routes (protected, tenant only)
+		// Tenant-specific routes (protected)
 		tenant := v1.Group("/tenant")
-		tenant.Use(
-			middleware.AuthMiddleware(services.GetAuthService()),
-			middleware.RequireTenant(),
-		)
+		tenant.Use(middleware.AuthMiddleware(services.GetAuthService()))
 		{
 			// TODO: Add tenant controller
 			// tenantController := controllers.NewTenantController(services.GetTenantService())
@@ -102,12 +138,23 @@ func NewRouter(services *services.Services) *gin.Engine {
 			// tenant.GET("/payments", tenantController.GetPayments)
 		}
 
-		// Shared routes (protected, both landlord and tenant)
+		// Contractor portal routes (protected)
+		contractor := v1.Group("/contractor")
+		contractor.Use(middleware.AuthMiddleware(services.GetAuthService()))
+		{
+			contractorController := controllers.NewContractorController(services.GetContractorService(), services.GetMaintenanceService())
+			contractor.GET("/jobs", middleware.Require(authz.ResourceJobs, authz.ActionRead), contractorController.GetJobs)
+			contractor.GET("/jobs/:id", middleware.Require(authz.ResourceJobs, authz.ActionRead), contractorController.GetJob)
+			contractor.POST("/jobs/:id/accept", middleware.Require(authz.ResourceJobs, authz.ActionUpdate), contractorController.AcceptJob)
+			contractor.POST("/jobs/:id/decline", middleware.Require(authz.ResourceJobs, authz.ActionUpdate), contractorController.DeclineJob)
+			contractor.POST("/jobs/:id/updates", middleware.Require(authz.ResourceJobs, authz.ActionUpdate), contractorController.PostJobUpdate)
+			contractor.POST("/jobs/:id/photos", middleware.Require(authz.ResourceJobs, authz.ActionUpdate), contractorController.UploadJobPhotos)
+			contractor.PUT("/jobs/:id/cost", middleware.Require(authz.ResourceJobs, authz.ActionUpdate), contractorController.SubmitJobCost)
+		}
+
+		// Shared routes (protected)
 		shared := v1.Group("/shared")
-		shared.Use(
-			middleware.AuthMiddleware(services.GetAuthService()),
-			middleware.RequireLandlordOrTenant(),
-		)
+		shared.Use(middleware.AuthMiddleware(services.GetAuthService()))
 		{
 			// TODO: Add shared controller
 			// sharedController := controllers.NewSharedController(services.GetSharedService())
@@ -115,51 +162,50 @@ func NewRouter(services *services.Services) *gin.Engine {
 			// shared.PUT("/notifications/:id/read", sharedController.MarkNotificationRead)
 		}
 
-		// Maintenance routes (protected, both landlord and tenant)
+		// Maintenance routes (protected)
 		maintenance := v1.Group("/maintenance")
-		maintenance.Use(
-			middleware.AuthMiddleware(services.GetAuthService()),
-			middleware.RequireLandlordOrTenant(),
-		)
+		maintenance.Use(middleware.AuthMiddleware(services.GetAuthService()))
 		{
-			// TODO: Add maintenance controller
-			// maintenanceController := controllers.NewMaintenanceController(services.GetMaintenanceService())
-			// maintenance.POST("/requests", maintenanceController.CreateRequest)
-			// maintenance.GET("/requests", maintenanceController.GetRequests)
-			// maintenance.GET("/requests/:id", maintenanceController.GetRequest)
-			// maintenance.PUT("/requests/:id", maintenanceController.UpdateRequest)
-			// maintenance.POST("/requests/:id/photos", maintenanceController.UploadPhotos)
+			maintenanceController := controllers.NewMaintenanceController(services.GetMaintenanceService())
+			maintenance.POST("/requests", middleware.Require(authz.ResourceMaintenance, authz.ActionCreate), maintenanceController.CreateRequest)
+			maintenance.GET("/requests", middleware.Require(authz.ResourceMaintenance, authz.ActionRead), maintenanceController.GetRequests)
+			maintenance.GET("/requests/:id", middleware.Require(authz.ResourceMaintenance, authz.ActionRead), maintenanceController.GetRequest)
+			maintenance.PUT("/requests/:id", middleware.Require(authz.ResourceMaintenance, authz.ActionUpdate), maintenanceController.UpdateRequest)
+			maintenance.POST("/requests/:id/photos", middleware.Require(authz.ResourceMaintenance, authz.ActionUpdate), maintenanceController.UploadPhotos)
+			maintenance.GET("/requests/:id/photos", middleware.Require(authz.ResourceMaintenance, authz.ActionRead), maintenanceController.GetPhotos)
+			maintenance.DELETE("/requests/:id/photos/:photo_id", middleware.Require(authz.ResourceMaintenance, authz.ActionDelete), maintenanceController.DeletePhoto)
+
+			aiController := controllers.NewAIController(services.GetAIService())
+			maintenance.POST("/requests/:id/triage", middleware.Require(authz.ResourceTriage, authz.ActionCreate), aiController.TriageMaintenanceRequest)
+			maintenance.GET("/requests/:id/triage", middleware.Require(authz.ResourceTriage, authz.ActionRead), aiController.GetTriage)
+			maintenance.POST("/requests/:id/triage/:triage_id/approve", middleware.Require(authz.ResourceTriage, authz.ActionUpdate), aiController.ApproveTriage)
+			maintenance.POST("/requests/:id/triage/:triage_id/reject", middleware.Require(authz.ResourceTriage, authz.ActionUpdate), aiController.RejectTriage)
 		}
 
-		// Payment routes (protected, both landlord and tenant)
+		// Payment routes (protected)
 		payments := v1.Group("/payments")
-		payments.Use(
-			middleware.AuthMiddleware(services.GetAuthService()),
-			middleware.RequireLandlordOrTenant(),
-		)
+		payments.Use(middleware.AuthMiddleware(services.GetAuthService()))
 		{
-			// TODO: Add payment controller
-			// paymentController := controllers.NewPaymentController(services.GetPaymentService())
-			// payments.GET("/", paymentController.GetPayments)
-			// payments.GET("/:id", paymentController.GetPayment)
-			// payments.POST("/", paymentController.CreatePayment)
-			// payments.PUT("/:id", paymentController.UpdatePayment)
+			paymentController := controllers.NewPaymentController(services.GetPaymentService())
+			payments.GET("", middleware.Require(authz.ResourcePayments, authz.ActionRead), paymentController.GetPayments)
+			payments.GET("/ledger", middleware.Require(authz.ResourcePayments, authz.ActionRead), paymentController.GetLedger)
+			payments.GET("/:id", middleware.Require(authz.ResourcePayments, authz.ActionRead), paymentController.GetPayment)
+			payments.POST("", middleware.Require(authz.ResourcePayments, authz.ActionCreate), paymentController.CreateCharge)
+			payments.POST("/generate", middleware.Require(authz.ResourcePayments, authz.ActionCreate), paymentController.GenerateCharges)
+			payments.POST("/:id/receipts", middleware.Require(authz.ResourcePayments, authz.ActionUpdate), paymentController.RecordPayment)
+			payments.POST("/:id/cancel", middleware.Require(authz.ResourcePayments, authz.ActionUpdate), paymentController.CancelCharge)
 		}
 
-		// Property routes (protected, both landlord and tenant)
+		// Property routes (protected)
 		properties := v1.Group("/properties")
-		properties.Use(
-			middleware.AuthMiddleware(services.GetAuthService()),
-			middleware.RequireLandlordOrTenant(),
-		)
+		properties.Use(middleware.AuthMiddleware(services.GetAuthService()))
 		{
-			// TODO: Add property controller
-			// propertyController := controllers.NewPropertyController(services.GetPropertyService())
-			// properties.GET("/", propertyController.GetProperties)
-			// properties.GET("/:id", propertyController.GetProperty)
-			// properties.POST("/", propertyController.CreateProperty)
-			// properties.PUT("/:id", propertyController.UpdateProperty)
-			// properties.DELETE("/:id", propertyController.DeleteProperty)
+			propertyController := controllers.NewPropertyController(services.GetPropertyService())
+			properties.GET("", middleware.Require(authz.ResourceProperties, authz.ActionRead), propertyController.GetProperties)
+			properties.GET("/:id", middleware.Require(authz.ResourceProperties, authz.ActionRead), propertyController.GetProperty)
+			properties.POST("", middleware.Require(authz.ResourceProperties, authz.ActionCreate), propertyController.CreateProperty)
+			properties.PUT("/:id", middleware.Require(authz.ResourceProperties, authz.ActionUpdate), propertyController.UpdateProperty)
+			properties.DELETE("/:id", middleware.Require(authz.ResourceProperties, authz.ActionDelete), propertyController.DeleteProperty)
 		}
 	}
 
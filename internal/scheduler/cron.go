@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed five-field cron expression (minute hour day-of-month
+// month day-of-week) evaluated in UTC
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record unrestricted day fields; when both day fields
+	// are restricted a time matches if either one does, as in standard cron
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var (
+	minuteField = cronField{0, 59}
+	hourField   = cronField{0, 23}
+	domField    = cronField{1, 31}
+	monthField  = cronField{1, 12}
+	dowField    = cronField{0, 7} // 0 and 7 are both Sunday
+)
+
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+}
+
+// ParseSchedule parses a cron expression such as "*/15 * * * *" or "0 2 * * 1-5".
+// Fields accept *, single values, ranges, steps and comma-separated lists, and
+// the @hourly, @daily, @weekly, @monthly and @yearly shorthands are supported.
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := cronDescriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &Schedule{
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+	var err error
+	if s.minute, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, fmt.Errorf("invalid minute in %q: %w", spec, err)
+	}
+	if s.hour, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, fmt.Errorf("invalid hour in %q: %w", spec, err)
+	}
+	if s.dom, err = parseCronField(fields[2], domField); err != nil {
+		return nil, fmt.Errorf("invalid day of month in %q: %w", spec, err)
+	}
+	if s.month, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, fmt.Errorf("invalid month in %q: %w", spec, err)
+	}
+	if s.dow, err = parseCronField(fields[4], dowField); err != nil {
+		return nil, fmt.Errorf("invalid day of week in %q: %w", spec, err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// Next returns the first matching minute strictly after t
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+
+	// Every schedule matches at least once within five years (Feb 29 is the
+	// rarest day), so the search is bounded
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseCronField returns a bitmask of the values a field allows
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part[i+1:])
+			}
+		}
+
+		lo, hi := bounds.min, bounds.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bits := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bits[0], bounds); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bits[1], bounds); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			v, err := parseCronValue(rangePart, bounds)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			// "5/10" means every 10 starting at 5
+			if !strings.Contains(part, "/") {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+func parseCronValue(s string, bounds cronField) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < bounds.min || v > bounds.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, bounds.min, bounds.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2026, time.January, 30, 10, 17, 42, 0, time.UTC) // a Friday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.January, 30, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.January, 30, 10, 30, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, time.January, 30, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.January, 30, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, time.January, 31, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, time.February, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 8,20 * * *", time.Date(2026, time.January, 30, 20, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, time.January, 30, 10, 25, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match
+		{"0 0 15 * 0", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error = %v", tt.spec, err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@often",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// jobLockClass namespaces scheduler advisory locks from other pg_advisory_lock
+// users; the second key is hashtext(job name)
+const jobLockClass int32 = 1935895908
+
+// Job run statuses
+const (
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+// JobFunc is the work performed by a scheduled job
+type JobFunc func(ctx context.Context) error
+
+// Run is one recorded execution of a job
+type Run struct {
+	ID          uuid.UUID  `json:"id"`
+	JobName     string     `json:"job_name"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	Instance    string     `json:"instance"`
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule *Schedule
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs on cron schedules inside the API process.
+// Every replica runs the same scheduler; a Postgres advisory lock per job and
+// a unique (job, scheduled time) row in job_runs make sure each scheduled run
+// happens on exactly one replica.
+type Scheduler struct {
+	db       *sql.DB
+	instance string
+	jobs     []*job
+
+	mu        sync.Mutex
+	started   bool
+	stopLoops context.CancelFunc
+	stopRuns  context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// New creates a scheduler that coordinates through the given database
+func New(db *sql.DB) *Scheduler {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &Scheduler{
+		db:       db,
+		instance: fmt.Sprintf("%s/%d", host, os.Getpid()),
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(name, spec string, run JobFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("cannot register job %q after the scheduler has started", name)
+	}
+	for _, j := range s.jobs {
+		if j.name == name {
+			return fmt.Errorf("job %q is already registered", name)
+		}
+	}
+
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("job %q: %w", name, err)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("job %q: schedule %q never fires", name, spec)
+	}
+
+	s.jobs = append(s.jobs, &job{name: name, spec: spec, schedule: schedule, run: run})
+	return nil
+}
+
+// Start launches the scheduling loops. It returns immediately.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	// Loops stop on Stop; running jobs only lose their context if Stop gives up waiting
+	loopCtx, stopLoops := context.WithCancel(context.Background())
+	runCtx, stopRuns := context.WithCancel(context.Background())
+	s.stopLoops, s.stopRuns = stopLoops, stopRuns
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(loopCtx, runCtx, j)
+		log.Printf("Scheduled job %s (%s)", j.name, j.spec)
+	}
+}
+
+// Stop stops scheduling new runs and waits for running jobs to finish. If ctx
+// expires first, running jobs are cancelled and ctx's error is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopLoops()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.stopRuns()
+		return nil
+	case <-ctx.Done():
+		s.stopRuns()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// RunNow runs a registered job immediately, subject to the same locking as
+// scheduled runs. The run is recorded at the current minute.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (*Run, error) {
+	for _, j := range s.jobs {
+		if j.name == name {
+			return s.execute(ctx, j, time.Now().UTC().Truncate(time.Minute))
+		}
+	}
+	return nil, fmt.Errorf("unknown job %q", name)
+}
+
+// RecentRuns returns the most recent runs of every job, newest first
+func (s *Scheduler) RecentRuns(ctx context.Context, limit int) ([]Run, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, job_name, scheduled_at, started_at, finished_at,
+		status, error, instance FROM job_runs ORDER BY started_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []Run{}
+	for rows.Next() {
+		var r Run
+		if err := rows.Scan(&r.ID, &r.JobName, &r.ScheduledAt, &r.StartedAt, &r.FinishedAt,
+			&r.Status, &r.Error, &r.Instance); err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// loop waits for each scheduled time of a job and runs it
+func (s *Scheduler) loop(loopCtx, runCtx context.Context, j *job) {
+	defer s.wg.Done()
+
+	for {
+		next := j.schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-loopCtx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		run, err := s.execute(runCtx, j, next)
+		switch {
+		case err != nil:
+			log.Printf("Job %s: %v", j.name, err)
+		case run == nil:
+			// Another replica holds the lock or already ran this slot
+		case run.Status == RunStatusFailed:
+			log.Printf("Job %s failed after %s: %s", j.name, run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond), run.Error)
+		}
+	}
+}
+
+// execute runs a job for a scheduled time if this replica wins the job lock
+// and no replica has run that slot yet. It returns nil when the run was skipped.
+func (s *Scheduler) execute(ctx context.Context, j *job, scheduledAt time.Time) (*Run, error) {
+	// Session-level advisory locks belong to a connection, so the lock, the
+	// run bookkeeping and the unlock all use the same *sql.Conn
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, hashtext($2))", jobLockClass, j.name).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1, hashtext($2))", jobLockClass, j.name); err != nil {
+			log.Printf("Failed to release lock for job %s: %v", j.name, err)
+		}
+	}()
+
+	run := &Run{
+		ID:          uuid.New(),
+		JobName:     j.name,
+		ScheduledAt: scheduledAt,
+		Status:      RunStatusRunning,
+		Instance:    s.instance,
+	}
+	err = conn.QueryRowContext(ctx, `INSERT INTO job_runs (id, job_name, scheduled_at, status, instance)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (job_name, scheduled_at) DO NOTHING
+		RETURNING started_at`,
+		run.ID, run.JobName, run.ScheduledAt, run.Status, run.Instance,
+	).Scan(&run.StartedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	runErr := safeRun(ctx, j.run)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = RunStatusSucceeded
+	if runErr != nil {
+		run.Status = RunStatusFailed
+		run.Error = runErr.Error()
+	}
+
+	// Record the outcome even if the run was cancelled during shutdown
+	if _, err := conn.ExecContext(context.Background(),
+		`UPDATE job_runs SET finished_at = $2, status = $3, error = $4 WHERE id = $1`,
+		run.ID, finishedAt, run.Status, run.Error,
+	); err != nil {
+		return run, fmt.Errorf("failed to record job result: %w", err)
+	}
+	return run, nil
+}
+
+// safeRun calls fn and converts a panic into an error
+func safeRun(ctx context.Context, fn JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
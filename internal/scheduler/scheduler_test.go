@@ -0,0 +1,24 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	s := New(nil)
+	noop := func(ctx context.Context) error { return nil }
+
+	if err := s.Register("sweep", "0 * * * *", noop); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := s.Register("sweep", "0 * * * *", noop); err == nil {
+		t.Error("expected an error registering a duplicate job")
+	}
+	if err := s.Register("bad", "not a schedule", noop); err == nil {
+		t.Error("expected an error for an invalid schedule")
+	}
+	if err := s.Register("never", "0 0 31 2 *", noop); err == nil {
+		t.Error("expected an error for a schedule that never fires")
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// landlordScope returns the landlord account the caller acts within
+func landlordScope(claims *domain.UserClaims) (uuid.UUID, error) {
+	if claims == nil || claims.LandlordID == nil {
+		return uuid.Nil, fmt.Errorf("%w: user is not associated with a landlord", ErrForbidden)
+	}
+	return *claims.LandlordID, nil
+}
+
+// authorize checks that the caller's role grants the action on resource and
+// returns the landlord account the caller acts within
+func authorize(claims *domain.UserClaims, resource authz.Resource, action authz.Action) (uuid.UUID, error) {
+	if err := authz.Authorize(claims, resource, action); err != nil {
+		return uuid.Nil, err
+	}
+	return landlordScope(claims)
+}
+
+// checkPropertyScope hides properties outside the caller's property scope
+func checkPropertyScope(claims *domain.UserClaims, propertyID uuid.UUID) error {
+	if !authz.CanAccessProperty(claims, propertyID) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// checkTenantScope hides tenants who do not currently rent a property within
+// the caller's property scope
+func checkTenantScope(ctx context.Context, properties *repository.PropertyRepository, claims *domain.UserClaims, landlordID, tenantID uuid.UUID) error {
+	if authz.PropertyScope(claims) == nil {
+		return nil
+	}
+	property, err := properties.GetByCurrentTenant(ctx, landlordID, tenantID)
+	if err != nil {
+		return err
+	}
+	return checkPropertyScope(claims, property.ID)
+}
+
+// currentTenant resolves the tenant row linked to a tenant user
+func currentTenant(ctx context.Context, tenants *repository.TenantRepository, claims *domain.UserClaims) (*domain.Tenant, error) {
+	if claims == nil || claims.UserType != "tenant" {
+		return nil, fmt.Errorf("%w: tenant privileges required", ErrForbidden)
+	}
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := tenants.GetByUserID(ctx, landlordID, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no tenant profile is linked to this account", ErrForbidden)
+		}
+		return nil, fmt.Errorf("failed to resolve tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// currentContractor resolves the active contractor row linked to a contractor user
+func currentContractor(ctx context.Context, contractors *repository.ContractorRepository, claims *domain.UserClaims) (*domain.Contractor, error) {
+	if claims == nil || claims.UserType != "contractor" {
+		return nil, fmt.Errorf("%w: contractor privileges required", ErrForbidden)
+	}
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	contractor, err := contractors.GetByUserID(ctx, landlordID, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no contractor profile is linked to this account", ErrForbidden)
+		}
+		return nil, fmt.Errorf("failed to resolve contractor: %w", err)
+	}
+	if !contractor.IsActive {
+		return nil, fmt.Errorf("%w: contractor is inactive", ErrForbidden)
+	}
+	return contractor, nil
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Budget states, also used as alert kinds. Each alert is sent to the landlord
+// at most once per month.
+const (
+	budgetOK           = ""
+	budgetSoftLimit    = "soft_limit"
+	budgetLimitReached = "limit_reached"
+)
+
+// UpdateAIBudgetRequest replaces a landlord's AI budget. Limits of 0 mean no
+// limit.
+type UpdateAIBudgetRequest struct {
+	MonthlyTokenLimit     int64   `json:"monthly_token_limit" binding:"min=0"`
+	MonthlyCostLimit      float64 `json:"monthly_cost_limit" binding:"min=0"`
+	TenantDailyTokenLimit int     `json:"tenant_daily_token_limit" binding:"min=0"`
+	SoftLimitPercent      int     `json:"soft_limit_percent" binding:"omitempty,min=1,max=100"` // default 80
+}
+
+// AIBudgetUsageRequest selects the usage to report
+type AIBudgetUsageRequest struct {
+	TenantID string `form:"tenant_id" binding:"omitempty,uuid"` // landlords only
+}
+
+// AIBudgetUsageResponse reports consumption against the caller's AI budget.
+// Landlords see their account's month and, if they ask for a tenant, that
+// tenant's day; tenants see only their own day.
+type AIBudgetUsageResponse struct {
+	Monthly     *AIBudgetPeriodUsage `json:"monthly,omitempty"`
+	TenantDaily *AIBudgetPeriodUsage `json:"tenant_daily,omitempty"`
+}
+
+// AIBudgetPeriodUsage is the usage of one budget period. Limits of 0 mean no
+// limit.
+type AIBudgetPeriodUsage struct {
+	TenantID   *uuid.UUID `json:"tenant_id,omitempty"`
+	From       string     `json:"from"` // first day of the period, YYYY-MM-DD (UTC)
+	ResetsAt   time.Time  `json:"resets_at"`
+	Queries    int        `json:"queries"`
+	TokensUsed int64      `json:"tokens_used"`
+	TokenLimit int64      `json:"token_limit"`
+	CostUsed   float64    `json:"cost_used"`
+	CostLimit  float64    `json:"cost_limit"`
+	Status     string     `json:"status"` // ok, soft_limit or limit_reached
+}
+
+// GetBudget returns the caller's AI budget, or the configured default if none
+// has been saved
+func (s *AIService) GetBudget(ctx context.Context, claims *domain.UserClaims) (*domain.AIBudget, error) {
+	landlordID, err := authorize(claims, authz.ResourceAIUsage, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.budget(ctx, landlordID)
+}
+
+// UpdateBudget replaces the caller's AI budget
+func (s *AIService) UpdateBudget(ctx context.Context, claims *domain.UserClaims, req *UpdateAIBudgetRequest) (*domain.AIBudget, error) {
+	landlordID, err := authorize(claims, authz.ResourceAIUsage, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := &domain.AIBudget{
+		LandlordID:            landlordID,
+		MonthlyTokenLimit:     req.MonthlyTokenLimit,
+		MonthlyCostLimit:      roundCents(req.MonthlyCostLimit),
+		TenantDailyTokenLimit: req.TenantDailyTokenLimit,
+		SoftLimitPercent:      req.SoftLimitPercent,
+	}
+	if budget.SoftLimitPercent == 0 {
+		budget.SoftLimitPercent = 80
+	}
+	if err := s.repos.AIBudgets.Upsert(ctx, budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// GetBudgetUsage reports the caller's consumption against their AI budget
+func (s *AIService) GetBudgetUsage(ctx context.Context, claims *domain.UserClaims, req *AIBudgetUsageRequest) (*AIBudgetUsageResponse, error) {
+	caller, err := s.resolveCaller(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := s.budget(ctx, caller.landlordID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	resp := &AIBudgetUsageResponse{}
+	tenantID := caller.tenantID
+	if caller.userType != "tenant" {
+		month := startOfMonth(now.UTC())
+		totals, err := s.repos.AIChatMessages.SpendSince(ctx, caller.landlordID, nil, month)
+		if err != nil {
+			return nil, err
+		}
+		resp.Monthly = &AIBudgetPeriodUsage{
+			From:       month.Format(dateLayout),
+			ResetsAt:   month.AddDate(0, 1, 0),
+			Queries:    totals.Queries,
+			TokensUsed: totals.Tokens,
+			TokenLimit: budget.MonthlyTokenLimit,
+			CostUsed:   math.Round(totals.Cost*1e6) / 1e6,
+			CostLimit:  budget.MonthlyCostLimit,
+			Status:     usageStatus(monthlyBudgetStatus(budget, totals)),
+		}
+
+		if req.TenantID == "" {
+			return resp, nil
+		}
+		id, err := uuid.Parse(req.TenantID)
+		if err != nil {
+			return nil, newValidationError("tenant_id must be a valid UUID")
+		}
+		if _, err := s.repos.Tenants.GetByID(ctx, caller.landlordID, id); err != nil {
+			return nil, err
+		}
+		tenantID = &id
+	}
+
+	day := truncateDay(now.UTC())
+	totals, err := s.repos.AIChatMessages.SpendSince(ctx, caller.landlordID, tenantID, day)
+	if err != nil {
+		return nil, err
+	}
+	status := budgetOK
+	if tenantCapReached(budget, totals) {
+		status = budgetLimitReached
+	}
+	resp.TenantDaily = &AIBudgetPeriodUsage{
+		TenantID:   tenantID,
+		From:       day.Format(dateLayout),
+		ResetsAt:   day.AddDate(0, 0, 1),
+		Queries:    totals.Queries,
+		TokensUsed: totals.Tokens,
+		TokenLimit: int64(budget.TenantDailyTokenLimit),
+		CostUsed:   math.Round(totals.Cost*1e6) / 1e6,
+		Status:     usageStatus(status),
+	}
+	return resp, nil
+}
+
+// enforceBudget refuses a model call once the landlord's monthly budget or
+// the tenant's daily cap is used up, and warns the landlord as the monthly
+// budget runs low. Usage is checked before the call, so the call that crosses
+// a limit is still answered.
+func (s *AIService) enforceBudget(ctx context.Context, caller *aiCaller) error {
+	budget, err := s.budget(ctx, caller.landlordID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	if budget.MonthlyTokenLimit > 0 || budget.MonthlyCostLimit > 0 {
+		month := startOfMonth(now.UTC())
+		totals, err := s.repos.AIChatMessages.SpendSince(ctx, caller.landlordID, nil, month)
+		if err != nil {
+			return err
+		}
+		status := monthlyBudgetStatus(budget, totals)
+		if status != budgetOK {
+			s.sendBudgetAlert(ctx, budget, month, status, totals)
+		}
+		if status == budgetLimitReached {
+			return &QuotaError{
+				Message: "the monthly AI budget for this account has been used up",
+				ResetAt: month.AddDate(0, 1, 0),
+			}
+		}
+	}
+
+	if caller.tenantID != nil && budget.TenantDailyTokenLimit > 0 {
+		day := truncateDay(now.UTC())
+		totals, err := s.repos.AIChatMessages.SpendSince(ctx, caller.landlordID, caller.tenantID, day)
+		if err != nil {
+			return err
+		}
+		if tenantCapReached(budget, totals) {
+			return &QuotaError{
+				Message: "your daily AI usage limit has been reached",
+				ResetAt: day.AddDate(0, 0, 1),
+			}
+		}
+	}
+	return nil
+}
+
+// sendBudgetAlert emails the landlord the first time in a month their budget
+// reaches a state. Failures are logged rather than failing the query.
+func (s *AIService) sendBudgetAlert(ctx context.Context, budget *domain.AIBudget, month time.Time, status string, totals repository.AIUsageTotals) {
+	recorded, err := s.repos.AIBudgets.RecordAlert(ctx, budget.LandlordID, month, status)
+	if err != nil {
+		log.Printf("Warning: failed to record AI budget alert for landlord %s: %v", budget.LandlordID, err)
+		return
+	}
+	if !recorded {
+		return
+	}
+
+	landlord, err := s.repos.Landlords.GetByID(ctx, budget.LandlordID)
+	if err != nil {
+		log.Printf("Warning: failed to load landlord %s for AI budget alert: %v", budget.LandlordID, err)
+		return
+	}
+
+	title := "AI budget running low"
+	message := fmt.Sprintf("Your account has used %s of this month's AI budget. You will be notified again if the budget is used up.", budgetUsageSummary(budget, totals))
+	if status == budgetLimitReached {
+		title = "AI budget used up"
+		message = fmt.Sprintf("Your account has used %s of this month's AI budget. The AI assistant is unavailable until %s unless you raise the budget.",
+			budgetUsageSummary(budget, totals), month.AddDate(0, 1, 0).Format("January 2, 2006"))
+	}
+
+	_, err = s.notificationService.SendNotification(ctx, &NotificationRequest{
+		Type:              "ai_budget_alert",
+		Title:             title,
+		Message:           message,
+		LandlordID:        landlord.ID.String(),
+		RecipientID:       landlord.ID.String(),
+		RecipientType:     "landlord",
+		RecipientEmail:    landlord.Email,
+		RecipientPhone:    landlord.Phone,
+		RelatedEntityType: "ai_budget",
+		Priority:          "medium",
+	})
+	if err != nil {
+		log.Printf("Warning: failed to send AI budget alert to landlord %s: %v", landlord.ID, err)
+	}
+}
+
+// budget returns a landlord's AI budget, falling back to the configured
+// defaults
+func (s *AIService) budget(ctx context.Context, landlordID uuid.UUID) (*domain.AIBudget, error) {
+	budget, err := s.repos.AIBudgets.Get(ctx, landlordID)
+	if errors.Is(err, ErrNotFound) {
+		return &domain.AIBudget{
+			LandlordID:            landlordID,
+			MonthlyTokenLimit:     int64(s.config.AI.MonthlyTokenBudget),
+			MonthlyCostLimit:      s.config.AI.MonthlyCostBudget,
+			TenantDailyTokenLimit: s.config.AI.TenantDailyTokenCap,
+			SoftLimitPercent:      s.config.AI.BudgetSoftLimitPercent,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// monthlyBudgetStatus compares a month's usage with the landlord's limits. A
+// limit is reached once usage meets it; the soft limit is the configured share
+// of either limit.
+func monthlyBudgetStatus(budget *domain.AIBudget, totals repository.AIUsageTotals) string {
+	status := budgetOK
+	if budget.MonthlyTokenLimit > 0 {
+		used := float64(totals.Tokens) / float64(budget.MonthlyTokenLimit)
+		status = worseBudgetStatus(status, budgetShareStatus(used, budget.SoftLimitPercent))
+	}
+	if budget.MonthlyCostLimit > 0 {
+		used := totals.Cost / budget.MonthlyCostLimit
+		status = worseBudgetStatus(status, budgetShareStatus(used, budget.SoftLimitPercent))
+	}
+	return status
+}
+
+// budgetShareStatus classifies the share of a limit used
+func budgetShareStatus(used float64, softLimitPercent int) string {
+	switch {
+	case used >= 1:
+		return budgetLimitReached
+	case softLimitPercent > 0 && used*100 >= float64(softLimitPercent):
+		return budgetSoftLimit
+	default:
+		return budgetOK
+	}
+}
+
+func worseBudgetStatus(a, b string) string {
+	if a == budgetLimitReached || b == budgetOK {
+		return a
+	}
+	return b
+}
+
+// tenantCapReached reports whether a tenant has used up their daily tokens
+func tenantCapReached(budget *domain.AIBudget, totals repository.AIUsageTotals) bool {
+	return budget.TenantDailyTokenLimit > 0 && totals.Tokens >= int64(budget.TenantDailyTokenLimit)
+}
+
+// budgetUsageSummary describes usage against each monthly limit, e.g.
+// "80,000 of 100,000 tokens and $8.00 of $10.00"
+func budgetUsageSummary(budget *domain.AIBudget, totals repository.AIUsageTotals) string {
+	var parts []string
+	if budget.MonthlyTokenLimit > 0 {
+		parts = append(parts, fmt.Sprintf("%s of %s tokens", formatThousands(totals.Tokens), formatThousands(budget.MonthlyTokenLimit)))
+	}
+	if budget.MonthlyCostLimit > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f of $%.2f", totals.Cost, budget.MonthlyCostLimit))
+	}
+	switch len(parts) {
+	case 0:
+		return "all"
+	case 1:
+		return parts[0]
+	default:
+		return parts[0] + " and " + parts[1]
+	}
+}
+
+// formatThousands formats a non-negative number with comma separators
+func formatThousands(n int64) string {
+	s := fmt.Sprintf("%d", n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// usageStatus names a budget state in usage reports
+func usageStatus(status string) string {
+	if status == budgetOK {
+		return "ok"
+	}
+	return status
+}
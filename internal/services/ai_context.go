@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+)
+
+// Limits on how much account data is placed in a prompt
+const (
+	groundingMaxProperties   = 20
+	groundingMaxMaintenance  = 10
+	groundingMaxPayments     = 6
+	groundingMaxOverdue      = 10
+	groundingLeaseWindowDays = 60
+)
+
+const groundingPreamble = `Account data for the user you are speaking with follows. It comes from the Dwell database and is authoritative: answer questions about the user's own property, lease, rent, payments and maintenance from it, and say so when something the user asks about is not included rather than guessing.`
+
+// openMaintenanceStatuses are the statuses of requests that still need work
+var openMaintenanceStatuses = []string{domain.MaintenanceStatusOpen, domain.MaintenanceStatusInProgress}
+
+// tenantGrounding is the data a tenant's answers are grounded in
+type tenantGrounding struct {
+	tenant      *domain.Tenant
+	property    *domain.Property // nil when no property is linked
+	maintenance []domain.MaintenanceRequest
+	payments    []domain.Payment
+	balance     float64
+}
+
+// landlordGrounding is a summary of a landlord's portfolio. Sections the
+// caller's role cannot read are left out.
+type landlordGrounding struct {
+	readable         map[authz.Resource]bool
+	properties       []domain.Property
+	propertyTotal    int
+	maintenance      []domain.MaintenanceRequest
+	maintenanceTotal int
+	overdue          []domain.Payment
+	overdueTotal     int
+	leasesEnding     []domain.Tenant
+}
+
+// groundingContext loads the caller's own account data and renders it for the
+// system prompt. Every lookup is scoped to the caller's landlord, tenants only
+// see their own lease, requests and payments, and landlord-side staff only see
+// what their role may read at the properties assigned to them.
+func (s *AIService) groundingContext(ctx context.Context, caller *aiCaller, now time.Time) (string, error) {
+	today := truncateDay(now.UTC())
+	if caller.tenantID != nil {
+		g, err := s.loadTenantGrounding(ctx, caller)
+		if err != nil {
+			return "", fmt.Errorf("failed to load tenant context: %w", err)
+		}
+		return g.render(today), nil
+	}
+	g, err := s.loadLandlordGrounding(ctx, caller, today)
+	if err != nil {
+		return "", fmt.Errorf("failed to load landlord context: %w", err)
+	}
+	return g.render(today), nil
+}
+
+func (s *AIService) loadTenantGrounding(ctx context.Context, caller *aiCaller) (*tenantGrounding, error) {
+	tenant, err := s.repos.Tenants.GetByID(ctx, caller.landlordID, *caller.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	g := &tenantGrounding{tenant: tenant}
+
+	g.property, err = s.repos.Properties.GetByCurrentTenant(ctx, caller.landlordID, tenant.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	g.maintenance, _, err = s.repos.MaintenanceRequests.List(ctx, repository.MaintenanceRequestFilter{
+		LandlordID: caller.landlordID,
+		TenantID:   &tenant.ID,
+		Statuses:   openMaintenanceStatuses,
+	}, repository.ListOptions{Limit: groundingMaxMaintenance})
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := s.repos.Payments.ListByTenant(ctx, caller.landlordID, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range charges {
+		if charges[i].Status == domain.PaymentStatusPending || charges[i].Status == domain.PaymentStatusOverdue {
+			g.balance += outstanding(&charges[i])
+		}
+	}
+	g.balance = roundCents(g.balance)
+
+	g.payments, _, err = s.repos.Payments.List(ctx, repository.PaymentFilter{
+		LandlordID: caller.landlordID,
+		TenantID:   &tenant.ID,
+	}, repository.ListOptions{Limit: groundingMaxPayments})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *AIService) loadLandlordGrounding(ctx context.Context, caller *aiCaller, today time.Time) (*landlordGrounding, error) {
+	role := authz.RoleOf(caller.claims)
+	scope := authz.PropertyScope(caller.claims)
+	g := &landlordGrounding{readable: map[authz.Resource]bool{}}
+	for _, resource := range []authz.Resource{authz.ResourceProperties, authz.ResourceMaintenance, authz.ResourcePayments, authz.ResourceTenants} {
+		g.readable[resource] = authz.Allowed(role, resource, authz.ActionRead)
+	}
+	var err error
+
+	if g.readable[authz.ResourceProperties] {
+		g.properties, g.propertyTotal, err = s.repos.Properties.List(ctx, repository.PropertyFilter{
+			LandlordID: caller.landlordID,
+			IDs:        scope,
+		}, repository.ListOptions{Limit: groundingMaxProperties})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if g.readable[authz.ResourceMaintenance] {
+		g.maintenance, g.maintenanceTotal, err = s.repos.MaintenanceRequests.List(ctx, repository.MaintenanceRequestFilter{
+			LandlordID:  caller.landlordID,
+			PropertyIDs: scope,
+			Statuses:    openMaintenanceStatuses,
+		}, repository.ListOptions{Limit: groundingMaxMaintenance})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if g.readable[authz.ResourcePayments] {
+		g.overdue, g.overdueTotal, err = s.repos.Payments.List(ctx, repository.PaymentFilter{
+			LandlordID:  caller.landlordID,
+			PropertyIDs: scope,
+			Status:      domain.PaymentStatusOverdue,
+		}, repository.ListOptions{Limit: groundingMaxOverdue})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if g.readable[authz.ResourceTenants] {
+		active := true
+		windowEnd := today.AddDate(0, 0, groundingLeaseWindowDays)
+		g.leasesEnding, _, err = s.repos.Tenants.List(ctx, repository.TenantFilter{
+			LandlordID:      caller.landlordID,
+			PropertyIDs:     scope,
+			IsActive:        &active,
+			LeaseEndsAfter:  &today,
+			LeaseEndsBefore: &windowEnd,
+		}, repository.ListOptions{Limit: repository.MaxLimit})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (g *tenantGrounding) render(today time.Time) string {
+	var b strings.Builder
+	b.WriteString(groundingPreamble)
+	fmt.Fprintf(&b, "\n\nToday's date: %s\n", today.Format(dateLayout))
+
+	t := g.tenant
+	fmt.Fprintf(&b, "\nTenant: %s %s\n", t.FirstName, t.LastName)
+	fmt.Fprintf(&b, "Lease: %s to %s (%s)\n", t.LeaseStartDate.Format(dateLayout), t.LeaseEndDate.Format(dateLayout), leaseRemaining(t.LeaseEndDate, today))
+	fmt.Fprintf(&b, "Monthly rent: $%.2f\n", t.MonthlyRent)
+	fmt.Fprintf(&b, "Security deposit: $%.2f\n", t.SecurityDeposit)
+
+	if p := g.property; p != nil {
+		fmt.Fprintf(&b, "\nProperty: %s, %s, %s, %s %s\n", p.Name, p.Address, p.City, p.State, p.ZipCode)
+		fmt.Fprintf(&b, "Type: %s, %d bedrooms, %d bathrooms", p.PropertyType, p.Bedrooms, p.Bathrooms)
+		if p.SquareFootage > 0 {
+			fmt.Fprintf(&b, ", %d sq ft", p.SquareFootage)
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("\nProperty: none linked to this tenant\n")
+	}
+
+	fmt.Fprintf(&b, "\nOutstanding balance: $%.2f\n", g.balance)
+	if len(g.payments) > 0 {
+		b.WriteString("Recent charges:\n")
+		for _, p := range g.payments {
+			writePaymentLine(&b, &p)
+		}
+	}
+
+	writeMaintenanceList(&b, "Open maintenance requests", g.maintenance, len(g.maintenance))
+	return b.String()
+}
+
+func (g *landlordGrounding) render(today time.Time) string {
+	var b strings.Builder
+	b.WriteString(groundingPreamble)
+	fmt.Fprintf(&b, "\n\nToday's date: %s\n", today.Format(dateLayout))
+
+	if g.readable[authz.ResourceProperties] {
+		fmt.Fprintf(&b, "\nProperties (%d):\n", g.propertyTotal)
+		for _, p := range g.properties {
+			occupancy := "vacant"
+			if p.CurrentTenantID != nil {
+				occupancy = "occupied"
+			}
+			fmt.Fprintf(&b, "- %s, %s, %s (id %s): %s, rent $%.2f\n", p.Name, p.Address, p.City, p.ID, occupancy, p.MonthlyRent)
+		}
+		if g.propertyTotal > len(g.properties) {
+			fmt.Fprintf(&b, "- and %d more\n", g.propertyTotal-len(g.properties))
+		}
+	}
+
+	if g.readable[authz.ResourceMaintenance] {
+		writeMaintenanceList(&b, "Open maintenance requests", g.maintenance, g.maintenanceTotal)
+	}
+
+	if g.readable[authz.ResourcePayments] {
+		fmt.Fprintf(&b, "\nOverdue charges (%d):\n", g.overdueTotal)
+		for _, p := range g.overdue {
+			writePaymentLine(&b, &p)
+		}
+		if g.overdueTotal > len(g.overdue) {
+			fmt.Fprintf(&b, "- and %d more\n", g.overdueTotal-len(g.overdue))
+		}
+	}
+
+	if g.readable[authz.ResourceTenants] {
+		fmt.Fprintf(&b, "\nLeases ending in the next %d days (%d):\n", groundingLeaseWindowDays, len(g.leasesEnding))
+		for _, t := range g.leasesEnding {
+			fmt.Fprintf(&b, "- %s %s (id %s): ends %s\n", t.FirstName, t.LastName, t.ID, t.LeaseEndDate.Format(dateLayout))
+		}
+	}
+	return b.String()
+}
+
+func writeMaintenanceList(b *strings.Builder, heading string, requests []domain.MaintenanceRequest, total int) {
+	fmt.Fprintf(b, "\n%s (%d):\n", heading, total)
+	for _, r := range requests {
+		fmt.Fprintf(b, "- %s [%s, %s priority", r.Title, r.Status, r.Priority)
+		if r.Category != "" {
+			fmt.Fprintf(b, ", %s", r.Category)
+		}
+		fmt.Fprintf(b, "] requested %s\n", r.RequestedDate.Format(dateLayout))
+	}
+	if total > len(requests) {
+		fmt.Fprintf(b, "- and %d more\n", total-len(requests))
+	}
+}
+
+func writePaymentLine(b *strings.Builder, p *domain.Payment) {
+	fmt.Fprintf(b, "- %s $%.2f due %s: %s", p.PaymentType, p.Amount, p.DueDate.Format(dateLayout), p.Status)
+	if p.AmountPaid > 0 && p.Status != domain.PaymentStatusPaid {
+		fmt.Fprintf(b, ", $%.2f paid", p.AmountPaid)
+	}
+	b.WriteString("\n")
+}
+
+// leaseRemaining describes how long a lease has left
+func leaseRemaining(end, today time.Time) string {
+	days := int(truncateDay(end).Sub(today).Hours() / 24)
+	switch {
+	case days < 0:
+		return fmt.Sprintf("ended %d days ago", -days)
+	case days == 0:
+		return "ends today"
+	case days == 1:
+		return "ends tomorrow"
+	default:
+		return fmt.Sprintf("%d days remaining", days)
+	}
+}
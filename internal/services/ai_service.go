@@ -2,154 +2,374 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
+	"math"
+	"time"
 
-	"dwell/internal/aws"
+	"dwell/internal/authz"
 	"dwell/internal/config"
 	"dwell/internal/domain"
+	"dwell/internal/llm"
+	"dwell/internal/repository"
 
-	awssdk "github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/google/uuid"
 )
 
 type AIService struct {
-	awsClients *aws.Clients
-	config     *config.Config
+	llm                 llm.Provider
+	prices              *llm.PriceTable
+	repos               *repository.Repositories
+	maintenanceService  *MaintenanceService
+	paymentService      *PaymentService
+	tenantService       *TenantService
+	notificationService *NotificationService
+	s3Service           *S3Service
+	config              *config.Config
+	tipsCache           tipsCache
+	triageQueue         triageQueue
 }
 
+// AIQueryRequest is a question for the assistant. The landlord and tenant the
+// conversation belongs to come from the caller's token.
 type AIQueryRequest struct {
-	Question   string `json:"question" binding:"required"`
-	UserType   string `json:"user_type" binding:"required,oneof=landlord tenant"`
-	LandlordID string `json:"landlord_id" binding:"required"`
-	TenantID   string `json:"tenant_id,omitempty"`
-	Context    string `json:"context,omitempty"` // Additional context about the user's situation
+	Question string `json:"question" binding:"required"`
+	Context  string `json:"context,omitempty"` // Additional context about the user's situation
 }
 
 type AIQueryResponse struct {
-	Answer     string  `json:"answer"`
-	ModelUsed  string  `json:"model_used"`
-	TokensUsed int     `json:"tokens_used"`
-	Cost       float64 `json:"cost"`
-	Confidence float64 `json:"confidence"`
+	MessageID  uuid.UUID  `json:"message_id"`
+	ThreadID   *uuid.UUID `json:"thread_id,omitempty"`
+	Answer     string     `json:"answer"`
+	ModelUsed  string     `json:"model_used"`
+	TokensUsed int        `json:"tokens_used"`
+	Cost       float64    `json:"cost"`
+	Confidence float64    `json:"confidence"`
+	// Actions the assistant proposed that wait for the user to confirm them
+	Actions []domain.AIToolInvocation `json:"actions,omitempty"`
 }
 
-type ClaudeRequest struct {
-	AnthropicVersion string    `json:"anthropic_version"`
-	MaxTokens        int       `json:"max_tokens"`
-	Messages         []Message `json:"messages"`
-	System           string    `json:"system"`
+// AIChatHistoryRequest represents chat history filters and pagination
+type AIChatHistoryRequest struct {
+	TenantID string `form:"tenant_id" binding:"omitempty,uuid"` // landlords only
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset   int    `form:"offset" binding:"omitempty,min=0"`
 }
 
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+// AIChatHistoryResponse represents a page of stored chat messages, newest first
+type AIChatHistoryResponse struct {
+	Messages []domain.AIChatMessage `json:"messages"`
+	Total    int                    `json:"total"`
+	Limit    int                    `json:"limit"`
+	Offset   int                    `json:"offset"`
 }
 
-type ClaudeResponse struct {
-	Content []Content `json:"content"`
-	Usage   Usage     `json:"usage"`
+// AIAnalyticsRequest selects the analytics window
+type AIAnalyticsRequest struct {
+	Period   string `form:"period" binding:"omitempty,oneof=day week month year"`
+	TenantID string `form:"tenant_id" binding:"omitempty,uuid"` // landlords only
 }
 
-type Content struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+// AIAnalyticsResponse summarizes assistant usage over a window ending today
+type AIAnalyticsResponse struct {
+	Period        string         `json:"period"`
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+	TotalQueries  int            `json:"total_queries"`
+	TotalTokens   int            `json:"total_tokens"`
+	TotalCost     float64        `json:"total_cost"`
+	AverageTokens int            `json:"average_tokens"`
+	PopularTopics []string       `json:"popular_topics"`
+	UsageByDay    map[string]int `json:"usage_by_day"` // queries per day, YYYY-MM-DD
 }
 
-type Usage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+// Popular topics are drawn from at most this many recent questions
+const (
+	topicSampleSize = 500
+	topicCount      = 5
+)
+
+// aiCaller identifies who a chat message belongs to
+type aiCaller struct {
+	userID     string
+	userType   string
+	landlordID uuid.UUID
+	tenantID   *uuid.UUID
+	claims     *domain.UserClaims // the caller's role and property scope; nil for background work
 }
 
-func NewAIService(awsClients *aws.Clients, config *config.Config) *AIService {
+// StreamDeltaFunc receives answer text as the model generates it. Returning an
+// error stops the stream.
+type StreamDeltaFunc func(text string) error
+
+func NewAIService(provider llm.Provider, prices *llm.PriceTable, repos *repository.Repositories, maintenanceService *MaintenanceService, paymentService *PaymentService, tenantService *TenantService, notificationService *NotificationService, s3Service *S3Service, config *config.Config) *AIService {
 	return &AIService{
-		awsClients: awsClients,
-		config:     config,
+		llm:                 provider,
+		prices:              prices,
+		repos:               repos,
+		maintenanceService:  maintenanceService,
+		paymentService:      paymentService,
+		tenantService:       tenantService,
+		notificationService: notificationService,
+		s3Service:           s3Service,
+		config:              config,
+	}
+}
+
+// QueryAI answers a question through the configured model provider and stores the exchange in
+// the caller's chat history. The model may call the tools the caller's role
+// allows; tools that change data are returned as actions for the caller to
+// confirm.
+func (s *AIService) QueryAI(ctx context.Context, claims *domain.UserClaims, req *AIQueryRequest) (*AIQueryResponse, error) {
+	caller, err := s.resolveCaller(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.enforceBudget(ctx, caller); err != nil {
+		return nil, err
+	}
+	systemPrompt, err := s.groundedSystemPrompt(ctx, caller, req.Context)
+	if err != nil {
+		return nil, err
 	}
+	return s.queryWithTools(ctx, claims, caller, systemPrompt, req)
 }
 
-// QueryAI processes a question through AWS Bedrock and returns an AI-generated answer
-func (s *AIService) QueryAI(ctx context.Context, req *AIQueryRequest) (*AIQueryResponse, error) {
-	// Prepare the system prompt based on user type
-	systemPrompt := s.buildSystemPrompt(req.UserType, req.Context)
-
-	// Prepare the user message
-	userMessage := fmt.Sprintf("Question: %s", req.Question)
-
-	// Create Claude request
-	claudeReq := &ClaudeRequest{
-		AnthropicVersion: "bedrock-2023-05-31",
-		MaxTokens:        1000,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: userMessage,
-			},
-		},
-		System: systemPrompt,
-	}
-
-	// Convert to JSON
-	requestBody, err := json.Marshal(claudeReq)
+// StreamQueryAI answers a question like QueryAI but passes the answer to
+// onDelta as the model generates it. The exchange is stored once the answer is
+// complete; a stream cancelled part way through is not recorded.
+func (s *AIService) StreamQueryAI(ctx context.Context, claims *domain.UserClaims, req *AIQueryRequest, onDelta StreamDeltaFunc) (*AIQueryResponse, error) {
+	caller, err := s.resolveCaller(ctx, claims)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
+	}
+	if err := s.enforceBudget(ctx, caller); err != nil {
+		return nil, err
+	}
+	systemPrompt, err := s.groundedSystemPrompt(ctx, caller, req.Context)
+	if err != nil {
+		return nil, err
+	}
+	return s.query(ctx, caller, systemPrompt, req, onDelta)
+}
+
+// GetChatHistory returns stored chat messages visible to the caller. Landlords
+// see every conversation under their account, staff limited to some
+// properties only those of the tenants renting them, and tenants only their
+// own.
+func (s *AIService) GetChatHistory(ctx context.Context, claims *domain.UserClaims, req *AIChatHistoryRequest) (*AIChatHistoryResponse, error) {
+	caller, err := s.resolveCaller(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeAIUsage(claims); err != nil {
+		return nil, err
 	}
 
-	// Call Bedrock
-	invokeInput := &bedrockruntime.InvokeModelInput{
-		ModelId:     awssdk.String(s.config.AWS.Bedrock.Model),
-		Body:        requestBody,
-		ContentType: awssdk.String("application/json"),
+	filter, err := s.chatMessageFilter(ctx, caller, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if req.From != "" {
+		from, err := time.Parse(dateLayout, req.From)
+		if err != nil {
+			return nil, newValidationError("from must be formatted as YYYY-MM-DD")
+		}
+		filter.CreatedAfter = &from
+	}
+	if req.To != "" {
+		to, err := time.Parse(dateLayout, req.To)
+		if err != nil {
+			return nil, newValidationError("to must be formatted as YYYY-MM-DD")
+		}
+		// to is inclusive of the whole day
+		before := to.AddDate(0, 0, 1)
+		filter.CreatedBefore = &before
 	}
 
-	result, err := s.awsClients.GetBedrockClient().InvokeModel(ctx, invokeInput)
+	messages, total, err := s.repos.AIChatMessages.List(ctx, filter, repository.ListOptions{Limit: req.Limit, Offset: req.Offset})
 	if err != nil {
-		return nil, fmt.Errorf("failed to invoke Bedrock model: %w", err)
+		return nil, err
 	}
 
-	// Parse response
-	var claudeResp ClaudeResponse
-	if err := json.Unmarshal(result.Body, &claudeResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	limit := req.Limit
+	if limit == 0 {
+		limit = repository.DefaultLimit
 	}
+	return &AIChatHistoryResponse{
+		Messages: messages,
+		Total:    total,
+		Limit:    limit,
+		Offset:   req.Offset,
+	}, nil
+}
 
-	// Extract answer
-	var answer string
-	if len(claudeResp.Content) > 0 {
-		answer = claudeResp.Content[0].Text
+// GetAnalytics aggregates the caller's stored chat messages over the requested
+// period. It covers the same messages as GetChatHistory.
+func (s *AIService) GetAnalytics(ctx context.Context, claims *domain.UserClaims, req *AIAnalyticsRequest) (*AIAnalyticsResponse, error) {
+	caller, err := s.resolveCaller(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeAIUsage(claims); err != nil {
+		return nil, err
+	}
+
+	period := req.Period
+	if period == "" {
+		period = "month"
+	}
+	from, to, err := analyticsWindow(period, time.Now())
+	if err != nil {
+		return nil, err
 	}
 
-	// Calculate cost (approximate - actual costs may vary)
-	cost := s.calculateCost(claudeResp.Usage.InputTokens, claudeResp.Usage.OutputTokens)
+	filter, err := s.chatMessageFilter(ctx, caller, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	filter.CreatedAfter = &from
 
-	// Create AI chat message record
-	landlordID, _ := uuid.Parse(req.LandlordID)
-	var tenantID *uuid.UUID
-	if req.TenantID != "" {
-		if id, err := uuid.Parse(req.TenantID); err == nil {
-			tenantID = &id
+	days, err := s.repos.AIChatMessages.UsageByDay(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.repos.AIChatMessages.RecentQuestions(ctx, filter, topicSampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &AIAnalyticsResponse{
+		Period:        period,
+		From:          from.Format(dateLayout),
+		To:            to.Format(dateLayout),
+		PopularTopics: popularTopics(questions, topicCount),
+		UsageByDay:    map[string]int{},
+	}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		resp.UsageByDay[d.Format(dateLayout)] = 0
+	}
+	for _, day := range days {
+		resp.UsageByDay[day.Day.Format(dateLayout)] = day.Queries
+		resp.TotalQueries += day.Queries
+		resp.TotalTokens += day.Tokens
+		resp.TotalCost += day.Cost
+	}
+	resp.TotalCost = math.Round(resp.TotalCost*1e6) / 1e6
+	if resp.TotalQueries > 0 {
+		resp.AverageTokens = resp.TotalTokens / resp.TotalQueries
+	}
+	return resp, nil
+}
+
+// chatMessageFilter limits chat messages to those the caller may see. Tenants
+// only see their own. Staff limited to some properties only see the
+// conversations of tenants currently renting them, so landlord-side messages,
+// which may concern any property, are left out.
+func (s *AIService) chatMessageFilter(ctx context.Context, caller *aiCaller, tenantID string) (repository.AIChatMessageFilter, error) {
+	filter := repository.AIChatMessageFilter{LandlordID: caller.landlordID}
+	if caller.userType == "tenant" {
+		filter.TenantID = caller.tenantID
+		return filter, nil
+	}
+
+	filter.PropertyIDs = authz.PropertyScope(caller.claims)
+	if tenantID != "" {
+		id, err := uuid.Parse(tenantID)
+		if err != nil {
+			return filter, newValidationError("tenant_id must be a valid UUID")
+		}
+		if err := checkTenantScope(ctx, s.repos.Properties, caller.claims, caller.landlordID, id); err != nil {
+			return filter, err
 		}
+		filter.TenantID = &id
 	}
+	return filter, nil
+}
+
+// analyticsWindow returns the first and last UTC day of an analytics period
+// ending on now's day
+func analyticsWindow(period string, now time.Time) (time.Time, time.Time, error) {
+	days := map[string]int{"day": 1, "week": 7, "month": 30, "year": 365}[period]
+	if days == 0 {
+		return time.Time{}, time.Time{}, newValidationError("period must be one of day, week, month or year")
+	}
+	to := truncateDay(now.UTC())
+	return to.AddDate(0, 0, 1-days), to, nil
+}
+
+// resolveCaller determines the landlord and, for tenants, the tenant row a
+// conversation belongs to
+func (s *AIService) resolveCaller(ctx context.Context, claims *domain.UserClaims) (*aiCaller, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	caller := &aiCaller{userID: claims.UserID, userType: claims.UserType, landlordID: landlordID, claims: claims}
+	if claims.UserType == "tenant" {
+		tenant, err := currentTenant(ctx, s.repos.Tenants, claims)
+		if err != nil {
+			return nil, err
+		}
+		caller.tenantID = &tenant.ID
+	}
+	return caller, nil
+}
 
-	// TODO: Save AI message to database
-	// This would typically be done through a repository layer
-	_ = &domain.AIChatMessage{
-		LandlordID: landlordID,
-		TenantID:   tenantID,
-		UserType:   req.UserType,
+// authorizeAIUsage lets tenants through to their own AI usage and requires
+// everyone else to be allowed to read the account-wide usage
+func authorizeAIUsage(claims *domain.UserClaims) error {
+	if claims.UserType == "tenant" {
+		return nil
+	}
+	return authz.Authorize(claims, authz.ResourceAIUsage, authz.ActionRead)
+}
+
+// query sends a question to the model and records the answer. A non-nil
+// onDelta streams the answer as it is generated.
+func (s *AIService) query(ctx context.Context, caller *aiCaller, systemPrompt string, req *AIQueryRequest, onDelta StreamDeltaFunc) (*AIQueryResponse, error) {
+	modelReq := &llm.Request{
+		System:    systemPrompt,
+		Messages:  []llm.Message{llm.Text(llm.RoleUser, fmt.Sprintf("Question: %s", req.Question))},
+		MaxTokens: 1000,
+	}
+	var modelResp *llm.Response
+	var err error
+	if onDelta != nil {
+		modelResp, err = s.llm.Stream(ctx, modelReq, llm.DeltaFunc(onDelta))
+	} else {
+		modelResp, err = s.llm.Complete(ctx, modelReq)
+	}
+	if err != nil {
+		return nil, err
+	}
+	answer := modelResp.Text()
+
+	cost := s.cost(modelResp.Usage)
+
+	message := &domain.AIChatMessage{
+		LandlordID: caller.landlordID,
+		TenantID:   caller.tenantID,
+		UserType:   caller.userType,
 		Question:   req.Question,
 		Answer:     answer,
-		ModelUsed:  s.config.AWS.Bedrock.Model,
-		TokensUsed: claudeResp.Usage.InputTokens + claudeResp.Usage.OutputTokens,
+		ModelUsed:  s.llm.Model(),
+		TokensUsed: modelResp.Usage.Total(),
 		Cost:       cost,
 	}
+	// The answer has been generated and paid for, so record it even if the
+	// caller has gone away in the meantime
+	if err := s.repos.AIChatMessages.Create(context.WithoutCancel(ctx), message); err != nil {
+		return nil, err
+	}
 
 	return &AIQueryResponse{
+		MessageID:  message.ID,
 		Answer:     answer,
-		ModelUsed:  s.config.AWS.Bedrock.Model,
-		TokensUsed: claudeResp.Usage.InputTokens + claudeResp.Usage.OutputTokens,
+		ModelUsed:  message.ModelUsed,
+		TokensUsed: message.TokensUsed,
 		Cost:       cost,
 		Confidence: 0.85, // Placeholder - Claude doesn't provide confidence scores
 	}, nil
@@ -207,69 +427,19 @@ You are speaking with a TENANT. Focus on:
 	return basePrompt
 }
 
-// calculateCost estimates the cost of the AI query based on token usage
-func (s *AIService) calculateCost(inputTokens, outputTokens int) float64 {
-	// Claude 3 Sonnet pricing (approximate, as of 2024)
-	// Input: $3.00 per 1M tokens
-	// Output: $15.00 per 1M tokens
-	inputCost := float64(inputTokens) * 3.00 / 1000000
-	outputCost := float64(outputTokens) * 15.00 / 1000000
-	return inputCost + outputCost
-}
-
-// GetPropertyManagementTips returns AI-generated tips for property management
-func (s *AIService) GetPropertyManagementTips(ctx context.Context, landlordID string, category string) ([]string, error) {
-	question := fmt.Sprintf("Provide 5 practical tips for %s in property management. Keep each tip concise and actionable.", category)
-
-	req := &AIQueryRequest{
-		Question:   question,
-		UserType:   "landlord",
-		LandlordID: landlordID,
-		Context:    fmt.Sprintf("Category: %s", category),
-	}
-
-	resp, err := s.QueryAI(ctx, req)
+// groundedSystemPrompt builds the system prompt for a caller with their own
+// account data appended
+func (s *AIService) groundedSystemPrompt(ctx context.Context, caller *aiCaller, userContext string) (string, error) {
+	grounding, err := s.groundingContext(ctx, caller, time.Now())
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	// Parse the response into individual tips
-	tips := s.parseTipsFromResponse(resp.Answer)
-	return tips, nil
+	return s.buildSystemPrompt(caller.userType, userContext) + "\n\n" + grounding, nil
 }
 
-// parseTipsFromResponse extracts individual tips from the AI response
-func (s *AIService) parseTipsFromResponse(response string) []string {
-	// Simple parsing - look for numbered or bulleted items
-	lines := strings.Split(response, "\n")
-	var tips []string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// Look for lines that start with numbers, bullets, or dashes
-		if (len(line) > 2 && (line[0] >= '1' && line[0] <= '9')) ||
-			strings.HasPrefix(line, "- ") ||
-			strings.HasPrefix(line, "• ") ||
-			strings.HasPrefix(line, "* ") {
-			tip := strings.TrimPrefix(line, "- ")
-			tip = strings.TrimPrefix(tip, "• ")
-			tip = strings.TrimPrefix(tip, "* ")
-			// Remove leading numbers and dots
-			if len(tip) > 0 && tip[0] >= '1' && tip[0] <= '9' {
-				if idx := strings.Index(tip, "."); idx != -1 {
-					tip = strings.TrimSpace(tip[idx+1:])
-				}
-			}
-			if tip != "" {
-				tips = append(tips, tip)
-			}
-		}
-	}
-
-	// If no structured tips found, return the whole response as one tip
-	if len(tips) == 0 && response != "" {
-		tips = []string{response}
-	}
-
-	return tips
+// cost prices token usage on the model the provider uses. A model missing
+// from the price table is reported at startup and costs nothing here.
+func (s *AIService) cost(usage llm.Usage) float64 {
+	cost, _ := s.prices.Cost(s.llm.Model(), usage)
+	return cost
 }
@@ -0,0 +1,764 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+	"dwell/internal/llm"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+func TestAnalyticsWindow(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		period   string
+		wantFrom string
+	}{
+		{"day", "2026-03-10"},
+		{"week", "2026-03-04"},
+		{"month", "2026-02-09"},
+		{"year", "2025-03-11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			from, to, err := analyticsWindow(tt.period, now)
+			if err != nil {
+				t.Fatalf("analyticsWindow() error = %v", err)
+			}
+			if got := from.Format(dateLayout); got != tt.wantFrom {
+				t.Errorf("from = %s, want %s", got, tt.wantFrom)
+			}
+			if got := to.Format(dateLayout); got != "2026-03-10" {
+				t.Errorf("to = %s, want 2026-03-10", got)
+			}
+		})
+	}
+
+	if _, _, err := analyticsWindow("decade", now); err == nil {
+		t.Error("analyticsWindow() expected an error for an unknown period")
+	}
+}
+
+func TestPopularTopics(t *testing.T) {
+	questions := []string{
+		"How do I handle late rent payments?",
+		"What late fee can I charge when rent is late?",
+		"When is rent due?",
+		"Can I raise the rent at lease renewal?",
+		"How much notice is needed before a lease renewal?",
+		"Should lease renewal terms be in writing?",
+		"My sink is leaking, what should I do?",
+		"Is a leaking sink an emergency?",
+		"?",
+	}
+
+	want := []string{"rent", "leaking sink", "lease renewal"}
+	if got := popularTopics(questions, 5); !reflect.DeepEqual(got, want) {
+		t.Errorf("popularTopics() = %v, want %v", got, want)
+	}
+	if got := popularTopics(questions, 1); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("popularTopics(n=1) = %v, want %v", got, want[:1])
+	}
+	if got := popularTopics(nil, 5); len(got) != 0 {
+		t.Errorf("popularTopics(nil) = %v, want none", got)
+	}
+}
+
+func TestTurnsToDrop(t *testing.T) {
+	// Each turn is 10 + 10 estimated tokens
+	turn := domain.AIChatMessage{Question: strings.Repeat("q", 40), Answer: strings.Repeat("a", 40)}
+	turns := []domain.AIChatMessage{turn, turn, turn, turn}
+
+	tests := []struct {
+		budget int
+		want   int
+	}{
+		{100, 0},
+		{80, 0},
+		{79, 1},
+		{40, 2},
+		{19, 4},
+		{-5, 4},
+	}
+	for _, tt := range tests {
+		if got := turnsToDrop(turns, tt.budget); got != tt.want {
+			t.Errorf("turnsToDrop(budget=%d) = %d, want %d", tt.budget, got, tt.want)
+		}
+	}
+	if got := turnsToDrop(nil, 10); got != 0 {
+		t.Errorf("turnsToDrop(nil) = %d, want 0", got)
+	}
+}
+
+func TestSummarizeTurns(t *testing.T) {
+	fake := llm.NewFake().Strict().ReplyText("  Tenant reported a leaking sink; plumber booked for Friday.  ")
+	s := &AIService{llm: fake}
+
+	summary, usage, err := s.summarizeTurns(context.Background(), "Tenant moved in March 1.", []domain.AIChatMessage{
+		{Question: "My sink is leaking", Answer: "I'll let your landlord know."},
+	})
+	if err != nil {
+		t.Fatalf("summarizeTurns() error = %v", err)
+	}
+	if summary != "Tenant reported a leaking sink; plumber booked for Friday." {
+		t.Errorf("summary = %q", summary)
+	}
+	if usage.InputTokens == 0 || usage.OutputTokens == 0 {
+		t.Errorf("usage = %+v, want the summary call's usage", usage)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("got %d model requests, want 1", len(requests))
+	}
+	prompt := requests[0].Messages[0].Content[0].Text
+	for _, want := range []string{"Summary so far:\nTenant moved in March 1.", "User: My sink is leaking\nAssistant: I'll let your landlord know."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q in:\n%s", want, prompt)
+		}
+	}
+
+	fake.Fail(errors.New("throttled"))
+	if _, _, err := s.summarizeTurns(context.Background(), "", nil); err == nil {
+		t.Error("summarizeTurns() expected the provider error")
+	}
+}
+
+func TestThreadTitle(t *testing.T) {
+	if got := threadTitle("  When is   my rent\ndue? "); got != "When is my rent due?" {
+		t.Errorf("threadTitle() = %q", got)
+	}
+
+	long := strings.Repeat("word ", 30)
+	got := threadTitle(long)
+	if !strings.HasSuffix(got, "…") || len([]rune(got)) > maxThreadTitleLength+1 {
+		t.Errorf("threadTitle(long) = %q, want at most %d characters ending in an ellipsis", got, maxThreadTitleLength)
+	}
+	if strings.HasSuffix(strings.TrimSuffix(got, "…"), " ") || strings.Contains(got, "wor…") {
+		t.Errorf("threadTitle(long) = %q, want a cut at a word boundary", got)
+	}
+}
+
+func TestTenantGroundingRender(t *testing.T) {
+	today := mustParseDate("2026-03-10")
+	g := &tenantGrounding{
+		tenant: &domain.Tenant{
+			FirstName:      "Ada",
+			LastName:       "Lovelace",
+			LeaseStartDate: mustParseDate("2025-06-01"),
+			LeaseEndDate:   mustParseDate("2026-05-31"),
+			MonthlyRent:    1450,
+		},
+		property: &domain.Property{Name: "Maple Court 4B", Address: "12 Maple St", City: "Austin", State: "TX", ZipCode: "78701", PropertyType: "apartment", Bedrooms: 2, Bathrooms: 1},
+		maintenance: []domain.MaintenanceRequest{
+			{Title: "Leaking sink", Status: "open", Priority: "medium", Category: "plumbing", RequestedDate: mustParseDate("2026-03-08")},
+		},
+		payments: []domain.Payment{
+			{PaymentType: "rent", Amount: 1450, AmountPaid: 400, DueDate: mustParseDate("2026-03-01"), Status: "overdue"},
+		},
+		balance: 1050,
+	}
+
+	got := g.render(today)
+	for _, want := range []string{
+		"Today's date: 2026-03-10",
+		"Lease: 2025-06-01 to 2026-05-31 (82 days remaining)",
+		"Monthly rent: $1450.00",
+		"Property: Maple Court 4B, 12 Maple St, Austin, TX 78701",
+		"Outstanding balance: $1050.00",
+		"- rent $1450.00 due 2026-03-01: overdue, $400.00 paid",
+		"Open maintenance requests (1):\n- Leaking sink [open, medium priority, plumbing] requested 2026-03-08",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("render() missing %q in:\n%s", want, got)
+		}
+	}
+
+	g.property = nil
+	if got := g.render(today); !strings.Contains(got, "Property: none linked to this tenant") {
+		t.Errorf("render() without a property = %s", got)
+	}
+}
+
+func TestLandlordGroundingRender(t *testing.T) {
+	today := mustParseDate("2026-03-10")
+	tenantID := uuid.MustParse("7d0c1a52-9c3e-4f0b-8a61-2f4e5b6c7d80")
+	mapleID := uuid.MustParse("0b6f2e1a-3c4d-4e5f-9a0b-1c2d3e4f5a6b")
+	oakID := uuid.MustParse("5e4d3c2b-1a09-4f8e-b7d6-c5b4a3928170")
+	g := &landlordGrounding{
+		readable: map[authz.Resource]bool{
+			authz.ResourceProperties:  true,
+			authz.ResourceMaintenance: true,
+			authz.ResourcePayments:    true,
+			authz.ResourceTenants:     true,
+		},
+		properties: []domain.Property{
+			{BaseEntity: domain.BaseEntity{ID: mapleID}, Name: "Maple Court 4B", Address: "12 Maple St", City: "Austin", MonthlyRent: 1450, CurrentTenantID: &tenantID},
+			{BaseEntity: domain.BaseEntity{ID: oakID}, Name: "Oak House", Address: "3 Oak Ave", City: "Austin", MonthlyRent: 2100},
+		},
+		propertyTotal:    25,
+		maintenanceTotal: 0,
+		overdue: []domain.Payment{
+			{PaymentType: "rent", Amount: 2100, DueDate: mustParseDate("2026-03-01"), Status: "overdue"},
+		},
+		overdueTotal: 1,
+		leasesEnding: []domain.Tenant{
+			{BaseEntity: domain.BaseEntity{ID: tenantID}, FirstName: "Ada", LastName: "Lovelace", LeaseEndDate: mustParseDate("2026-04-30")},
+		},
+	}
+
+	got := g.render(today)
+	for _, want := range []string{
+		"Properties (25):\n- Maple Court 4B, 12 Maple St, Austin (id 0b6f2e1a-3c4d-4e5f-9a0b-1c2d3e4f5a6b): occupied, rent $1450.00\n" +
+			"- Oak House, 3 Oak Ave, Austin (id 5e4d3c2b-1a09-4f8e-b7d6-c5b4a3928170): vacant, rent $2100.00\n- and 23 more",
+		"Open maintenance requests (0):",
+		"Overdue charges (1):\n- rent $2100.00 due 2026-03-01: overdue",
+		"Leases ending in the next 60 days (1):\n- Ada Lovelace (id 7d0c1a52-9c3e-4f0b-8a61-2f4e5b6c7d80): ends 2026-04-30",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("render() missing %q in:\n%s", want, got)
+		}
+	}
+	// Sections the caller's role cannot read are left out
+	g.readable[authz.ResourcePayments] = false
+	g.readable[authz.ResourceTenants] = false
+	got = g.render(today)
+	for _, unwanted := range []string{"Overdue charges", "Leases ending", "Ada Lovelace"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("render() without payment and tenant access contains %q in:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestLeaseRemaining(t *testing.T) {
+	today := mustParseDate("2026-03-10")
+	tests := []struct {
+		end  string
+		want string
+	}{
+		{"2026-03-05", "ended 5 days ago"},
+		{"2026-03-10", "ends today"},
+		{"2026-03-11", "ends tomorrow"},
+		{"2026-04-09", "30 days remaining"},
+	}
+	for _, tt := range tests {
+		if got := leaseRemaining(mustParseDate(tt.end), today); got != tt.want {
+			t.Errorf("leaseRemaining(%s) = %q, want %q", tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestToolsFor(t *testing.T) {
+	names := func(defs []llm.Tool) []string {
+		var out []string
+		for _, d := range defs {
+			out = append(out, d.Name)
+		}
+		return out
+	}
+
+	tests := map[string][]string{
+		authz.RoleOwner:            {"find_tenants", "get_payment_balance", "create_maintenance_request", "draft_tenant_notification"},
+		authz.RolePropertyManager:  {"find_tenants", "get_payment_balance", "create_maintenance_request", "draft_tenant_notification"},
+		authz.RoleLeasingAgent:     {"find_tenants", "get_payment_balance", "draft_tenant_notification"},
+		authz.RoleMaintenanceStaff: {"find_tenants", "create_maintenance_request"},
+		authz.RoleBookkeeper:       {"find_tenants", "get_payment_balance"},
+		authz.RoleTenant:           {"get_payment_balance", "create_maintenance_request"},
+		authz.RoleContractor:       nil,
+	}
+	for role, want := range tests {
+		if got := names(toolsFor(role)); !reflect.DeepEqual(got, want) {
+			t.Errorf("toolsFor(%q) = %v, want %v", role, got, want)
+		}
+	}
+
+	for _, tool := range aiTools {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(tool.InputSchema, &schema); err != nil {
+			t.Errorf("%s input schema is not valid JSON: %v", tool.Name, err)
+		}
+	}
+}
+
+func TestCallTool(t *testing.T) {
+	s := &AIService{}
+	messageID := uuid.New()
+	landlordID := uuid.New()
+	claims := &domain.UserClaims{UserID: "tenant-user", UserType: "tenant", LandlordID: &landlordID}
+	tenant := &aiCaller{userID: "tenant-user", userType: "tenant", landlordID: landlordID, claims: claims}
+
+	tests := []struct {
+		name       string
+		tool       string
+		input      string
+		wantStatus string
+		wantError  bool
+	}{
+		{"landlord-only tool", "draft_tenant_notification", `{"tenant_id":"` + uuid.NewString() + `","title":"Hi","message":"Hello"}`, domain.AIToolStatusFailed, true},
+		{"unknown tool", "delete_property", `{}`, domain.AIToolStatusFailed, true},
+		{"invalid input", "create_maintenance_request", `{"title":"Leak"}`, domain.AIToolStatusFailed, true},
+		{"bad priority", "create_maintenance_request", `{"title":"Leak","description":"Under the sink","priority":"asap"}`, domain.AIToolStatusFailed, true},
+		{"action needs confirmation", "create_maintenance_request", `{"title":"Leak","description":"Under the sink","priority":"high"}`, domain.AIToolStatusPending, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := llm.Block{Type: llm.BlockToolUse, ID: "toolu_1", Name: tt.tool, Input: json.RawMessage(tt.input)}
+			inv, result := s.callTool(context.Background(), claims, tenant, messageID, block)
+
+			if inv.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (error %q)", inv.Status, tt.wantStatus, inv.Error)
+			}
+			if inv.MessageID != messageID || inv.UserID != "tenant-user" || inv.ToolName != tt.tool {
+				t.Errorf("invocation not attributed to the message and caller: %+v", inv)
+			}
+			if result.Type != "tool_result" || result.ToolUseID != "toolu_1" || result.IsError != tt.wantError {
+				t.Errorf("result = %+v", result)
+			}
+			if tt.wantStatus == domain.AIToolStatusPending && !strings.Contains(result.Result, inv.ID.String()) {
+				t.Errorf("pending result %q does not name action %s", result.Result, inv.ID)
+			}
+		})
+	}
+}
+
+func TestToolsFollowRolePolicy(t *testing.T) {
+	s := &AIService{}
+	landlordID := uuid.New()
+	bookkeeper := &domain.UserClaims{UserID: "bookkeeper-user", UserType: "landlord", Role: authz.RoleBookkeeper, AllProperties: true, LandlordID: &landlordID}
+	caller := &aiCaller{userID: bookkeeper.UserID, userType: "landlord", landlordID: landlordID, claims: bookkeeper}
+	input := json.RawMessage(`{"tenant_id":"` + uuid.NewString() + `","title":"Hi","message":"Hello"}`)
+
+	block := llm.Block{Type: llm.BlockToolUse, ID: "toolu_1", Name: "draft_tenant_notification", Input: input}
+	inv, result := s.callTool(context.Background(), bookkeeper, caller, uuid.New(), block)
+	if inv.Status != domain.AIToolStatusFailed || !result.IsError {
+		t.Errorf("bookkeeper drafting a tenant email: status = %q, result = %+v", inv.Status, result)
+	}
+
+	// A stored action is checked again when it runs
+	if _, err := runTenantNotification(context.Background(), s, bookkeeper, input); !errors.Is(err, ErrForbidden) {
+		t.Errorf("runTenantNotification(bookkeeper) error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestToolErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{newValidationError("title is required"), "title is required"},
+		{ErrNotFound, "not found"},
+		{errors.New("pq: connection refused"), "the tool failed; try again later"},
+	}
+	for _, tt := range tests {
+		if got := toolErrorMessage(tt.err); got != tt.want {
+			t.Errorf("toolErrorMessage(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteWithToolsAnswersAfterLastRound(t *testing.T) {
+	toolCall := &llm.Response{
+		Content:    []llm.Block{{Type: llm.BlockToolUse, ID: "toolu_1", Name: "find_tenants", Input: json.RawMessage(`{}`)}},
+		StopReason: llm.StopToolUse,
+		Usage:      llm.Usage{InputTokens: 10, OutputTokens: 5},
+	}
+	fake := llm.NewFake().Strict()
+	for range maxToolRounds {
+		fake.Reply(toolCall)
+	}
+	fake.ReplyText("Two tenants are behind on rent.")
+	s := &AIService{llm: fake}
+
+	calls := 0
+	req := &llm.Request{Messages: []llm.Message{llm.Text(llm.RoleUser, "Who is behind?")}, Tools: toolsFor(authz.RoleOwner)}
+	resp, _, err := s.completeWithTools(context.Background(), req, func(block llm.Block) llm.Block {
+		calls++
+		return llm.Block{Type: llm.BlockToolResult, ToolUseID: block.ID, Result: "[]"}
+	})
+	if err != nil {
+		t.Fatalf("completeWithTools() error = %v", err)
+	}
+	if resp.Text() != "Two tenants are behind on rent." {
+		t.Errorf("answer = %q", resp.Text())
+	}
+	if calls != maxToolRounds {
+		t.Errorf("ran %d tool calls, want %d", calls, maxToolRounds)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != maxToolRounds+1 {
+		t.Fatalf("got %d model requests, want %d", len(requests), maxToolRounds+1)
+	}
+	if requests[maxToolRounds-1].ToolChoice != "" || requests[maxToolRounds].ToolChoice != llm.ToolChoiceNone {
+		t.Errorf("only the last request should rule out tools, got %q then %q", requests[maxToolRounds-1].ToolChoice, requests[maxToolRounds].ToolChoice)
+	}
+
+	// A model that ignores the instruction gets an error rather than an empty answer
+	fake = llm.NewFake().Strict()
+	for range maxToolRounds + 1 {
+		fake.Reply(toolCall)
+	}
+	s.llm = fake
+	req = &llm.Request{Messages: []llm.Message{llm.Text(llm.RoleUser, "Who is behind?")}, Tools: toolsFor(authz.RoleOwner)}
+	if _, _, err := s.completeWithTools(context.Background(), req, func(block llm.Block) llm.Block {
+		return llm.Block{Type: llm.BlockToolResult, ToolUseID: block.ID, Result: "[]"}
+	}); err == nil {
+		t.Error("completeWithTools() error = nil, want an error when tools are still called")
+	}
+}
+
+func TestMonthlyBudgetStatus(t *testing.T) {
+	tokens := domain.AIBudget{MonthlyTokenLimit: 100000, SoftLimitPercent: 80}
+	cost := domain.AIBudget{MonthlyCostLimit: 10, SoftLimitPercent: 80}
+	both := domain.AIBudget{MonthlyTokenLimit: 100000, MonthlyCostLimit: 10, SoftLimitPercent: 80}
+
+	tests := []struct {
+		name   string
+		budget domain.AIBudget
+		totals repository.AIUsageTotals
+		want   string
+	}{
+		{"no limits", domain.AIBudget{SoftLimitPercent: 80}, repository.AIUsageTotals{Tokens: 1e9, Cost: 1e4}, budgetOK},
+		{"tokens under soft limit", tokens, repository.AIUsageTotals{Tokens: 79999}, budgetOK},
+		{"tokens at soft limit", tokens, repository.AIUsageTotals{Tokens: 80000}, budgetSoftLimit},
+		{"tokens at limit", tokens, repository.AIUsageTotals{Tokens: 100000}, budgetLimitReached},
+		{"cost at soft limit", cost, repository.AIUsageTotals{Cost: 8}, budgetSoftLimit},
+		{"cost over limit", cost, repository.AIUsageTotals{Cost: 10.5}, budgetLimitReached},
+		{"cost reached, tokens low", both, repository.AIUsageTotals{Tokens: 100, Cost: 10}, budgetLimitReached},
+		{"tokens soft, cost low", both, repository.AIUsageTotals{Tokens: 90000, Cost: 1}, budgetSoftLimit},
+		{"no soft limit", domain.AIBudget{MonthlyTokenLimit: 100}, repository.AIUsageTotals{Tokens: 99}, budgetOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monthlyBudgetStatus(&tt.budget, tt.totals); got != tt.want {
+				t.Errorf("monthlyBudgetStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantCapReached(t *testing.T) {
+	capped := &domain.AIBudget{TenantDailyTokenLimit: 5000}
+	if tenantCapReached(capped, repository.AIUsageTotals{Tokens: 4999}) {
+		t.Error("cap reached below the limit")
+	}
+	if !tenantCapReached(capped, repository.AIUsageTotals{Tokens: 5000}) {
+		t.Error("cap not reached at the limit")
+	}
+	if tenantCapReached(&domain.AIBudget{}, repository.AIUsageTotals{Tokens: 1e9}) {
+		t.Error("a zero cap should not limit tenants")
+	}
+}
+
+func TestBudgetUsageSummary(t *testing.T) {
+	budget := &domain.AIBudget{MonthlyTokenLimit: 1000000, MonthlyCostLimit: 25}
+	got := budgetUsageSummary(budget, repository.AIUsageTotals{Tokens: 812345, Cost: 20.456})
+	if want := "812,345 of 1,000,000 tokens and $20.46 of $25.00"; got != want {
+		t.Errorf("budgetUsageSummary() = %q, want %q", got, want)
+	}
+	if got := formatThousands(999); got != "999" {
+		t.Errorf("formatThousands(999) = %q", got)
+	}
+}
+
+func TestQuotaErrorMatches(t *testing.T) {
+	var err error = &QuotaError{Message: "used up", ResetAt: time.Now()}
+	if !errors.Is(fmt.Errorf("query: %w", err), ErrQuotaExceeded) {
+		t.Error("QuotaError does not match ErrQuotaExceeded")
+	}
+}
+
+func TestParseTips(t *testing.T) {
+	valid := `{"tips":[{"title":" Inspect smoke alarms ","body":"Test every alarm twice a year.","category":"safety","difficulty":"Easy","estimated_cost":0}]}`
+	toolUse := func(input string) *llm.Response {
+		return &llm.Response{Content: []llm.Block{{Type: llm.BlockToolUse, ID: "toolu_1", Name: "record_tips", Input: json.RawMessage(input)}}}
+	}
+
+	tests := []struct {
+		name    string
+		resp    *llm.Response
+		wantErr string
+	}{
+		{"tool call", toolUse(valid), ""},
+		{"JSON answered as text", &llm.Response{Content: []llm.Block{{Type: llm.BlockText, Text: "```json\n" + valid + "\n```"}}}, ""},
+		{"plain text", &llm.Response{Content: []llm.Block{{Type: llm.BlockText, Text: "1. Inspect smoke alarms"}}}, "record_tips was not called"},
+		{"malformed JSON", toolUse(`{"tips":[{"title":`), "not valid JSON"},
+		{"no tips", toolUse(`{"tips":[]}`), "no tips were given"},
+		{"missing title", toolUse(`{"tips":[{"body":"b","difficulty":"easy","estimated_cost":0}]}`), "tip 1 has no title"},
+		{"unknown difficulty", toolUse(`{"tips":[{"title":"t","body":"b","difficulty":"trivial","estimated_cost":0}]}`), "difficulty must be one of"},
+		{"negative cost", toolUse(`{"tips":[{"title":"t","body":"b","difficulty":"hard","estimated_cost":-5}]}`), "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tips, err := parseTips(tt.resp, "maintenance")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseTips() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTips() error = %v", err)
+			}
+			want := []PropertyManagementTip{{Title: "Inspect smoke alarms", Body: "Test every alarm twice a year.", Category: "maintenance", Difficulty: "easy"}}
+			if !reflect.DeepEqual(tips, want) {
+				t.Errorf("parseTips() = %+v, want %+v", tips, want)
+			}
+		})
+	}
+}
+
+func TestRequestTipsRetries(t *testing.T) {
+	bad := &llm.Response{
+		Content:    []llm.Block{{Type: llm.BlockToolUse, ID: "toolu_1", Name: "record_tips", Input: json.RawMessage(`{"tips":[{"title":"t","body":"b","difficulty":"hard","estimated_cost":-1}]}`)}},
+		StopReason: llm.StopToolUse,
+		Usage:      llm.Usage{InputTokens: 100, OutputTokens: 20},
+	}
+	good := &llm.Response{
+		Content:    []llm.Block{{Type: llm.BlockToolUse, ID: "toolu_2", Name: "record_tips", Input: json.RawMessage(`{"tips":[{"title":"t","body":"b","difficulty":"hard","estimated_cost":1}]}`)}},
+		StopReason: llm.StopToolUse,
+		Usage:      llm.Usage{InputTokens: 150, OutputTokens: 20},
+	}
+	fake := llm.NewFake().Strict().Reply(bad).Reply(good)
+	s := &AIService{llm: fake}
+
+	tips, usage, err := s.requestTips(context.Background(), "maintenance")
+	if err != nil {
+		t.Fatalf("requestTips() error = %v", err)
+	}
+	if len(tips) != 1 || tips[0].EstimatedCost != 1 {
+		t.Errorf("tips = %+v", tips)
+	}
+	if usage != (llm.Usage{InputTokens: 250, OutputTokens: 40}) {
+		t.Errorf("usage = %+v, want both attempts", usage)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d model requests, want 2", len(requests))
+	}
+	if requests[0].ToolChoice != "record_tips" || len(requests[0].Tools) != 1 {
+		t.Errorf("first request does not force record_tips: %+v", requests[0])
+	}
+	retry := requests[1].Messages
+	if len(retry) != 3 {
+		t.Fatalf("retry has %d messages, want question, bad answer and correction", len(retry))
+	}
+	correction := retry[2].Content[0]
+	if correction.Type != llm.BlockToolResult || correction.ToolUseID != "toolu_1" || !correction.IsError || !strings.Contains(correction.Result, "must not be negative") {
+		t.Errorf("correction = %+v", correction)
+	}
+
+	fake.Reply(bad).Reply(bad).Reply(bad)
+	if _, usage, err := s.requestTips(context.Background(), "maintenance"); err == nil || usage.InputTokens != 300 {
+		t.Errorf("requestTips() = usage %+v, error %v; want an error with every attempt's usage", usage, err)
+	}
+}
+
+func TestTipsCache(t *testing.T) {
+	var c tipsCache
+	now := time.Now()
+	tips := []PropertyManagementTip{{Title: "t"}}
+
+	if _, ok := c.get("maintenance", now); ok {
+		t.Fatal("empty cache returned tips")
+	}
+	c.put("maintenance", tips, now.Add(time.Hour))
+	if got, ok := c.get("maintenance", now); !ok || !reflect.DeepEqual(got, tips) {
+		t.Errorf("get() = %v, %v", got, ok)
+	}
+	if _, ok := c.get("maintenance", now.Add(time.Hour)); ok {
+		t.Error("expired tips returned")
+	}
+
+	for i := 0; i < maxCachedTipCategories+10; i++ {
+		c.put(fmt.Sprintf("category %d", i), tips, now.Add(time.Duration(i+2)*time.Hour))
+	}
+	if len(c.entries) != maxCachedTipCategories {
+		t.Errorf("cache holds %d categories, want %d", len(c.entries), maxCachedTipCategories)
+	}
+	if _, ok := c.get("category 0", now); ok {
+		t.Error("entry closest to expiring was not evicted")
+	}
+}
+
+func TestNormalizeTipCategory(t *testing.T) {
+	if got := normalizeTipCategory("  Tenant   Screening "); got != "tenant screening" {
+		t.Errorf("normalizeTipCategory() = %q", got)
+	}
+}
+
+func TestParseTriage(t *testing.T) {
+	plumber := domain.Contractor{BaseEntity: domain.BaseEntity{ID: uuid.New()}, CompanyName: "Pipes Inc", Specialization: "plumbing"}
+	toolUse := func(input string) *llm.Response {
+		return &llm.Response{Content: []llm.Block{{Type: llm.BlockToolUse, ID: "toolu_1", Name: "record_triage", Input: json.RawMessage(input)}}}
+	}
+	suggestion := func(fields string) *llm.Response {
+		return toolUse(`{"category":"plumbing","estimated_cost_min":150,"estimated_cost_max":400.555,"contractor_id":"","reasoning":"Leaking trap under the sink.",` + fields + `}`)
+	}
+
+	tests := []struct {
+		name          string
+		resp          *llm.Response
+		wantErr       string
+		wantPriority  string
+		wantEmergency bool
+	}{
+		{"routine", suggestion(`"priority":"medium","is_emergency":false,"emergency_reason":"ignored"`), "", "medium", false},
+		{"emergency flag raises priority", suggestion(`"priority":"high","is_emergency":true,"emergency_reason":"Water is flooding the kitchen"`), "", "emergency", true},
+		{"emergency priority sets flag", suggestion(`"priority":"emergency","is_emergency":false,"emergency_reason":"Gas smell"`), "", "emergency", true},
+		{"emergency without reason", suggestion(`"priority":"emergency","is_emergency":true`), "emergency_reason is required", "", false},
+		{"unknown category", toolUse(`{"category":"magic","priority":"low","estimated_cost_min":0,"estimated_cost_max":0,"reasoning":"r"}`), "category must be one of", "", false},
+		{"unknown priority", suggestion(`"priority":"whenever","is_emergency":false`), "priority must be one of", "", false},
+		{"inverted cost range", toolUse(`{"category":"general","priority":"low","estimated_cost_min":500,"estimated_cost_max":100,"reasoning":"r"}`), "must not exceed", "", false},
+		{"missing costs", toolUse(`{"category":"general","priority":"low","reasoning":"r"}`), "are required", "", false},
+		{"missing reasoning", toolUse(`{"category":"general","priority":"low","estimated_cost_min":0,"estimated_cost_max":0}`), "reasoning is required", "", false},
+		{"unknown contractor", toolUse(`{"category":"general","priority":"low","estimated_cost_min":0,"estimated_cost_max":0,"reasoning":"r","contractor_id":"` + uuid.NewString() + `"}`), "not one of the listed contractors", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTriage(tt.resp, []domain.Contractor{plumber})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseTriage() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTriage() error = %v", err)
+			}
+			if got.Priority != tt.wantPriority || got.IsEmergency != tt.wantEmergency {
+				t.Errorf("priority = %q, emergency = %t, want %q, %t", got.Priority, got.IsEmergency, tt.wantPriority, tt.wantEmergency)
+			}
+			if !tt.wantEmergency && got.EmergencyReason != "" {
+				t.Errorf("emergency_reason = %q on a non-emergency", got.EmergencyReason)
+			}
+			if got.Status != domain.TriageStatusPending || *got.EstimatedCostMin != 150 || *got.EstimatedCostMax != 400.56 {
+				t.Errorf("suggestion = %+v", got)
+			}
+		})
+	}
+
+	got, err := parseTriage(toolUse(`{"category":"Plumbing","priority":"low","estimated_cost_min":0,"estimated_cost_max":0,"reasoning":"r","contractor_id":"`+plumber.ID.String()+`"}`), []domain.Contractor{plumber})
+	if err != nil {
+		t.Fatalf("parseTriage() error = %v", err)
+	}
+	if got.Category != "plumbing" || got.ContractorID == nil || *got.ContractorID != plumber.ID {
+		t.Errorf("suggestion = %+v, want plumbing assigned to %s", got, plumber.ID)
+	}
+}
+
+func TestRequestTriage(t *testing.T) {
+	electrician := domain.Contractor{BaseEntity: domain.BaseEntity{ID: uuid.New()}, CompanyName: "Sparks Ltd", Specialization: "electrical", HourlyRate: 85}
+	bad := &llm.Response{
+		Content:    []llm.Block{{Type: llm.BlockToolUse, ID: "toolu_1", Name: "record_triage", Input: json.RawMessage(`{"category":"electrical","priority":"emergency","is_emergency":true,"estimated_cost_min":100,"estimated_cost_max":300,"reasoning":"Sparking outlet."}`)}},
+		StopReason: llm.StopToolUse,
+		Usage:      llm.Usage{InputTokens: 900, OutputTokens: 60},
+	}
+	good := &llm.Response{
+		Content: []llm.Block{{Type: llm.BlockToolUse, ID: "toolu_2", Name: "record_triage", Input: json.RawMessage(`{"category":"electrical","priority":"emergency","is_emergency":true,` +
+			`"emergency_reason":"Sparking outlet is a fire risk","estimated_cost_min":100,"estimated_cost_max":300,"contractor_id":"` + electrician.ID.String() + `","reasoning":"Sparking outlet."}`)}},
+		StopReason: llm.StopToolUse,
+		Usage:      llm.Usage{InputTokens: 1000, OutputTokens: 70},
+	}
+	fake := llm.NewFake().Strict().Reply(bad).Reply(good)
+	s := &AIService{llm: fake}
+
+	request := &domain.MaintenanceRequest{Title: "Outlet sparking", Description: "The kitchen outlet sparks when I plug in the kettle.", Priority: "medium"}
+	photo := llm.Image("image/png", []byte("\x89PNG\r\n\x1a\n"))
+	suggestion, usage, err := s.requestTriage(context.Background(), request, []domain.Contractor{electrician}, []llm.Block{photo})
+	if err != nil {
+		t.Fatalf("requestTriage() error = %v", err)
+	}
+	if !suggestion.IsEmergency || suggestion.ContractorID == nil || *suggestion.ContractorID != electrician.ID {
+		t.Errorf("suggestion = %+v", suggestion)
+	}
+	if usage != (llm.Usage{InputTokens: 1900, OutputTokens: 130}) {
+		t.Errorf("usage = %+v, want both attempts", usage)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d model requests, want 2", len(requests))
+	}
+	first := requests[0]
+	if first.ToolChoice != "record_triage" {
+		t.Errorf("first request does not force record_triage: %+v", first)
+	}
+	content := first.Messages[0].Content
+	if len(content) != 2 || content[1].Type != llm.BlockImage {
+		t.Fatalf("question content = %+v, want text then the photo", content)
+	}
+	for _, want := range []string{"Outlet sparking", "Tenant's priority: medium", "Photos attached: 1", electrician.ID.String(), "$85.00/hour"} {
+		if !strings.Contains(content[0].Text, want) {
+			t.Errorf("question is missing %q:\n%s", want, content[0].Text)
+		}
+	}
+	correction := requests[1].Messages[2].Content[0]
+	if correction.ToolUseID != "toolu_1" || !strings.Contains(correction.Result, "emergency_reason is required") {
+		t.Errorf("correction = %+v", correction)
+	}
+}
+
+func TestTriageQueue(t *testing.T) {
+	var q triageQueue
+	key, other := uuid.New(), uuid.New()
+	runs := make(chan string, 10)
+
+	// Rescheduling a run that has not started replaces it
+	q.schedule(key, time.Hour, func() { runs <- "first" })
+	q.schedule(key, time.Hour, func() { runs <- "second" })
+	q.schedule(other, time.Hour, func() { runs <- "other" })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.stop(ctx); err != nil {
+		t.Fatalf("stop() error = %v", err)
+	}
+	close(runs)
+	var got []string
+	for run := range runs {
+		got = append(got, run)
+	}
+	slices.Sort(got)
+	if !reflect.DeepEqual(got, []string{"other", "second"}) {
+		t.Errorf("runs = %v, want the latest run per key, started by stop()", got)
+	}
+
+	q.schedule(key, 0, func() { t.Error("run scheduled after stop()") })
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestTriageQueueStopWaitsForRunning(t *testing.T) {
+	var q triageQueue
+	started, release := make(chan struct{}), make(chan struct{})
+	q.schedule(uuid.New(), 0, func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("stop() with a run in progress error = %v, want DeadlineExceeded", err)
+	}
+
+	close(release)
+	if err := q.stop(context.Background()); err != nil {
+		t.Errorf("stop() after the run finished error = %v", err)
+	}
+}
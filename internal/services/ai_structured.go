@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"dwell/internal/llm"
+)
+
+// maxStructuredAttempts bounds how many times the model is asked to fill in a
+// schema when it returns malformed output
+const maxStructuredAttempts = 3
+
+// completeStructured sends a request that forces the model to call a tool and
+// passes each response to parse, which extracts and validates the tool input.
+// Responses parse rejects are sent back with the error for the model to
+// correct. The usage of every attempt is returned, even on failure.
+func (s *AIService) completeStructured(ctx context.Context, req *llm.Request, parse func(*llm.Response) error) (llm.Usage, error) {
+	var usage llm.Usage
+	var lastErr error
+	for attempt := 0; attempt < maxStructuredAttempts; attempt++ {
+		resp, err := s.llm.Complete(ctx, req)
+		if err != nil {
+			return usage, err
+		}
+		usage.Add(resp.Usage)
+
+		if lastErr = parse(resp); lastErr == nil {
+			return usage, nil
+		}
+		if len(resp.Content) > 0 {
+			req.Messages = append(req.Messages,
+				llm.Message{Role: llm.RoleAssistant, Content: resp.Content},
+				structuredCorrection(resp, req.ToolChoice, lastErr),
+			)
+		}
+	}
+	return usage, fmt.Errorf("model returned malformed %s output %d times: %w", req.ToolChoice, maxStructuredAttempts, lastErr)
+}
+
+// structuredInput returns the input of a response's call to the named tool.
+// Servers that ignore the forced tool choice may answer with the JSON as text
+// instead.
+func structuredInput(resp *llm.Response, toolName string) (json.RawMessage, error) {
+	for _, b := range resp.Content {
+		if b.Type == llm.BlockToolUse && b.Name == toolName {
+			return b.Input, nil
+		}
+	}
+
+	text := resp.Text()
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%s was not called", toolName)
+	}
+	return json.RawMessage(text[start : end+1]), nil
+}
+
+// structuredCorrection asks the model to call the tool again with the error
+// fixed
+func structuredCorrection(resp *llm.Response, toolName string, err error) llm.Message {
+	instruction := fmt.Sprintf("The %s input could not be used: %v. Call %s again with the problem fixed.", toolName, err, toolName)
+	var results []llm.Block
+	for _, b := range resp.Content {
+		if b.Type == llm.BlockToolUse {
+			results = append(results, llm.Block{Type: llm.BlockToolResult, ToolUseID: b.ID, Result: instruction, IsError: true})
+		}
+	}
+	if len(results) == 0 {
+		return llm.Text(llm.RoleUser, instruction)
+	}
+	return llm.Message{Role: llm.RoleUser, Content: results}
+}
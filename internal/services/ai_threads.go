@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"dwell/internal/domain"
+	"dwell/internal/llm"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxThreadTitleLength bounds titles derived from a thread's first question
+const maxThreadTitleLength = 80
+
+const threadSummaryPrompt = `You condense conversations between DwellAI, a property management assistant, and its user.
+Write a brief summary of the facts, decisions and open questions a later answer would need.
+Reply with the summary only.`
+
+// CreateAIThreadRequest starts a new conversation
+type CreateAIThreadRequest struct {
+	Title string `json:"title" binding:"omitempty,max=255"`
+}
+
+// AIThreadListRequest represents thread list pagination
+type AIThreadListRequest struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// AIThreadListResponse represents a page of the caller's threads, most recently active first
+type AIThreadListResponse struct {
+	Threads []domain.AIThread `json:"threads"`
+	Total   int               `json:"total"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+}
+
+// AIThreadDetailResponse is a thread with its messages, oldest first
+type AIThreadDetailResponse struct {
+	domain.AIThread
+	Messages []domain.AIChatMessage `json:"messages"`
+}
+
+// CreateThread starts a conversation owned by the caller
+func (s *AIService) CreateThread(ctx context.Context, claims *domain.UserClaims, req *CreateAIThreadRequest) (*domain.AIThread, error) {
+	caller, err := s.resolveCaller(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &domain.AIThread{
+		LandlordID: caller.landlordID,
+		TenantID:   caller.tenantID,
+		UserID:     caller.userID,
+		UserType:   caller.userType,
+		Title:      strings.TrimSpace(req.Title),
+	}
+	if err := s.repos.AIThreads.Create(ctx, thread); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// ListThreads returns the caller's threads
+func (s *AIService) ListThreads(ctx context.Context, claims *domain.UserClaims, req *AIThreadListRequest) (*AIThreadListResponse, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	threads, total, err := s.repos.AIThreads.ListByUser(ctx, landlordID, claims.UserID, repository.ListOptions{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = repository.DefaultLimit
+	}
+	return &AIThreadListResponse{
+		Threads: threads,
+		Total:   total,
+		Limit:   limit,
+		Offset:  req.Offset,
+	}, nil
+}
+
+// GetThread returns one of the caller's threads with its messages
+func (s *AIService) GetThread(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*AIThreadDetailResponse, error) {
+	thread, err := s.getOwnThread(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.repos.AIChatMessages.ListByThread(ctx, thread.LandlordID, thread.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &AIThreadDetailResponse{AIThread: *thread, Messages: messages}, nil
+}
+
+// DeleteThread deletes one of the caller's threads and its messages
+func (s *AIService) DeleteThread(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) error {
+	thread, err := s.getOwnThread(ctx, claims, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repos.AIChatMessages.WithTx(tx).DeleteByThread(ctx, thread.LandlordID, thread.ID); err != nil {
+			return err
+		}
+		return s.repos.AIThreads.WithTx(tx).Delete(ctx, thread.LandlordID, thread.ID)
+	})
+}
+
+// PostThreadMessage asks a follow-up question in a thread. Earlier turns are
+// sent with the question; turns that exceed the context budget are folded
+// into the thread summary first.
+func (s *AIService) PostThreadMessage(ctx context.Context, claims *domain.UserClaims, id uuid.UUID, req *AIQueryRequest) (*AIQueryResponse, error) {
+	thread, err := s.getOwnThread(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+	caller := threadCaller(thread, claims)
+	if err := s.enforceBudget(ctx, caller); err != nil {
+		return nil, err
+	}
+	history, err := s.repos.AIChatMessages.ListByThread(ctx, thread.LandlordID, thread.ID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.SummarizedMessages > len(history) {
+		thread.SummarizedMessages = len(history)
+	}
+
+	systemPrompt, err := s.groundedSystemPrompt(ctx, caller, req.Context)
+	if err != nil {
+		return nil, err
+	}
+	budget := s.config.AI.ContextTokenBudget - estimateTokens(systemPrompt) - estimateTokens(req.Question)
+	keepFrom := thread.SummarizedMessages + turnsToDrop(history[thread.SummarizedMessages:], budget)
+
+	// Usage of the summarization call is billed to the message that needed it
+	var usage llm.Usage
+	if keepFrom > thread.SummarizedMessages {
+		summary, summaryUsage, err := s.summarizeTurns(ctx, thread.Summary, history[thread.SummarizedMessages:keepFrom])
+		if err != nil {
+			return nil, err
+		}
+		thread.Summary = summary
+		thread.SummarizedMessages = keepFrom
+		usage = summaryUsage
+	}
+	if thread.Summary != "" {
+		systemPrompt += "\n\nSummary of the earlier conversation:\n" + thread.Summary
+	}
+
+	messages := make([]llm.Message, 0, 2*(len(history)-keepFrom)+1)
+	for _, m := range history[keepFrom:] {
+		messages = append(messages,
+			llm.Text(llm.RoleUser, m.Question),
+			llm.Text(llm.RoleAssistant, m.Answer),
+		)
+	}
+	messages = append(messages, llm.Text(llm.RoleUser, req.Question))
+
+	modelResp, err := s.llm.Complete(ctx, &llm.Request{System: systemPrompt, Messages: messages, MaxTokens: 1000})
+	if err != nil {
+		return nil, err
+	}
+	usage.Add(modelResp.Usage)
+
+	message := &domain.AIChatMessage{
+		LandlordID: thread.LandlordID,
+		TenantID:   thread.TenantID,
+		ThreadID:   &thread.ID,
+		UserType:   thread.UserType,
+		Question:   req.Question,
+		Answer:     modelResp.Text(),
+		ModelUsed:  s.llm.Model(),
+		TokensUsed: usage.Total(),
+		Cost:       s.cost(usage),
+	}
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		// Another message may have been posted to the thread while this one
+		// was answered. Its summary is kept if it covers more turns, and a
+		// title it set is not replaced.
+		threads := s.repos.AIThreads.WithTx(tx)
+		current, err := threads.GetByIDForUpdate(ctx, thread.LandlordID, thread.ID)
+		if err != nil {
+			return err
+		}
+		if current.SummarizedMessages > thread.SummarizedMessages {
+			thread.Summary, thread.SummarizedMessages = current.Summary, current.SummarizedMessages
+		}
+		thread.Title = current.Title
+		if thread.Title == "" {
+			thread.Title = threadTitle(req.Question)
+		}
+
+		if err := s.repos.AIChatMessages.WithTx(tx).Create(ctx, message); err != nil {
+			return err
+		}
+		return threads.Update(ctx, thread)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AIQueryResponse{
+		MessageID:  message.ID,
+		ThreadID:   message.ThreadID,
+		Answer:     message.Answer,
+		ModelUsed:  message.ModelUsed,
+		TokensUsed: message.TokensUsed,
+		Cost:       message.Cost,
+		Confidence: 0.85, // Placeholder - Claude doesn't provide confidence scores
+	}, nil
+}
+
+// getOwnThread returns a thread if it belongs to the caller. Threads are
+// private to the user who started them, even within a landlord account.
+func (s *AIService) getOwnThread(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.AIThread, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	thread, err := s.repos.AIThreads.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+	if thread.UserID != claims.UserID {
+		return nil, ErrNotFound
+	}
+	return thread, nil
+}
+
+// threadCaller identifies the owner of a thread, who is the calling user
+func threadCaller(thread *domain.AIThread, claims *domain.UserClaims) *aiCaller {
+	return &aiCaller{
+		userID:     thread.UserID,
+		userType:   thread.UserType,
+		landlordID: thread.LandlordID,
+		tenantID:   thread.TenantID,
+		claims:     claims,
+	}
+}
+
+// summarizeTurns folds turns into an existing thread summary
+func (s *AIService) summarizeTurns(ctx context.Context, previous string, turns []domain.AIChatMessage) (string, llm.Usage, error) {
+	var b strings.Builder
+	if previous != "" {
+		fmt.Fprintf(&b, "Summary so far:\n%s\n\n", previous)
+	}
+	b.WriteString("Conversation to add to the summary:\n")
+	for _, m := range turns {
+		fmt.Fprintf(&b, "User: %s\nAssistant: %s\n", m.Question, m.Answer)
+	}
+
+	modelResp, err := s.llm.Complete(ctx, &llm.Request{
+		System:    threadSummaryPrompt,
+		Messages:  []llm.Message{llm.Text(llm.RoleUser, b.String())},
+		MaxTokens: 500,
+	})
+	if err != nil {
+		return "", llm.Usage{}, fmt.Errorf("failed to summarize thread: %w", err)
+	}
+	return strings.TrimSpace(modelResp.Text()), modelResp.Usage, nil
+}
+
+// turnsToDrop returns how many of the oldest turns must be dropped for the
+// rest to fit within budget estimated tokens. Newer turns are kept first.
+func turnsToDrop(turns []domain.AIChatMessage, budget int) int {
+	used := 0
+	for i := len(turns) - 1; i >= 0; i-- {
+		used += estimateTokens(turns[i].Question) + estimateTokens(turns[i].Answer)
+		if used > budget {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// estimateTokens approximates the token count of English text at four
+// characters per token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// threadTitle derives a title from a thread's first question
+func threadTitle(question string) string {
+	title := strings.Join(strings.Fields(question), " ")
+	runes := []rune(title)
+	if len(runes) <= maxThreadTitleLength {
+		return title
+	}
+	title = string(runes[:maxThreadTitleLength])
+	if cut := strings.LastIndex(title, " "); cut > 0 {
+		title = title[:cut]
+	}
+	return title + "…"
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+	"dwell/internal/llm"
+)
+
+const (
+	// tipCount is how many tips are asked for per category; the record_tips
+	// schema allows no more
+	tipCount = 5
+	// maxTipCategoryLength bounds the category, which is also the cache key
+	maxTipCategoryLength = 50
+	// maxCachedTipCategories bounds the tips cache
+	maxCachedTipCategories = 100
+)
+
+// tipDifficulties are the accepted tip difficulties, easiest first
+var tipDifficulties = []string{"easy", "moderate", "hard"}
+
+// PropertyManagementTip is one piece of property management advice
+type PropertyManagementTip struct {
+	Title         string  `json:"title"`
+	Body          string  `json:"body"`
+	Category      string  `json:"category"`
+	Difficulty    string  `json:"difficulty"`     // easy, moderate or hard
+	EstimatedCost float64 `json:"estimated_cost"` // rough cost in USD, 0 if free
+}
+
+// recordTipsTool is the schema the model fills in with its tips
+var recordTipsTool = llm.Tool{
+	Name:        "record_tips",
+	Description: "Record the property management tips for the requested category.",
+	InputSchema: json.RawMessage(`{"type":"object","properties":{"tips":{"type":"array","minItems":1,"maxItems":5,"items":{"type":"object","properties":{` +
+		`"title":{"type":"string","maxLength":100,"description":"Short, actionable headline"},` +
+		`"body":{"type":"string","description":"One to three sentences explaining what to do and why"},` +
+		`"category":{"type":"string","description":"The requested category"},` +
+		`"difficulty":{"type":"string","enum":["easy","moderate","hard"]},` +
+		`"estimated_cost":{"type":"number","minimum":0,"description":"Rough cost to carry out in US dollars, 0 if free"}},` +
+		`"required":["title","body","category","difficulty","estimated_cost"]}}},"required":["tips"]}`),
+}
+
+// tipsCache holds generated tips per category. Tips are general advice, not
+// account data, so they are shared by every landlord.
+type tipsCache struct {
+	mu      sync.Mutex
+	entries map[string]tipsCacheEntry
+}
+
+type tipsCacheEntry struct {
+	tips    []PropertyManagementTip
+	expires time.Time
+}
+
+// GetPropertyManagementTips returns AI-generated tips for a property
+// management category. Tips are cached per category for the configured TTL,
+// and cached tips do not count against the AI budget.
+func (s *AIService) GetPropertyManagementTips(ctx context.Context, claims *domain.UserClaims, category string) ([]PropertyManagementTip, error) {
+	landlordID, err := authorize(claims, authz.ResourceAITips, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+	category = normalizeTipCategory(category)
+	if category == "" {
+		return nil, newValidationError("category is required")
+	}
+	if len(category) > maxTipCategoryLength {
+		return nil, newValidationError(fmt.Sprintf("category must be at most %d characters", maxTipCategoryLength))
+	}
+
+	if tips, ok := s.tipsCache.get(category, time.Now()); ok {
+		return tips, nil
+	}
+
+	caller := &aiCaller{userID: claims.UserID, userType: "landlord", landlordID: landlordID, claims: claims}
+	if err := s.enforceBudget(ctx, caller); err != nil {
+		return nil, err
+	}
+
+	question := tipsQuestion(category)
+	tips, usage, err := s.requestTips(ctx, category)
+	if usage.Total() > 0 {
+		// Failed attempts were still paid for, so they count against the budget
+		message := &domain.AIChatMessage{
+			LandlordID: landlordID,
+			UserType:   caller.userType,
+			Question:   question,
+			Answer:     renderTips(tips),
+			ModelUsed:  s.llm.Model(),
+			TokensUsed: usage.Total(),
+			Cost:       s.cost(usage),
+		}
+		if createErr := s.repos.AIChatMessages.Create(context.WithoutCancel(ctx), message); createErr != nil && err == nil {
+			return nil, createErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl := s.config.AI.TipsCacheTTL; ttl > 0 {
+		s.tipsCache.put(category, tips, time.Now().Add(ttl))
+	}
+	return tips, nil
+}
+
+// requestTips asks the model to fill in the record_tips schema. The usage of
+// every attempt is returned, even on failure.
+func (s *AIService) requestTips(ctx context.Context, category string) ([]PropertyManagementTip, llm.Usage, error) {
+	req := &llm.Request{
+		System:     s.buildSystemPrompt("landlord", "Category: "+category),
+		Messages:   []llm.Message{llm.Text(llm.RoleUser, tipsQuestion(category))},
+		Tools:      []llm.Tool{recordTipsTool},
+		ToolChoice: recordTipsTool.Name,
+		MaxTokens:  1500,
+	}
+
+	var tips []PropertyManagementTip
+	usage, err := s.completeStructured(ctx, req, func(resp *llm.Response) error {
+		var err error
+		tips, err = parseTips(resp, category)
+		return err
+	})
+	if err != nil {
+		return nil, usage, err
+	}
+	return tips, usage, nil
+}
+
+// tipsQuestion is the request sent to the model for a category
+func tipsQuestion(category string) string {
+	return fmt.Sprintf("Provide %d practical tips for %s in property management. Keep each tip concise and actionable.", tipCount, category)
+}
+
+// parseTips extracts and validates the tips in a model response
+func parseTips(resp *llm.Response, category string) ([]PropertyManagementTip, error) {
+	input, err := structuredInput(resp, recordTipsTool.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Tips []PropertyManagementTip `json:"tips"`
+	}
+	if err := json.Unmarshal(input, &out); err != nil {
+		return nil, fmt.Errorf("tips are not valid JSON: %v", err)
+	}
+	if len(out.Tips) == 0 {
+		return nil, errors.New("no tips were given")
+	}
+	if len(out.Tips) > tipCount {
+		out.Tips = out.Tips[:tipCount]
+	}
+
+	for i := range out.Tips {
+		tip := &out.Tips[i]
+		tip.Title = strings.TrimSpace(tip.Title)
+		tip.Body = strings.TrimSpace(tip.Body)
+		tip.Difficulty = strings.ToLower(strings.TrimSpace(tip.Difficulty))
+		tip.Category = category
+
+		switch {
+		case tip.Title == "":
+			return nil, fmt.Errorf("tip %d has no title", i+1)
+		case len(tip.Title) > 100:
+			return nil, fmt.Errorf("tip %d title is longer than 100 characters", i+1)
+		case tip.Body == "":
+			return nil, fmt.Errorf("tip %d has no body", i+1)
+		case !slices.Contains(tipDifficulties, tip.Difficulty):
+			return nil, fmt.Errorf("tip %d difficulty must be one of %s", i+1, strings.Join(tipDifficulties, ", "))
+		case tip.EstimatedCost < 0:
+			return nil, fmt.Errorf("tip %d estimated_cost must not be negative", i+1)
+		}
+	}
+	return out.Tips, nil
+}
+
+// renderTips formats tips as a numbered list for the chat history
+func renderTips(tips []PropertyManagementTip) string {
+	lines := make([]string, len(tips))
+	for i, tip := range tips {
+		lines[i] = fmt.Sprintf("%d. %s: %s", i+1, tip.Title, tip.Body)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// normalizeTipCategory lower-cases a category and collapses its whitespace, so
+// equivalent categories share a cache entry
+func normalizeTipCategory(category string) string {
+	return strings.ToLower(strings.Join(strings.Fields(category), " "))
+}
+
+// get returns unexpired tips for a category
+func (c *tipsCache) get(category string, now time.Time) ([]PropertyManagementTip, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[category]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	return entry.tips, true
+}
+
+// put caches tips for a category. When the cache is full, expired entries are
+// dropped first, then the entry closest to expiring.
+func (c *tipsCache) put(category string, tips []PropertyManagementTip, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]tipsCacheEntry)
+	}
+
+	if _, ok := c.entries[category]; !ok && len(c.entries) >= maxCachedTipCategories {
+		now := time.Now()
+		var oldest string
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			} else if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+				oldest = key
+			}
+		}
+		if len(c.entries) >= maxCachedTipCategories {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[category] = tipsCacheEntry{tips: tips, expires: expires}
+}
@@ -0,0 +1,623 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+	"dwell/internal/llm"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxToolRounds bounds how many times one question may go back to the
+	// model with tool results
+	maxToolRounds = 5
+	// toolActionExpiry is how long a proposed action waits for confirmation
+	toolActionExpiry = 24 * time.Hour
+	// toolSearchLimit caps the tenants returned by find_tenants
+	toolSearchLimit = 10
+)
+
+const toolPrompt = `You can call tools to look up or act on the user's account. Tools that change data or contact someone are not run straight away: the user is shown the action and must confirm it, so tell them what you have prepared and that it is waiting for their confirmation rather than saying it is done.`
+
+// aiTool is an operation the assistant may call on the user's behalf. Tools
+// run through the same services as the REST API with the user's own claims,
+// so they are subject to the same access rules. A tool is only offered to
+// roles the policy grants its action on its resource.
+type aiTool struct {
+	llm.Tool
+	resource authz.Resource
+	action   authz.Action
+	// confirm marks tools that change data or contact someone. They are
+	// validated and stored as pending actions, and only run once the user
+	// confirms them.
+	confirm  bool
+	validate func(input json.RawMessage) error
+	run      func(ctx context.Context, s *AIService, claims *domain.UserClaims, input json.RawMessage) (interface{}, error)
+}
+
+// AIActionListRequest represents assistant action filters and pagination
+type AIActionListRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending_confirmation confirmed executed failed rejected expired"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// AIActionListResponse represents a page of assistant tool invocations, newest first
+type AIActionListResponse struct {
+	Actions []domain.AIToolInvocation `json:"actions"`
+	Total   int                       `json:"total"`
+	Limit   int                       `json:"limit"`
+	Offset  int                       `json:"offset"`
+}
+
+type findTenantsInput struct {
+	Query string `json:"query"`
+}
+
+type paymentBalanceInput struct {
+	TenantID *uuid.UUID `json:"tenant_id"`
+}
+
+type maintenanceToolInput struct {
+	PropertyID  *uuid.UUID `json:"property_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	Category    string     `json:"category"`
+}
+
+type tenantNotificationInput struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message"`
+}
+
+var aiTools = []aiTool{
+	{
+		Tool: llm.Tool{
+			Name:        "find_tenants",
+			Description: "Search the landlord's tenants by name or email. Returns tenant IDs for use with other tools.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"query":{"type":"string","description":"Part of the tenant's name or email"}},"required":["query"]}`),
+		},
+		resource: authz.ResourceTenants,
+		action:   authz.ActionRead,
+		validate: validateFindTenants,
+		run:      runFindTenants,
+	},
+	{
+		Tool: llm.Tool{
+			Name:        "get_payment_balance",
+			Description: "Look up a tenant's total charged, total paid and outstanding balance. Tenants always get their own balance; landlords must give tenant_id.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"tenant_id":{"type":"string","format":"uuid","description":"Tenant to look up (landlords only)"}}}`),
+		},
+		resource: authz.ResourcePayments,
+		action:   authz.ActionRead,
+		validate: validatePaymentBalance,
+		run:      runPaymentBalance,
+	},
+	{
+		Tool: llm.Tool{
+			Name:        "create_maintenance_request",
+			Description: "File a maintenance request. Tenants file against the property they rent; landlords must give property_id. The user confirms the request before it is filed.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"property_id":{"type":"string","format":"uuid","description":"Property the request is for (landlords only)"},"title":{"type":"string","maxLength":255},"description":{"type":"string"},"priority":{"type":"string","enum":["low","medium","high","emergency"]},"category":{"type":"string","maxLength":50,"description":"For example plumbing, electrical or appliance"}},"required":["title","description"]}`),
+		},
+		resource: authz.ResourceMaintenance,
+		action:   authz.ActionCreate,
+		confirm:  true,
+		validate: validateMaintenanceTool,
+		run:      runMaintenanceTool,
+	},
+	{
+		Tool: llm.Tool{
+			Name:        "draft_tenant_notification",
+			Description: "Draft an email to one of the landlord's tenants. The landlord reviews and confirms the draft before it is sent.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"tenant_id":{"type":"string","format":"uuid"},"title":{"type":"string","maxLength":255,"description":"Email subject"},"message":{"type":"string","description":"Email body"}},"required":["tenant_id","title","message"]}`),
+		},
+		resource: authz.ResourceTenantMessages,
+		action:   authz.ActionCreate,
+		confirm:  true,
+		validate: validateTenantNotification,
+		run:      runTenantNotification,
+	},
+}
+
+// toolsFor returns the definitions of the tools a role may call
+func toolsFor(role string) []llm.Tool {
+	var defs []llm.Tool
+	for i := range aiTools {
+		if aiTools[i].allows(role) {
+			defs = append(defs, aiTools[i].Tool)
+		}
+	}
+	return defs
+}
+
+// findTool returns the named tool if the role may call it
+func findTool(name, role string) *aiTool {
+	for i := range aiTools {
+		if aiTools[i].Name == name && aiTools[i].allows(role) {
+			return &aiTools[i]
+		}
+	}
+	return nil
+}
+
+func (t *aiTool) allows(role string) bool {
+	return authz.Allowed(role, t.resource, t.action)
+}
+
+// completeWithTools sends req to the model, running each tool it asks for
+// with call and sending the results back. Once maxToolRounds rounds of tools
+// have run the model is told to answer without them, so a question always
+// ends in an answer rather than a pending tool call.
+func (s *AIService) completeWithTools(ctx context.Context, req *llm.Request, call func(block llm.Block) llm.Block) (*llm.Response, llm.Usage, error) {
+	var usage llm.Usage
+	for round := 1; ; round++ {
+		resp, err := s.llm.Complete(ctx, req)
+		if err != nil {
+			return nil, usage, err
+		}
+		usage.Add(resp.Usage)
+		if resp.StopReason != llm.StopToolUse {
+			return resp, usage, nil
+		}
+		if round > maxToolRounds {
+			return nil, usage, fmt.Errorf("model kept calling tools after %d rounds", maxToolRounds)
+		}
+
+		var results []llm.Block
+		for _, block := range resp.Content {
+			if block.Type == llm.BlockToolUse {
+				results = append(results, call(block))
+			}
+		}
+		req.Messages = append(req.Messages,
+			llm.Message{Role: llm.RoleAssistant, Content: resp.Content},
+			llm.Message{Role: llm.RoleUser, Content: results},
+		)
+		if round == maxToolRounds {
+			req.ToolChoice = llm.ToolChoiceNone
+		}
+	}
+}
+
+// queryWithTools answers a question, letting the model call tools until it
+// produces an answer. The message and every tool invocation are stored
+// together.
+func (s *AIService) queryWithTools(ctx context.Context, claims *domain.UserClaims, caller *aiCaller, systemPrompt string, req *AIQueryRequest) (*AIQueryResponse, error) {
+	tools := toolsFor(authz.RoleOf(claims))
+	if len(tools) > 0 {
+		systemPrompt += "\n\n" + toolPrompt
+	}
+
+	message := &domain.AIChatMessage{
+		BaseEntity: domain.BaseEntity{ID: uuid.New()},
+		LandlordID: caller.landlordID,
+		TenantID:   caller.tenantID,
+		UserType:   caller.userType,
+		Question:   req.Question,
+		ModelUsed:  s.llm.Model(),
+	}
+
+	modelReq := &llm.Request{
+		System:    systemPrompt,
+		Messages:  []llm.Message{llm.Text(llm.RoleUser, fmt.Sprintf("Question: %s", req.Question))},
+		Tools:     tools,
+		MaxTokens: 1000,
+	}
+	var invocations []domain.AIToolInvocation
+	modelResp, usage, err := s.completeWithTools(ctx, modelReq, func(block llm.Block) llm.Block {
+		invocation, result := s.callTool(ctx, claims, caller, message.ID, block)
+		invocations = append(invocations, *invocation)
+		return result
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message.Answer = modelResp.Text()
+	message.TokensUsed = usage.Total()
+	message.Cost = s.cost(usage)
+
+	// As with plain questions, the answer is recorded even if the caller has
+	// gone away, and tool calls are never recorded without their message
+	persistCtx := context.WithoutCancel(ctx)
+	err = s.repos.RunInTx(persistCtx, func(tx *sql.Tx) error {
+		if err := s.repos.AIChatMessages.WithTx(tx).Create(persistCtx, message); err != nil {
+			return err
+		}
+		invocationRepo := s.repos.AIToolInvocations.WithTx(tx)
+		for i := range invocations {
+			if err := invocationRepo.Create(persistCtx, &invocations[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &AIQueryResponse{
+		MessageID:  message.ID,
+		Answer:     message.Answer,
+		ModelUsed:  message.ModelUsed,
+		TokensUsed: message.TokensUsed,
+		Cost:       message.Cost,
+		Confidence: 0.85, // Placeholder - Claude doesn't provide confidence scores
+	}
+	for _, inv := range invocations {
+		if inv.Status == domain.AIToolStatusPending {
+			resp.Actions = append(resp.Actions, inv)
+		}
+	}
+	return resp, nil
+}
+
+// callTool handles one tool_use block. Read-only tools run immediately;
+// tools that need confirmation are validated and left pending. It returns
+// the audit record and the tool_result to send back to the model.
+func (s *AIService) callTool(ctx context.Context, claims *domain.UserClaims, caller *aiCaller, messageID uuid.UUID, block llm.Block) (*domain.AIToolInvocation, llm.Block) {
+	invocation := &domain.AIToolInvocation{
+		BaseEntity: domain.BaseEntity{ID: uuid.New()},
+		LandlordID: caller.landlordID,
+		MessageID:  messageID,
+		UserID:     caller.userID,
+		UserType:   caller.userType,
+		ToolName:   block.Name,
+		Input:      block.Input,
+	}
+
+	var result interface{}
+	var err error
+	role := authz.RoleOf(claims)
+	tool := findTool(block.Name, role)
+	switch {
+	case tool == nil:
+		err = fmt.Errorf("%w: tool %s is not available to the %s role", ErrForbidden, block.Name, role)
+	case tool.confirm:
+		if err = tool.validate(block.Input); err == nil {
+			invocation.Status = domain.AIToolStatusPending
+			result = map[string]interface{}{
+				"status":    domain.AIToolStatusPending,
+				"action_id": invocation.ID,
+			}
+		}
+	default:
+		if err = tool.validate(block.Input); err == nil {
+			result, err = tool.run(ctx, s, claims, block.Input)
+		}
+		if err == nil {
+			invocation.Status = domain.AIToolStatusExecuted
+			invocation.Result, err = json.Marshal(result)
+		}
+	}
+
+	if err != nil {
+		invocation.Status = domain.AIToolStatusFailed
+		invocation.Error = err.Error()
+		return invocation, llm.Block{Type: llm.BlockToolResult, ToolUseID: block.ID, Result: toolErrorMessage(err), IsError: true}
+	}
+	encoded := invocation.Result
+	if encoded == nil {
+		encoded, _ = json.Marshal(result)
+	}
+	return invocation, llm.Block{Type: llm.BlockToolResult, ToolUseID: block.ID, Result: string(encoded)}
+}
+
+// toolErrorMessage describes a tool failure to the model without exposing
+// internal errors
+func toolErrorMessage(err error) string {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr), errors.Is(err, ErrForbidden), errors.Is(err, ErrConflict):
+		return err.Error()
+	case errors.Is(err, ErrNotFound):
+		return "not found"
+	default:
+		return "the tool failed; try again later"
+	}
+}
+
+// ListActions returns assistant tool invocations visible to the caller.
+// Callers allowed to read the account's AI usage see every invocation under
+// the account; everyone else only their own.
+func (s *AIService) ListActions(ctx context.Context, claims *domain.UserClaims, req *AIActionListRequest) (*AIActionListResponse, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.AIToolInvocationFilter{LandlordID: landlordID, Status: req.Status}
+	if !authz.Allowed(authz.RoleOf(claims), authz.ResourceAIUsage, authz.ActionRead) {
+		filter.UserID = claims.UserID
+	}
+
+	actions, total, err := s.repos.AIToolInvocations.List(ctx, filter, repository.ListOptions{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = repository.DefaultLimit
+	}
+	return &AIActionListResponse{
+		Actions: actions,
+		Total:   total,
+		Limit:   limit,
+		Offset:  req.Offset,
+	}, nil
+}
+
+// ConfirmAction runs an action the assistant proposed to the caller. The
+// outcome, including a failure, is recorded on the action.
+func (s *AIService) ConfirmAction(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.AIToolInvocation, error) {
+	action, err := s.getPendingAction(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+	role := authz.RoleOf(claims)
+	tool := findTool(action.ToolName, role)
+	if tool == nil {
+		return nil, fmt.Errorf("%w: tool %s is not available to the %s role", ErrForbidden, action.ToolName, role)
+	}
+
+	// Claim the action first so a double submit cannot run it twice
+	now := time.Now()
+	action.Status = domain.AIToolStatusConfirmed
+	action.ConfirmedAt = &now
+	if err := s.transitionAction(ctx, action, domain.AIToolStatusPending); err != nil {
+		return nil, err
+	}
+
+	result, runErr := tool.run(ctx, s, claims, action.Input)
+	if runErr == nil {
+		action.Result, runErr = json.Marshal(result)
+	}
+	if runErr != nil {
+		action.Status = domain.AIToolStatusFailed
+		action.Error = runErr.Error()
+	} else {
+		action.Status = domain.AIToolStatusExecuted
+	}
+	if err := s.transitionAction(context.WithoutCancel(ctx), action, domain.AIToolStatusConfirmed); err != nil {
+		return nil, err
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+	return action, nil
+}
+
+// RejectAction discards an action the assistant proposed to the caller
+func (s *AIService) RejectAction(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.AIToolInvocation, error) {
+	action, err := s.getPendingAction(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+	action.Status = domain.AIToolStatusRejected
+	if err := s.transitionAction(ctx, action, domain.AIToolStatusPending); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// getPendingAction returns an action awaiting the caller's confirmation.
+// Actions can only be confirmed or rejected by the user they were proposed
+// to; stale actions are marked expired.
+func (s *AIService) getPendingAction(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.AIToolInvocation, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := s.repos.AIToolInvocations.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+	if action.UserID != claims.UserID {
+		return nil, ErrNotFound
+	}
+	if action.Status != domain.AIToolStatusPending {
+		return nil, fmt.Errorf("%w: action is already %s", ErrConflict, action.Status)
+	}
+	if time.Since(action.CreatedAt) > toolActionExpiry {
+		action.Status = domain.AIToolStatusExpired
+		if err := s.transitionAction(ctx, action, domain.AIToolStatusPending); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: action has expired", ErrConflict)
+	}
+	return action, nil
+}
+
+// transitionAction stores an action's new status if it is still in the from
+// status
+func (s *AIService) transitionAction(ctx context.Context, action *domain.AIToolInvocation, from string) error {
+	err := s.repos.AIToolInvocations.Transition(ctx, action, from)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: action is no longer %s", ErrConflict, from)
+	}
+	return err
+}
+
+// decodeToolInput unmarshals tool input, reporting malformed input as a
+// validation error the model can correct
+func decodeToolInput(input json.RawMessage, v interface{}) error {
+	if len(input) == 0 {
+		input = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(input, v); err != nil {
+		return newValidationError(fmt.Sprintf("invalid tool input: %v", err))
+	}
+	return nil
+}
+
+func validateFindTenants(input json.RawMessage) error {
+	var in findTenantsInput
+	if err := decodeToolInput(input, &in); err != nil {
+		return err
+	}
+	if strings.TrimSpace(in.Query) == "" {
+		return newValidationError("query is required")
+	}
+	return nil
+}
+
+func runFindTenants(ctx context.Context, s *AIService, claims *domain.UserClaims, input json.RawMessage) (interface{}, error) {
+	var in findTenantsInput
+	if err := decodeToolInput(input, &in); err != nil {
+		return nil, err
+	}
+	page, err := s.tenantService.ListTenants(ctx, claims, &TenantListRequest{Search: in.Query, Limit: toolSearchLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	type tenantMatch struct {
+		ID       uuid.UUID `json:"id"`
+		Name     string    `json:"name"`
+		Email    string    `json:"email"`
+		IsActive bool      `json:"is_active"`
+	}
+	matches := make([]tenantMatch, 0, len(page.Tenants))
+	for _, t := range page.Tenants {
+		matches = append(matches, tenantMatch{ID: t.ID, Name: t.FirstName + " " + t.LastName, Email: t.Email, IsActive: t.IsActive})
+	}
+	return map[string]interface{}{"tenants": matches, "total": page.Total}, nil
+}
+
+func validatePaymentBalance(input json.RawMessage) error {
+	var in paymentBalanceInput
+	return decodeToolInput(input, &in)
+}
+
+func runPaymentBalance(ctx context.Context, s *AIService, claims *domain.UserClaims, input json.RawMessage) (interface{}, error) {
+	var in paymentBalanceInput
+	if err := decodeToolInput(input, &in); err != nil {
+		return nil, err
+	}
+	ledger, err := s.paymentService.GetLedger(ctx, claims, in.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"tenant_id":     ledger.TenantID,
+		"total_charged": ledger.TotalCharged,
+		"total_paid":    ledger.TotalPaid,
+		"balance":       ledger.Balance,
+	}, nil
+}
+
+func validateMaintenanceTool(input json.RawMessage) error {
+	var in maintenanceToolInput
+	if err := decodeToolInput(input, &in); err != nil {
+		return err
+	}
+	switch {
+	case strings.TrimSpace(in.Title) == "":
+		return newValidationError("title is required")
+	case len(in.Title) > 255:
+		return newValidationError("title must be at most 255 characters")
+	case strings.TrimSpace(in.Description) == "":
+		return newValidationError("description is required")
+	case len(in.Category) > 50:
+		return newValidationError("category must be at most 50 characters")
+	}
+	switch in.Priority {
+	case "", "low", "medium", "high", "emergency":
+		return nil
+	default:
+		return newValidationError("priority must be one of low, medium, high or emergency")
+	}
+}
+
+func runMaintenanceTool(ctx context.Context, s *AIService, claims *domain.UserClaims, input json.RawMessage) (interface{}, error) {
+	if err := validateMaintenanceTool(input); err != nil {
+		return nil, err
+	}
+	var in maintenanceToolInput
+	if err := decodeToolInput(input, &in); err != nil {
+		return nil, err
+	}
+	detail, err := s.maintenanceService.CreateRequest(ctx, claims, &CreateMaintenanceRequest{
+		PropertyID:  in.PropertyID,
+		Title:       strings.TrimSpace(in.Title),
+		Description: strings.TrimSpace(in.Description),
+		Priority:    in.Priority,
+		Category:    in.Category,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"maintenance_request_id": detail.Request.ID,
+		"status":                 detail.Request.Status,
+		"priority":               detail.Request.Priority,
+	}, nil
+}
+
+func validateTenantNotification(input json.RawMessage) error {
+	var in tenantNotificationInput
+	if err := decodeToolInput(input, &in); err != nil {
+		return err
+	}
+	switch {
+	case in.TenantID == uuid.Nil:
+		return newValidationError("tenant_id is required")
+	case strings.TrimSpace(in.Title) == "":
+		return newValidationError("title is required")
+	case len(in.Title) > 255:
+		return newValidationError("title must be at most 255 characters")
+	case strings.TrimSpace(in.Message) == "":
+		return newValidationError("message is required")
+	}
+	return nil
+}
+
+func runTenantNotification(ctx context.Context, s *AIService, claims *domain.UserClaims, input json.RawMessage) (interface{}, error) {
+	if err := validateTenantNotification(input); err != nil {
+		return nil, err
+	}
+	if err := authz.Authorize(claims, authz.ResourceTenantMessages, authz.ActionCreate); err != nil {
+		return nil, err
+	}
+	var in tenantNotificationInput
+	if err := decodeToolInput(input, &in); err != nil {
+		return nil, err
+	}
+	detail, err := s.tenantService.GetTenant(ctx, claims, in.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	tenant := detail.Tenant
+
+	sent, err := s.notificationService.SendNotification(ctx, &NotificationRequest{
+		Type:           "landlord_message",
+		Title:          strings.TrimSpace(in.Title),
+		Message:        strings.TrimSpace(in.Message),
+		LandlordID:     tenant.LandlordID.String(),
+		RecipientID:    tenant.ID.String(),
+		RecipientType:  "tenant",
+		RecipientEmail: tenant.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"notification_id": sent.NotificationID,
+		"status":          sent.Status,
+		"recipient_email": tenant.Email,
+	}, nil
+}
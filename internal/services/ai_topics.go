@@ -0,0 +1,119 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// topicStopwords are common words that say nothing about what a question is about
+var topicStopwords = map[string]bool{
+	"about": true, "after": true, "also": true, "and": true, "any": true, "are": true,
+	"been": true, "before": true, "but": true, "can": true, "could": true, "did": true,
+	"does": true, "doe": true, "for": true, "from": true, "get": true, "has": true,
+	"have": true, "her": true, "his": true, "how": true, "into": true, "its": true,
+	"just": true, "know": true, "like": true, "make": true, "may": true, "more": true,
+	"much": true, "must": true, "need": true, "not": true, "now": true, "our": true,
+	"out": true, "please": true, "should": true, "some": true, "tell": true, "than": true,
+	"that": true, "the": true, "their": true, "them": true, "then": true, "there": true,
+	"they": true, "thi": true, "this": true, "want": true, "was": true, "way": true,
+	"were": true, "what": true, "when": true, "where": true, "which": true, "who": true,
+	"why": true, "will": true, "with": true, "would": true, "you": true, "your": true,
+}
+
+// popularTopics groups questions by shared keywords and returns labels for the
+// n largest groups. Each question joins the group of its most common keyword
+// across all questions; a group's label adds the keyword most of its members
+// share, so "late rent fee" and "rent due date" questions end up under "rent".
+func popularTopics(questions []string, n int) []string {
+	keywords := make([][]string, len(questions))
+	docFreq := map[string]int{}
+	for i, q := range questions {
+		keywords[i] = topicKeywords(q)
+		for _, k := range keywords[i] {
+			docFreq[k]++
+		}
+	}
+
+	type cluster struct {
+		label   string
+		members [][]string
+	}
+	clusters := map[string]*cluster{}
+	for _, words := range keywords {
+		if len(words) == 0 {
+			continue
+		}
+		best := words[0]
+		for _, k := range words[1:] {
+			if docFreq[k] > docFreq[best] || (docFreq[k] == docFreq[best] && k < best) {
+				best = k
+			}
+		}
+		c, ok := clusters[best]
+		if !ok {
+			c = &cluster{label: best}
+			clusters[best] = c
+		}
+		c.members = append(c.members, words)
+	}
+
+	ranked := make([]*cluster, 0, len(clusters))
+	for _, c := range clusters {
+		// Qualify the label with a second keyword shared by most of the group
+		if len(c.members) > 1 {
+			counts := map[string]int{}
+			for _, words := range c.members {
+				for _, k := range words {
+					if k != c.label {
+						counts[k]++
+					}
+				}
+			}
+			second, secondCount := "", 0
+			for k, count := range counts {
+				if count > secondCount || (count == secondCount && k < second) {
+					second, secondCount = k, count
+				}
+			}
+			if secondCount*2 > len(c.members) {
+				c.label += " " + second
+			}
+		}
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if len(ranked[i].members) != len(ranked[j].members) {
+			return len(ranked[i].members) > len(ranked[j].members)
+		}
+		return ranked[i].label < ranked[j].label
+	})
+
+	topics := []string{}
+	for i := 0; i < len(ranked) && i < n; i++ {
+		topics = append(topics, ranked[i].label)
+	}
+	return topics
+}
+
+// topicKeywords returns the distinct, normalized content words of a question
+func topicKeywords(question string) []string {
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	seen := map[string]bool{}
+	keywords := []string{}
+	for _, w := range words {
+		// Fold simple plurals so "leases" and "lease" cluster together
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			w = strings.TrimSuffix(w, "s")
+		}
+		if len(w) < 3 || topicStopwords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		keywords = append(keywords, w)
+	}
+	return keywords
+}
@@ -2,23 +2,24 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"log"
 
-	"dwell/internal/aws"
+	"dwell/internal/auth"
 	"dwell/internal/config"
 	"dwell/internal/domain"
 
-	awssdk "github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
-	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// AuthService manages accounts through the configured identity provider
 type AuthService struct {
-	awsClients *aws.Clients
-	config     *config.Config
+	identity          auth.Provider
+	config            *config.Config
+	tenantService     *TenantService
+	contractorService *ContractorService
+	teamService       *TeamService
 }
 
 type AuthRequest struct {
@@ -42,7 +43,8 @@ type SignUpRequest struct {
 	LastName    string `json:"last_name" binding:"required"`
 	Phone       string `json:"phone"`
 	CompanyName string `json:"company_name"`
-	UserType    string `json:"user_type" binding:"required,oneof=landlord tenant"`
+	UserType    string `json:"user_type" binding:"required,oneof=landlord tenant contractor"`
+	InviteCode  string `json:"invite_code,omitempty"` // links the signup to the invited tenant, contractor or team member; required for contractors
 }
 
 type SignUpResponse struct {
@@ -52,229 +54,217 @@ type SignUpResponse struct {
 	ConfirmCode string `json:"confirm_code,omitempty"`
 }
 
-func NewAuthService(awsClients *aws.Clients, config *config.Config) *AuthService {
+func NewAuthService(identity auth.Provider, config *config.Config) *AuthService {
 	return &AuthService{
-		awsClients: awsClients,
-		config:     config,
+		identity: identity,
+		config:   config,
 	}
 }
 
-// SignUp creates a new user account in Cognito
+// SetTenantService enables tenant invitation codes on signup
+func (s *AuthService) SetTenantService(tenantService *TenantService) {
+	s.tenantService = tenantService
+}
+
+// SetContractorService enables contractor signups, which require an invitation code
+func (s *AuthService) SetContractorService(contractorService *ContractorService) {
+	s.contractorService = contractorService
+}
+
+// SetTeamService enables staff invitation codes on landlord signups and
+// applies team memberships when tokens are validated
+func (s *AuthService) SetTeamService(teamService *TeamService) {
+	s.teamService = teamService
+}
+
+// SignUp creates a new user account with the identity provider
 func (s *AuthService) SignUp(ctx context.Context, req *SignUpRequest) (*SignUpResponse, error) {
-	// Prepare Cognito signup request
-	signUpInput := &cognitoidentityprovider.SignUpInput{
-		ClientId: awssdk.String(s.config.AWS.Cognito.ClientID),
-		Username: awssdk.String(req.Email),
-		Password: awssdk.String(req.Password),
-		UserAttributes: []types.AttributeType{
-			{
-				Name:  awssdk.String("email"),
-				Value: awssdk.String(req.Email),
-			},
-			{
-				Name:  awssdk.String("given_name"),
-				Value: awssdk.String(req.FirstName),
-			},
-			{
-				Name:  awssdk.String("family_name"),
-				Value: awssdk.String(req.LastName),
-			},
-			{
-				Name:  awssdk.String("phone_number"),
-				Value: awssdk.String(req.Phone),
-			},
-			{
-				Name:  awssdk.String("custom:user_type"),
-				Value: awssdk.String(req.UserType),
-			},
-			{
-				Name:  awssdk.String("custom:company_name"),
-				Value: awssdk.String(req.CompanyName),
-			},
-		},
+	// Resolve the invitation before creating the account so a bad code fails
+	// fast. linkAccount runs once the account exists.
+	var landlordID *uuid.UUID
+	var linkAccount func(userID string) error
+	switch {
+	case req.InviteCode == "":
+		if req.UserType == "contractor" {
+			return nil, newValidationError("contractors need an invitation code to sign up")
+		}
+		if req.UserType == "landlord" && s.teamService != nil {
+			// A new owner gets a landlord account of their own
+			landlord := &domain.Landlord{
+				BaseEntity:  domain.BaseEntity{ID: uuid.New()},
+				Email:       req.Email,
+				FirstName:   req.FirstName,
+				LastName:    req.LastName,
+				Phone:       req.Phone,
+				CompanyName: req.CompanyName,
+				IsActive:    true,
+			}
+			landlordID = &landlord.ID
+			linkAccount = func(userID string) error {
+				return s.teamService.CreateAccount(ctx, landlord, userID)
+			}
+		}
+	case req.UserType == "tenant" && s.tenantService != nil:
+		invitation, err := s.tenantService.ResolveInvitation(ctx, req.InviteCode, req.Email)
+		if err != nil {
+			return nil, err
+		}
+		landlordID = &invitation.LandlordID
+		linkAccount = func(userID string) error {
+			return s.tenantService.AcceptInvitation(ctx, invitation, userID)
+		}
+	case req.UserType == "contractor" && s.contractorService != nil:
+		invitation, err := s.contractorService.ResolveInvitation(ctx, req.InviteCode, req.Email)
+		if err != nil {
+			return nil, err
+		}
+		landlordID = &invitation.LandlordID
+		linkAccount = func(userID string) error {
+			return s.contractorService.AcceptInvitation(ctx, invitation, userID)
+		}
+	case req.UserType == "landlord" && s.teamService != nil:
+		invitation, err := s.teamService.ResolveInvitation(ctx, req.InviteCode, req.Email)
+		if err != nil {
+			return nil, err
+		}
+		landlordID = &invitation.LandlordID
+		linkAccount = func(userID string) error {
+			return s.teamService.AcceptInvitation(ctx, invitation, userID, req.FirstName, req.LastName)
+		}
+	default:
+		return nil, newValidationError("invitation codes are only valid for tenant, contractor and team member signups")
 	}
 
-	// Call Cognito SignUp
-	result, err := s.awsClients.GetCognitoClient().SignUp(ctx, signUpInput)
+	newUser := &auth.NewUser{
+		Email:       req.Email,
+		Password:    req.Password,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Phone:       req.Phone,
+		CompanyName: req.CompanyName,
+		UserType:    req.UserType,
+		LandlordID:  landlordID,
+	}
+
+	userID, err := s.identity.SignUp(ctx, newUser)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign up user: %w", err)
+		return nil, identityError(err)
+	}
+
+	if linkAccount != nil {
+		if err := linkAccount(userID); err != nil {
+			// Remove the half-created login so the user can sign up again
+			if delErr := s.identity.DeleteUser(context.WithoutCancel(ctx), userID); delErr != nil {
+				log.Printf("Warning: failed to delete account %s after a failed signup: %v", userID, delErr)
+			}
+			return nil, fmt.Errorf("failed to link %s account: %w", req.UserType, err)
+		}
 	}
 
 	return &SignUpResponse{
-		UserID:      *result.UserSub,
-		UserType:    req.UserType,
-		Message:     "User registered successfully. Please check your email for confirmation code.",
-		ConfirmCode: "", // Cognito will send this via email
+		UserID:   userID,
+		UserType: req.UserType,
+		Message:  "User registered successfully. Please check your email for confirmation code.",
 	}, nil
 }
 
 // ConfirmSignUp confirms user registration with confirmation code
 func (s *AuthService) ConfirmSignUp(ctx context.Context, email, confirmationCode string) error {
-	confirmInput := &cognitoidentityprovider.ConfirmSignUpInput{
-		ClientId:         awssdk.String(s.config.AWS.Cognito.ClientID),
-		Username:         awssdk.String(email),
-		ConfirmationCode: awssdk.String(confirmationCode),
+	if err := s.identity.ConfirmSignUp(ctx, email, confirmationCode); err != nil {
+		return identityError(err)
 	}
+	return nil
+}
 
-	_, err := s.awsClients.GetCognitoClient().ConfirmSignUp(ctx, confirmInput)
-	if err != nil {
-		return fmt.Errorf("failed to confirm signup: %w", err)
+// ResendConfirmationCode sends a new confirmation code to an unconfirmed user
+func (s *AuthService) ResendConfirmationCode(ctx context.Context, email string) error {
+	if err := s.identity.ResendConfirmationCode(ctx, email); err != nil {
+		return identityError(err)
 	}
-
 	return nil
 }
 
 // SignIn authenticates user and returns tokens
 func (s *AuthService) SignIn(ctx context.Context, req *AuthRequest) (*AuthResponse, error) {
-	// Prepare Cognito signin request
-	authInput := &cognitoidentityprovider.InitiateAuthInput{
-		ClientId: awssdk.String(s.config.AWS.Cognito.ClientID),
-		AuthFlow: types.AuthFlowTypeUserPasswordAuth,
-		AuthParameters: map[string]string{
-			"USERNAME": req.Email,
-			"PASSWORD": req.Password,
-		},
-	}
-
-	// Call Cognito InitiateAuth
-	result, err := s.awsClients.GetCognitoClient().InitiateAuth(ctx, authInput)
-	if err != nil {
-		return nil, fmt.Errorf("failed to sign in: %w", err)
-	}
-
-	// Extract tokens and user info
-	accessToken := *result.AuthenticationResult.AccessToken
-	refreshToken := *result.AuthenticationResult.RefreshToken
-	expiresIn := int(result.AuthenticationResult.ExpiresIn)
-
-	// Get user attributes to determine user type
-	userInfo, err := s.getUserInfo(ctx, accessToken)
+	session, err := s.identity.SignIn(ctx, req.Email, req.Password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+		return nil, err
 	}
-
-	return &AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    expiresIn,
-		TokenType:    "Bearer",
-		UserID:       userInfo.UserID,
-		UserType:     userInfo.UserType,
-	}, nil
+	return newAuthResponse(session), nil
 }
 
-// RefreshToken refreshes the access token using refresh token
+// RefreshToken refreshes the access token using refresh token. The response
+// carries a new refresh token when the provider rotates them.
 func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*AuthResponse, error) {
-	authInput := &cognitoidentityprovider.InitiateAuthInput{
-		ClientId: awssdk.String(s.config.AWS.Cognito.ClientID),
-		AuthFlow: types.AuthFlowTypeRefreshTokenAuth,
-		AuthParameters: map[string]string{
-			"REFRESH_TOKEN": refreshToken,
-		},
-	}
-
-	result, err := s.awsClients.GetCognitoClient().InitiateAuth(ctx, authInput)
-	if err != nil {
-		return nil, fmt.Errorf("failed to refresh token: %w", err)
-	}
-
-	accessToken := *result.AuthenticationResult.AccessToken
-	expiresIn := int(result.AuthenticationResult.ExpiresIn)
-
-	// Get user info from the new access token
-	userInfo, err := s.getUserInfo(ctx, accessToken)
+	session, err := s.identity.Refresh(ctx, refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+		return nil, err
 	}
-
-	return &AuthResponse{
-		AccessToken: accessToken,
-		ExpiresIn:   expiresIn,
-		TokenType:   "Bearer",
-		UserID:      userInfo.UserID,
-		UserType:    userInfo.UserType,
-	}, nil
+	return newAuthResponse(session), nil
 }
 
 // SignOut signs out the user
 func (s *AuthService) SignOut(ctx context.Context, accessToken string) error {
-	signOutInput := &cognitoidentityprovider.GlobalSignOutInput{
-		AccessToken: awssdk.String(accessToken),
-	}
-
-	_, err := s.awsClients.GetCognitoClient().GlobalSignOut(ctx, signOutInput)
-	if err != nil {
-		return fmt.Errorf("failed to sign out: %w", err)
-	}
-
-	return nil
+	return s.identity.SignOut(ctx, accessToken)
 }
 
-// ValidateToken validates the JWT token and returns user claims
-func (s *AuthService) ValidateToken(tokenString string) (*domain.UserClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWT.SecretKey), nil
-	})
-
+// ValidateToken verifies an access or ID token and returns the caller's
+// claims. Landlord-side users get the role and property assignments of their
+// current team membership.
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*domain.UserClaims, error) {
+	claims, err := s.identity.VerifyToken(ctx, tokenString)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
+		return nil, err
 	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID, _ := claims["user_id"].(string)
-		userType, _ := claims["user_type"].(string)
-		landlordID, _ := claims["landlord_id"].(string)
-
-		var landlordUUID *uuid.UUID
-		if landlordID != "" {
-			if id, err := uuid.Parse(landlordID); err == nil {
-				landlordUUID = &id
-			}
+	if s.teamService != nil {
+		if err := s.teamService.ResolveMembership(ctx, claims); err != nil {
+			return nil, err
 		}
-
-		return &domain.UserClaims{
-			UserID:     userID,
-			UserType:   userType,
-			LandlordID: landlordUUID,
-			ExpiresAt:  time.Unix(int64(claims["exp"].(float64)), 0),
-		}, nil
 	}
-
-	return nil, fmt.Errorf("invalid token")
+	return claims, nil
 }
 
-// getUserInfo retrieves user information from Cognito
-func (s *AuthService) getUserInfo(ctx context.Context, accessToken string) (*domain.UserInfo, error) {
-	getUserInput := &cognitoidentityprovider.GetUserInput{
-		AccessToken: awssdk.String(accessToken),
-	}
-
-	result, err := s.awsClients.GetCognitoClient().GetUser(ctx, getUserInput)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+func newAuthResponse(session *auth.Session) *AuthResponse {
+	return &AuthResponse{
+		AccessToken:  session.AccessToken,
+		RefreshToken: session.RefreshToken,
+		ExpiresIn:    session.ExpiresIn,
+		TokenType:    "Bearer",
+		UserID:       session.UserID,
+		UserType:     session.UserType,
 	}
+}
 
-	userInfo := &domain.UserInfo{
-		UserID: *result.Username,
+// identityError maps identity provider errors callers can fix to service
+// errors
+func identityError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrUserExists):
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	case errors.Is(err, auth.ErrInvalidCode), errors.Is(err, auth.ErrInvalidPassword):
+		return newValidationError(err.Error())
 	}
+	return err
+}
 
-	// Extract user attributes
-	for _, attr := range result.UserAttributes {
-		switch *attr.Name {
-		case "custom:user_type":
-			userInfo.UserType = *attr.Value
-		case "custom:landlord_id":
-			if *attr.Value != "" {
-				if id, err := uuid.Parse(*attr.Value); err == nil {
-					userInfo.LandlordID = &id
-				}
-			}
+// ConfirmationCodeSender emails the local identity provider's confirmation
+// codes. Accounts not yet linked to a landlord are recorded under their own
+// ID.
+func ConfirmationCodeSender(notifications *NotificationService) auth.CodeSender {
+	return func(ctx context.Context, user *domain.AuthUser, code string) error {
+		landlordID := user.ID
+		if user.LandlordID != nil {
+			landlordID = *user.LandlordID
 		}
+		_, err := notifications.SendNotification(ctx, &NotificationRequest{
+			Type:           "account_confirmation",
+			Title:          "Confirm your account",
+			Message:        fmt.Sprintf("Your confirmation code is %s. Enter it to finish signing up.", code),
+			LandlordID:     landlordID.String(),
+			RecipientID:    user.ID.String(),
+			RecipientType:  user.UserType,
+			RecipientEmail: user.Email,
+		})
+		return err
 	}
-
-	return userInfo, nil
 }
@@ -1,10 +1,18 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 
+	"dwell/internal/auth"
+	"dwell/internal/authz"
 	"dwell/internal/aws"
 	"dwell/internal/config"
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
 )
 
 func TestNewAuthService(t *testing.T) {
@@ -24,11 +32,11 @@ func TestNewAuthService(t *testing.T) {
 		},
 	}
 
-	// Create mock AWS clients
-	awsClients := &aws.Clients{}
+	// Create the identity provider
+	identity := auth.NewCognitoProvider(nil, &cfg.AWS.Cognito)
 
 	// Test service creation
-	service := NewAuthService(awsClients, cfg)
+	service := NewAuthService(identity, cfg)
 
 	if service == nil {
 		t.Error("Expected AuthService to be created, got nil")
@@ -38,8 +46,8 @@ func TestNewAuthService(t *testing.T) {
 		t.Error("Expected config to be set correctly")
 	}
 
-	if service.awsClients != awsClients {
-		t.Error("Expected AWS clients to be set correctly")
+	if service.identity != identity {
+		t.Error("Expected identity provider to be set correctly")
 	}
 }
 
@@ -52,20 +60,17 @@ func TestAuthService_ValidateToken(t *testing.T) {
 		},
 	}
 
-	// Create mock AWS clients
-	awsClients := &aws.Clients{}
-
 	// Create service
-	service := NewAuthService(awsClients, cfg)
+	service := NewAuthService(auth.NewCognitoProvider(nil, &cfg.AWS.Cognito), cfg)
 
 	// Test with invalid token
-	_, err := service.ValidateToken("invalid-token")
+	_, err := service.ValidateToken(context.Background(), "invalid-token")
 	if err == nil {
 		t.Error("Expected error for invalid token, got nil")
 	}
 
 	// Test with empty token
-	_, err = service.ValidateToken("")
+	_, err = service.ValidateToken(context.Background(), "")
 	if err == nil {
 		t.Error("Expected error for empty token, got nil")
 	}
@@ -169,11 +174,11 @@ func BenchmarkNewAuthService(b *testing.B) {
 		},
 	}
 
-	awsClients := &aws.Clients{}
+	identity := auth.NewCognitoProvider(nil, &cfg.AWS.Cognito)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		NewAuthService(awsClients, cfg)
+		NewAuthService(identity, cfg)
 	}
 }
 
@@ -197,8 +202,7 @@ func createTestConfig() *config.Config {
 
 func createTestAuthService() *AuthService {
 	cfg := createTestConfig()
-	awsClients := &aws.Clients{}
-	return NewAuthService(awsClients, cfg)
+	return NewAuthService(auth.NewCognitoProvider(nil, &cfg.AWS.Cognito), cfg)
 }
 
 // Table-driven tests
@@ -233,3 +237,131 @@ func TestAuthService_UserTypeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentityError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		conflict   bool
+		validation bool
+	}{
+		{"Existing Account", fmt.Errorf("failed to sign up user: %w", auth.ErrUserExists), true, false},
+		{"Wrong Code", auth.ErrInvalidCode, false, true},
+		{"Weak Password", fmt.Errorf("%w: must be at least 8 characters", auth.ErrInvalidPassword), false, true},
+		{"Provider Failure", errors.New("connection refused"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := identityError(tt.err)
+			var validationErr *ValidationError
+			if got := errors.Is(err, ErrConflict); got != tt.conflict {
+				t.Errorf("errors.Is(err, ErrConflict) = %v, want %v", got, tt.conflict)
+			}
+			if got := errors.As(err, &validationErr); got != tt.validation {
+				t.Errorf("errors.As(err, *ValidationError) = %v, want %v", got, tt.validation)
+			}
+		})
+	}
+}
+
+func TestSignUpInvitationRules(t *testing.T) {
+	// Each case is rejected before the identity provider is called
+	service := NewAuthService(auth.NewCognitoProvider(nil, &createTestConfig().AWS.Cognito), createTestConfig())
+	service.SetTenantService(&TenantService{})
+	service.SetContractorService(&ContractorService{})
+
+	tests := []struct {
+		name string
+		req  SignUpRequest
+	}{
+		{"Contractor Without Code", SignUpRequest{Email: "fix@example.com", UserType: "contractor"}},
+		{"Landlord With Code", SignUpRequest{Email: "owner@example.com", UserType: "landlord", InviteCode: "ABCDEFGHJK"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SignUp(context.Background(), &tt.req)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("SignUp() error = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+// stubIdentity hands out a fixed user ID, records signups and deletions and
+// finds the users it was given by email. Methods a test does not use panic
+// through the nil embedded interface.
+type stubIdentity struct {
+	auth.Provider
+	userID  string
+	signUps []*auth.NewUser
+	deleted []string
+	users   map[string]*domain.UserInfo
+}
+
+func (s *stubIdentity) FindUser(_ context.Context, email string) (*domain.UserInfo, error) {
+	if info, ok := s.users[email]; ok {
+		return info, nil
+	}
+	return nil, auth.ErrUserNotFound
+}
+
+func (s *stubIdentity) SignUp(_ context.Context, user *auth.NewUser) (string, error) {
+	s.signUps = append(s.signUps, user)
+	return s.userID, nil
+}
+
+func (s *stubIdentity) DeleteUser(_ context.Context, userID string) error {
+	s.deleted = append(s.deleted, userID)
+	return nil
+}
+
+func TestSignUpCreatesLandlordAccount(t *testing.T) {
+	repos := setupTestRepos(t)
+	ctx := context.Background()
+	identity := &stubIdentity{userID: uuid.NewString()}
+	service := NewAuthService(identity, createTestConfig())
+	teamService := NewTeamService(repos, nil, nil, createTestConfig())
+	service.SetTeamService(teamService)
+
+	req := &SignUpRequest{Email: uuid.NewString() + "@example.com", FirstName: "Jane", LastName: "Owner", UserType: "landlord"}
+	if _, err := service.SignUp(ctx, req); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	landlordID := identity.signUps[0].LandlordID
+	if landlordID == nil {
+		t.Fatal("SignUp() did not give the account a landlord")
+	}
+	landlord, err := repos.Landlords.GetByID(ctx, *landlordID)
+	if err != nil {
+		t.Fatalf("landlord record not created: %v", err)
+	}
+	if landlord.Email != req.Email || !landlord.IsActive {
+		t.Errorf("landlord = %+v, want an active record for %s", landlord, req.Email)
+	}
+
+	claims := &domain.UserClaims{UserID: identity.userID, UserType: "landlord"}
+	if err := teamService.ResolveMembership(ctx, claims); err != nil {
+		t.Fatalf("ResolveMembership() error = %v", err)
+	}
+	if claims.LandlordID == nil || *claims.LandlordID != *landlordID || claims.Role != authz.RoleOwner {
+		t.Errorf("claims = %+v, want the owner of landlord %s", claims, landlordID)
+	}
+	if len(identity.deleted) != 0 {
+		t.Errorf("SignUp() deleted accounts %v", identity.deleted)
+	}
+
+	// The same login cannot own a second account, so the new identity is removed
+	again := &SignUpRequest{Email: uuid.NewString() + "@example.com", FirstName: "Jane", LastName: "Owner", UserType: "landlord"}
+	if _, err := service.SignUp(ctx, again); !errors.Is(err, ErrConflict) {
+		t.Fatalf("SignUp() error = %v, want ErrConflict", err)
+	}
+	if len(identity.deleted) != 1 || identity.deleted[0] != identity.userID {
+		t.Errorf("deleted accounts = %v, want [%s]", identity.deleted, identity.userID)
+	}
+	if _, err := repos.Landlords.GetByID(ctx, *identity.signUps[1].LandlordID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("failed signup left landlord record behind, error = %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// contractorInvitationTTL is how long an emailed contractor invitation code stays valid
+const contractorInvitationTTL = 7 * 24 * time.Hour
+
+type ContractorService struct {
+	repos               *repository.Repositories
+	notificationService *NotificationService
+	config              *config.Config
+}
+
+// CreateContractorRequest represents adding a contractor to the landlord's roster
+type CreateContractorRequest struct {
+	CompanyName    string  `json:"company_name" binding:"required,max=255"`
+	ContactPerson  string  `json:"contact_person" binding:"max=255"`
+	Email          string  `json:"email" binding:"omitempty,email"`
+	Phone          string  `json:"phone" binding:"max=50"`
+	Specialization string  `json:"specialization" binding:"max=100"`
+	LicenseNumber  string  `json:"license_number" binding:"max=100"`
+	InsuranceInfo  string  `json:"insurance_info"`
+	HourlyRate     float64 `json:"hourly_rate" binding:"min=0"`
+	SendInvite     bool    `json:"send_invite"` // requires an email address
+}
+
+// ContractorListRequest represents contractor roster filters and pagination
+type ContractorListRequest struct {
+	Specialization string `form:"specialization"`
+	IsActive       *bool  `form:"is_active"`
+	Limit          int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset         int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ContractorListResponse represents a page of contractors
+type ContractorListResponse struct {
+	Contractors []domain.Contractor `json:"contractors"`
+	Total       int                 `json:"total"`
+	Limit       int                 `json:"limit"`
+	Offset      int                 `json:"offset"`
+}
+
+// ContractorInvitationResponse describes an invitation that was emailed to a contractor
+type ContractorInvitationResponse struct {
+	InvitationID uuid.UUID `json:"invitation_id"`
+	ContractorID uuid.UUID `json:"contractor_id"`
+	Email        string    `json:"email"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func NewContractorService(repos *repository.Repositories, notificationService *NotificationService, config *config.Config) *ContractorService {
+	return &ContractorService{
+		repos:               repos,
+		notificationService: notificationService,
+		config:              config,
+	}
+}
+
+// CreateContractor adds a contractor to the calling landlord's roster
+func (s *ContractorService) CreateContractor(ctx context.Context, claims *domain.UserClaims, req *CreateContractorRequest) (*domain.Contractor, error) {
+	landlordID, err := authorize(claims, authz.ResourceContractors, authz.ActionCreate)
+	if err != nil {
+		return nil, err
+	}
+	if req.SendInvite && strings.TrimSpace(req.Email) == "" {
+		return nil, newValidationError("email is required to send an invitation")
+	}
+
+	contractor := &domain.Contractor{
+		LandlordID:     landlordID,
+		CompanyName:    req.CompanyName,
+		ContactPerson:  req.ContactPerson,
+		Email:          strings.TrimSpace(req.Email),
+		Phone:          req.Phone,
+		Specialization: req.Specialization,
+		LicenseNumber:  req.LicenseNumber,
+		InsuranceInfo:  req.InsuranceInfo,
+		HourlyRate:     req.HourlyRate,
+		IsActive:       true,
+	}
+	if err := s.repos.Contractors.Create(ctx, contractor); err != nil {
+		return nil, err
+	}
+
+	if req.SendInvite {
+		if _, err := s.sendInvitation(ctx, contractor); err != nil {
+			return nil, err
+		}
+	}
+	return contractor, nil
+}
+
+// GetContractor returns one of the calling landlord's contractors
+func (s *ContractorService) GetContractor(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.Contractor, error) {
+	landlordID, err := authorize(claims, authz.ResourceContractors, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.repos.Contractors.GetByID(ctx, landlordID, id)
+}
+
+// ListContractors returns the calling landlord's contractor roster
+func (s *ContractorService) ListContractors(ctx context.Context, claims *domain.UserClaims, req *ContractorListRequest) (*ContractorListResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceContractors, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.ContractorFilter{
+		LandlordID:     landlordID,
+		Specialization: strings.TrimSpace(req.Specialization),
+		IsActive:       req.IsActive,
+	}
+	contractors, total, err := s.repos.Contractors.List(ctx, filter, repository.ListOptions{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = repository.DefaultLimit
+	}
+	return &ContractorListResponse{
+		Contractors: contractors,
+		Total:       total,
+		Limit:       limit,
+		Offset:      req.Offset,
+	}, nil
+}
+
+// InviteContractor emails a signup invitation to a contractor, replacing any outstanding one
+func (s *ContractorService) InviteContractor(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*ContractorInvitationResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceContractors, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	contractor, err := s.repos.Contractors.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !contractor.IsActive {
+		return nil, fmt.Errorf("%w: contractor is inactive", ErrConflict)
+	}
+	if contractor.UserID != "" {
+		return nil, fmt.Errorf("%w: contractor has already signed up", ErrConflict)
+	}
+	if contractor.Email == "" {
+		return nil, newValidationError("contractor has no email address")
+	}
+
+	return s.sendInvitation(ctx, contractor)
+}
+
+// ResolveInvitation looks up the pending invitation for a code and checks that
+// it was issued to the given email address
+func (s *ContractorService) ResolveInvitation(ctx context.Context, code, email string) (*domain.ContractorInvitation, error) {
+	invitation, err := s.repos.ContractorInvitations.GetPendingByCodeHash(ctx, hashInviteCode(code))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, newValidationError("invitation code is invalid or has expired")
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(invitation.Email, strings.TrimSpace(email)) {
+		return nil, newValidationError("invitation code is invalid or has expired")
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation links an identity-provider user to the invited contractor row
+func (s *ContractorService) AcceptInvitation(ctx context.Context, invitation *domain.ContractorInvitation, userID string) error {
+	return s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repos.ContractorInvitations.WithTx(tx).MarkAccepted(ctx, invitation.ID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("%w: invitation has already been used", ErrConflict)
+			}
+			return err
+		}
+
+		contractors := s.repos.Contractors.WithTx(tx)
+		contractor, err := contractors.GetByID(ctx, invitation.LandlordID, invitation.ContractorID)
+		if err != nil {
+			return err
+		}
+		contractor.UserID = userID
+		if err := contractors.Update(ctx, contractor); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return fmt.Errorf("%w: account is already linked to a contractor", ErrConflict)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// sendInvitation revokes outstanding invitations, stores a new one and emails its code
+func (s *ContractorService) sendInvitation(ctx context.Context, contractor *domain.Contractor) (*ContractorInvitationResponse, error) {
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &domain.ContractorInvitation{
+		LandlordID:   contractor.LandlordID,
+		ContractorID: contractor.ID,
+		Email:        contractor.Email,
+		CodeHash:     hashInviteCode(code),
+		ExpiresAt:    time.Now().Add(contractorInvitationTTL),
+	}
+
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		invitations := s.repos.ContractorInvitations.WithTx(tx)
+		if err := invitations.RevokePending(ctx, contractor.LandlordID, contractor.ID); err != nil {
+			return err
+		}
+		return invitations.Create(ctx, invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	name := contractor.ContactPerson
+	if name == "" {
+		name = contractor.CompanyName
+	}
+	_, err = s.notificationService.SendNotification(ctx, &NotificationRequest{
+		Type:  "contractor_invitation",
+		Title: "You're invited to Dwell",
+		Message: fmt.Sprintf("Hi %s, a landlord has invited you to receive work orders on Dwell. Sign up as a contractor "+
+			"with this email address and invitation code %s before %s.", name, code, invitation.ExpiresAt.Format("January 2, 2006")),
+		LandlordID:        contractor.LandlordID.String(),
+		RecipientID:       contractor.ID.String(),
+		RecipientType:     "contractor",
+		RecipientEmail:    contractor.Email,
+		RelatedEntityID:   &invitation.ID,
+		RelatedEntityType: "contractor_invitation",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send contractor invitation: %w", err)
+	}
+
+	return &ContractorInvitationResponse{
+		InvitationID: invitation.ID,
+		ContractorID: contractor.ID,
+		Email:        contractor.Email,
+		ExpiresAt:    invitation.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/repository"
+)
+
+var (
+	// ErrNotFound is returned when a resource does not exist or is outside the caller's landlord scope
+	ErrNotFound = repository.ErrNotFound
+	// ErrInvalidCursor is returned for malformed or mismatched pagination cursors
+	ErrInvalidCursor = repository.ErrInvalidCursor
+	// ErrForbidden is returned when the caller's role does not permit the operation
+	ErrForbidden = authz.ErrForbidden
+	// ErrConflict is returned when an operation conflicts with the current state of a resource
+	ErrConflict = errors.New("conflict with current state")
+	// ErrQuotaExceeded is returned when a usage budget does not allow the operation
+	ErrQuotaExceeded = errors.New("usage quota exceeded")
+)
+
+// ValidationError reports a request that is well-formed but violates a business rule
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(message string) error {
+	return &ValidationError{Message: message}
+}
+
+// QuotaError reports an exhausted usage budget and when it resets. It matches
+// ErrQuotaExceeded.
+type QuotaError struct {
+	Message string
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return e.Message
+}
+
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+	"dwell/internal/scheduler"
+)
+
+// Background job names
+const (
+	JobOverdueSweep  = "overdue-sweep"
+	JobRentCharges   = "rent-charges"
+	JobRentReminders = "rent-reminders"
+	JobLeaseExpiry   = "lease-expiry"
+	JobOrphanedFiles = "orphaned-files"
+)
+
+// scheduleOff disables a configured job
+const scheduleOff = "off"
+
+// leaseExpiryAlertDays are the days before a lease ends that its landlord is alerted
+var leaseExpiryAlertDays = []int{60, 30}
+
+// orphanedFileMinAge leaves recent uploads alone, since a photo is stored
+// before its row is committed
+const orphanedFileMinAge = 24 * time.Hour
+
+// RegisterJobs adds the periodic background jobs to the scheduler
+func (s *Services) RegisterJobs(sched *scheduler.Scheduler) error {
+	cfg := s.config.Scheduler
+
+	jobs := []struct {
+		name string
+		spec string
+		run  scheduler.JobFunc
+	}{
+		{JobOverdueSweep, cfg.OverdueSweepSchedule, s.runOverdueSweep},
+		{JobRentCharges, cfg.RentChargeSchedule, s.runRentCharges},
+		{JobRentReminders, cfg.RentReminderSchedule, s.runRentReminders},
+		{JobLeaseExpiry, cfg.LeaseExpirySchedule, s.runLeaseExpiryAlerts},
+		{JobOrphanedFiles, cfg.OrphanedFileSchedule, s.runOrphanedFileCleanup},
+	}
+	for _, job := range jobs {
+		if job.spec == scheduleOff {
+			continue
+		}
+		if err := sched.Register(job.name, job.spec, job.run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runOverdueSweep marks overdue charges and assesses late fees
+func (s *Services) runOverdueSweep(ctx context.Context) error {
+	result, err := s.lateFeeService.ProcessOverduePayments(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if result.MarkedOverdue > 0 || result.LateFeesAssessed > 0 {
+		log.Printf("Overdue sweep marked %d charge(s) overdue and assessed %d late fee(s)",
+			result.MarkedOverdue, result.LateFeesAssessed)
+	}
+	return nil
+}
+
+// runRentCharges generates the current month's rent charges for every landlord
+func (s *Services) runRentCharges(ctx context.Context) error {
+	created := 0
+	err := forEachActiveLandlord(ctx, s.repos, func(landlord *domain.Landlord) error {
+		result, err := s.paymentService.GenerateDueRentCharges(ctx, landlord.ID)
+		if err != nil {
+			return fmt.Errorf("rent charges for landlord %s: %w", landlord.ID, err)
+		}
+		created += len(result.Created)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if created > 0 {
+		log.Printf("Generated %d rent charge(s)", created)
+	}
+	return nil
+}
+
+// runRentReminders reminds tenants of pending charges due in
+// RentReminderDays days. Each charge is reminded on that one day, so the job
+// should run once a day.
+func (s *Services) runRentReminders(ctx context.Context) error {
+	days := s.config.Billing.RentReminderDays
+	if days <= 0 {
+		return nil
+	}
+	due := truncateDay(time.Now().UTC()).AddDate(0, 0, days)
+
+	sent := 0
+	err := forEachActiveLandlord(ctx, s.repos, func(landlord *domain.Landlord) error {
+		filter := repository.PaymentFilter{
+			LandlordID: landlord.ID,
+			Status:     domain.PaymentStatusPending,
+			DueFrom:    &due,
+			DueTo:      &due,
+		}
+		for offset := 0; ; offset += repository.MaxLimit {
+			charges, total, err := s.repos.Payments.List(ctx, filter, repository.ListOptions{Limit: repository.MaxLimit, Offset: offset})
+			if err != nil {
+				return fmt.Errorf("rent reminders for landlord %s: %w", landlord.ID, err)
+			}
+			for i := range charges {
+				if charges[i].Amount-charges[i].AmountPaid <= 0 {
+					continue
+				}
+				// A failed reminder must not stop the others
+				if err := s.sendRentReminder(ctx, &charges[i]); err != nil {
+					log.Printf("Failed to send rent reminder for payment %s: %v", charges[i].ID, err)
+					continue
+				}
+				sent++
+			}
+			if len(charges) == 0 || offset+len(charges) >= total {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if sent > 0 {
+		log.Printf("Sent %d rent reminder(s)", sent)
+	}
+	return nil
+}
+
+// sendRentReminder sends the payment_due reminder to a charge's tenant
+func (s *Services) sendRentReminder(ctx context.Context, payment *domain.Payment) error {
+	tenant, err := s.repos.Tenants.GetByID(ctx, payment.LandlordID, payment.TenantID)
+	if err != nil {
+		return err
+	}
+	property, err := s.repos.Properties.GetByID(ctx, payment.LandlordID, payment.PropertyID)
+	if err != nil {
+		return err
+	}
+	return s.notificationService.SendRentReminderNotification(ctx, payment, tenant, property.Name)
+}
+
+// runLeaseExpiryAlerts alerts landlords of active leases ending in exactly
+// one of leaseExpiryAlertDays days, so the job should run once a day
+func (s *Services) runLeaseExpiryAlerts(ctx context.Context) error {
+	today := truncateDay(time.Now().UTC())
+	active := true
+
+	sent := 0
+	err := forEachActiveLandlord(ctx, s.repos, func(landlord *domain.Landlord) error {
+		for _, days := range leaseExpiryAlertDays {
+			ends := today.AddDate(0, 0, days)
+			filter := repository.TenantFilter{
+				LandlordID:      landlord.ID,
+				IsActive:        &active,
+				LeaseEndsAfter:  &ends,
+				LeaseEndsBefore: &ends,
+			}
+			for offset := 0; ; offset += repository.MaxLimit {
+				tenants, total, err := s.repos.Tenants.List(ctx, filter, repository.ListOptions{Limit: repository.MaxLimit, Offset: offset})
+				if err != nil {
+					return fmt.Errorf("lease expiry alerts for landlord %s: %w", landlord.ID, err)
+				}
+				for i := range tenants {
+					if err := s.notificationService.SendLeaseExpiryNotification(ctx, &tenants[i], landlord, days); err != nil {
+						log.Printf("Failed to send lease expiry alert for tenant %s: %v", tenants[i].ID, err)
+						continue
+					}
+					sent++
+				}
+				if len(tenants) == 0 || offset+len(tenants) >= total {
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if sent > 0 {
+		log.Printf("Sent %d lease expiry alert(s)", sent)
+	}
+	return nil
+}
+
+// runOrphanedFileCleanup deletes stored maintenance photos that no photo
+// row refers to, such as leftovers of failed uploads and deletions. Only the
+// category written by the maintenance endpoints is scanned; files uploaded
+// through the generic file routes have no rows and are never touched.
+func (s *Services) runOrphanedFileCleanup(ctx context.Context) error {
+	cutoff := time.Now().Add(-orphanedFileMinAge)
+
+	deleted := 0
+	err := forEachActiveLandlord(ctx, s.repos, func(landlord *domain.Landlord) error {
+		prefix := landlord.ID.String() + "/" + MaintenancePhotoCategory + "/"
+		err := s.s3Service.forEachObjectPage(ctx, prefix, func(files []FileInfo) error {
+			if len(files) == 0 {
+				return nil
+			}
+			keys := make([]string, 0, len(files))
+			for _, file := range files {
+				keys = append(keys, file.FileKey)
+			}
+			referenced, err := s.repos.MaintenancePhotos.ReferencedKeys(ctx, keys)
+			if err != nil {
+				return err
+			}
+			for _, key := range orphanedKeys(files, referenced, cutoff) {
+				if err := s.s3Service.DeleteFile(ctx, &FileDeleteRequest{FileKey: key, LandlordID: landlord.ID.String()}); err != nil {
+					log.Printf("Failed to delete orphaned file %s: %v", key, err)
+					continue
+				}
+				deleted++
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("orphaned file cleanup for landlord %s: %w", landlord.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d orphaned file(s)", deleted)
+	}
+	return nil
+}
+
+// orphanedKeys returns the keys of files uploaded before cutoff that are not referenced
+func orphanedKeys(files []FileInfo, referenced map[string]bool, cutoff time.Time) []string {
+	var orphaned []string
+	for _, file := range files {
+		if !referenced[file.FileKey] && file.UploadedAt.Before(cutoff) {
+			orphaned = append(orphaned, file.FileKey)
+		}
+	}
+	return orphaned
+}
+
+// forEachActiveLandlord calls fn for every active landlord, stopping at the first error
+func forEachActiveLandlord(ctx context.Context, repos *repository.Repositories, fn func(landlord *domain.Landlord) error) error {
+	active := true
+	filter := repository.LandlordFilter{IsActive: &active}
+
+	for offset := 0; ; offset += repository.MaxLimit {
+		landlords, total, err := repos.Landlords.List(ctx, filter, repository.ListOptions{Limit: repository.MaxLimit, Offset: offset})
+		if err != nil {
+			return err
+		}
+		for i := range landlords {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(&landlords[i]); err != nil {
+				return err
+			}
+		}
+		if len(landlords) == 0 || offset+len(landlords) >= total {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrphanedKeys(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-orphanedFileMinAge)
+	files := []FileInfo{
+		{FileKey: "l/maintenance_photo/r/kept.jpg", UploadedAt: now.AddDate(0, 0, -3)},
+		{FileKey: "l/maintenance_photo/r/orphan.jpg", UploadedAt: now.AddDate(0, 0, -3)},
+		{FileKey: "l/maintenance_photo/r/uploading.jpg", UploadedAt: now.Add(-time.Hour)},
+	}
+	referenced := map[string]bool{"l/maintenance_photo/r/kept.jpg": true}
+
+	got := orphanedKeys(files, referenced, cutoff)
+	want := []string{"l/maintenance_photo/r/orphan.jpg"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("orphanedKeys() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type LateFeeService struct {
+	repos               *repository.Repositories
+	notificationService *NotificationService
+	config              *config.Config
+}
+
+// UpdateLateFeePolicyRequest replaces a landlord's late fee policy
+type UpdateLateFeePolicyRequest struct {
+	Enabled         bool    `json:"enabled"`
+	GracePeriodDays int     `json:"grace_period_days" binding:"min=0,max=60"`
+	FlatAmount      float64 `json:"flat_amount" binding:"min=0"`
+	Percentage      float64 `json:"percentage" binding:"min=0,max=100"` // percent of the overdue charge
+	DailyAmount     float64 `json:"daily_amount" binding:"min=0"`
+	MaxAmount       float64 `json:"max_amount" binding:"min=0"` // cap per charge, 0 for no cap
+}
+
+// OverdueSweepResult summarizes an overdue sweep
+type OverdueSweepResult struct {
+	LandlordsProcessed int `json:"landlords_processed"`
+	MarkedOverdue      int `json:"marked_overdue"`
+	LateFeesAssessed   int `json:"late_fees_assessed"` // late fees created or increased
+}
+
+func NewLateFeeService(repos *repository.Repositories, notificationService *NotificationService, config *config.Config) *LateFeeService {
+	return &LateFeeService{
+		repos:               repos,
+		notificationService: notificationService,
+		config:              config,
+	}
+}
+
+// GetPolicy returns the caller's late fee policy, or the disabled default if
+// none has been saved
+func (s *LateFeeService) GetPolicy(ctx context.Context, claims *domain.UserClaims) (*domain.LateFeePolicy, error) {
+	landlordID, err := authorize(claims, authz.ResourceLateFeePolicy, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.policy(ctx, landlordID)
+}
+
+// UpdatePolicy replaces the caller's late fee policy
+func (s *LateFeeService) UpdatePolicy(ctx context.Context, claims *domain.UserClaims, req *UpdateLateFeePolicyRequest) (*domain.LateFeePolicy, error) {
+	landlordID, err := authorize(claims, authz.ResourceLateFeePolicy, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if req.Enabled && req.FlatAmount == 0 && req.Percentage == 0 && req.DailyAmount == 0 {
+		return nil, newValidationError("an enabled policy needs a flat_amount, percentage or daily_amount")
+	}
+
+	policy := &domain.LateFeePolicy{
+		LandlordID:      landlordID,
+		Enabled:         req.Enabled,
+		GracePeriodDays: req.GracePeriodDays,
+		FlatAmount:      roundCents(req.FlatAmount),
+		Percentage:      req.Percentage,
+		DailyAmount:     roundCents(req.DailyAmount),
+		MaxAmount:       roundCents(req.MaxAmount),
+	}
+	if err := s.repos.LateFeePolicies.Upsert(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ProcessOverduePayments marks pending charges past their grace period as
+// overdue, notifies their tenants and assesses late fees for every active
+// landlord. It is safe to run repeatedly.
+func (s *LateFeeService) ProcessOverduePayments(ctx context.Context, now time.Time) (*OverdueSweepResult, error) {
+	today := truncateDay(now)
+
+	result := &OverdueSweepResult{}
+	err := forEachActiveLandlord(ctx, s.repos, func(landlord *domain.Landlord) error {
+		if err := s.sweepLandlord(ctx, landlord.ID, today, result); err != nil {
+			return fmt.Errorf("overdue sweep for landlord %s: %w", landlord.ID, err)
+		}
+		result.LandlordsProcessed++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sweepLandlord processes one landlord's overdue charges
+func (s *LateFeeService) sweepLandlord(ctx context.Context, landlordID uuid.UUID, today time.Time, result *OverdueSweepResult) error {
+	policy, err := s.policy(ctx, landlordID)
+	if err != nil {
+		return err
+	}
+
+	overdue, err := s.repos.Payments.MarkOverdue(ctx, landlordID, today.AddDate(0, 0, -policy.GracePeriodDays))
+	if err != nil {
+		return err
+	}
+	result.MarkedOverdue += len(overdue)
+	for i := range overdue {
+		// A failed notification must not stop the sweep; the charge is already overdue
+		if err := s.notifyOverdue(ctx, &overdue[i]); err != nil {
+			log.Printf("Failed to send overdue notification for payment %s: %v", overdue[i].ID, err)
+		}
+	}
+
+	if !policy.Enabled {
+		return nil
+	}
+	charges, err := s.repos.Payments.ListOverdueCharges(ctx, landlordID)
+	if err != nil {
+		return err
+	}
+	for i := range charges {
+		assessed, err := s.assessLateFee(ctx, policy, &charges[i], today)
+		if err != nil {
+			return err
+		}
+		if assessed {
+			result.LateFeesAssessed++
+		}
+	}
+	return nil
+}
+
+// assessLateFee creates or grows the late fee on an overdue charge. Fees never
+// shrink, and a paid fee is reopened when daily accrual raises it.
+func (s *LateFeeService) assessLateFee(ctx context.Context, policy *domain.LateFeePolicy, charge *domain.Payment, today time.Time) (bool, error) {
+	daysLate := int(today.Sub(truncateDay(charge.DueDate)).Hours()/24) - policy.GracePeriodDays
+	amount := lateFeeAmount(policy, charge.Amount, daysLate)
+	if amount <= 0 {
+		return false, nil
+	}
+
+	assessed := false
+	err := s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		payments := s.repos.Payments.WithTx(tx)
+
+		fee, err := payments.GetLateFeeForUpdate(ctx, charge.LandlordID, charge.ID)
+		if errors.Is(err, ErrNotFound) {
+			parentID := charge.ID
+			fee = &domain.Payment{
+				LandlordID:      charge.LandlordID,
+				PropertyID:      charge.PropertyID,
+				TenantID:        charge.TenantID,
+				Amount:          amount,
+				PaymentType:     "late_fee",
+				DueDate:         today,
+				Status:          domain.PaymentStatusPending,
+				Notes:           "Late fee: " + charge.Notes,
+				ParentPaymentID: &parentID,
+			}
+			assessed = true
+			return payments.Create(ctx, fee)
+		}
+		if err != nil {
+			return err
+		}
+
+		if fee.Status == domain.PaymentStatusCancelled || amount <= fee.Amount {
+			return nil
+		}
+		fee.Amount = amount
+		if fee.Status == domain.PaymentStatusPaid {
+			fee.Status = domain.PaymentStatusPending
+			fee.PaidDate = nil
+		}
+		assessed = true
+		return payments.Update(ctx, fee)
+	})
+	if err != nil {
+		return false, err
+	}
+	return assessed, nil
+}
+
+// notifyOverdue sends the payment_overdue notification to a charge's tenant
+func (s *LateFeeService) notifyOverdue(ctx context.Context, payment *domain.Payment) error {
+	tenant, err := s.repos.Tenants.GetByID(ctx, payment.LandlordID, payment.TenantID)
+	if err != nil {
+		return err
+	}
+	property, err := s.repos.Properties.GetByID(ctx, payment.LandlordID, payment.PropertyID)
+	if err != nil {
+		return err
+	}
+	return s.notificationService.SendPaymentOverdueNotification(ctx, payment, tenant, property.Name)
+}
+
+// policy returns a landlord's late fee policy, falling back to a disabled
+// policy with the configured grace period
+func (s *LateFeeService) policy(ctx context.Context, landlordID uuid.UUID) (*domain.LateFeePolicy, error) {
+	policy, err := s.repos.LateFeePolicies.Get(ctx, landlordID)
+	if errors.Is(err, ErrNotFound) {
+		return &domain.LateFeePolicy{
+			LandlordID:      landlordID,
+			GracePeriodDays: s.config.Billing.LateFeeGraceDays,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// lateFeeAmount computes the total late fee owed on a charge that is daysLate
+// days past its grace period
+func lateFeeAmount(policy *domain.LateFeePolicy, chargeAmount float64, daysLate int) float64 {
+	if daysLate <= 0 {
+		return 0
+	}
+
+	fee := policy.FlatAmount + chargeAmount*policy.Percentage/100 + policy.DailyAmount*float64(daysLate)
+	if policy.MaxAmount > 0 && fee > policy.MaxAmount {
+		fee = policy.MaxAmount
+	}
+	return roundCents(fee)
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"dwell/internal/domain"
+)
+
+func TestLateFeeAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   domain.LateFeePolicy
+		daysLate int
+		want     float64
+	}{
+		{"within grace period", domain.LateFeePolicy{FlatAmount: 50}, 0, 0},
+		{"flat", domain.LateFeePolicy{FlatAmount: 50}, 3, 50},
+		{"percentage", domain.LateFeePolicy{Percentage: 5}, 1, 60},
+		{"daily", domain.LateFeePolicy{DailyAmount: 10}, 4, 40},
+		{"combined", domain.LateFeePolicy{FlatAmount: 25, Percentage: 1, DailyAmount: 5}, 2, 47},
+		{"capped", domain.LateFeePolicy{FlatAmount: 25, DailyAmount: 10, MaxAmount: 100}, 30, 100},
+		{"under cap", domain.LateFeePolicy{DailyAmount: 10, MaxAmount: 100}, 5, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lateFeeAmount(&tt.policy, 1200, tt.daysLate); got != tt.want {
+				t.Errorf("lateFeeAmount() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// DeclineJobRequest represents a contractor turning down an assigned job
+type DeclineJobRequest struct {
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
+
+// ContractorJobUpdateRequest represents a progress update on an accepted job.
+// Status may move the job to in_progress or completed; a note alone is
+// recorded without changing the status.
+type ContractorJobUpdateRequest struct {
+	Status string `json:"status" binding:"omitempty,oneof=in_progress completed"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+// ContractorJobCostRequest represents the hours and materials a contractor
+// spent on a job. The actual cost is billed at the contractor's hourly rate.
+type ContractorJobCostRequest struct {
+	Hours         float64 `json:"hours" binding:"required,gt=0,max=1000"`
+	MaterialsCost float64 `json:"materials_cost" binding:"min=0"`
+	Note          string  `json:"note" binding:"max=1000"`
+}
+
+// AcceptJob records the assigned contractor's acceptance of a pending job
+func (s *MaintenanceService) AcceptJob(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*MaintenanceDetailResponse, error) {
+	_, err := s.updateContractorJob(ctx, claims, id, func(request *domain.MaintenanceRequest, _ *domain.Contractor) (*contractorChange, error) {
+		if isTerminalMaintenanceStatus(request.Status) {
+			return nil, fmt.Errorf("%w: cannot accept a %s request", ErrConflict, request.Status)
+		}
+		if request.ContractorStatus != domain.ContractorStatusPending {
+			return nil, fmt.Errorf("%w: job has already been %s", ErrConflict, request.ContractorStatus)
+		}
+
+		changes := map[string]domain.FieldChange{
+			"contractor_status": {From: request.ContractorStatus, To: domain.ContractorStatusAccepted},
+		}
+		request.ContractorStatus = domain.ContractorStatusAccepted
+		return &contractorChange{fromStatus: request.Status, changes: changes}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRequest(ctx, claims, id)
+}
+
+// DeclineJob unassigns the calling contractor from a job so the landlord can
+// assign someone else. The reason is recorded in the request's history.
+func (s *MaintenanceService) DeclineJob(ctx context.Context, claims *domain.UserClaims, id uuid.UUID, req *DeclineJobRequest) (*domain.MaintenanceRequest, error) {
+	request, err := s.updateContractorJob(ctx, claims, id, func(request *domain.MaintenanceRequest, _ *domain.Contractor) (*contractorChange, error) {
+		if isTerminalMaintenanceStatus(request.Status) {
+			return nil, fmt.Errorf("%w: cannot decline a %s request", ErrConflict, request.Status)
+		}
+
+		changes := map[string]domain.FieldChange{
+			"contractor_id":     {From: request.ContractorID, To: nil},
+			"contractor_status": {From: request.ContractorStatus, To: domain.ContractorStatusDeclined},
+		}
+		request.ContractorID = nil
+		request.ContractorStatus = domain.ContractorStatusDeclined
+		return &contractorChange{fromStatus: request.Status, note: req.Reason, changes: changes}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The contractor can no longer see the request, so only the request itself is returned
+	return request, nil
+}
+
+// PostJobUpdate records a status change or progress note on an accepted job
+func (s *MaintenanceService) PostJobUpdate(ctx context.Context, claims *domain.UserClaims, id uuid.UUID, req *ContractorJobUpdateRequest) (*MaintenanceDetailResponse, error) {
+	_, err := s.updateContractorJob(ctx, claims, id, func(request *domain.MaintenanceRequest, _ *domain.Contractor) (*contractorChange, error) {
+		if err := checkContractorCanWork(request); err != nil {
+			return nil, err
+		}
+		if isTerminalMaintenanceStatus(request.Status) {
+			return nil, fmt.Errorf("%w: cannot update a %s request", ErrConflict, request.Status)
+		}
+
+		fromStatus := request.Status
+		if req.Status != "" && req.Status != request.Status {
+			if err := validateMaintenanceTransition(request.Status, req.Status); err != nil {
+				return nil, err
+			}
+			request.Status = req.Status
+			if request.Status == domain.MaintenanceStatusCompleted {
+				now := time.Now()
+				request.CompletedDate = &now
+			}
+		}
+		if request.Status == fromStatus && req.Note == "" {
+			return nil, newValidationError("a status change or note is required")
+		}
+		return &contractorChange{fromStatus: fromStatus, note: req.Note, changes: map[string]domain.FieldChange{}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRequest(ctx, claims, id)
+}
+
+// SubmitJobCost records the hours and materials spent on an accepted job and
+// sets its actual cost from the contractor's hourly rate
+func (s *MaintenanceService) SubmitJobCost(ctx context.Context, claims *domain.UserClaims, id uuid.UUID, req *ContractorJobCostRequest) (*MaintenanceDetailResponse, error) {
+	_, err := s.updateContractorJob(ctx, claims, id, func(request *domain.MaintenanceRequest, contractor *domain.Contractor) (*contractorChange, error) {
+		if err := checkContractorCanWork(request); err != nil {
+			return nil, err
+		}
+
+		hours := roundCents(req.Hours)
+		cost := contractorJobCost(hours, contractor.HourlyRate, req.MaterialsCost)
+		changes := map[string]domain.FieldChange{}
+		if !equalFloatPtr(request.ActualHours, &hours) {
+			changes["actual_hours"] = domain.FieldChange{From: request.ActualHours, To: hours}
+			request.ActualHours = &hours
+		}
+		if !equalFloatPtr(request.ActualCost, &cost) {
+			changes["actual_cost"] = domain.FieldChange{From: request.ActualCost, To: cost}
+			request.ActualCost = &cost
+		}
+
+		note := fmt.Sprintf("%.2f hours at %.2f/hour plus %.2f materials", hours, contractor.HourlyRate, roundCents(req.MaterialsCost))
+		if req.Note != "" {
+			note += ": " + req.Note
+		}
+		return &contractorChange{fromStatus: request.Status, note: note, changes: changes}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRequest(ctx, claims, id)
+}
+
+// contractorChange describes a contractor's edit to a job for its history entry
+type contractorChange struct {
+	fromStatus string
+	note       string
+	changes    map[string]domain.FieldChange
+}
+
+// updateContractorJob locks a maintenance request assigned to the calling
+// contractor, lets apply validate and modify it, and saves it together with
+// its history entry. The assignment and status are checked against the locked
+// row so a concurrent reassignment or cancellation by the landlord is never
+// overwritten.
+func (s *MaintenanceService) updateContractorJob(ctx context.Context, claims *domain.UserClaims, id uuid.UUID, apply func(*domain.MaintenanceRequest, *domain.Contractor) (*contractorChange, error)) (*domain.MaintenanceRequest, error) {
+	contractor, err := currentContractor(ctx, s.repos.Contractors, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	var request *domain.MaintenanceRequest
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		request, err = s.repos.MaintenanceRequests.WithTx(tx).GetByIDForUpdate(ctx, contractor.LandlordID, id)
+		if err != nil {
+			return err
+		}
+		if request.ContractorID == nil || *request.ContractorID != contractor.ID {
+			return ErrNotFound
+		}
+
+		change, err := apply(request, contractor)
+		if err != nil {
+			return err
+		}
+		if err := s.repos.MaintenanceRequests.WithTx(tx).Update(ctx, request); err != nil {
+			return err
+		}
+		return s.repos.MaintenanceHistory.WithTx(tx).Create(ctx, &domain.MaintenanceStatusChange{
+			MaintenanceRequestID: request.ID,
+			LandlordID:           request.LandlordID,
+			FromStatus:           change.fromStatus,
+			ToStatus:             request.Status,
+			ChangedBy:            claims.UserID,
+			ChangedByType:        claims.UserType,
+			Note:                 change.note,
+			Changes:              change.changes,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// checkContractorCanWork requires the contractor to have accepted a job that
+// has not been cancelled
+func checkContractorCanWork(request *domain.MaintenanceRequest) error {
+	if request.ContractorStatus != domain.ContractorStatusAccepted {
+		return fmt.Errorf("%w: the job must be accepted first", ErrConflict)
+	}
+	if request.Status == domain.MaintenanceStatusCancelled {
+		return fmt.Errorf("%w: the request has been cancelled", ErrConflict)
+	}
+	return nil
+}
+
+// contractorJobCost bills hours at the contractor's hourly rate plus materials
+func contractorJobCost(hours, hourlyRate, materials float64) float64 {
+	return roundCents(hours*hourlyRate + materials)
+}
@@ -0,0 +1,734 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maintenanceTransitions lists the statuses each status may move to.
+// completed and cancelled are terminal.
+var maintenanceTransitions = map[string][]string{
+	domain.MaintenanceStatusOpen: {
+		domain.MaintenanceStatusInProgress,
+		domain.MaintenanceStatusCompleted,
+		domain.MaintenanceStatusCancelled,
+	},
+	domain.MaintenanceStatusInProgress: {
+		domain.MaintenanceStatusOpen,
+		domain.MaintenanceStatusCompleted,
+		domain.MaintenanceStatusCancelled,
+	},
+}
+
+const (
+	// maxPhotoSize is the largest maintenance photo accepted, in bytes
+	maxPhotoSize = 10 << 20
+	// maxPhotosPerUpload caps the number of files in a single upload request
+	maxPhotosPerUpload = 10
+	// photoURLExpiry is how long presigned photo URLs stay valid
+	photoURLExpiry = 15 * time.Minute
+)
+
+type MaintenanceService struct {
+	repos     *repository.Repositories
+	s3Service *S3Service
+	aiService *AIService
+	config    *config.Config
+}
+
+// CreateMaintenanceRequest represents a new maintenance request. Tenants file
+// against the property they rent; landlords must name the property.
+type CreateMaintenanceRequest struct {
+	PropertyID  *uuid.UUID `json:"property_id"`
+	Title       string     `json:"title" binding:"required,max=255"`
+	Description string     `json:"description" binding:"required"`
+	Priority    string     `json:"priority" binding:"omitempty,oneof=low medium high emergency"`
+	Category    string     `json:"category" binding:"max=50"`
+}
+
+// UpdateMaintenanceRequest represents a partial update; nil fields are left unchanged.
+// An empty contractor_id unassigns the current contractor.
+type UpdateMaintenanceRequest struct {
+	Title         *string  `json:"title" binding:"omitempty,min=1,max=255"`
+	Description   *string  `json:"description"`
+	Priority      *string  `json:"priority" binding:"omitempty,oneof=low medium high emergency"`
+	Category      *string  `json:"category" binding:"omitempty,max=50"`
+	Status        *string  `json:"status" binding:"omitempty,oneof=open in_progress completed cancelled"`
+	ContractorID  *string  `json:"contractor_id"`
+	EstimatedCost *float64 `json:"estimated_cost" binding:"omitempty,min=0"`
+	ActualCost    *float64 `json:"actual_cost" binding:"omitempty,min=0"`
+	Notes         *string  `json:"notes"`
+	Note          string   `json:"note"` // reason recorded in the status history
+}
+
+// MaintenanceListRequest represents maintenance request list filters and pagination
+type MaintenanceListRequest struct {
+	PropertyID   string `form:"property_id" binding:"omitempty,uuid"`
+	ContractorID string `form:"contractor_id" binding:"omitempty,uuid"`
+	Status       string `form:"status" binding:"omitempty,oneof=open in_progress completed cancelled"`
+	Priority     string `form:"priority" binding:"omitempty,oneof=low medium high emergency"`
+	Category     string `form:"category"`
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset       int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// MaintenanceListResponse represents a page of maintenance requests
+type MaintenanceListResponse struct {
+	Requests []domain.MaintenanceRequest `json:"requests"`
+	Total    int                         `json:"total"`
+	Limit    int                         `json:"limit"`
+	Offset   int                         `json:"offset"`
+}
+
+// MaintenanceDetailResponse represents a maintenance request with its change history and photos
+type MaintenanceDetailResponse struct {
+	Request *domain.MaintenanceRequest       `json:"request"`
+	History []domain.MaintenanceStatusChange `json:"history"`
+	Photos  MaintenancePhotoGroups           `json:"photos"`
+}
+
+// MaintenancePhotoResponse represents a maintenance photo with a temporary download URL
+type MaintenancePhotoResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Description string    `json:"description"`
+	IsBefore    bool      `json:"is_before"`
+	URL         string    `json:"url"` // presigned, valid until url_expires_at
+	URLExpires  time.Time `json:"url_expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MaintenancePhotoGroups splits a request's photos into before and after sets
+type MaintenancePhotoGroups struct {
+	Before []MaintenancePhotoResponse `json:"before"`
+	After  []MaintenancePhotoResponse `json:"after"`
+}
+
+// UploadMaintenancePhotosRequest represents one or more photos attached to a maintenance request
+type UploadMaintenancePhotosRequest struct {
+	Files       []*multipart.FileHeader
+	Description string
+	IsBefore    bool
+}
+
+func NewMaintenanceService(repos *repository.Repositories, s3Service *S3Service, config *config.Config) *MaintenanceService {
+	return &MaintenanceService{
+		repos:     repos,
+		s3Service: s3Service,
+		config:    config,
+	}
+}
+
+// SetAIService enables AI triage of requests filed by tenants
+func (s *MaintenanceService) SetAIService(aiService *AIService) {
+	s.aiService = aiService
+}
+
+// CreateRequest files a new maintenance request. Requests filed by tenants are
+// triaged by the AI in the background.
+func (s *MaintenanceService) CreateRequest(ctx context.Context, claims *domain.UserClaims, req *CreateMaintenanceRequest) (*MaintenanceDetailResponse, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	var property *domain.Property
+	switch claims.UserType {
+	case "tenant":
+		tenant, err := currentTenant(ctx, s.repos.Tenants, claims)
+		if err != nil {
+			return nil, err
+		}
+		property, err = s.repos.Properties.GetByCurrentTenant(ctx, landlordID, tenant.ID)
+		if errors.Is(err, ErrNotFound) || (err == nil && req.PropertyID != nil && *req.PropertyID != property.ID) {
+			return nil, newValidationError("you can only file maintenance requests for the property you rent")
+		}
+		if err != nil {
+			return nil, err
+		}
+	case "landlord":
+		if req.PropertyID == nil {
+			return nil, newValidationError("property_id is required")
+		}
+		if err := checkPropertyScope(claims, *req.PropertyID); err != nil {
+			return nil, err
+		}
+		property, err = s.repos.Properties.GetByID(ctx, landlordID, *req.PropertyID)
+		if err != nil {
+			return nil, err
+		}
+		if property.CurrentTenantID == nil {
+			return nil, newValidationError("maintenance requests can only be filed for a property with a current tenant")
+		}
+	default:
+		return nil, fmt.Errorf("%w: landlord or tenant privileges required", ErrForbidden)
+	}
+
+	priority := req.Priority
+	if priority == "" {
+		priority = "medium"
+	}
+
+	request := &domain.MaintenanceRequest{
+		LandlordID:    landlordID,
+		PropertyID:    property.ID,
+		TenantID:      *property.CurrentTenantID,
+		Title:         req.Title,
+		Description:   req.Description,
+		Priority:      priority,
+		Status:        domain.MaintenanceStatusOpen,
+		Category:      req.Category,
+		RequestedDate: time.Now(),
+	}
+	history := domain.MaintenanceStatusChange{
+		LandlordID:    landlordID,
+		ToStatus:      domain.MaintenanceStatusOpen,
+		ChangedBy:     claims.UserID,
+		ChangedByType: claims.UserType,
+	}
+
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repos.MaintenanceRequests.WithTx(tx).Create(ctx, request); err != nil {
+			return err
+		}
+		history.MaintenanceRequestID = request.ID
+		return s.repos.MaintenanceHistory.WithTx(tx).Create(ctx, &history)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.UserType == "tenant" && s.aiService != nil {
+		s.aiService.triageInBackground(request)
+	}
+
+	return &MaintenanceDetailResponse{
+		Request: request,
+		History: []domain.MaintenanceStatusChange{history},
+		Photos:  MaintenancePhotoGroups{Before: []MaintenancePhotoResponse{}, After: []MaintenancePhotoResponse{}},
+	}, nil
+}
+
+// GetRequest returns a maintenance request visible to the caller with its history
+func (s *MaintenanceService) GetRequest(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*MaintenanceDetailResponse, error) {
+	request, err := s.getVisibleRequest(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repos.MaintenanceHistory.ListByRequest(ctx, request.LandlordID, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := s.listPhotos(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &MaintenanceDetailResponse{Request: request, History: history, Photos: groupPhotos(photos)}, nil
+}
+
+// UploadPhotos stores photos for a maintenance request in S3 and records them.
+// Tenants may only add photos to their own requests while they are still
+// active, and their photos have the request triaged again. Contractors add
+// after photos to jobs they have accepted.
+func (s *MaintenanceService) UploadPhotos(ctx context.Context, claims *domain.UserClaims, requestID uuid.UUID, req *UploadMaintenancePhotosRequest) ([]MaintenancePhotoResponse, error) {
+	request, err := s.getVisibleRequest(ctx, claims, requestID)
+	if err != nil {
+		return nil, err
+	}
+	switch claims.UserType {
+	case "tenant":
+		if isTerminalMaintenanceStatus(request.Status) {
+			return nil, fmt.Errorf("%w: photos cannot be added to a %s request", ErrConflict, request.Status)
+		}
+	case "contractor":
+		if err := checkContractorCanWork(request); err != nil {
+			return nil, err
+		}
+		// Contractors document the finished work
+		req.IsBefore = false
+	}
+
+	if len(req.Files) == 0 {
+		return nil, newValidationError("at least one photo is required")
+	}
+	if len(req.Files) > maxPhotosPerUpload {
+		return nil, newValidationError(fmt.Sprintf("at most %d photos can be uploaded at once", maxPhotosPerUpload))
+	}
+	for _, file := range req.Files {
+		if err := validatePhotoFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	// Upload every file before storing any rows so a failed upload leaves
+	// nothing behind
+	photos := make([]*domain.MaintenancePhoto, 0, len(req.Files))
+	for _, file := range req.Files {
+		upload, err := s.s3Service.UploadFile(ctx, &FileUploadRequest{
+			File:          file,
+			LandlordID:    request.LandlordID.String(),
+			Category:      MaintenancePhotoCategory,
+			EntityID:      request.ID.String(),
+			Description:   req.Description,
+			IsBeforePhoto: req.IsBefore,
+		})
+		if err != nil {
+			s.deletePhotoObjects(ctx, request.LandlordID, photos)
+			return nil, err
+		}
+		photos = append(photos, &domain.MaintenancePhoto{
+			MaintenanceRequestID: request.ID,
+			PhotoURL:             upload.URL,
+			PhotoKey:             upload.FileKey,
+			Description:          req.Description,
+			IsBefore:             req.IsBefore,
+		})
+	}
+
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repos.MaintenancePhotos.WithTx(tx)
+		for _, photo := range photos {
+			if err := repo.Create(ctx, photo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Don't leave orphaned objects behind if the rows could not be stored
+		s.deletePhotoObjects(ctx, request.LandlordID, photos)
+		return nil, err
+	}
+
+	uploaded := make([]MaintenancePhotoResponse, 0, len(photos))
+	for _, photo := range photos {
+		response, err := s.photoResponse(ctx, photo)
+		if err != nil {
+			return nil, err
+		}
+		uploaded = append(uploaded, *response)
+	}
+	if claims.UserType == "tenant" && s.aiService != nil {
+		s.aiService.triageInBackground(request)
+	}
+	return uploaded, nil
+}
+
+// ListPhotos returns a maintenance request's photos grouped into before and after
+func (s *MaintenanceService) ListPhotos(ctx context.Context, claims *domain.UserClaims, requestID uuid.UUID) (*MaintenancePhotoGroups, error) {
+	request, err := s.getVisibleRequest(ctx, claims, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := s.listPhotos(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups := groupPhotos(photos)
+	return &groups, nil
+}
+
+// DeletePhoto removes a photo row and then its S3 object. An object that
+// cannot be removed once the row is gone is logged rather than failing the
+// request.
+func (s *MaintenanceService) DeletePhoto(ctx context.Context, claims *domain.UserClaims, requestID, photoID uuid.UUID) error {
+	landlordID, err := authorize(claims, authz.ResourceMaintenance, authz.ActionDelete)
+	if err != nil {
+		return err
+	}
+
+	request, err := s.repos.MaintenanceRequests.GetByID(ctx, landlordID, requestID)
+	if err != nil {
+		return err
+	}
+	if err := checkPropertyScope(claims, request.PropertyID); err != nil {
+		return err
+	}
+
+	photo, err := s.repos.MaintenancePhotos.GetByID(ctx, request.ID, photoID)
+	if err != nil {
+		return err
+	}
+	if err := s.repos.MaintenancePhotos.Delete(ctx, request.ID, photo.ID); err != nil {
+		return err
+	}
+	s.deletePhotoObjects(ctx, landlordID, []*domain.MaintenancePhoto{photo})
+	return nil
+}
+
+// deletePhotoObjects removes photos' S3 objects, logging any that could not be
+// removed
+func (s *MaintenanceService) deletePhotoObjects(ctx context.Context, landlordID uuid.UUID, photos []*domain.MaintenancePhoto) {
+	for _, photo := range photos {
+		err := s.s3Service.DeleteFile(context.WithoutCancel(ctx), &FileDeleteRequest{FileKey: photo.PhotoKey, LandlordID: landlordID.String()})
+		if err != nil {
+			log.Printf("Warning: failed to delete maintenance photo object %s: %v", photo.PhotoKey, err)
+		}
+	}
+}
+
+// listPhotos returns all photos of a request with presigned URLs
+func (s *MaintenanceService) listPhotos(ctx context.Context, requestID uuid.UUID) ([]MaintenancePhotoResponse, error) {
+	photos, _, err := s.repos.MaintenancePhotos.List(ctx,
+		repository.MaintenancePhotoFilter{MaintenanceRequestID: requestID},
+		repository.ListOptions{Limit: repository.MaxLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]MaintenancePhotoResponse, 0, len(photos))
+	for i := range photos {
+		response, err := s.photoResponse(ctx, &photos[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// photoResponse presigns a download URL for a stored photo
+func (s *MaintenanceService) photoResponse(ctx context.Context, photo *domain.MaintenancePhoto) (*MaintenancePhotoResponse, error) {
+	url, err := s.s3Service.GetSignedURL(ctx, photo.PhotoKey, photoURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+	return &MaintenancePhotoResponse{
+		ID:          photo.ID,
+		Description: photo.Description,
+		IsBefore:    photo.IsBefore,
+		URL:         url,
+		URLExpires:  time.Now().Add(photoURLExpiry),
+		CreatedAt:   photo.CreatedAt,
+	}, nil
+}
+
+// groupPhotos splits photos into before and after sets, preserving order
+func groupPhotos(photos []MaintenancePhotoResponse) MaintenancePhotoGroups {
+	groups := MaintenancePhotoGroups{
+		Before: []MaintenancePhotoResponse{},
+		After:  []MaintenancePhotoResponse{},
+	}
+	for _, photo := range photos {
+		if photo.IsBefore {
+			groups.Before = append(groups.Before, photo)
+		} else {
+			groups.After = append(groups.After, photo)
+		}
+	}
+	return groups
+}
+
+// validatePhotoFile checks an uploaded file's size and content type
+func validatePhotoFile(file *multipart.FileHeader) error {
+	if file.Size > maxPhotoSize {
+		return newValidationError(fmt.Sprintf("%s exceeds the %d MB photo limit", file.Filename, maxPhotoSize>>20))
+	}
+	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
+		return newValidationError(fmt.Sprintf("%s is not an image", file.Filename))
+	}
+	return nil
+}
+
+func isTerminalMaintenanceStatus(status string) bool {
+	return len(maintenanceTransitions[status]) == 0
+}
+
+// ListRequests returns maintenance requests visible to the caller
+func (s *MaintenanceService) ListRequests(ctx context.Context, claims *domain.UserClaims, req *MaintenanceListRequest) (*MaintenanceListResponse, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.MaintenanceRequestFilter{
+		LandlordID:  landlordID,
+		PropertyIDs: authz.PropertyScope(claims),
+		Status:      req.Status,
+		Priority:    req.Priority,
+		Category:    req.Category,
+	}
+	if req.PropertyID != "" {
+		propertyID, err := uuid.Parse(req.PropertyID)
+		if err != nil {
+			return nil, newValidationError("property_id must be a valid UUID")
+		}
+		filter.PropertyID = &propertyID
+	}
+	if req.ContractorID != "" {
+		contractorID, err := uuid.Parse(req.ContractorID)
+		if err != nil {
+			return nil, newValidationError("contractor_id must be a valid UUID")
+		}
+		filter.ContractorID = &contractorID
+	}
+	switch claims.UserType {
+	case "tenant":
+		tenant, err := currentTenant(ctx, s.repos.Tenants, claims)
+		if err != nil {
+			return nil, err
+		}
+		filter.TenantID = &tenant.ID
+	case "contractor":
+		contractor, err := currentContractor(ctx, s.repos.Contractors, claims)
+		if err != nil {
+			return nil, err
+		}
+		filter.ContractorID = &contractor.ID
+	}
+
+	requests, total, err := s.repos.MaintenanceRequests.List(ctx, filter, repository.ListOptions{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = repository.DefaultLimit
+	}
+	return &MaintenanceListResponse{
+		Requests: requests,
+		Total:    total,
+		Limit:    limit,
+		Offset:   req.Offset,
+	}, nil
+}
+
+// UpdateRequest applies a partial update and status transition to a maintenance
+// request, recording the change in its history. Tenants may only edit the
+// description of, or cancel, their own open requests.
+func (s *MaintenanceService) UpdateRequest(ctx context.Context, claims *domain.UserClaims, id uuid.UUID, req *UpdateMaintenanceRequest) (*MaintenanceDetailResponse, error) {
+	visible, err := s.getVisibleRequest(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+	switch claims.UserType {
+	case "tenant":
+		if err := checkTenantMaintenanceUpdate(visible, req); err != nil {
+			return nil, err
+		}
+	case "contractor":
+		return nil, fmt.Errorf("%w: contractors update jobs through the contractor portal", ErrForbidden)
+	}
+
+	var contractorID *uuid.UUID
+	if req.ContractorID != nil {
+		contractorID, err = s.resolveContractor(ctx, visible.LandlordID, *req.ContractorID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The request is re-read under a row lock so the transition and the
+	// history row are based on the status it is actually moving from
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		request, err := s.repos.MaintenanceRequests.WithTx(tx).GetByIDForUpdate(ctx, visible.LandlordID, id)
+		if err != nil {
+			return err
+		}
+		history, err := applyMaintenanceUpdate(claims, request, req, contractorID)
+		if err != nil || history == nil {
+			return err
+		}
+		if err := s.repos.MaintenanceRequests.WithTx(tx).Update(ctx, request); err != nil {
+			return err
+		}
+		return s.repos.MaintenanceHistory.WithTx(tx).Create(ctx, history)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRequest(ctx, claims, id)
+}
+
+// applyMaintenanceUpdate validates and applies req to a locked request. It
+// returns the history entry to record, or nil when nothing changed.
+func applyMaintenanceUpdate(claims *domain.UserClaims, request *domain.MaintenanceRequest, req *UpdateMaintenanceRequest, contractorID *uuid.UUID) (*domain.MaintenanceStatusChange, error) {
+	if claims.UserType == "tenant" {
+		if err := checkTenantMaintenanceUpdate(request, req); err != nil {
+			return nil, err
+		}
+	}
+
+	fromStatus := request.Status
+	changes := map[string]domain.FieldChange{}
+
+	if req.Status != nil && *req.Status != request.Status {
+		if err := validateMaintenanceTransition(request.Status, *req.Status); err != nil {
+			return nil, err
+		}
+		request.Status = *req.Status
+		if request.Status == domain.MaintenanceStatusCompleted {
+			now := time.Now()
+			request.CompletedDate = &now
+		}
+	}
+	if req.Title != nil && *req.Title != request.Title {
+		changes["title"] = domain.FieldChange{From: request.Title, To: *req.Title}
+		request.Title = *req.Title
+	}
+	if req.Description != nil && *req.Description != request.Description {
+		changes["description"] = domain.FieldChange{From: request.Description, To: *req.Description}
+		request.Description = *req.Description
+	}
+	if req.Priority != nil && *req.Priority != request.Priority {
+		changes["priority"] = domain.FieldChange{From: request.Priority, To: *req.Priority}
+		request.Priority = *req.Priority
+	}
+	if req.Category != nil && *req.Category != request.Category {
+		changes["category"] = domain.FieldChange{From: request.Category, To: *req.Category}
+		request.Category = *req.Category
+	}
+	if req.ContractorID != nil && !equalUUIDPtr(request.ContractorID, contractorID) {
+		changes["contractor_id"] = domain.FieldChange{From: request.ContractorID, To: contractorID}
+		request.ContractorID = contractorID
+		// A newly assigned contractor has to accept the job
+		request.ContractorStatus = ""
+		if contractorID != nil {
+			request.ContractorStatus = domain.ContractorStatusPending
+		}
+	}
+	if req.EstimatedCost != nil && !equalFloatPtr(request.EstimatedCost, req.EstimatedCost) {
+		changes["estimated_cost"] = domain.FieldChange{From: request.EstimatedCost, To: *req.EstimatedCost}
+		request.EstimatedCost = req.EstimatedCost
+	}
+	if req.ActualCost != nil && !equalFloatPtr(request.ActualCost, req.ActualCost) {
+		changes["actual_cost"] = domain.FieldChange{From: request.ActualCost, To: *req.ActualCost}
+		request.ActualCost = req.ActualCost
+	}
+	if req.Notes != nil && *req.Notes != request.Notes {
+		changes["notes"] = domain.FieldChange{From: request.Notes, To: *req.Notes}
+		request.Notes = *req.Notes
+	}
+
+	if request.Status == fromStatus && len(changes) == 0 {
+		return nil, nil
+	}
+
+	return &domain.MaintenanceStatusChange{
+		MaintenanceRequestID: request.ID,
+		LandlordID:           request.LandlordID,
+		FromStatus:           fromStatus,
+		ToStatus:             request.Status,
+		ChangedBy:            claims.UserID,
+		ChangedByType:        claims.UserType,
+		Note:                 req.Note,
+		Changes:              changes,
+	}, nil
+}
+
+// getVisibleRequest loads a maintenance request the caller may see. Tenants
+// only see requests filed for their own tenancy and contractors only the jobs
+// assigned to them.
+func (s *MaintenanceService) getVisibleRequest(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.MaintenanceRequest, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := s.repos.MaintenanceRequests.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch claims.UserType {
+	case "tenant":
+		tenant, err := currentTenant(ctx, s.repos.Tenants, claims)
+		if err != nil {
+			return nil, err
+		}
+		if request.TenantID != tenant.ID {
+			return nil, ErrNotFound
+		}
+	case "contractor":
+		contractor, err := currentContractor(ctx, s.repos.Contractors, claims)
+		if err != nil {
+			return nil, err
+		}
+		if request.ContractorID == nil || *request.ContractorID != contractor.ID {
+			return nil, ErrNotFound
+		}
+	default:
+		if err := checkPropertyScope(claims, request.PropertyID); err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
+
+// resolveContractor validates a contractor assignment; an empty ID unassigns
+func (s *MaintenanceService) resolveContractor(ctx context.Context, landlordID uuid.UUID, rawID string) (*uuid.UUID, error) {
+	if rawID == "" {
+		return nil, nil
+	}
+	contractorID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, newValidationError("contractor_id must be a valid UUID")
+	}
+
+	contractor, err := s.repos.Contractors.GetByID(ctx, landlordID, contractorID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, newValidationError("contractor not found")
+		}
+		return nil, err
+	}
+	if !contractor.IsActive {
+		return nil, newValidationError("contractor is inactive")
+	}
+	return &contractor.ID, nil
+}
+
+// checkTenantMaintenanceUpdate restricts tenants to editing or cancelling their open requests
+func checkTenantMaintenanceUpdate(request *domain.MaintenanceRequest, req *UpdateMaintenanceRequest) error {
+	if req.Priority != nil || req.ContractorID != nil || req.EstimatedCost != nil ||
+		req.ActualCost != nil || req.Notes != nil {
+		return fmt.Errorf("%w: tenants may only update the title, description and category", ErrForbidden)
+	}
+	if req.Status != nil && *req.Status != request.Status && *req.Status != domain.MaintenanceStatusCancelled {
+		return fmt.Errorf("%w: tenants may only cancel maintenance requests", ErrForbidden)
+	}
+	if request.Status != domain.MaintenanceStatusOpen {
+		return fmt.Errorf("%w: only open requests can be changed by tenants", ErrConflict)
+	}
+	return nil
+}
+
+// validateMaintenanceTransition rejects status changes not allowed by maintenanceTransitions
+func validateMaintenanceTransition(from, to string) error {
+	for _, allowed := range maintenanceTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot move maintenance request from %s to %s", ErrConflict, from, to)
+}
+
+func equalUUIDPtr(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"dwell/internal/domain"
+)
+
+func TestValidateMaintenanceTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{domain.MaintenanceStatusOpen, domain.MaintenanceStatusInProgress, true},
+		{domain.MaintenanceStatusOpen, domain.MaintenanceStatusCompleted, true},
+		{domain.MaintenanceStatusOpen, domain.MaintenanceStatusCancelled, true},
+		{domain.MaintenanceStatusInProgress, domain.MaintenanceStatusOpen, true},
+		{domain.MaintenanceStatusInProgress, domain.MaintenanceStatusCompleted, true},
+		{domain.MaintenanceStatusInProgress, domain.MaintenanceStatusCancelled, true},
+		{domain.MaintenanceStatusCompleted, domain.MaintenanceStatusOpen, false},
+		{domain.MaintenanceStatusCompleted, domain.MaintenanceStatusInProgress, false},
+		{domain.MaintenanceStatusCompleted, domain.MaintenanceStatusCancelled, false},
+		{domain.MaintenanceStatusCancelled, domain.MaintenanceStatusOpen, false},
+		{domain.MaintenanceStatusCancelled, domain.MaintenanceStatusCompleted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			err := validateMaintenanceTransition(tt.from, tt.to)
+			if tt.allowed && err != nil {
+				t.Errorf("expected transition to be allowed, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrConflict) {
+				t.Errorf("expected ErrConflict, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckTenantMaintenanceUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	cost := 100.0
+
+	tests := []struct {
+		name    string
+		status  string
+		req     UpdateMaintenanceRequest
+		wantErr error
+	}{
+		{"edit open request", domain.MaintenanceStatusOpen, UpdateMaintenanceRequest{Description: str("still leaking")}, nil},
+		{"cancel open request", domain.MaintenanceStatusOpen, UpdateMaintenanceRequest{Status: str(domain.MaintenanceStatusCancelled)}, nil},
+		{"complete request", domain.MaintenanceStatusOpen, UpdateMaintenanceRequest{Status: str(domain.MaintenanceStatusCompleted)}, ErrForbidden},
+		{"set cost", domain.MaintenanceStatusOpen, UpdateMaintenanceRequest{EstimatedCost: &cost}, ErrForbidden},
+		{"assign contractor", domain.MaintenanceStatusOpen, UpdateMaintenanceRequest{ContractorID: str("")}, ErrForbidden},
+		{"edit in-progress request", domain.MaintenanceStatusInProgress, UpdateMaintenanceRequest{Title: str("new title")}, ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &domain.MaintenanceRequest{Status: tt.status}
+			err := checkTenantMaintenanceUpdate(request, &tt.req)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestApplyMaintenanceUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	landlord := &domain.UserClaims{UserType: "landlord"}
+
+	t.Run("validates against the locked status", func(t *testing.T) {
+		// The request was cancelled after the caller last read it as open
+		request := &domain.MaintenanceRequest{Status: domain.MaintenanceStatusCancelled}
+		_, err := applyMaintenanceUpdate(landlord, request, &UpdateMaintenanceRequest{Status: str(domain.MaintenanceStatusInProgress)}, nil)
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("records the locked status as from_status", func(t *testing.T) {
+		request := &domain.MaintenanceRequest{Status: domain.MaintenanceStatusInProgress}
+		history, err := applyMaintenanceUpdate(landlord, request, &UpdateMaintenanceRequest{Status: str(domain.MaintenanceStatusCompleted)}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if history.FromStatus != domain.MaintenanceStatusInProgress || history.ToStatus != domain.MaintenanceStatusCompleted {
+			t.Errorf("history = %s -> %s", history.FromStatus, history.ToStatus)
+		}
+		if request.CompletedDate == nil {
+			t.Error("expected completed date to be set")
+		}
+	})
+
+	t.Run("rechecks tenant edits", func(t *testing.T) {
+		tenant := &domain.UserClaims{UserType: "tenant"}
+		request := &domain.MaintenanceRequest{Status: domain.MaintenanceStatusInProgress}
+		_, err := applyMaintenanceUpdate(tenant, request, &UpdateMaintenanceRequest{Title: str("new title")}, nil)
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		request := &domain.MaintenanceRequest{Status: domain.MaintenanceStatusOpen, Title: "Leak"}
+		history, err := applyMaintenanceUpdate(landlord, request, &UpdateMaintenanceRequest{Title: str("Leak")}, nil)
+		if err != nil || history != nil {
+			t.Errorf("expected no history, got %+v, %v", history, err)
+		}
+	})
+}
+
+func TestGroupPhotos(t *testing.T) {
+	photos := []MaintenancePhotoResponse{
+		{Description: "leak", IsBefore: true},
+		{Description: "fixed"},
+		{Description: "stain", IsBefore: true},
+	}
+
+	groups := groupPhotos(photos)
+	if len(groups.Before) != 2 || groups.Before[0].Description != "leak" || groups.Before[1].Description != "stain" {
+		t.Errorf("unexpected before photos: %+v", groups.Before)
+	}
+	if len(groups.After) != 1 || groups.After[0].Description != "fixed" {
+		t.Errorf("unexpected after photos: %+v", groups.After)
+	}
+
+	empty := groupPhotos(nil)
+	if empty.Before == nil || empty.After == nil {
+		t.Error("expected empty groups to be non-nil so they encode as []")
+	}
+}
+
+func TestCheckContractorCanWork(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           string
+		contractorStatus string
+		wantErr          bool
+	}{
+		{"accepted open job", domain.MaintenanceStatusOpen, domain.ContractorStatusAccepted, false},
+		{"accepted completed job", domain.MaintenanceStatusCompleted, domain.ContractorStatusAccepted, false},
+		{"pending job", domain.MaintenanceStatusOpen, domain.ContractorStatusPending, true},
+		{"cancelled job", domain.MaintenanceStatusCancelled, domain.ContractorStatusAccepted, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkContractorCanWork(&domain.MaintenanceRequest{Status: tt.status, ContractorStatus: tt.contractorStatus})
+			if tt.wantErr && !errors.Is(err, ErrConflict) {
+				t.Errorf("expected ErrConflict, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestContractorJobCost(t *testing.T) {
+	tests := []struct {
+		hours, rate, materials, want float64
+	}{
+		{2.5, 80, 0, 200},
+		{1.25, 65.5, 42.1, 123.98},
+		{3, 0, 15, 15},
+	}
+
+	for _, tt := range tests {
+		if got := contractorJobCost(tt.hours, tt.rate, tt.materials); got != tt.want {
+			t.Errorf("contractorJobCost(%v, %v, %v) = %v, want %v", tt.hours, tt.rate, tt.materials, got, tt.want)
+		}
+	}
+}
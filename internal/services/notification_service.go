@@ -35,6 +35,8 @@ type NotificationRequest struct {
 	RelatedEntityID   *uuid.UUID `json:"related_entity_id,omitempty"`
 	RelatedEntityType string     `json:"related_entity_type,omitempty"`
 	Priority          string     `json:"priority,omitempty"` // low, medium, high, urgent
+	// Variables fills type-specific template placeholders such as {{amount}}
+	Variables map[string]string `json:"variables,omitempty"`
 }
 
 type NotificationResponse struct {
@@ -248,9 +250,14 @@ func (s *NotificationService) getEmailTemplate(notificationType string, req *Not
 	variables := map[string]string{
 		"recipient_name": req.RecipientType,
 		"landlord_name":  "Property Management",
+		"title":          req.Title,
+		"message":        req.Message,
 		"date":           time.Now().Format("January 2, 2006"),
 		"time":           time.Now().Format("3:04 PM"),
 	}
+	for key, value := range req.Variables {
+		variables[key] = value
+	}
 
 	switch notificationType {
 	case "maintenance_request":
@@ -351,6 +358,52 @@ Please ensure your payment is submitted on time to avoid any late fees.`,
 			Variables: variables,
 		}
 
+	case "payment_overdue":
+		return &EmailTemplate{
+			Subject: "Payment Overdue - {{property_name}}",
+			HTMLBody: `
+				<!DOCTYPE html>
+				<html>
+				<head>
+					<style>
+						body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+						.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+						.header { background-color: #f8d7da; padding: 20px; border-radius: 5px; border: 1px solid #f5c6cb; }
+						.content { padding: 20px; }
+						.amount { font-size: 24px; font-weight: bold; color: #d63031; }
+					</style>
+				</head>
+				<body>
+					<div class="container">
+						<div class="header">
+							<h2>Payment Overdue</h2>
+						</div>
+						<div class="content">
+							<p>Hello {{recipient_name}},</p>
+							<p>{{message}}</p>
+							<p class="amount">Amount: ${{amount}}</p>
+							<p><strong>Due Date:</strong> {{due_date}}</p>
+							<p><strong>Property:</strong> {{property_name}}</p>
+							<p>Please submit your payment as soon as possible or contact your landlord.</p>
+						</div>
+					</div>
+				</body>
+				</html>`,
+			TextBody: `
+Payment Overdue
+
+Hello {{recipient_name}},
+
+{{message}}
+
+Amount: ${{amount}}
+Due Date: {{due_date}}
+Property: {{property_name}}
+
+Please submit your payment as soon as possible or contact your landlord.`,
+			Variables: variables,
+		}
+
 	default:
 		// Generic template
 		return &EmailTemplate{
@@ -396,6 +449,11 @@ Date: {{date}} at {{time}}`,
 func (s *NotificationService) getSMSTemplate(notificationType string, req *NotificationRequest) *SMSTemplate {
 	variables := map[string]string{
 		"recipient_name": req.RecipientType,
+		"title":          req.Title,
+		"message":        req.Message,
+	}
+	for key, value := range req.Variables {
+		variables[key] = value
 	}
 
 	switch notificationType {
@@ -483,3 +541,82 @@ func (s *NotificationService) SendPaymentNotification(ctx context.Context, payme
 	_, err := s.SendNotification(ctx, req)
 	return err
 }
+
+// SendPaymentOverdueNotification tells a tenant a charge is overdue. It is sent
+// as a high priority critical notification, so it also goes out by SMS.
+func (s *NotificationService) SendPaymentOverdueNotification(ctx context.Context, payment *domain.Payment, tenant *domain.Tenant, propertyName string) error {
+	outstanding := payment.Amount - payment.AmountPaid
+	req := &NotificationRequest{
+		Type:              "payment_overdue",
+		Title:             "Payment Overdue",
+		Message:           fmt.Sprintf("Your payment of $%.2f due %s is overdue.", outstanding, payment.DueDate.Format("January 2, 2006")),
+		LandlordID:        payment.LandlordID.String(),
+		RecipientID:       tenant.ID.String(),
+		RecipientType:     "tenant",
+		RecipientEmail:    tenant.Email,
+		RecipientPhone:    tenant.Phone,
+		RelatedEntityID:   &payment.ID,
+		RelatedEntityType: "payment",
+		Priority:          "high",
+		Variables: map[string]string{
+			"recipient_name": tenant.FirstName,
+			"amount":         fmt.Sprintf("%.2f", outstanding),
+			"due_date":       payment.DueDate.Format("January 2, 2006"),
+			"property_name":  propertyName,
+		},
+	}
+
+	_, err := s.SendNotification(ctx, req)
+	return err
+}
+
+// SendRentReminderNotification reminds a tenant of an upcoming charge
+func (s *NotificationService) SendRentReminderNotification(ctx context.Context, payment *domain.Payment, tenant *domain.Tenant, propertyName string) error {
+	outstanding := payment.Amount - payment.AmountPaid
+	req := &NotificationRequest{
+		Type:              "payment_due",
+		Title:             "Payment Due Reminder",
+		Message:           fmt.Sprintf("Your payment of $%.2f is due %s.", outstanding, payment.DueDate.Format("January 2, 2006")),
+		LandlordID:        payment.LandlordID.String(),
+		RecipientID:       tenant.ID.String(),
+		RecipientType:     "tenant",
+		RecipientEmail:    tenant.Email,
+		RecipientPhone:    tenant.Phone,
+		RelatedEntityID:   &payment.ID,
+		RelatedEntityType: "payment",
+		Priority:          "medium",
+		Variables: map[string]string{
+			"recipient_name": tenant.FirstName,
+			"amount":         fmt.Sprintf("%.2f", outstanding),
+			"due_date":       payment.DueDate.Format("January 2, 2006"),
+			"property_name":  propertyName,
+		},
+	}
+
+	_, err := s.SendNotification(ctx, req)
+	return err
+}
+
+// SendLeaseExpiryNotification tells a landlord a tenant's lease is ending
+func (s *NotificationService) SendLeaseExpiryNotification(ctx context.Context, tenant *domain.Tenant, landlord *domain.Landlord, daysLeft int) error {
+	req := &NotificationRequest{
+		Type:  "lease_expiring",
+		Title: "Lease Expiring",
+		Message: fmt.Sprintf("The lease of %s %s ends %s, in %d days.",
+			tenant.FirstName, tenant.LastName, tenant.LeaseEndDate.Format("January 2, 2006"), daysLeft),
+		LandlordID:        landlord.ID.String(),
+		RecipientID:       landlord.ID.String(),
+		RecipientType:     "landlord",
+		RecipientEmail:    landlord.Email,
+		RecipientPhone:    landlord.Phone,
+		RelatedEntityID:   &tenant.ID,
+		RelatedEntityType: "tenant",
+		Priority:          "medium",
+		Variables: map[string]string{
+			"recipient_name": landlord.FirstName,
+		},
+	}
+
+	_, err := s.SendNotification(ctx, req)
+	return err
+}
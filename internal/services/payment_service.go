@@ -0,0 +1,627 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+type PaymentService struct {
+	repos  *repository.Repositories
+	config *config.Config
+}
+
+// PaymentListRequest represents payment list filters and pagination
+type PaymentListRequest struct {
+	TenantID    string `form:"tenant_id" binding:"omitempty,uuid"`
+	PropertyID  string `form:"property_id" binding:"omitempty,uuid"`
+	Status      string `form:"status" binding:"omitempty,oneof=pending paid overdue cancelled"`
+	PaymentType string `form:"payment_type"`
+	DueFrom     string `form:"due_from" binding:"omitempty,datetime=2006-01-02"`
+	DueTo       string `form:"due_to" binding:"omitempty,datetime=2006-01-02"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset      int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// PaymentListResponse represents a page of payment charges
+type PaymentListResponse struct {
+	Payments []domain.Payment `json:"payments"`
+	Total    int              `json:"total"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+}
+
+// PaymentDetailResponse represents a charge with the payments received against it
+type PaymentDetailResponse struct {
+	Payment  *domain.Payment         `json:"payment"`
+	Receipts []domain.PaymentReceipt `json:"receipts"`
+	Balance  float64                 `json:"balance"` // amount still owed on this charge
+}
+
+// CreateChargeRequest represents a one-off charge such as a deposit or repair fee
+type CreateChargeRequest struct {
+	TenantID    uuid.UUID `json:"tenant_id" binding:"required"`
+	Amount      float64   `json:"amount" binding:"required,gt=0"`
+	PaymentType string    `json:"payment_type" binding:"required,oneof=rent security_deposit late_fee utility repair other"`
+	DueDate     string    `json:"due_date" binding:"required,datetime=2006-01-02"`
+	Notes       string    `json:"notes"`
+}
+
+// RecordPaymentRequest represents money received against a charge
+type RecordPaymentRequest struct {
+	Amount          float64    `json:"amount" binding:"required,gt=0"`
+	PaymentMethod   string     `json:"payment_method" binding:"required,oneof=bank_transfer credit_card debit_card check cash other"`
+	ReferenceNumber string     `json:"reference_number"`
+	ReceivedAt      *time.Time `json:"received_at"` // defaults to now
+	Notes           string     `json:"notes"`
+}
+
+// GenerateChargesRequest represents a request to generate rent charges from leases
+type GenerateChargesRequest struct {
+	TenantID *uuid.UUID `json:"tenant_id"` // all active tenants when omitted
+	Through  string     `json:"through" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// GenerateChargesResponse reports the rent charges created by a generation run
+type GenerateChargesResponse struct {
+	Created        []domain.Payment `json:"created"`
+	TenantsSkipped []uuid.UUID      `json:"tenants_skipped,omitempty"` // tenants without a current property
+	Through        string           `json:"through"`
+}
+
+// LedgerEntry is one line of a tenant's ledger
+type LedgerEntry struct {
+	Date        time.Time  `json:"date"`
+	Type        string     `json:"type"` // charge, payment
+	PaymentID   uuid.UUID  `json:"payment_id"`
+	ReceiptID   *uuid.UUID `json:"receipt_id,omitempty"`
+	PaymentType string     `json:"payment_type,omitempty"`
+	Description string     `json:"description"`
+	Charge      float64    `json:"charge"`
+	Credit      float64    `json:"credit"`
+	Balance     float64    `json:"balance"` // running balance owed after this entry
+}
+
+// LedgerResponse represents a tenant's ledger with running balances
+type LedgerResponse struct {
+	TenantID     uuid.UUID     `json:"tenant_id"`
+	Entries      []LedgerEntry `json:"entries"`
+	TotalCharged float64       `json:"total_charged"`
+	TotalPaid    float64       `json:"total_paid"`
+	Balance      float64       `json:"balance"`
+}
+
+// rentPeriod is one month (or partial month) of a lease
+type rentPeriod struct {
+	Start  time.Time
+	End    time.Time
+	Amount float64
+}
+
+func NewPaymentService(repos *repository.Repositories, config *config.Config) *PaymentService {
+	return &PaymentService{
+		repos:  repos,
+		config: config,
+	}
+}
+
+// ListPayments returns charges visible to the caller. Tenants only see their own.
+func (s *PaymentService) ListPayments(ctx context.Context, claims *domain.UserClaims, req *PaymentListRequest) (*PaymentListResponse, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.PaymentFilter{
+		LandlordID:  landlordID,
+		PropertyIDs: authz.PropertyScope(claims),
+		Status:      req.Status,
+		PaymentType: req.PaymentType,
+	}
+	if req.TenantID != "" {
+		id, err := uuid.Parse(req.TenantID)
+		if err != nil {
+			return nil, newValidationError("tenant_id must be a valid UUID")
+		}
+		filter.TenantID = &id
+	}
+	if req.PropertyID != "" {
+		id, err := uuid.Parse(req.PropertyID)
+		if err != nil {
+			return nil, newValidationError("property_id must be a valid UUID")
+		}
+		filter.PropertyID = &id
+	}
+	if req.DueFrom != "" {
+		from, err := time.Parse(dateLayout, req.DueFrom)
+		if err != nil {
+			return nil, newValidationError("due_from must be formatted as YYYY-MM-DD")
+		}
+		filter.DueFrom = &from
+	}
+	if req.DueTo != "" {
+		to, err := time.Parse(dateLayout, req.DueTo)
+		if err != nil {
+			return nil, newValidationError("due_to must be formatted as YYYY-MM-DD")
+		}
+		filter.DueTo = &to
+	}
+	if claims.UserType == "tenant" {
+		tenant, err := currentTenant(ctx, s.repos.Tenants, claims)
+		if err != nil {
+			return nil, err
+		}
+		filter.TenantID = &tenant.ID
+	}
+
+	payments, total, err := s.repos.Payments.List(ctx, filter, repository.ListOptions{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = repository.DefaultLimit
+	}
+	return &PaymentListResponse{
+		Payments: payments,
+		Total:    total,
+		Limit:    limit,
+		Offset:   req.Offset,
+	}, nil
+}
+
+// GetPayment returns a charge and its receipts
+func (s *PaymentService) GetPayment(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*PaymentDetailResponse, error) {
+	payment, err := s.getVisiblePayment(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := s.repos.PaymentReceipts.ListByPayment(ctx, payment.LandlordID, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentDetailResponse{
+		Payment:  payment,
+		Receipts: receipts,
+		Balance:  outstanding(payment),
+	}, nil
+}
+
+// CreateCharge adds a one-off charge for one of the landlord's tenants
+func (s *PaymentService) CreateCharge(ctx context.Context, claims *domain.UserClaims, req *CreateChargeRequest) (*domain.Payment, error) {
+	landlordID, err := authorize(claims, authz.ResourcePayments, authz.ActionCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	dueDate, err := time.Parse(dateLayout, req.DueDate)
+	if err != nil {
+		return nil, newValidationError("due_date must be formatted as YYYY-MM-DD")
+	}
+
+	tenant, err := s.repos.Tenants.GetByID(ctx, landlordID, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	property, err := s.repos.Properties.GetByCurrentTenant(ctx, landlordID, tenant.ID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, newValidationError("tenant does not currently rent a property")
+		}
+		return nil, err
+	}
+	if err := checkPropertyScope(claims, property.ID); err != nil {
+		return nil, err
+	}
+
+	payment := &domain.Payment{
+		LandlordID:  landlordID,
+		PropertyID:  property.ID,
+		TenantID:    tenant.ID,
+		Amount:      roundCents(req.Amount),
+		PaymentType: req.PaymentType,
+		DueDate:     dueDate,
+		Status:      domain.PaymentStatusPending,
+		Notes:       req.Notes,
+	}
+	if err := s.repos.Payments.Create(ctx, payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// RecordPayment records money received against a charge, marking it paid once
+// the full amount has been received
+func (s *PaymentService) RecordPayment(ctx context.Context, claims *domain.UserClaims, paymentID uuid.UUID, req *RecordPaymentRequest) (*PaymentDetailResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourcePayments, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	receivedAt := time.Now()
+	if req.ReceivedAt != nil {
+		receivedAt = *req.ReceivedAt
+	}
+	amount := roundCents(req.Amount)
+
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		payments := s.repos.Payments.WithTx(tx)
+		payment, err := payments.GetByIDForUpdate(ctx, landlordID, paymentID)
+		if err != nil {
+			return err
+		}
+		if err := checkPropertyScope(claims, payment.PropertyID); err != nil {
+			return err
+		}
+
+		switch payment.Status {
+		case domain.PaymentStatusCancelled:
+			return fmt.Errorf("%w: charge has been cancelled", ErrConflict)
+		case domain.PaymentStatusPaid:
+			return fmt.Errorf("%w: charge is already paid", ErrConflict)
+		}
+		if amount > outstanding(payment) {
+			return newValidationError(fmt.Sprintf("amount exceeds the outstanding balance of %.2f", outstanding(payment)))
+		}
+
+		receipt := &domain.PaymentReceipt{
+			LandlordID:      landlordID,
+			TenantID:        payment.TenantID,
+			PaymentID:       payment.ID,
+			Amount:          amount,
+			PaymentMethod:   req.PaymentMethod,
+			ReferenceNumber: req.ReferenceNumber,
+			ReceivedAt:      receivedAt,
+			Notes:           req.Notes,
+		}
+		if err := s.repos.PaymentReceipts.WithTx(tx).Create(ctx, receipt); err != nil {
+			return err
+		}
+
+		payment.AmountPaid = roundCents(payment.AmountPaid + amount)
+		payment.PaymentMethod = req.PaymentMethod
+		payment.ReferenceNumber = req.ReferenceNumber
+		if outstanding(payment) == 0 {
+			payment.Status = domain.PaymentStatusPaid
+			payment.PaidDate = &receivedAt
+		}
+		return payments.Update(ctx, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPayment(ctx, claims, paymentID)
+}
+
+// CancelCharge cancels a charge that has not received any payments
+func (s *PaymentService) CancelCharge(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.Payment, error) {
+	landlordID, err := authorize(claims, authz.ResourcePayments, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment *domain.Payment
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		payments := s.repos.Payments.WithTx(tx)
+
+		var err error
+		payment, err = payments.GetByIDForUpdate(ctx, landlordID, id)
+		if err != nil {
+			return err
+		}
+		if err := checkPropertyScope(claims, payment.PropertyID); err != nil {
+			return err
+		}
+		if payment.Status == domain.PaymentStatusCancelled {
+			return fmt.Errorf("%w: charge is already cancelled", ErrConflict)
+		}
+		if payment.AmountPaid > 0 {
+			return fmt.Errorf("%w: charges with recorded payments cannot be cancelled", ErrConflict)
+		}
+
+		payment.Status = domain.PaymentStatusCancelled
+		return payments.Update(ctx, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GenerateRentCharges creates the monthly rent charges due up to the
+// requested date for one or all of the landlord's active tenants. Periods
+// that already have a charge are skipped, so generation can be rerun safely.
+func (s *PaymentService) GenerateRentCharges(ctx context.Context, claims *domain.UserClaims, req *GenerateChargesRequest) (*GenerateChargesResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourcePayments, authz.ActionCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	through := endOfMonth(time.Now().UTC())
+	if req.Through != "" {
+		through, err = time.Parse(dateLayout, req.Through)
+		if err != nil {
+			return nil, newValidationError("through must be formatted as YYYY-MM-DD")
+		}
+	}
+
+	var tenants []domain.Tenant
+	if req.TenantID != nil {
+		tenant, err := s.repos.Tenants.GetByID(ctx, landlordID, *req.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTenantScope(ctx, s.repos.Properties, claims, landlordID, tenant.ID); err != nil {
+			return nil, err
+		}
+		if !tenant.IsActive {
+			return nil, newValidationError("tenant is inactive")
+		}
+		tenants = append(tenants, *tenant)
+	} else {
+		tenants, err = s.activeTenants(ctx, landlordID, authz.PropertyScope(claims))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.generateRentCharges(ctx, landlordID, tenants, through)
+}
+
+// GenerateDueRentCharges creates rent charges through the end of the current
+// month for every active tenant of a landlord. It is intended for scheduled runs.
+func (s *PaymentService) GenerateDueRentCharges(ctx context.Context, landlordID uuid.UUID) (*GenerateChargesResponse, error) {
+	tenants, err := s.activeTenants(ctx, landlordID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.generateRentCharges(ctx, landlordID, tenants, endOfMonth(time.Now().UTC()))
+}
+
+// GetLedger returns a tenant's charges and payments with running balances.
+// Tenants always get their own ledger; landlords must name the tenant.
+func (s *PaymentService) GetLedger(ctx context.Context, claims *domain.UserClaims, tenantID *uuid.UUID) (*LedgerResponse, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenant *domain.Tenant
+	if claims.UserType == "tenant" {
+		tenant, err = currentTenant(ctx, s.repos.Tenants, claims)
+	} else {
+		if err := authz.Authorize(claims, authz.ResourcePayments, authz.ActionRead); err != nil {
+			return nil, err
+		}
+		if tenantID == nil {
+			return nil, newValidationError("tenant_id is required")
+		}
+		tenant, err = s.repos.Tenants.GetByID(ctx, landlordID, *tenantID)
+		if err == nil {
+			err = checkTenantScope(ctx, s.repos.Properties, claims, landlordID, tenant.ID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := s.repos.Payments.ListByTenant(ctx, landlordID, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.repos.PaymentReceipts.ListByTenant(ctx, landlordID, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := buildLedger(charges, receipts)
+	ledger.TenantID = tenant.ID
+	return ledger, nil
+}
+
+// generateRentCharges creates missing rent charges for the given tenants
+func (s *PaymentService) generateRentCharges(ctx context.Context, landlordID uuid.UUID, tenants []domain.Tenant, through time.Time) (*GenerateChargesResponse, error) {
+	response := &GenerateChargesResponse{
+		Created: []domain.Payment{},
+		Through: through.Format(dateLayout),
+	}
+
+	for _, tenant := range tenants {
+		property, err := s.repos.Properties.GetByCurrentTenant(ctx, landlordID, tenant.ID)
+		if errors.Is(err, ErrNotFound) {
+			response.TenantsSkipped = append(response.TenantsSkipped, tenant.ID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, period := range rentPeriods(tenant.LeaseStartDate, tenant.LeaseEndDate, tenant.MonthlyRent, through) {
+			start, end := period.Start, period.End
+			charge := &domain.Payment{
+				LandlordID:  landlordID,
+				PropertyID:  property.ID,
+				TenantID:    tenant.ID,
+				Amount:      period.Amount,
+				PaymentType: "rent",
+				DueDate:     start,
+				Status:      domain.PaymentStatusPending,
+				Notes:       period.description(),
+				PeriodStart: &start,
+				PeriodEnd:   &end,
+			}
+			created, err := s.repos.Payments.CreateRentCharge(ctx, charge)
+			if err != nil {
+				return nil, err
+			}
+			if created {
+				response.Created = append(response.Created, *charge)
+			}
+		}
+	}
+	return response, nil
+}
+
+// activeTenants returns every active tenant of a landlord, limited to tenants
+// renting one of propertyIDs when it is non-nil
+func (s *PaymentService) activeTenants(ctx context.Context, landlordID uuid.UUID, propertyIDs []uuid.UUID) ([]domain.Tenant, error) {
+	active := true
+	filter := repository.TenantFilter{LandlordID: landlordID, PropertyIDs: propertyIDs, IsActive: &active}
+
+	var tenants []domain.Tenant
+	for offset := 0; ; offset += repository.MaxLimit {
+		page, total, err := s.repos.Tenants.List(ctx, filter, repository.ListOptions{Limit: repository.MaxLimit, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, page...)
+		if len(page) == 0 || len(tenants) >= total {
+			return tenants, nil
+		}
+	}
+}
+
+// getVisiblePayment loads a charge the caller may see
+func (s *PaymentService) getVisiblePayment(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.Payment, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := s.repos.Payments.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.UserType == "tenant" {
+		tenant, err := currentTenant(ctx, s.repos.Tenants, claims)
+		if err != nil {
+			return nil, err
+		}
+		if payment.TenantID != tenant.ID {
+			return nil, ErrNotFound
+		}
+	} else if err := checkPropertyScope(claims, payment.PropertyID); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// rentPeriods splits a lease into calendar-month rent periods starting on or
+// before through. Partial first and last months are prorated by day.
+func rentPeriods(leaseStart, leaseEnd time.Time, monthlyRent float64, through time.Time) []rentPeriod {
+	leaseStart, leaseEnd, through = truncateDay(leaseStart), truncateDay(leaseEnd), truncateDay(through)
+
+	var periods []rentPeriod
+	for month := startOfMonth(leaseStart); !month.After(leaseEnd); month = month.AddDate(0, 1, 0) {
+		start, end := month, endOfMonth(month)
+		if start.Before(leaseStart) {
+			start = leaseStart
+		}
+		if end.After(leaseEnd) {
+			end = leaseEnd
+		}
+		if start.After(through) {
+			break
+		}
+
+		amount := monthlyRent
+		daysInMonth := endOfMonth(month).Day()
+		if days := int(end.Sub(start).Hours()/24) + 1; days < daysInMonth {
+			amount = monthlyRent * float64(days) / float64(daysInMonth)
+		}
+		periods = append(periods, rentPeriod{Start: start, End: end, Amount: roundCents(amount)})
+	}
+	return periods
+}
+
+// description renders the ledger description of a rent period
+func (p rentPeriod) description() string {
+	if p.Start.Day() == 1 && p.End.Equal(endOfMonth(p.Start)) {
+		return "Rent for " + p.Start.Format("January 2006")
+	}
+	return fmt.Sprintf("Prorated rent for %s - %s", p.Start.Format("Jan 2"), p.End.Format("Jan 2, 2006"))
+}
+
+// buildLedger merges charges and receipts by date and computes running balances.
+// Charges sort before payments on the same day.
+func buildLedger(charges []domain.Payment, receipts []domain.PaymentReceipt) *LedgerResponse {
+	entries := make([]LedgerEntry, 0, len(charges)+len(receipts))
+	for _, c := range charges {
+		entries = append(entries, LedgerEntry{
+			Date:        c.DueDate,
+			Type:        "charge",
+			PaymentID:   c.ID,
+			PaymentType: c.PaymentType,
+			Description: c.Notes,
+			Charge:      c.Amount,
+		})
+	}
+	for _, r := range receipts {
+		receiptID := r.ID
+		entries = append(entries, LedgerEntry{
+			Date:        r.ReceivedAt,
+			Type:        "payment",
+			PaymentID:   r.PaymentID,
+			ReceiptID:   &receiptID,
+			Description: "Payment received (" + r.PaymentMethod + ")",
+			Credit:      r.Amount,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		di, dj := truncateDay(entries[i].Date), truncateDay(entries[j].Date)
+		if !di.Equal(dj) {
+			return di.Before(dj)
+		}
+		return entries[i].Type == "charge" && entries[j].Type != "charge"
+	})
+
+	ledger := &LedgerResponse{Entries: entries}
+	var balance float64
+	for i := range entries {
+		balance = roundCents(balance + entries[i].Charge - entries[i].Credit)
+		entries[i].Balance = balance
+		ledger.TotalCharged = roundCents(ledger.TotalCharged + entries[i].Charge)
+		ledger.TotalPaid = roundCents(ledger.TotalPaid + entries[i].Credit)
+	}
+	ledger.Balance = balance
+	return ledger
+}
+
+// outstanding returns the amount still owed on a charge
+func outstanding(p *domain.Payment) float64 {
+	return math.Max(0, roundCents(p.Amount-p.AmountPaid))
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func endOfMonth(t time.Time) time.Time {
+	return startOfMonth(t).AddDate(0, 1, -1)
+}
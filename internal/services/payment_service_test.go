@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func mustParseDate(s string) time.Time {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestRentPeriods(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		through    string
+		want       []rentPeriod
+	}{
+		{
+			name:  "full months",
+			start: "2026-01-01", end: "2026-12-31", through: "2026-03-31",
+			want: []rentPeriod{
+				{mustParseDate("2026-01-01"), mustParseDate("2026-01-31"), 1200},
+				{mustParseDate("2026-02-01"), mustParseDate("2026-02-28"), 1200},
+				{mustParseDate("2026-03-01"), mustParseDate("2026-03-31"), 1200},
+			},
+		},
+		{
+			name:  "prorated first month",
+			start: "2026-04-16", end: "2027-04-15", through: "2026-05-01",
+			want: []rentPeriod{
+				{mustParseDate("2026-04-16"), mustParseDate("2026-04-30"), 600},
+				{mustParseDate("2026-05-01"), mustParseDate("2026-05-31"), 1200},
+			},
+		},
+		{
+			name:  "prorated last month",
+			start: "2026-01-01", end: "2026-02-07", through: "2026-12-31",
+			want: []rentPeriod{
+				{mustParseDate("2026-01-01"), mustParseDate("2026-01-31"), 1200},
+				{mustParseDate("2026-02-01"), mustParseDate("2026-02-07"), 300},
+			},
+		},
+		{
+			name:  "lease starts after cut-off",
+			start: "2026-06-01", end: "2027-05-31", through: "2026-05-31",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rentPeriods(mustParseDate(tt.start), mustParseDate(tt.end), 1200, mustParseDate(tt.through))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d periods, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) || got[i].Amount != tt.want[i].Amount {
+					t.Errorf("period %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildLedger(t *testing.T) {
+	jan, feb := uuid.New(), uuid.New()
+	charges := []domain.Payment{
+		{BaseEntity: domain.BaseEntity{ID: jan}, Amount: 1000, PaymentType: "rent", DueDate: mustParseDate("2026-01-01")},
+		{BaseEntity: domain.BaseEntity{ID: feb}, Amount: 1000, PaymentType: "rent", DueDate: mustParseDate("2026-02-01")},
+	}
+	receipts := []domain.PaymentReceipt{
+		{PaymentID: jan, Amount: 600, PaymentMethod: "cash", ReceivedAt: mustParseDate("2026-01-05")},
+		{PaymentID: jan, Amount: 400, PaymentMethod: "check", ReceivedAt: mustParseDate("2026-02-01")},
+	}
+
+	ledger := buildLedger(charges, receipts)
+
+	wantBalances := []float64{1000, 400, 1400, 1000}
+	wantTypes := []string{"charge", "payment", "charge", "payment"}
+	if len(ledger.Entries) != len(wantBalances) {
+		t.Fatalf("got %d entries, want %d", len(ledger.Entries), len(wantBalances))
+	}
+	for i, e := range ledger.Entries {
+		if e.Type != wantTypes[i] || e.Balance != wantBalances[i] {
+			t.Errorf("entry %d = %s/%.2f, want %s/%.2f", i, e.Type, e.Balance, wantTypes[i], wantBalances[i])
+		}
+	}
+	if ledger.TotalCharged != 2000 || ledger.TotalPaid != 1000 || ledger.Balance != 1000 {
+		t.Errorf("totals = %.2f/%.2f/%.2f, want 2000/1000/1000", ledger.TotalCharged, ledger.TotalPaid, ledger.Balance)
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"dwell/internal/authz"
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type PropertyService struct {
+	repos  *repository.Repositories
+	config *config.Config
+}
+
+// CreatePropertyRequest represents a property creation request
+type CreatePropertyRequest struct {
+	Name            string  `json:"name" binding:"required"`
+	Address         string  `json:"address" binding:"required"`
+	City            string  `json:"city" binding:"required"`
+	State           string  `json:"state" binding:"required"`
+	ZipCode         string  `json:"zip_code" binding:"required"`
+	PropertyType    string  `json:"property_type" binding:"required,oneof=apartment house condo townhouse commercial other"`
+	Bedrooms        int     `json:"bedrooms" binding:"min=0"`
+	Bathrooms       int     `json:"bathrooms" binding:"min=0"`
+	SquareFootage   int     `json:"square_footage" binding:"min=0"`
+	YearBuilt       int     `json:"year_built" binding:"omitempty,min=1800"`
+	MonthlyRent     float64 `json:"monthly_rent" binding:"min=0"`
+	SecurityDeposit float64 `json:"security_deposit" binding:"min=0"`
+	IsAvailable     *bool   `json:"is_available"`
+}
+
+// UpdatePropertyRequest represents a partial property update; nil fields are left unchanged
+type UpdatePropertyRequest struct {
+	Name            *string  `json:"name" binding:"omitempty,min=1"`
+	Address         *string  `json:"address" binding:"omitempty,min=1"`
+	City            *string  `json:"city"`
+	State           *string  `json:"state"`
+	ZipCode         *string  `json:"zip_code"`
+	PropertyType    *string  `json:"property_type" binding:"omitempty,oneof=apartment house condo townhouse commercial other"`
+	Bedrooms        *int     `json:"bedrooms" binding:"omitempty,min=0"`
+	Bathrooms       *int     `json:"bathrooms" binding:"omitempty,min=0"`
+	SquareFootage   *int     `json:"square_footage" binding:"omitempty,min=0"`
+	YearBuilt       *int     `json:"year_built" binding:"omitempty,min=1800"`
+	MonthlyRent     *float64 `json:"monthly_rent" binding:"omitempty,min=0"`
+	SecurityDeposit *float64 `json:"security_deposit" binding:"omitempty,min=0"`
+	IsAvailable     *bool    `json:"is_available"`
+}
+
+// PropertyListRequest represents property list filters, sorting and cursor pagination
+type PropertyListRequest struct {
+	City         string `form:"city"`
+	State        string `form:"state"`
+	PropertyType string `form:"property_type"`
+	IsAvailable  *bool  `form:"is_available"`
+	SortBy       string `form:"sort_by" binding:"omitempty,oneof=created_at name city monthly_rent"`
+	Order        string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Cursor       string `form:"cursor"`
+}
+
+// PropertyListResponse represents a page of properties
+type PropertyListResponse struct {
+	Properties []domain.Property `json:"properties"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
+
+func NewPropertyService(repos *repository.Repositories, config *config.Config) *PropertyService {
+	return &PropertyService{
+		repos:  repos,
+		config: config,
+	}
+}
+
+// CreateProperty creates a property owned by the calling landlord
+func (s *PropertyService) CreateProperty(ctx context.Context, claims *domain.UserClaims, req *CreatePropertyRequest) (*domain.Property, error) {
+	landlordID, err := authorize(claims, authz.ResourceProperties, authz.ActionCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	property := &domain.Property{
+		LandlordID:      landlordID,
+		Name:            req.Name,
+		Address:         req.Address,
+		City:            req.City,
+		State:           req.State,
+		ZipCode:         req.ZipCode,
+		PropertyType:    req.PropertyType,
+		Bedrooms:        req.Bedrooms,
+		Bathrooms:       req.Bathrooms,
+		SquareFootage:   req.SquareFootage,
+		YearBuilt:       req.YearBuilt,
+		MonthlyRent:     req.MonthlyRent,
+		SecurityDeposit: req.SecurityDeposit,
+		IsAvailable:     true,
+	}
+	if req.IsAvailable != nil {
+		property.IsAvailable = *req.IsAvailable
+	}
+
+	if err := s.repos.Properties.Create(ctx, property); err != nil {
+		return nil, err
+	}
+	return property, nil
+}
+
+// GetProperty returns a property visible to the caller. Landlords can read any
+// of their properties, staff those assigned to them and tenants only the
+// property they currently rent.
+func (s *PropertyService) GetProperty(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.Property, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.UserType == "tenant" {
+		tenant, err := currentTenant(ctx, s.repos.Tenants, claims)
+		if err != nil {
+			return nil, err
+		}
+		property, err := s.repos.Properties.GetByCurrentTenant(ctx, landlordID, tenant.ID)
+		if err != nil {
+			return nil, err
+		}
+		if property.ID != id {
+			return nil, ErrNotFound
+		}
+		return property, nil
+	}
+
+	if err := checkPropertyScope(claims, id); err != nil {
+		return nil, err
+	}
+	return s.repos.Properties.GetByID(ctx, landlordID, id)
+}
+
+// ListProperties returns a page of properties visible to the caller
+func (s *PropertyService) ListProperties(ctx context.Context, claims *domain.UserClaims, req *PropertyListRequest) (*PropertyListResponse, error) {
+	landlordID, err := landlordScope(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.UserType == "tenant" {
+		return s.listTenantProperties(ctx, claims, landlordID)
+	}
+
+	filter := repository.PropertyFilter{
+		LandlordID:   landlordID,
+		IDs:          authz.PropertyScope(claims),
+		City:         req.City,
+		State:        req.State,
+		PropertyType: req.PropertyType,
+		IsAvailable:  req.IsAvailable,
+	}
+	page, err := s.repos.Properties.ListPage(ctx, filter, repository.PageOptions{
+		Limit:    req.Limit,
+		Cursor:   req.Cursor,
+		SortBy:   req.SortBy,
+		SortDesc: req.Order == "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PropertyListResponse{
+		Properties: page.Items,
+		NextCursor: page.NextCursor,
+		HasMore:    page.NextCursor != "",
+	}, nil
+}
+
+// listTenantProperties returns the single property a tenant currently rents, if any
+func (s *PropertyService) listTenantProperties(ctx context.Context, claims *domain.UserClaims, landlordID uuid.UUID) (*PropertyListResponse, error) {
+	tenant, err := currentTenant(ctx, s.repos.Tenants, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PropertyListResponse{Properties: []domain.Property{}}
+	property, err := s.repos.Properties.GetByCurrentTenant(ctx, landlordID, tenant.ID)
+	if err == nil {
+		response.Properties = append(response.Properties, *property)
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return response, nil
+}
+
+// UpdateProperty applies a partial update to one of the calling landlord's properties
+func (s *PropertyService) UpdateProperty(ctx context.Context, claims *domain.UserClaims, id uuid.UUID, req *UpdatePropertyRequest) (*domain.Property, error) {
+	landlordID, err := authorize(claims, authz.ResourceProperties, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPropertyScope(claims, id); err != nil {
+		return nil, err
+	}
+
+	property, err := s.repos.Properties.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		property.Name = *req.Name
+	}
+	if req.Address != nil {
+		property.Address = *req.Address
+	}
+	if req.City != nil {
+		property.City = *req.City
+	}
+	if req.State != nil {
+		property.State = *req.State
+	}
+	if req.ZipCode != nil {
+		property.ZipCode = *req.ZipCode
+	}
+	if req.PropertyType != nil {
+		property.PropertyType = *req.PropertyType
+	}
+	if req.Bedrooms != nil {
+		property.Bedrooms = *req.Bedrooms
+	}
+	if req.Bathrooms != nil {
+		property.Bathrooms = *req.Bathrooms
+	}
+	if req.SquareFootage != nil {
+		property.SquareFootage = *req.SquareFootage
+	}
+	if req.YearBuilt != nil {
+		property.YearBuilt = *req.YearBuilt
+	}
+	if req.MonthlyRent != nil {
+		property.MonthlyRent = *req.MonthlyRent
+	}
+	if req.SecurityDeposit != nil {
+		property.SecurityDeposit = *req.SecurityDeposit
+	}
+	if req.IsAvailable != nil {
+		if *req.IsAvailable && property.CurrentTenantID != nil {
+			return nil, newValidationError("a property with a current tenant cannot be marked available")
+		}
+		property.IsAvailable = *req.IsAvailable
+	}
+
+	if err := s.repos.Properties.Update(ctx, property); err != nil {
+		return nil, err
+	}
+	return property, nil
+}
+
+// DeleteProperty soft-deletes one of the calling landlord's properties
+func (s *PropertyService) DeleteProperty(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) error {
+	landlordID, err := authorize(claims, authz.ResourceProperties, authz.ActionDelete)
+	if err != nil {
+		return err
+	}
+	if err := checkPropertyScope(claims, id); err != nil {
+		return err
+	}
+
+	property, err := s.repos.Properties.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return err
+	}
+	if property.CurrentTenantID != nil {
+		return fmt.Errorf("%w: property has a current tenant", ErrConflict)
+	}
+
+	return s.repos.Properties.Delete(ctx, landlordID, id)
+}
@@ -2,21 +2,37 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"dwell/internal/authz"
 	"dwell/internal/aws"
 	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
 
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
 )
 
+// MaintenancePhotoCategory is the file category of photos uploaded through the
+// maintenance endpoints. Every object stored under it has a photo row, so the
+// generic file routes may not upload to it.
+const MaintenancePhotoCategory = "maintenance_request_photo"
+
+// legacyMaintenancePhotoCategory holds maintenance photos uploaded before they
+// had a category of their own
+const legacyMaintenancePhotoCategory = "maintenance_photo"
+
 type S3Service struct {
 	awsClients *aws.Clients
+	repos      *repository.Repositories
 	config     *config.Config
 }
 
@@ -82,13 +98,73 @@ type SignedURLResponse struct {
 	FileKey   string `json:"file_key"`
 }
 
-func NewS3Service(awsClients *aws.Clients, config *config.Config) *S3Service {
+func NewS3Service(awsClients *aws.Clients, repos *repository.Repositories, config *config.Config) *S3Service {
 	return &S3Service{
 		awsClients: awsClients,
+		repos:      repos,
 		config:     config,
 	}
 }
 
+// AuthorizeFiles checks that the caller may act on the files stored under an
+// entity. The files must belong to the caller's landlord account, and staff
+// limited to some properties only reach files of a property or tenant within
+// them. Maintenance photos have photo rows and are only reachable through the
+// maintenance request routes.
+func (s *S3Service) AuthorizeFiles(ctx context.Context, claims *domain.UserClaims, action authz.Action, landlordID, category, entityID string) error {
+	scope, err := authorize(claims, authz.ResourceFiles, action)
+	if err != nil {
+		return err
+	}
+	if landlordID != scope.String() {
+		return fmt.Errorf("%w: files belong to another landlord account", ErrForbidden)
+	}
+	if category == MaintenancePhotoCategory || category == legacyMaintenancePhotoCategory {
+		return fmt.Errorf("%w: maintenance photos are managed through the maintenance request routes", ErrForbidden)
+	}
+	if authz.PropertyScope(claims) == nil {
+		return nil
+	}
+
+	id, err := uuid.Parse(entityID)
+	if err != nil {
+		return ErrNotFound
+	}
+	propertyID, err := s.entityProperty(ctx, scope, id)
+	if err != nil {
+		return err
+	}
+	return checkPropertyScope(claims, propertyID)
+}
+
+// AuthorizeFileKey checks that the caller may act on a stored file, given its
+// key. Keys are laid out as landlordID/category/entityID/filename.
+func (s *S3Service) AuthorizeFileKey(ctx context.Context, claims *domain.UserClaims, action authz.Action, fileKey string) error {
+	parts := strings.SplitN(fileKey, "/", 4)
+	if len(parts) != 4 {
+		return newValidationError("file_key is not a stored file")
+	}
+	return s.AuthorizeFiles(ctx, claims, action, parts[0], parts[1], parts[2])
+}
+
+// entityProperty resolves the entity files are stored under to its property:
+// a property is its own, and a tenant's is the property they currently rent
+func (s *S3Service) entityProperty(ctx context.Context, landlordID, entityID uuid.UUID) (uuid.UUID, error) {
+	property, err := s.repos.Properties.GetByID(ctx, landlordID, entityID)
+	if err == nil {
+		return property.ID, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return uuid.Nil, err
+	}
+
+	property, err = s.repos.Properties.GetByCurrentTenant(ctx, landlordID, entityID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return property.ID, nil
+}
+
 // UploadFile uploads a file to S3
 func (s *S3Service) UploadFile(ctx context.Context, req *FileUploadRequest) (*FileUploadResponse, error) {
 	// Generate unique file key
@@ -149,7 +225,7 @@ func (s *S3Service) DeleteFile(ctx context.Context, req *FileDeleteRequest) erro
 
 // ListFiles lists files for a specific landlord, category, and entity
 func (s *S3Service) ListFiles(ctx context.Context, landlordID, category, entityID string) ([]FileInfo, error) {
-	prefix := s.generateFileKey(landlordID, category, entityID, "")
+	prefix := fileKeyPrefix(landlordID, category, entityID)
 
 	result, err := s.awsClients.GetS3Client().ListObjectsV2(ctx, &s3.ListObjectsV2Input{
 		Bucket: awssdk.String(s.config.AWS.S3.BucketName),
@@ -177,6 +253,32 @@ func (s *S3Service) ListFiles(ctx context.Context, landlordID, category, entityI
 	return files, nil
 }
 
+// forEachObjectPage calls fn with each page of objects stored under prefix
+func (s *S3Service) forEachObjectPage(ctx context.Context, prefix string, fn func(files []FileInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s.awsClients.GetS3Client(), &s3.ListObjectsV2Input{
+		Bucket: awssdk.String(s.config.AWS.S3.BucketName),
+		Prefix: awssdk.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list files from S3: %w", err)
+		}
+		files := make([]FileInfo, 0, len(page.Contents))
+		for _, obj := range page.Contents {
+			files = append(files, FileInfo{
+				FileKey:    awssdk.ToString(obj.Key),
+				Size:       awssdk.ToInt64(obj.Size),
+				UploadedAt: awssdk.ToTime(obj.LastModified),
+			})
+		}
+		if err := fn(files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetSignedURL generates a signed URL for temporary file access
 func (s *S3Service) GetSignedURL(ctx context.Context, fileKey string, expires time.Duration) (string, error) {
 	presignClient := s3.NewPresignClient(s.awsClients.GetS3Client())
@@ -206,12 +308,43 @@ func (s *S3Service) GetFileMetadata(ctx context.Context, fileKey string) (map[st
 	return result.Metadata, nil
 }
 
-// generateFileKey creates a unique file key for S3
+// DownloadFile reads a file from S3 along with its content type. Files larger
+// than maxSize bytes are rejected without being read.
+func (s *S3Service) DownloadFile(ctx context.Context, fileKey string, maxSize int64) ([]byte, string, error) {
+	result, err := s.awsClients.GetS3Client().GetObject(ctx, &s3.GetObjectInput{
+		Bucket: awssdk.String(s.config.AWS.S3.BucketName),
+		Key:    awssdk.String(fileKey),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download file from S3: %w", err)
+	}
+	defer result.Body.Close()
+
+	if result.ContentLength != nil && *result.ContentLength > maxSize {
+		return nil, "", fmt.Errorf("file %s is larger than %d bytes", fileKey, maxSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(result.Body, maxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file from S3: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", fmt.Errorf("file %s is larger than %d bytes", fileKey, maxSize)
+	}
+	return data, awssdk.ToString(result.ContentType), nil
+}
+
+// generateFileKey creates a unique file key for S3. The random suffix keeps
+// files with the same name uploaded in the same second apart.
 func (s *S3Service) generateFileKey(landlordID, category, entityID, filename string) string {
 	timestamp := time.Now().Format("20060102-150405")
 	ext := filepath.Ext(filename)
 	baseName := strings.TrimSuffix(filename, ext)
 
-	return fmt.Sprintf("%s/%s/%s/%s-%s%s",
-		landlordID, category, entityID, baseName, timestamp, ext)
+	return fmt.Sprintf("%s%s-%s-%s%s",
+		fileKeyPrefix(landlordID, category, entityID), baseName, timestamp, uuid.NewString()[:8], ext)
+}
+
+// fileKeyPrefix is the common prefix of the keys of an entity's files
+func fileKeyPrefix(landlordID, category, entityID string) string {
+	return fmt.Sprintf("%s/%s/%s/", landlordID, category, entityID)
 }
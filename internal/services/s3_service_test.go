@@ -0,0 +1,30 @@
+package services
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFileKey(t *testing.T) {
+	s := &S3Service{}
+	prefix := "landlord-1/maintenance_photo/request-1/"
+
+	first := s.generateFileKey("landlord-1", "maintenance_photo", "request-1", "sink.jpg")
+	second := s.generateFileKey("landlord-1", "maintenance_photo", "request-1", "sink.jpg")
+	if first == second {
+		t.Errorf("generateFileKey() returned %q twice for files with the same name", first)
+	}
+
+	for _, key := range []string{first, second} {
+		if !strings.HasPrefix(key, prefix) {
+			t.Errorf("generateFileKey() = %q, want prefix %q", key, prefix)
+		}
+		if !strings.HasPrefix(strings.TrimPrefix(key, prefix), "sink-") || filepath.Ext(key) != ".jpg" {
+			t.Errorf("generateFileKey() = %q, want the original name and extension", key)
+		}
+	}
+	if got := fileKeyPrefix("landlord-1", "maintenance_photo", "request-1"); got != prefix {
+		t.Errorf("fileKeyPrefix() = %q, want %q", got, prefix)
+	}
+}
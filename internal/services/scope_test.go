@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/database"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// These tests run against a real Postgres instance, like the repository
+// tests. Set TEST_DATABASE_URL to run them.
+func setupTestRepos(t *testing.T) *repository.Repositories {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping service integration tests")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	return repository.NewRepositories(&database.Connection{DB: db})
+}
+
+// scopeFixture is a landlord account with two rented properties, each with an
+// open maintenance request, an overdue charge and a lease ending soon
+type scopeFixture struct {
+	landlordID uuid.UUID
+	properties [2]*domain.Property
+	tenants    [2]*domain.Tenant
+}
+
+func newScopeFixture(t *testing.T, repos *repository.Repositories) *scopeFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	landlord := &domain.Landlord{Email: uuid.NewString() + "@example.com", FirstName: "Jane", LastName: "Owner", IsActive: true}
+	if err := repos.Landlords.Create(ctx, landlord); err != nil {
+		t.Fatalf("failed to create landlord: %v", err)
+	}
+	f := &scopeFixture{landlordID: landlord.ID}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for i, name := range []string{"Maple", "Oak"} {
+		tenant := &domain.Tenant{
+			LandlordID:     landlord.ID,
+			Email:          uuid.NewString() + "@example.com",
+			FirstName:      name + "Tenant",
+			LastName:       "Renter",
+			LeaseStartDate: today.AddDate(-1, 0, 0),
+			LeaseEndDate:   today.AddDate(0, 0, 30),
+			MonthlyRent:    1500,
+			IsActive:       true,
+		}
+		if err := repos.Tenants.Create(ctx, tenant); err != nil {
+			t.Fatalf("failed to create tenant: %v", err)
+		}
+		property := &domain.Property{
+			LandlordID:      landlord.ID,
+			Name:            name + " House",
+			City:            "Austin",
+			PropertyType:    "house",
+			MonthlyRent:     1400,
+			CurrentTenantID: &tenant.ID,
+		}
+		if err := repos.Properties.Create(ctx, property); err != nil {
+			t.Fatalf("failed to create property: %v", err)
+		}
+		request := &domain.MaintenanceRequest{
+			LandlordID:    landlord.ID,
+			PropertyID:    property.ID,
+			TenantID:      tenant.ID,
+			Title:         name + " leak",
+			Priority:      "medium",
+			Status:        domain.MaintenanceStatusOpen,
+			RequestedDate: today,
+		}
+		if err := repos.MaintenanceRequests.Create(ctx, request); err != nil {
+			t.Fatalf("failed to create maintenance request: %v", err)
+		}
+		charge := &domain.Payment{
+			LandlordID:  landlord.ID,
+			PropertyID:  property.ID,
+			TenantID:    tenant.ID,
+			Amount:      1500 + float64(i),
+			PaymentType: "rent",
+			DueDate:     today.AddDate(0, 0, -10),
+			Status:      domain.PaymentStatusOverdue,
+		}
+		if err := repos.Payments.Create(ctx, charge); err != nil {
+			t.Fatalf("failed to create charge: %v", err)
+		}
+		f.properties[i], f.tenants[i] = property, tenant
+	}
+	return f
+}
+
+func (f *scopeFixture) claims(role string, propertyIDs ...uuid.UUID) *domain.UserClaims {
+	return &domain.UserClaims{
+		UserID:      "user-" + role,
+		UserType:    "landlord",
+		Role:        role,
+		LandlordID:  &f.landlordID,
+		PropertyIDs: propertyIDs,
+	}
+}
+
+func TestLandlordGroundingScope(t *testing.T) {
+	repos := setupTestRepos(t)
+	f := newScopeFixture(t, repos)
+	service := &AIService{repos: repos, config: createTestConfig()}
+	maple := f.properties[0].ID
+
+	tests := []struct {
+		name     string
+		claims   *domain.UserClaims
+		want     []string
+		unwanted []string
+	}{
+		{
+			"owner sees the whole account",
+			f.claims(authz.RoleOwner),
+			[]string{"Maple House", "Oak House", "Maple leak", "Oak leak", "$1500.00", "$1501.00", "MapleTenant", "OakTenant"},
+			nil,
+		},
+		{
+			"manager only sees assigned properties",
+			f.claims(authz.RolePropertyManager, maple),
+			[]string{"Maple House", "Maple leak", "$1500.00", "MapleTenant"},
+			[]string{"Oak House", "Oak leak", "$1501.00", "OakTenant", f.properties[1].ID.String(), f.tenants[1].ID.String()},
+		},
+		{
+			"maintenance staff see no charges",
+			f.claims(authz.RoleMaintenanceStaff, maple),
+			[]string{"Maple House", "Maple leak"},
+			[]string{"Overdue charges", "$1500.00", "Oak"},
+		},
+		{
+			"bookkeeper sees no maintenance",
+			f.claims(authz.RoleBookkeeper, maple),
+			[]string{"Maple House", "$1500.00"},
+			[]string{"Open maintenance requests", "Maple leak", "Oak"},
+		},
+		{
+			"staff without assignments see nothing",
+			f.claims(authz.RolePropertyManager),
+			[]string{"Properties (0):"},
+			[]string{"Maple", "Oak"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller, err := service.resolveCaller(context.Background(), tt.claims)
+			if err != nil {
+				t.Fatalf("resolveCaller() error = %v", err)
+			}
+			got, err := service.groundingContext(context.Background(), caller, time.Now())
+			if err != nil {
+				t.Fatalf("groundingContext() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("grounding missing %q in:\n%s", want, got)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("grounding contains out-of-scope %q in:\n%s", unwanted, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAIHistoryFollowsTeamAssignments(t *testing.T) {
+	repos := setupTestRepos(t)
+	f := newScopeFixture(t, repos)
+	ctx := context.Background()
+	service := &AIService{repos: repos, config: createTestConfig()}
+
+	for i, name := range []string{"Maple", "Oak"} {
+		message := &domain.AIChatMessage{
+			LandlordID: f.landlordID,
+			TenantID:   &f.tenants[i].ID,
+			UserType:   "tenant",
+			Question:   name + " question",
+			TokensUsed: 100,
+		}
+		if err := repos.AIChatMessages.Create(ctx, message); err != nil {
+			t.Fatalf("failed to create chat message: %v", err)
+		}
+	}
+	landlordMessage := &domain.AIChatMessage{LandlordID: f.landlordID, UserType: "landlord", Question: "Portfolio question", TokensUsed: 100}
+	if err := repos.AIChatMessages.Create(ctx, landlordMessage); err != nil {
+		t.Fatalf("failed to create chat message: %v", err)
+	}
+
+	member := &domain.TeamMember{
+		LandlordID:  f.landlordID,
+		UserID:      uuid.NewString(),
+		Email:       uuid.NewString() + "@example.com",
+		Role:        authz.RolePropertyManager,
+		PropertyIDs: []uuid.UUID{f.properties[0].ID},
+	}
+	if err := repos.TeamMembers.Create(ctx, member); err != nil {
+		t.Fatalf("failed to create team member: %v", err)
+	}
+	claims := &domain.UserClaims{UserID: member.UserID, UserType: "landlord", LandlordID: &f.landlordID}
+	if err := NewTeamService(repos, nil, nil, createTestConfig()).ResolveMembership(ctx, claims); err != nil {
+		t.Fatalf("ResolveMembership() error = %v", err)
+	}
+
+	history, err := service.GetChatHistory(ctx, claims, &AIChatHistoryRequest{})
+	if err != nil {
+		t.Fatalf("GetChatHistory() error = %v", err)
+	}
+	if history.Total != 1 || len(history.Messages) != 1 || history.Messages[0].Question != "Maple question" {
+		t.Errorf("GetChatHistory() = %+v, want only the assigned property's tenant", history.Messages)
+	}
+
+	_, err = service.GetChatHistory(ctx, claims, &AIChatHistoryRequest{TenantID: f.tenants[1].ID.String()})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetChatHistory() for an unassigned tenant error = %v, want ErrNotFound", err)
+	}
+
+	analytics, err := service.GetAnalytics(ctx, claims, &AIAnalyticsRequest{})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+	if analytics.TotalQueries != 1 || analytics.TotalTokens != 100 {
+		t.Errorf("GetAnalytics() queries = %d, tokens = %d, want 1 and 100", analytics.TotalQueries, analytics.TotalTokens)
+	}
+	for _, topic := range analytics.PopularTopics {
+		if strings.Contains(topic, "oak") || strings.Contains(topic, "portfolio") {
+			t.Errorf("GetAnalytics() topics include out-of-scope %q", topic)
+		}
+	}
+
+	caller, err := service.resolveCaller(ctx, claims)
+	if err != nil {
+		t.Fatalf("resolveCaller() error = %v", err)
+	}
+	grounding, err := service.groundingContext(ctx, caller, time.Now())
+	if err != nil {
+		t.Fatalf("groundingContext() error = %v", err)
+	}
+	if !strings.Contains(grounding, "Maple House") || strings.Contains(grounding, "Oak") {
+		t.Errorf("grounding is not limited to the assigned property:\n%s", grounding)
+	}
+}
+
+func TestFileAccessFollowsPropertyScope(t *testing.T) {
+	repos := setupTestRepos(t)
+	f := newScopeFixture(t, repos)
+	ctx := context.Background()
+	service := NewS3Service(nil, repos, createTestConfig())
+	landlordID := f.landlordID.String()
+	owner := f.claims(authz.RoleOwner)
+	manager := f.claims(authz.RolePropertyManager, f.properties[0].ID)
+
+	tests := []struct {
+		name     string
+		claims   *domain.UserClaims
+		category string
+		entityID string
+		want     error
+	}{
+		{"manager reads an assigned property's files", manager, "property_photo", f.properties[0].ID.String(), nil},
+		{"manager reads an assigned tenant's files", manager, "document", f.tenants[0].ID.String(), nil},
+		{"manager cannot read another property's files", manager, "property_photo", f.properties[1].ID.String(), ErrNotFound},
+		{"manager cannot read another tenant's files", manager, "document", f.tenants[1].ID.String(), ErrNotFound},
+		{"manager cannot read files of an unknown entity", manager, "document", uuid.NewString(), ErrNotFound},
+		{"owner reads any property's files", owner, "property_photo", f.properties[1].ID.String(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.AuthorizeFiles(ctx, tt.claims, authz.ActionRead, landlordID, tt.category, tt.entityID)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("AuthorizeFiles() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	key := landlordID + "/document/" + f.tenants[1].ID.String() + "/lease.pdf"
+	if err := service.AuthorizeFileKey(ctx, manager, authz.ActionRead, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("AuthorizeFileKey(unassigned tenant) error = %v, want ErrNotFound", err)
+	}
+}
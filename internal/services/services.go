@@ -1,16 +1,32 @@
 package services
 
 import (
+	"context"
+	"log"
+
+	"dwell/internal/auth"
 	"dwell/internal/aws"
 	"dwell/internal/config"
 	"dwell/internal/database"
+	"dwell/internal/llm"
+	"dwell/internal/repository"
 )
 
 // Services holds all service instances
 type Services struct {
-	authService *AuthService
-	aiService   *AIService
-	s3Service   *S3Service
+	authService         *AuthService
+	aiService           *AIService
+	s3Service           *S3Service
+	propertyService     *PropertyService
+	tenantService       *TenantService
+	contractorService   *ContractorService
+	teamService         *TeamService
+	maintenanceService  *MaintenanceService
+	paymentService      *PaymentService
+	lateFeeService      *LateFeeService
+	notificationService *NotificationService
+	repos               *repository.Repositories
+	config              *config.Config
 	// Add other services as they are implemented
 }
 
@@ -22,18 +38,72 @@ func NewServices(cfg *config.Config, db *database.Connection) *Services {
 		panic(err) // This should be handled more gracefully in production
 	}
 
+	// Initialize the AI model provider
+	provider, err := llm.NewProvider(cfg, awsClients)
+	if err != nil {
+		panic(err) // This should be handled more gracefully in production
+	}
+
+	prices, err := llm.LoadPriceTable(cfg.AI.PriceTableFile)
+	if err != nil {
+		panic(err)
+	}
+	if err := prices.Check(provider.Model()); err != nil {
+		if cfg.AI.StrictPricing {
+			panic(err)
+		}
+		log.Printf("Warning: %v; AI costs will be recorded as 0. Add it with AI_PRICE_TABLE_FILE.", err)
+	}
+
+	// Initialize repositories
+	repos := repository.NewRepositories(db)
+	notificationService := NewNotificationService(awsClients, cfg)
+
+	// Initialize the identity provider
+	identity, err := auth.NewProvider(cfg, awsClients, repos.AuthUsers, ConfirmationCodeSender(notificationService))
+	if err != nil {
+		panic(err)
+	}
+
 	// Initialize individual services
-	authService := NewAuthService(awsClients, cfg)
-	aiService := NewAIService(awsClients, cfg)
-	s3Service := NewS3Service(awsClients, cfg)
+	authService := NewAuthService(identity, cfg)
+	s3Service := NewS3Service(awsClients, repos, cfg)
+	propertyService := NewPropertyService(repos, cfg)
+	tenantService := NewTenantService(repos, notificationService, cfg)
+	authService.SetTenantService(tenantService)
+	contractorService := NewContractorService(repos, notificationService, cfg)
+	authService.SetContractorService(contractorService)
+	teamService := NewTeamService(repos, identity, notificationService, cfg)
+	authService.SetTeamService(teamService)
+	maintenanceService := NewMaintenanceService(repos, s3Service, cfg)
+	paymentService := NewPaymentService(repos, cfg)
+	lateFeeService := NewLateFeeService(repos, notificationService, cfg)
+	aiService := NewAIService(provider, prices, repos, maintenanceService, paymentService, tenantService, notificationService, s3Service, cfg)
+	maintenanceService.SetAIService(aiService)
 
 	return &Services{
-		authService: authService,
-		aiService:   aiService,
-		s3Service:   s3Service,
+		authService:         authService,
+		aiService:           aiService,
+		s3Service:           s3Service,
+		propertyService:     propertyService,
+		tenantService:       tenantService,
+		contractorService:   contractorService,
+		teamService:         teamService,
+		maintenanceService:  maintenanceService,
+		paymentService:      paymentService,
+		lateFeeService:      lateFeeService,
+		notificationService: notificationService,
+		repos:               repos,
+		config:              cfg,
 	}
 }
 
+// Shutdown lets background work started by requests, such as AI triage,
+// finish until ctx is done
+func (s *Services) Shutdown(ctx context.Context) error {
+	return s.aiService.StopTriage(ctx)
+}
+
 // GetAuthService returns the auth service instance
 func (s *Services) GetAuthService() *AuthService {
 	return s.authService
@@ -48,3 +118,48 @@ func (s *Services) GetAIService() *AIService {
 func (s *Services) GetS3Service() *S3Service {
 	return s.s3Service
 }
+
+// GetPropertyService returns the property service instance
+func (s *Services) GetPropertyService() *PropertyService {
+	return s.propertyService
+}
+
+// GetTenantService returns the tenant service instance
+func (s *Services) GetTenantService() *TenantService {
+	return s.tenantService
+}
+
+// GetContractorService returns the contractor service instance
+func (s *Services) GetContractorService() *ContractorService {
+	return s.contractorService
+}
+
+// GetTeamService returns the team service instance
+func (s *Services) GetTeamService() *TeamService {
+	return s.teamService
+}
+
+// GetMaintenanceService returns the maintenance service instance
+func (s *Services) GetMaintenanceService() *MaintenanceService {
+	return s.maintenanceService
+}
+
+// GetPaymentService returns the payment service instance
+func (s *Services) GetPaymentService() *PaymentService {
+	return s.paymentService
+}
+
+// GetLateFeeService returns the late fee service instance
+func (s *Services) GetLateFeeService() *LateFeeService {
+	return s.lateFeeService
+}
+
+// GetNotificationService returns the notification service instance
+func (s *Services) GetNotificationService() *NotificationService {
+	return s.notificationService
+}
+
+// GetRepositories returns the repository layer shared by all services
+func (s *Services) GetRepositories() *repository.Repositories {
+	return s.repos
+}
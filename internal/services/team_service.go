@@ -0,0 +1,514 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"dwell/internal/auth"
+	"dwell/internal/authz"
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// teamInvitationTTL is how long an emailed staff invitation code stays valid
+const teamInvitationTTL = 7 * 24 * time.Hour
+
+// TeamService manages the staff of a landlord account. Staff sign in with
+// their own accounts and act within the landlord account with the role and
+// property assignments of their membership.
+type TeamService struct {
+	repos               *repository.Repositories
+	identity            auth.Provider
+	notificationService *NotificationService
+	config              *config.Config
+}
+
+// InviteTeamMemberRequest represents inviting a staff member into the landlord account
+type InviteTeamMemberRequest struct {
+	Email         string      `json:"email" binding:"required,email"`
+	Role          string      `json:"role" binding:"required,oneof=property_manager leasing_agent maintenance_staff bookkeeper"`
+	AllProperties bool        `json:"all_properties"`
+	PropertyIDs   []uuid.UUID `json:"property_ids"` // ignored when all_properties is set
+}
+
+// UpdateTeamMemberRequest represents changing a staff member's role or property
+// assignments. Omitted fields are left unchanged.
+type UpdateTeamMemberRequest struct {
+	Role          *string      `json:"role" binding:"omitempty,oneof=property_manager leasing_agent maintenance_staff bookkeeper"`
+	AllProperties *bool        `json:"all_properties"`
+	PropertyIDs   *[]uuid.UUID `json:"property_ids"`
+}
+
+// TransferOwnershipRequest names the member who becomes the account's owner
+type TransferOwnershipRequest struct {
+	MemberID uuid.UUID `json:"member_id" binding:"required"`
+}
+
+// TeamMemberListRequest represents team member filters and pagination
+type TeamMemberListRequest struct {
+	Role   string `form:"role"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// TeamMemberListResponse represents a page of team members
+type TeamMemberListResponse struct {
+	Members []domain.TeamMember `json:"members"`
+	Total   int                 `json:"total"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+}
+
+// TeamInvitationResponse describes an invitation that was emailed to a staff member
+type TeamInvitationResponse struct {
+	InvitationID uuid.UUID `json:"invitation_id"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// TransferOwnershipResponse holds the new owner and the former owner, who
+// stays on as a property manager for every property
+type TransferOwnershipResponse struct {
+	Owner         *domain.TeamMember `json:"owner"`
+	PreviousOwner *domain.TeamMember `json:"previous_owner"`
+}
+
+func NewTeamService(repos *repository.Repositories, identity auth.Provider, notificationService *NotificationService, config *config.Config) *TeamService {
+	return &TeamService{
+		repos:               repos,
+		identity:            identity,
+		notificationService: notificationService,
+		config:              config,
+	}
+}
+
+// ResolveMembership sets a landlord user's landlord account, role and property
+// assignments from their team membership. It runs on every request, so
+// removing a member or changing their assignments applies immediately.
+// Landlord users without a membership are refused: the landlord ID in their
+// token is an identity-provider attribute and is not trusted on its own.
+func (s *TeamService) ResolveMembership(ctx context.Context, claims *domain.UserClaims) error {
+	if claims.UserType != "landlord" {
+		return nil
+	}
+
+	member, err := s.repos.TeamMembers.GetByUserID(ctx, claims.UserID)
+	switch {
+	case err == nil:
+		claims.LandlordID = &member.LandlordID
+		claims.Role = member.Role
+		claims.AllProperties = member.AllProperties
+		claims.PropertyIDs = member.PropertyIDs
+		return nil
+	case !errors.Is(err, ErrNotFound):
+		return fmt.Errorf("failed to resolve team membership: %w", err)
+	}
+
+	if claims.LandlordID == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: not a member of this landlord account", ErrForbidden)
+}
+
+// ListMembers returns the landlord account's current members
+func (s *TeamService) ListMembers(ctx context.Context, claims *domain.UserClaims, req *TeamMemberListRequest) (*TeamMemberListResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceTeam, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.TeamMemberFilter{LandlordID: landlordID, Role: req.Role}
+	members, total, err := s.repos.TeamMembers.List(ctx, filter, repository.ListOptions{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = repository.DefaultLimit
+	}
+	return &TeamMemberListResponse{
+		Members: members,
+		Total:   total,
+		Limit:   limit,
+		Offset:  req.Offset,
+	}, nil
+}
+
+// GetMember returns one of the landlord account's current members
+func (s *TeamService) GetMember(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.TeamMember, error) {
+	landlordID, err := authorize(claims, authz.ResourceTeam, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.repos.TeamMembers.GetByID(ctx, landlordID, id)
+}
+
+// InviteMember emails a signup invitation to a staff member, replacing any
+// outstanding invitation to the same address
+func (s *TeamService) InviteMember(ctx context.Context, claims *domain.UserClaims, req *InviteTeamMemberRequest) (*TeamInvitationResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceTeam, authz.ActionCreate)
+	if err != nil {
+		return nil, err
+	}
+	propertyIDs, err := s.checkAssignments(ctx, landlordID, req.AllProperties, req.PropertyIDs)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.requireOwner(ctx, claims, landlordID); err != nil {
+		return nil, err
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &domain.TeamInvitation{
+		LandlordID:    landlordID,
+		Email:         strings.TrimSpace(req.Email),
+		Role:          req.Role,
+		AllProperties: req.AllProperties,
+		PropertyIDs:   propertyIDs,
+		InvitedBy:     claims.UserID,
+		CodeHash:      hashInviteCode(code),
+		ExpiresAt:     time.Now().Add(teamInvitationTTL),
+	}
+
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		invitations := s.repos.TeamInvitations.WithTx(tx)
+		if err := invitations.RevokePending(ctx, landlordID, invitation.Email); err != nil {
+			return err
+		}
+		return invitations.Create(ctx, invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.notificationService.SendNotification(ctx, &NotificationRequest{
+		Type:  "team_invitation",
+		Title: "You're invited to Dwell",
+		Message: fmt.Sprintf("You have been invited to join a property management team on Dwell as a %s. Sign up as a landlord "+
+			"with this email address and invitation code %s before %s.",
+			strings.ReplaceAll(invitation.Role, "_", " "), code, invitation.ExpiresAt.Format("January 2, 2006")),
+		LandlordID:        landlordID.String(),
+		RecipientID:       invitation.ID.String(),
+		RecipientType:     "landlord",
+		RecipientEmail:    invitation.Email,
+		RelatedEntityID:   &invitation.ID,
+		RelatedEntityType: "team_invitation",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send team invitation: %w", err)
+	}
+
+	return &TeamInvitationResponse{
+		InvitationID: invitation.ID,
+		Email:        invitation.Email,
+		Role:         invitation.Role,
+		ExpiresAt:    invitation.ExpiresAt,
+	}, nil
+}
+
+// ListInvitations returns the landlord account's outstanding staff invitations
+func (s *TeamService) ListInvitations(ctx context.Context, claims *domain.UserClaims) ([]domain.TeamInvitation, error) {
+	landlordID, err := authorize(claims, authz.ResourceTeam, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.repos.TeamInvitations.ListPending(ctx, landlordID)
+}
+
+// RevokeInvitation revokes an outstanding staff invitation
+func (s *TeamService) RevokeInvitation(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) error {
+	landlordID, err := authorize(claims, authz.ResourceTeam, authz.ActionDelete)
+	if err != nil {
+		return err
+	}
+	return s.repos.TeamInvitations.Revoke(ctx, landlordID, id)
+}
+
+// UpdateMember changes a staff member's role or property assignments. The
+// owner's membership only changes through an ownership transfer.
+func (s *TeamService) UpdateMember(ctx context.Context, claims *domain.UserClaims, id uuid.UUID, req *UpdateTeamMemberRequest) (*domain.TeamMember, error) {
+	landlordID, err := authorize(claims, authz.ResourceTeam, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repos.TeamMembers.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == authz.RoleOwner {
+		return nil, fmt.Errorf("%w: transfer ownership to change the owner's role", ErrConflict)
+	}
+
+	if req.Role != nil {
+		member.Role = *req.Role
+	}
+	if req.AllProperties != nil {
+		member.AllProperties = *req.AllProperties
+	}
+	if req.PropertyIDs != nil {
+		member.PropertyIDs = *req.PropertyIDs
+	}
+	member.PropertyIDs, err = s.checkAssignments(ctx, landlordID, member.AllProperties, member.PropertyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.TeamMembers.Update(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember removes a staff member from the landlord account and signs
+// them out everywhere. Their membership is checked on every request, so
+// tokens they still hold stop working straight away.
+func (s *TeamService) RemoveMember(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) error {
+	landlordID, err := authorize(claims, authz.ResourceTeam, authz.ActionDelete)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.repos.TeamMembers.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return err
+	}
+	if member.Role == authz.RoleOwner {
+		return fmt.Errorf("%w: transfer ownership before removing the owner", ErrConflict)
+	}
+	if err := s.repos.TeamMembers.Remove(ctx, landlordID, member.ID); err != nil {
+		return err
+	}
+
+	if err := s.identity.RevokeUser(ctx, member.UserID); err != nil {
+		log.Printf("Warning: removed team member %s but failed to revoke their sessions: %v", member.ID, err)
+	}
+	return nil
+}
+
+// TransferOwnership makes another member the owner of the landlord account.
+// The former owner stays on as a property manager for every property.
+func (s *TeamService) TransferOwnership(ctx context.Context, claims *domain.UserClaims, req *TransferOwnershipRequest) (*TransferOwnershipResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceOwnership, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.requireOwner(ctx, claims, landlordID)
+	if err != nil {
+		return nil, err
+	}
+	if owner.ID == req.MemberID {
+		return nil, newValidationError("you already own this account")
+	}
+
+	var member *domain.TeamMember
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		members := s.repos.TeamMembers.WithTx(tx)
+
+		var err error
+		member, err = members.GetByIDForUpdate(ctx, landlordID, req.MemberID)
+		if err != nil {
+			return err
+		}
+
+		// The former owner steps down first; an account has one owner at a time
+		owner.Role = authz.RolePropertyManager
+		owner.AllProperties = true
+		owner.PropertyIDs = nil
+		if err := members.Update(ctx, owner); err != nil {
+			return err
+		}
+
+		member.Role = authz.RoleOwner
+		member.AllProperties = true
+		member.PropertyIDs = nil
+		return members.Update(ctx, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TransferOwnershipResponse{Owner: member, PreviousOwner: owner}, nil
+}
+
+// ResolveInvitation looks up the pending invitation for a code and checks that
+// it was issued to the given email address
+func (s *TeamService) ResolveInvitation(ctx context.Context, code, email string) (*domain.TeamInvitation, error) {
+	invitation, err := s.repos.TeamInvitations.GetPendingByCodeHash(ctx, hashInviteCode(code))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, newValidationError("invitation code is invalid or has expired")
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(invitation.Email, strings.TrimSpace(email)) {
+		return nil, newValidationError("invitation code is invalid or has expired")
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation adds an identity-provider user to the landlord account with
+// the invitation's role and property assignments
+func (s *TeamService) AcceptInvitation(ctx context.Context, invitation *domain.TeamInvitation, userID, firstName, lastName string) error {
+	return s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repos.TeamInvitations.WithTx(tx).MarkAccepted(ctx, invitation.ID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("%w: invitation has already been used", ErrConflict)
+			}
+			return err
+		}
+
+		member := &domain.TeamMember{
+			LandlordID:    invitation.LandlordID,
+			UserID:        userID,
+			Email:         invitation.Email,
+			FirstName:     firstName,
+			LastName:      lastName,
+			Role:          invitation.Role,
+			AllProperties: invitation.AllProperties,
+			PropertyIDs:   invitation.PropertyIDs,
+		}
+		if err := s.repos.TeamMembers.WithTx(tx).Create(ctx, member); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return fmt.Errorf("%w: account already belongs to a landlord account", ErrConflict)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// CreateAccount creates the landlord account of an owner who signed up
+// without an invitation and records them as its owner
+func (s *TeamService) CreateAccount(ctx context.Context, landlord *domain.Landlord, userID string) error {
+	return s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repos.Landlords.WithTx(tx).Create(ctx, landlord); err != nil {
+			return err
+		}
+
+		owner := &domain.TeamMember{
+			LandlordID:    landlord.ID,
+			UserID:        userID,
+			Email:         landlord.Email,
+			FirstName:     landlord.FirstName,
+			LastName:      landlord.LastName,
+			Role:          authz.RoleOwner,
+			AllProperties: true,
+		}
+		if err := s.repos.TeamMembers.WithTx(tx).Create(ctx, owner); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return fmt.Errorf("%w: account already belongs to a landlord account", ErrConflict)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// requireOwner returns the owner's membership if the caller is the owner
+func (s *TeamService) requireOwner(ctx context.Context, claims *domain.UserClaims, landlordID uuid.UUID) (*domain.TeamMember, error) {
+	owner, err := s.repos.TeamMembers.GetOwner(ctx, landlordID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: the account has no owner", ErrForbidden)
+		}
+		return nil, err
+	}
+	if owner.UserID != claims.UserID {
+		return nil, fmt.Errorf("%w: only the account owner can manage the team", ErrForbidden)
+	}
+	return owner, nil
+}
+
+// BackfillOwners records the owners of landlord accounts that predate teams.
+// The owner is the confirmed identity-provider user whose verified email is
+// the landlord's email and whose landlord ID is the account's. Accounts
+// without such a user are logged and skipped.
+func (s *TeamService) BackfillOwners(ctx context.Context) (int, error) {
+	recorded := 0
+	err := forEachActiveLandlord(ctx, s.repos, func(landlord *domain.Landlord) error {
+		if _, err := s.repos.TeamMembers.GetOwner(ctx, landlord.ID); !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		info, err := s.identity.FindUser(ctx, landlord.Email)
+		if err != nil {
+			if errors.Is(err, auth.ErrUserNotFound) {
+				log.Printf("Warning: landlord %s has no owner: no confirmed user has the email %s", landlord.ID, landlord.Email)
+				return nil
+			}
+			return fmt.Errorf("failed to look up the owner of landlord %s: %w", landlord.ID, err)
+		}
+		if !ownsLandlord(info, landlord.ID) {
+			log.Printf("Warning: landlord %s has no owner: user %s with the email %s is not its landlord user", landlord.ID, info.UserID, landlord.Email)
+			return nil
+		}
+
+		owner := &domain.TeamMember{
+			LandlordID:    landlord.ID,
+			UserID:        info.UserID,
+			Email:         landlord.Email,
+			FirstName:     landlord.FirstName,
+			LastName:      landlord.LastName,
+			Role:          authz.RoleOwner,
+			AllProperties: true,
+		}
+		if err := s.repos.TeamMembers.Create(ctx, owner); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				log.Printf("Warning: landlord %s has no owner: user %s already belongs to a landlord account", landlord.ID, info.UserID)
+				return nil
+			}
+			return err
+		}
+		recorded++
+		return nil
+	})
+	return recorded, err
+}
+
+// ownsLandlord reports whether an identity-provider user is the landlord user
+// of an account. Cognito users may carry their type in a group instead of an
+// attribute, so a missing type is accepted.
+func ownsLandlord(info *domain.UserInfo, landlordID uuid.UUID) bool {
+	if info.UserType != "" && info.UserType != "landlord" {
+		return false
+	}
+	return info.LandlordID != nil && *info.LandlordID == landlordID
+}
+
+// checkAssignments validates a member's property assignments and returns the
+// properties to store: none when the member is assigned every property
+func (s *TeamService) checkAssignments(ctx context.Context, landlordID uuid.UUID, allProperties bool, propertyIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if allProperties {
+		return nil, nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(propertyIDs))
+	assigned := make([]uuid.UUID, 0, len(propertyIDs))
+	for _, id := range propertyIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.repos.Properties.GetByID(ctx, landlordID, id); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, newValidationError(fmt.Sprintf("property %s not found", id))
+			}
+			return nil, err
+		}
+		assigned = append(assigned, id)
+	}
+	return assigned, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"dwell/internal/authz"
+	"dwell/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestResolveMembershipLeavesOtherUsersAlone(t *testing.T) {
+	// Only landlord-side users have memberships, so no repository is needed
+	service := NewTeamService(nil, nil, nil, createTestConfig())
+	landlordID := uuid.New()
+
+	for _, userType := range []string{"tenant", "contractor"} {
+		t.Run(userType, func(t *testing.T) {
+			claims := &domain.UserClaims{UserID: "user-1", UserType: userType, LandlordID: &landlordID}
+			if err := service.ResolveMembership(context.Background(), claims); err != nil {
+				t.Fatalf("ResolveMembership() error = %v", err)
+			}
+			if claims.Role != "" || claims.AllProperties || claims.PropertyIDs != nil {
+				t.Errorf("ResolveMembership() changed claims to %+v", claims)
+			}
+			if authz.RoleOf(claims) != userType {
+				t.Errorf("RoleOf() = %q, want %q", authz.RoleOf(claims), userType)
+			}
+		})
+	}
+}
+
+func TestCheckAssignmentsAllProperties(t *testing.T) {
+	// Staff assigned every property store no property list, so none is looked up
+	service := NewTeamService(nil, nil, nil, createTestConfig())
+
+	assigned, err := service.checkAssignments(context.Background(), uuid.New(), true, []uuid.UUID{uuid.New()})
+	if err != nil {
+		t.Fatalf("checkAssignments() error = %v", err)
+	}
+	if assigned != nil {
+		t.Errorf("checkAssignments() = %v, want nil", assigned)
+	}
+}
+
+// newTeamAccount creates a landlord account owned by a new user
+func newTeamAccount(t *testing.T, service *TeamService) (*domain.Landlord, string) {
+	t.Helper()
+	landlord := &domain.Landlord{BaseEntity: domain.BaseEntity{ID: uuid.New()}, Email: uuid.NewString() + "@example.com", FirstName: "Jane", LastName: "Owner", IsActive: true}
+	ownerID := uuid.NewString()
+	if err := service.CreateAccount(context.Background(), landlord, ownerID); err != nil {
+		t.Fatalf("CreateAccount() error = %v", err)
+	}
+	return landlord, ownerID
+}
+
+func TestResolveMembershipRefusesNonMembers(t *testing.T) {
+	repos := setupTestRepos(t)
+	ctx := context.Background()
+	service := NewTeamService(repos, nil, nil, createTestConfig())
+
+	// Neither an account without an owner nor one with an owner trusts the
+	// landlord ID of a landlord user who is not a member
+	legacy := &domain.Landlord{Email: uuid.NewString() + "@example.com", IsActive: true}
+	if err := repos.Landlords.Create(ctx, legacy); err != nil {
+		t.Fatalf("failed to create landlord: %v", err)
+	}
+	owned, _ := newTeamAccount(t, service)
+
+	for _, landlordID := range []uuid.UUID{legacy.ID, owned.ID} {
+		claims := &domain.UserClaims{UserID: uuid.NewString(), UserType: "landlord", LandlordID: &landlordID}
+		if err := service.ResolveMembership(ctx, claims); !errors.Is(err, ErrForbidden) {
+			t.Errorf("ResolveMembership(landlord %s) error = %v, want ErrForbidden", landlordID, err)
+		}
+	}
+}
+
+func TestBackfillOwners(t *testing.T) {
+	repos := setupTestRepos(t)
+	ctx := context.Background()
+
+	legacy := &domain.Landlord{Email: uuid.NewString() + "@example.com", FirstName: "Lee", IsActive: true}
+	impersonated := &domain.Landlord{Email: uuid.NewString() + "@example.com", IsActive: true}
+	for _, landlord := range []*domain.Landlord{legacy, impersonated} {
+		if err := repos.Landlords.Create(ctx, landlord); err != nil {
+			t.Fatalf("failed to create landlord: %v", err)
+		}
+	}
+	otherID := uuid.New()
+	identity := &stubIdentity{users: map[string]*domain.UserInfo{
+		legacy.Email: {UserID: uuid.NewString(), UserType: "landlord", LandlordID: &legacy.ID},
+		// The user with this email belongs to a different account
+		impersonated.Email: {UserID: uuid.NewString(), UserType: "landlord", LandlordID: &otherID},
+	}}
+	service := NewTeamService(repos, identity, nil, createTestConfig())
+
+	recorded, err := service.BackfillOwners(ctx)
+	if err != nil {
+		t.Fatalf("BackfillOwners() error = %v", err)
+	}
+	if recorded != 1 {
+		t.Errorf("BackfillOwners() recorded %d owners, want 1", recorded)
+	}
+	owner, err := repos.TeamMembers.GetOwner(ctx, legacy.ID)
+	if err != nil {
+		t.Fatalf("owner not recorded: %v", err)
+	}
+	if owner.UserID != identity.users[legacy.Email].UserID || owner.Email != legacy.Email || owner.FirstName != "Lee" || !owner.AllProperties {
+		t.Errorf("owner = %+v", owner)
+	}
+	if _, err := repos.TeamMembers.GetOwner(ctx, impersonated.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetOwner(impersonated) error = %v, want ErrNotFound", err)
+	}
+
+	if recorded, err := service.BackfillOwners(ctx); err != nil || recorded != 0 {
+		t.Errorf("second BackfillOwners() = %d, %v, want 0, nil", recorded, err)
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	repos := setupTestRepos(t)
+	ctx := context.Background()
+	service := NewTeamService(repos, nil, nil, createTestConfig())
+	landlord, ownerID := newTeamAccount(t, service)
+
+	member := &domain.TeamMember{LandlordID: landlord.ID, UserID: uuid.NewString(), Email: uuid.NewString() + "@example.com", Role: authz.RolePropertyManager}
+	if err := repos.TeamMembers.Create(ctx, member); err != nil {
+		t.Fatalf("failed to create team member: %v", err)
+	}
+	resolve := func(userID string) *domain.UserClaims {
+		t.Helper()
+		claims := &domain.UserClaims{UserID: userID, UserType: "landlord"}
+		if err := service.ResolveMembership(ctx, claims); err != nil {
+			t.Fatalf("ResolveMembership(%s) error = %v", userID, err)
+		}
+		return claims
+	}
+
+	if _, err := service.TransferOwnership(ctx, resolve(member.UserID), &TransferOwnershipRequest{MemberID: member.ID}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("TransferOwnership(by staff) error = %v, want ErrForbidden", err)
+	}
+
+	resp, err := service.TransferOwnership(ctx, resolve(ownerID), &TransferOwnershipRequest{MemberID: member.ID})
+	if err != nil {
+		t.Fatalf("TransferOwnership() error = %v", err)
+	}
+	if resp.Owner.ID != member.ID || resp.Owner.Role != authz.RoleOwner || resp.PreviousOwner.UserID != ownerID {
+		t.Errorf("TransferOwnership() = owner %+v, previous %+v", resp.Owner, resp.PreviousOwner)
+	}
+
+	if claims := resolve(member.UserID); claims.Role != authz.RoleOwner || !claims.AllProperties {
+		t.Errorf("new owner claims = %+v", claims)
+	}
+	if claims := resolve(ownerID); claims.Role != authz.RolePropertyManager || !claims.AllProperties {
+		t.Errorf("previous owner claims = %+v, want a property manager for every property", claims)
+	}
+	if _, err := service.TransferOwnership(ctx, resolve(ownerID), &TransferOwnershipRequest{MemberID: resp.PreviousOwner.ID}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("TransferOwnership(by previous owner) error = %v, want ErrForbidden", err)
+	}
+}
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"dwell/internal/authz"
+	"dwell/internal/config"
+	"dwell/internal/domain"
+	"dwell/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// tenantInvitationTTL is how long an emailed invitation code stays valid
+	tenantInvitationTTL = 7 * 24 * time.Hour
+	// inviteCodeAlphabet omits characters that are easily confused (0/O, 1/I/L)
+	inviteCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	inviteCodeLength   = 10
+)
+
+type TenantService struct {
+	repos               *repository.Repositories
+	notificationService *NotificationService
+	config              *config.Config
+}
+
+// CreateTenantRequest represents adding a tenant to one of the landlord's properties
+type CreateTenantRequest struct {
+	PropertyID       uuid.UUID `json:"property_id" binding:"required"`
+	Email            string    `json:"email" binding:"required,email"`
+	FirstName        string    `json:"first_name" binding:"required"`
+	LastName         string    `json:"last_name" binding:"required"`
+	Phone            string    `json:"phone"`
+	EmergencyContact string    `json:"emergency_contact"`
+	LeaseStartDate   string    `json:"lease_start_date" binding:"required,datetime=2006-01-02"`
+	LeaseEndDate     string    `json:"lease_end_date" binding:"required,datetime=2006-01-02"`
+	MonthlyRent      *float64  `json:"monthly_rent" binding:"omitempty,min=0"`     // defaults to the property's rent
+	SecurityDeposit  *float64  `json:"security_deposit" binding:"omitempty,min=0"` // defaults to the property's deposit
+	SendInvite       bool      `json:"send_invite"`
+}
+
+// TenantListRequest represents tenant roster filters and pagination
+type TenantListRequest struct {
+	PropertyID            string `form:"property_id" binding:"omitempty,uuid"`
+	Email                 string `form:"email"`
+	Search                string `form:"search" binding:"max=100"`
+	IsActive              *bool  `form:"is_active"`
+	LeaseEndingWithinDays *int   `form:"lease_ending_within_days" binding:"omitempty,min=0,max=3650"`
+	Limit                 int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset                int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// TenantListResponse represents a page of tenants
+type TenantListResponse struct {
+	Tenants []domain.Tenant `json:"tenants"`
+	Total   int             `json:"total"`
+	Limit   int             `json:"limit"`
+	Offset  int             `json:"offset"`
+}
+
+// TenantDetailResponse represents a tenant together with the property they rent
+type TenantDetailResponse struct {
+	Tenant   *domain.Tenant   `json:"tenant"`
+	Property *domain.Property `json:"property,omitempty"`
+	// InviteSent is set when a new tenant was to be invited. If sending
+	// failed, the tenant still exists and can be invited again.
+	InviteSent *bool `json:"invite_sent,omitempty"`
+}
+
+// TenantInvitationResponse describes an invitation that was emailed to a tenant
+type TenantInvitationResponse struct {
+	InvitationID uuid.UUID `json:"invitation_id"`
+	TenantID     uuid.UUID `json:"tenant_id"`
+	Email        string    `json:"email"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func NewTenantService(repos *repository.Repositories, notificationService *NotificationService, config *config.Config) *TenantService {
+	return &TenantService{
+		repos:               repos,
+		notificationService: notificationService,
+		config:              config,
+	}
+}
+
+// CreateTenant adds a tenant to an available property and marks the property as let
+func (s *TenantService) CreateTenant(ctx context.Context, claims *domain.UserClaims, req *CreateTenantRequest) (*TenantDetailResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceTenants, authz.ActionCreate)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPropertyScope(claims, req.PropertyID); err != nil {
+		return nil, err
+	}
+
+	leaseStart, err := time.Parse(dateLayout, req.LeaseStartDate)
+	if err != nil {
+		return nil, newValidationError("lease_start_date must be formatted as YYYY-MM-DD")
+	}
+	leaseEnd, err := time.Parse(dateLayout, req.LeaseEndDate)
+	if err != nil {
+		return nil, newValidationError("lease_end_date must be formatted as YYYY-MM-DD")
+	}
+	if !leaseEnd.After(leaseStart) {
+		return nil, newValidationError("lease_end_date must be after lease_start_date")
+	}
+
+	tenant := &domain.Tenant{
+		LandlordID:       landlordID,
+		Email:            strings.TrimSpace(req.Email),
+		FirstName:        req.FirstName,
+		LastName:         req.LastName,
+		Phone:            req.Phone,
+		EmergencyContact: req.EmergencyContact,
+		LeaseStartDate:   leaseStart,
+		LeaseEndDate:     leaseEnd,
+		IsActive:         true,
+	}
+
+	var property *domain.Property
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		properties := s.repos.Properties.WithTx(tx)
+
+		var err error
+		property, err = properties.GetByID(ctx, landlordID, req.PropertyID)
+		if err != nil {
+			return err
+		}
+		if property.CurrentTenantID != nil {
+			return fmt.Errorf("%w: property already has a current tenant", ErrConflict)
+		}
+
+		tenant.MonthlyRent = property.MonthlyRent
+		if req.MonthlyRent != nil {
+			tenant.MonthlyRent = *req.MonthlyRent
+		}
+		tenant.SecurityDeposit = property.SecurityDeposit
+		if req.SecurityDeposit != nil {
+			tenant.SecurityDeposit = *req.SecurityDeposit
+		}
+
+		if err := s.repos.Tenants.WithTx(tx).Create(ctx, tenant); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return fmt.Errorf("%w: a tenant with this email already exists", ErrConflict)
+			}
+			return err
+		}
+
+		property.CurrentTenantID = &tenant.ID
+		property.IsAvailable = false
+		return properties.Update(ctx, property)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TenantDetailResponse{Tenant: tenant, Property: property}
+	if req.SendInvite {
+		// The tenant is already saved, so a failed invitation must not fail the request
+		sent := true
+		if _, err := s.sendInvitation(ctx, tenant); err != nil {
+			log.Printf("Warning: failed to invite tenant %s: %v", tenant.ID, err)
+			sent = false
+		}
+		response.InviteSent = &sent
+	}
+	return response, nil
+}
+
+// GetTenant returns one of the calling landlord's tenants and their current property
+func (s *TenantService) GetTenant(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*TenantDetailResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceTenants, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.repos.Tenants.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TenantDetailResponse{Tenant: tenant}
+	property, err := s.repos.Properties.GetByCurrentTenant(ctx, landlordID, tenant.ID)
+	if err == nil {
+		response.Property = property
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	// Staff limited to some properties only see the tenants renting them
+	if authz.PropertyScope(claims) != nil && (property == nil || !authz.CanAccessProperty(claims, property.ID)) {
+		return nil, ErrNotFound
+	}
+	return response, nil
+}
+
+// ListTenants returns the calling landlord's tenant roster
+func (s *TenantService) ListTenants(ctx context.Context, claims *domain.UserClaims, req *TenantListRequest) (*TenantListResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceTenants, authz.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.TenantFilter{
+		LandlordID:  landlordID,
+		PropertyIDs: authz.PropertyScope(claims),
+		Email:       req.Email,
+		Search:      strings.TrimSpace(req.Search),
+		IsActive:    req.IsActive,
+	}
+	if req.PropertyID != "" {
+		propertyID, err := uuid.Parse(req.PropertyID)
+		if err != nil {
+			return nil, newValidationError("property_id must be a valid UUID")
+		}
+		filter.PropertyID = &propertyID
+	}
+	if req.LeaseEndingWithinDays != nil {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		until := today.AddDate(0, 0, *req.LeaseEndingWithinDays)
+		filter.LeaseEndsAfter = &today
+		filter.LeaseEndsBefore = &until
+	}
+
+	opts := repository.ListOptions{Limit: req.Limit, Offset: req.Offset}
+	tenants, total, err := s.repos.Tenants.List(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = repository.DefaultLimit
+	}
+	return &TenantListResponse{
+		Tenants: tenants,
+		Total:   total,
+		Limit:   limit,
+		Offset:  req.Offset,
+	}, nil
+}
+
+// InviteTenant emails a signup invitation to a tenant, replacing any outstanding one
+func (s *TenantService) InviteTenant(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*TenantInvitationResponse, error) {
+	landlordID, err := authorize(claims, authz.ResourceTenants, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.repos.Tenants.GetByID(ctx, landlordID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTenantScope(ctx, s.repos.Properties, claims, landlordID, tenant.ID); err != nil {
+		return nil, err
+	}
+	if !tenant.IsActive {
+		return nil, fmt.Errorf("%w: tenant is inactive", ErrConflict)
+	}
+	if tenant.UserID != "" {
+		return nil, fmt.Errorf("%w: tenant has already signed up", ErrConflict)
+	}
+
+	return s.sendInvitation(ctx, tenant)
+}
+
+// DeactivateTenant marks a tenant inactive, frees their property and revokes
+// any outstanding invitations
+func (s *TenantService) DeactivateTenant(ctx context.Context, claims *domain.UserClaims, id uuid.UUID) (*domain.Tenant, error) {
+	landlordID, err := authorize(claims, authz.ResourceTenants, authz.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenant *domain.Tenant
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		tenants := s.repos.Tenants.WithTx(tx)
+		properties := s.repos.Properties.WithTx(tx)
+
+		var err error
+		tenant, err = tenants.GetByID(ctx, landlordID, id)
+		if err != nil {
+			return err
+		}
+		if err := checkTenantScope(ctx, properties, claims, landlordID, tenant.ID); err != nil {
+			return err
+		}
+		if !tenant.IsActive {
+			return fmt.Errorf("%w: tenant is already inactive", ErrConflict)
+		}
+
+		tenant.IsActive = false
+		if err := tenants.Update(ctx, tenant); err != nil {
+			return err
+		}
+
+		property, err := properties.GetByCurrentTenant(ctx, landlordID, tenant.ID)
+		switch {
+		case err == nil:
+			property.CurrentTenantID = nil
+			property.IsAvailable = true
+			if err := properties.Update(ctx, property); err != nil {
+				return err
+			}
+		case !errors.Is(err, ErrNotFound):
+			return err
+		}
+
+		return s.repos.TenantInvitations.WithTx(tx).RevokePending(ctx, landlordID, tenant.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// ResolveInvitation looks up the pending invitation for a code and checks that
+// it was issued to the given email address
+func (s *TenantService) ResolveInvitation(ctx context.Context, code, email string) (*domain.TenantInvitation, error) {
+	invitation, err := s.repos.TenantInvitations.GetPendingByCodeHash(ctx, hashInviteCode(code))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, newValidationError("invitation code is invalid or has expired")
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(invitation.Email, strings.TrimSpace(email)) {
+		return nil, newValidationError("invitation code is invalid or has expired")
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation links an identity-provider user to the invited tenant row
+func (s *TenantService) AcceptInvitation(ctx context.Context, invitation *domain.TenantInvitation, userID string) error {
+	return s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repos.TenantInvitations.WithTx(tx).MarkAccepted(ctx, invitation.ID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("%w: invitation has already been used", ErrConflict)
+			}
+			return err
+		}
+
+		tenants := s.repos.Tenants.WithTx(tx)
+		tenant, err := tenants.GetByID(ctx, invitation.LandlordID, invitation.TenantID)
+		if err != nil {
+			return err
+		}
+		tenant.UserID = userID
+		if err := tenants.Update(ctx, tenant); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return fmt.Errorf("%w: account is already linked to a tenant", ErrConflict)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// sendInvitation revokes outstanding invitations, stores a new one and emails its code
+func (s *TenantService) sendInvitation(ctx context.Context, tenant *domain.Tenant) (*TenantInvitationResponse, error) {
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &domain.TenantInvitation{
+		LandlordID: tenant.LandlordID,
+		TenantID:   tenant.ID,
+		Email:      tenant.Email,
+		CodeHash:   hashInviteCode(code),
+		ExpiresAt:  time.Now().Add(tenantInvitationTTL),
+	}
+
+	err = s.repos.RunInTx(ctx, func(tx *sql.Tx) error {
+		invitations := s.repos.TenantInvitations.WithTx(tx)
+		if err := invitations.RevokePending(ctx, tenant.LandlordID, tenant.ID); err != nil {
+			return err
+		}
+		return invitations.Create(ctx, invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.notificationService.SendNotification(ctx, &NotificationRequest{
+		Type:  "tenant_invitation",
+		Title: "You're invited to Dwell",
+		Message: fmt.Sprintf("Hi %s, your landlord has invited you to Dwell. Sign up as a tenant with this email address "+
+			"and invitation code %s before %s.", tenant.FirstName, code, invitation.ExpiresAt.Format("January 2, 2006")),
+		LandlordID:        tenant.LandlordID.String(),
+		RecipientID:       tenant.ID.String(),
+		RecipientType:     "tenant",
+		RecipientEmail:    tenant.Email,
+		RelatedEntityID:   &invitation.ID,
+		RelatedEntityType: "tenant_invitation",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tenant invitation: %w", err)
+	}
+
+	return &TenantInvitationResponse{
+		InvitationID: invitation.ID,
+		TenantID:     tenant.ID,
+		Email:        tenant.Email,
+		ExpiresAt:    invitation.ExpiresAt,
+	}, nil
+}
+
+// generateInviteCode returns a random human-typeable invitation code
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invitation code: %w", err)
+	}
+	code := make([]byte, inviteCodeLength)
+	for i, b := range buf {
+		code[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// hashInviteCode normalizes and hashes a code; only hashes are stored
+func hashInviteCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"dwell/internal/authz"
+	"dwell/internal/aws"
+	"dwell/internal/domain"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/google/uuid"
+)
+
+func TestGenerateInviteCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		code, err := generateInviteCode()
+		if err != nil {
+			t.Fatalf("generateInviteCode() error = %v", err)
+		}
+		if len(code) != inviteCodeLength {
+			t.Errorf("len(code) = %d, want %d", len(code), inviteCodeLength)
+		}
+		for _, r := range code {
+			if !strings.ContainsRune(inviteCodeAlphabet, r) {
+				t.Errorf("code %q contains %q outside the alphabet", code, r)
+			}
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashInviteCode(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"identical", "ABCDE23456", "ABCDE23456", true},
+		{"case insensitive", "abcde23456", "ABCDE23456", true},
+		{"surrounding whitespace", "  ABCDE23456\n", "ABCDE23456", true},
+		{"different codes", "ABCDE23456", "ABCDE23457", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashInviteCode(tt.a) == hashInviteCode(tt.b); got != tt.same {
+				t.Errorf("hashInviteCode(%q) == hashInviteCode(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+}
+
+func TestCreateTenantSurvivesFailedInvite(t *testing.T) {
+	repos := setupTestRepos(t)
+	ctx := context.Background()
+	cfg := createTestConfig()
+
+	landlord := &domain.Landlord{Email: uuid.NewString() + "@example.com", FirstName: "Jane", LastName: "Owner", IsActive: true}
+	if err := repos.Landlords.Create(ctx, landlord); err != nil {
+		t.Fatalf("failed to create landlord: %v", err)
+	}
+	property := &domain.Property{LandlordID: landlord.ID, Name: "Elm House", City: "Austin", PropertyType: "house", MonthlyRent: 1200, IsAvailable: true}
+	if err := repos.Properties.Create(ctx, property); err != nil {
+		t.Fatalf("failed to create property: %v", err)
+	}
+
+	// Nothing listens on the SES endpoint, so the invitation cannot be sent
+	unreachable := &aws.Clients{SES: ses.New(ses.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     awssdk.String("http://127.0.0.1:1"),
+		Credentials:      awssdk.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})}
+	service := NewTenantService(repos, NewNotificationService(unreachable, cfg), cfg)
+	claims := &domain.UserClaims{UserID: "owner-1", UserType: "landlord", Role: authz.RoleOwner, LandlordID: &landlord.ID}
+
+	resp, err := service.CreateTenant(ctx, claims, &CreateTenantRequest{
+		PropertyID:     property.ID,
+		Email:          uuid.NewString() + "@example.com",
+		FirstName:      "Sam",
+		LastName:       "Renter",
+		LeaseStartDate: "2026-01-01",
+		LeaseEndDate:   "2026-12-31",
+		SendInvite:     true,
+	})
+	if err != nil {
+		t.Fatalf("CreateTenant() error = %v, want the tenant despite the failed invitation", err)
+	}
+	if resp.InviteSent == nil || *resp.InviteSent {
+		t.Errorf("InviteSent = %v, want false", resp.InviteSent)
+	}
+	if _, err := repos.Tenants.GetByID(ctx, landlord.ID, resp.Tenant.ID); err != nil {
+		t.Errorf("tenant was not kept: %v", err)
+	}
+}
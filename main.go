@@ -2,16 +2,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"dwell/internal/config"
 	"dwell/internal/database"
 	"dwell/internal/router"
+	"dwell/internal/scheduler"
 	"dwell/internal/services"
 )
 
@@ -48,12 +51,53 @@ func main() {
 	}
 	defer db.Close()
 
+	// Handle the migrate subcommand: dwell migrate up|down [n]|status|redo
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// Apply pending migrations on boot when enabled
+	if cfg.Database.AutoMigrate {
+		if err := runMigrateCommand(db, []string{"up"}); err != nil {
+			log.Fatalf("Failed to apply migrations: %v", err)
+		}
+	}
+
 	// Initialize services
 	services := services.NewServices(cfg, db)
 
+	// Handle the team subcommand: dwell team backfill-owners
+	if len(os.Args) > 1 && os.Args[1] == "team" {
+		if err := runTeamCommand(services.GetTeamService(), os.Args[2:]); err != nil {
+			log.Fatalf("Team command failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize router
 	r := router.NewRouter(services)
 
+	// Register background jobs
+	sched := scheduler.New(db.GetDB())
+	if err := services.RegisterJobs(sched); err != nil {
+		log.Fatalf("Failed to register background jobs: %v", err)
+	}
+
+	// Handle the jobs subcommand: dwell jobs [history|run <name>]
+	if len(os.Args) > 1 && os.Args[1] == "jobs" {
+		if err := runJobsCommand(sched, os.Args[2:]); err != nil {
+			log.Fatalf("Jobs command failed: %v", err)
+		}
+		return
+	}
+
+	if cfg.Scheduler.Enabled {
+		sched.Start()
+	}
+
 	// Start server
 	srv := &http.Server{
 		Addr:    ":" + cfg.Server.Port,
@@ -82,6 +126,125 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// Let running jobs finish within the same shutdown window
+	if err := sched.Stop(ctx); err != nil {
+		log.Printf("Background jobs cancelled before finishing: %v", err)
+	}
+	if err := services.Shutdown(ctx); err != nil {
+		log.Printf("Background AI triage cancelled before finishing: %v", err)
+	}
+
 	log.Println("Server exited")
 }
 
+// runMigrateCommand executes a migration action against the database
+func runMigrateCommand(db *database.Connection, args []string) error {
+	migrator, err := database.NewMigrator(db.GetDB())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migration(s)", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("Rolled back %d migration(s)", rolledBack)
+	case "redo":
+		if err := migrator.Redo(ctx); err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			appliedAt := "pending"
+			if st.Applied {
+				appliedAt = "applied " + st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d  %-40s %s\n", st.Version, st.Name, appliedAt)
+		}
+	default:
+		return fmt.Errorf("unknown migrate action %q (expected up, down, status or redo)", action)
+	}
+
+	return nil
+}
+
+// runJobsCommand lists recent background job runs or runs a job immediately
+func runJobsCommand(sched *scheduler.Scheduler, args []string) error {
+	ctx := context.Background()
+	action := "history"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "history":
+		runs, err := sched.RecentRuns(ctx, 50)
+		if err != nil {
+			return err
+		}
+		for _, run := range runs {
+			duration := "-"
+			if run.FinishedAt != nil {
+				duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond).String()
+			}
+			fmt.Printf("%-20s %s  %-9s %8s  %s %s\n", run.JobName, run.StartedAt.Format(time.RFC3339),
+				run.Status, duration, run.Instance, run.Error)
+		}
+	case "run":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: jobs run <name>")
+		}
+		run, err := sched.RunNow(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return fmt.Errorf("job %s is already running or ran this minute", args[1])
+		}
+		if run.Status == scheduler.RunStatusFailed {
+			return fmt.Errorf("job %s failed: %s", args[1], run.Error)
+		}
+		log.Printf("Job %s finished in %s", args[1], run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond))
+	default:
+		return fmt.Errorf("unknown jobs action %q (expected history or run)", action)
+	}
+
+	return nil
+}
+
+// runTeamCommand runs team maintenance tasks
+func runTeamCommand(teamService *services.TeamService, args []string) error {
+	if len(args) == 0 || args[0] != "backfill-owners" {
+		return fmt.Errorf("usage: team backfill-owners")
+	}
+	recorded, err := teamService.BackfillOwners(context.Background())
+	if err != nil {
+		return err
+	}
+	log.Printf("Recorded %d account owner(s)", recorded)
+	return nil
+}